@@ -1,12 +1,33 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"main/internal/app"
 	"main/internal/config"
 	"main/internal/logger"
+	"main/internal/storage/migrator"
 	"os"
+	"os/signal"
+	"syscall"
 )
 
+const usage = `usage: server [command]
+
+commands:
+  serve                   start the server, it's the default command
+  migrate up              apply pending migrations
+  migrate down            roll back the last applied migration
+  migrate redo            roll back the last applied migration and apply it again
+  migrate status          print state of every migration
+  migrate create <name>   write an empty migration to migrations_path
+  db reset --confirm      roll back every migration and apply them again, all data is lost
+`
+
+var errUsage = errors.New("invalid usage")
+
 func main() {
 	// config init
 	cfg := config.MustLoad()
@@ -14,17 +35,53 @@ func main() {
 	// logger init
 	log := logger.New(cfg)
 
+	// command is stopped gracefully on interrupt or termination signal
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	var err error
+	switch command {
+	case "serve":
+		err = serve(ctx, cfg, log)
+	case "migrate":
+		err = migrate(ctx, cfg, log, args)
+	case "db":
+		err = db(ctx, cfg, log, args)
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		err = fmt.Errorf("%w: unknown command %q", errUsage, command)
+	}
+
+	if errors.Is(err, errUsage) {
+		fmt.Fprintf(os.Stderr, "%v\n\n%s", err, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Error("command failed", slog.String("command", command), "error", err)
+		os.Exit(1)
+	}
+}
+
+func serve(ctx context.Context, cfg *config.Config, log *slog.Logger) error {
 	// app init
 	myApp, err := app.New(cfg, log)
+	if errors.Is(err, migrator.ErrSchemaBehind) {
+		return fmt.Errorf("%w, run \"migrate up\" or enable auto_migrate", err)
+	}
 	if err != nil {
-		log.Error("failed to create app", "error", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to create app: %w", err)
 	}
 
 	// app start
-	err = myApp.Start()
-	if err != nil {
-		log.Error("failed to start app", "error", err)
-		os.Exit(1)
+	if err := myApp.Start(ctx); err != nil {
+		return fmt.Errorf("app stopped with error: %w", err)
 	}
+
+	return nil
 }
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"main/internal/app"
+	"main/internal/config"
+	"main/internal/storage/migrator"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// migrate runs migration subcommands against database of the configured storage driver
+func migrate(ctx context.Context, cfg *config.Config, log *slog.Logger, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: migrate needs a subcommand", errUsage)
+	}
+
+	subcommand, args := args[0], args[1:]
+
+	// create works with source tree only, database isn't needed
+	if subcommand == "create" {
+		if len(args) != 1 {
+			return fmt.Errorf("%w: migrate create needs a migration name", errUsage)
+		}
+
+		return migrator.Create(app.MigrationsDir(cfg), args[0])
+	}
+
+	switch subcommand {
+	case "up", "down", "redo", "status":
+	default:
+		return fmt.Errorf("%w: unknown migrate subcommand %q", errUsage, subcommand)
+	}
+
+	if len(args) > 0 {
+		return fmt.Errorf("%w: migrate %s takes no arguments", errUsage, subcommand)
+	}
+
+	return withMigrator(ctx, cfg, log, func(ctx context.Context, m *migrator.Migrator) error {
+		switch subcommand {
+		case "up":
+			return m.Up(ctx)
+		case "down":
+			return m.Down(ctx)
+		case "redo":
+			return m.Redo(ctx)
+		default:
+			return printStatus(ctx, m)
+		}
+	})
+}
+
+// db runs database subcommands, for now only reset
+func db(ctx context.Context, cfg *config.Config, log *slog.Logger, args []string) error {
+	if len(args) == 0 || args[0] != "reset" {
+		return fmt.Errorf("%w: db needs subcommand reset", errUsage)
+	}
+
+	flags := flag.NewFlagSet("db reset", flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	confirm := flags.Bool("confirm", false, "confirm that all data of the database is deleted")
+	if err := flags.Parse(args[1:]); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	if !*confirm {
+		return fmt.Errorf("%w: db reset deletes all data, rerun it with --confirm", errUsage)
+	}
+
+	return withMigrator(ctx, cfg, log, func(ctx context.Context, m *migrator.Migrator) error {
+		if err := m.Reset(ctx); err != nil {
+			return err
+		}
+
+		log.Info("database reset")
+
+		return m.Up(ctx)
+	})
+}
+
+func withMigrator(ctx context.Context, cfg *config.Config, log *slog.Logger, run func(ctx context.Context, m *migrator.Migrator) error) error {
+	m, err := app.NewMigrator(cfg, log)
+	if err != nil {
+		return err
+	}
+
+	return errors.Join(run(ctx, m), m.Close())
+}
+
+func printStatus(ctx context.Context, m *migrator.Migrator) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tSTATE\tAPPLIED AT\tMIGRATION")
+
+	for _, status := range statuses {
+		appliedAt := "-"
+		if !status.AppliedAt.IsZero() {
+			appliedAt = status.AppliedAt.UTC().Format(time.DateTime)
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Source.Version, status.State, appliedAt, status.Source.Path)
+	}
+
+	return w.Flush()
+}
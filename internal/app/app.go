@@ -2,51 +2,119 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"main/internal/config"
+	"main/internal/metrics"
+	"main/internal/models/user"
 	"main/internal/router"
-	"main/internal/storage/postgres"
+	"main/internal/scheduler"
+	"main/internal/storage/cache"
+	"main/internal/tracing"
+	"main/internal/webhook"
 	"net/http"
+	"sync"
 )
 
 type App struct {
-	config  *config.Config
-	logger  *slog.Logger
-	storage *postgres.Storage
-	router  *router.Router
+	config    *config.Config
+	logger    *slog.Logger
+	storage   Storage
+	cache     *cache.Storage
+	router    *router.Router
+	scheduler *scheduler.Scheduler
+	metrics   *metrics.Metrics
+	tracing   tracing.ShutdownFunc
 }
 
-func New(cfg *config.Config, log *slog.Logger) (*App, error) {
-	const op = "app.Start"
+func New(cfg *config.Config, log *slog.Logger) (_ *App, err error) {
+	const op = "app.New"
 
-	// init storage
-	storage, err := postgres.New(cfg, log)
+	// init tracing before anything which creates spans
+	shutdownTracing, err := tracing.New(context.Background(), cfg.Tracing)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	// init router and routes
-	router := router.New(cfg, log)
-	router.InitRoutes(storage, log, cfg)
-
-	return &App{
+	app := &App{
 		config:  cfg,
 		logger:  log,
-		storage: storage,
-		router:  router,
-	}, nil
+		tracing: shutdownTracing,
+	}
+
+	// release what is opened so far if init fails
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, app.close())
+		}
+	}()
+
+	// init storage, it's set only when opened, drivers return typed nil on failure
+	storage, err := newStorage(cfg, log)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	app.storage = storage
+
+	// grant admin role to configured users
+	if len(cfg.Authorization.AdminEmails) > 0 {
+		granted, err := app.storage.GrantRoleByEmails(context.Background(), cfg.Authorization.AdminEmails, user.RoleAdmin)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		log.Info("admin role granted", slog.Int("count", granted))
+	}
+
+	// init metrics, they are collected even if not exposed
+	app.metrics = metrics.New()
+	if db, ok := app.storage.(DBStatser); ok {
+		app.metrics.RegisterDBStats(db.Stats)
+	}
+	app.metrics.RegisterActiveUsers(app.storage.CountActiveUsers, cfg.HTTPServer.Timeout, log)
+
+	// init cache of notes, routes use it in place of storage
+	var routesStorage router.Storage = app.storage
+	if cfg.Cache.Enabled {
+		app.cache, err = newCache(cfg, app.storage, app.metrics, log)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		routesStorage = app.cache
+	}
+
+	// init router and routes
+	app.router, err = router.New(cfg, log, app.metrics)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	app.router.InitRoutes(routesStorage, log, cfg)
+
+	// init background jobs
+	app.scheduler = scheduler.New(app.storage, cfg.Jobs, log)
+	if err := registerJobs(app.scheduler, log, cfg, app.storage, app.metrics); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return app, nil
 }
 
-func (a *App) Start() error {
+// Start serves requests and runs background jobs until ctx is cancelled. Then
+// requests in flight are drained, jobs are stopped and connections are closed
+func (a *App) Start(ctx context.Context) error {
 	const op = "app.Start"
 
-	// init context
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// init jobs context, it's cancelled on shutdown
+	jobsCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
+
+	var jobs sync.WaitGroup
 
 	// init jobs
-	startTokensRevokingJob(ctx, a.logger, a.storage)
+	a.runJob(&jobs, func() { a.scheduler.Run(jobsCtx) })
+	a.runJob(&jobs, func() { webhook.NewDispatcher(a.storage, a.config.Webhooks, a.logger).Run(jobsCtx) })
 
 	a.logger.Info("starting server", slog.String("address", a.config.HTTPServer.Address))
 
@@ -59,6 +127,121 @@ func (a *App) Start() error {
 		WriteTimeout: a.config.HTTPServer.Timeout,
 	}
 
-	// start server
-	return fmt.Errorf("%s: %w", op, srv.ListenAndServe())
+	servers := []*http.Server{srv}
+
+	// init admin server, if metrics are bound to a separate listener
+	if a.config.Metrics.Enabled && a.config.Metrics.Address != "" {
+		a.logger.Info("starting metrics server", slog.String("address", a.config.Metrics.Address))
+
+		mux := http.NewServeMux()
+		mux.Handle(a.config.Metrics.Path, a.metrics.Handler())
+
+		servers = append(servers, &http.Server{
+			Addr:         a.config.Metrics.Address,
+			Handler:      mux,
+			ReadTimeout:  a.config.HTTPServer.Timeout,
+			IdleTimeout:  a.config.HTTPServer.IdleTimeout,
+			WriteTimeout: a.config.HTTPServer.Timeout,
+		})
+	}
+
+	// start servers
+	serveErr := make(chan error, len(servers))
+	for _, srv := range servers {
+		go func() {
+			serveErr <- srv.ListenAndServe()
+		}()
+	}
+
+	var err error
+
+	select {
+	case <-ctx.Done():
+		a.logger.Info("shutting down", slog.Duration("timeout", a.config.HTTPServer.ShutdownTimeout))
+
+		err = a.shutdown(servers...)
+	case err = <-serveErr:
+		err = errors.Join(fmt.Errorf("%s: %w", op, err), a.shutdown(servers...))
+	}
+
+	// stop jobs before closing connections they use
+	cancelJobs()
+	jobs.Wait()
+
+	if closeErr := a.close(); closeErr != nil {
+		err = errors.Join(err, closeErr)
+	}
+
+	if err == nil {
+		a.logger.Info("server stopped")
+	}
+
+	return err
+}
+
+func (a *App) runJob(jobs *sync.WaitGroup, job func()) {
+	jobs.Add(1)
+
+	go func() {
+		defer jobs.Done()
+
+		job()
+	}()
+}
+
+// shutdown stops accepting connections and waits for requests in flight,
+// the ones still running after timeout are cut off
+func (a *App) shutdown(servers ...*http.Server) error {
+	const op = "app.shutdown"
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.config.HTTPServer.ShutdownTimeout)
+	defer cancel()
+
+	var errs []error
+
+	for _, srv := range servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			_ = srv.Close()
+
+			errs = append(errs, fmt.Errorf("%s: %w", op, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// close releases connections and flushes spans, parts which are not
+// opened yet are skipped, so it's safe to call when New fails midway
+func (a *App) close() error {
+	const op = "app.close"
+
+	var errs []error
+
+	if a.router != nil {
+		if err := a.router.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: router: %w", op, err))
+		}
+	}
+
+	if a.cache != nil {
+		if err := a.cache.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: cache: %w", op, err))
+		}
+	}
+
+	if a.storage != nil {
+		if err := a.storage.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: storage: %w", op, err))
+		}
+	}
+
+	// flush spans which are not exported yet
+	ctx, cancel := context.WithTimeout(context.Background(), a.config.HTTPServer.ShutdownTimeout)
+	defer cancel()
+
+	if err := a.tracing(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("%s: tracing: %w", op, err))
+	}
+
+	return errors.Join(errs...)
 }
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"io"
+	"log/slog"
+	"main/internal/config"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+)
+
+// openFiles returns paths of files opened by the process
+func openFiles(t *testing.T) map[string]bool {
+	t.Helper()
+
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skipf("open files can't be listed: %v", err)
+	}
+
+	files := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if path, err := os.Readlink(filepath.Join("/proc/self/fd", entry.Name())); err == nil {
+			files[path] = true
+		}
+	}
+
+	return files
+}
+
+func TestNewReleasesOpenedOnFailure(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	dir := t.TempDir()
+
+	cfg := &config.Config{AutoMigrate: true}
+	cfg.Storage.Driver = "sqlite"
+	cfg.SQLite.Path = filepath.Join(dir, "notes.db")
+	cfg.Tracing = config.Tracing{
+		Enabled:     true,
+		ServiceName: "notes",
+		Exporter:    "file",
+		FilePath:    filepath.Join(dir, "spans.json"),
+		SampleRatio: 1,
+	}
+	// cache is initialized after storage, so storage and tracing are open when it fails
+	cfg.Cache.Enabled = true
+	cfg.Cache.Backend = "unknown"
+
+	if _, err := New(cfg, slog.New(slog.NewTextHandler(io.Discard, nil))); err == nil {
+		t.Fatal("New succeeded with unknown cache backend")
+	}
+
+	files := openFiles(t)
+	for _, path := range []string{cfg.SQLite.Path, cfg.Tracing.FilePath} {
+		if files[path] {
+			t.Errorf("%s is left open", filepath.Base(path))
+		}
+	}
+}
@@ -2,35 +2,109 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"main/internal/config"
+	"main/internal/scheduler"
 	"time"
 )
 
-type TokenRevoker interface {
-	DeleteExpiredRefreshTokens() (int, error)
+type Cleaner interface {
+	DeleteExpiredRefreshTokens(ctx context.Context) (int, error)
+	DeleteExpiredRevokedAccessTokens(ctx context.Context) (int, error)
+	DeleteExpiredOneTimeTokens(ctx context.Context) (int, error)
+	DeleteExpiredOIDCStates(ctx context.Context) (int, error)
+	DeleteExpiredWebAuthnSessions(ctx context.Context) (int, error)
+	DeleteStaleLoginAttempts(ctx context.Context, window time.Duration) (int, error)
+	DeleteExpiredWorkspaceInvitations(ctx context.Context) (int, error)
+	DeleteAuditEventsBefore(ctx context.Context, before time.Time) (int, error)
+	DeleteJobRunsBefore(ctx context.Context, before time.Time) (int, error)
 }
 
-func startTokensRevokingJob(ctx context.Context, log *slog.Logger, tokenRevoker TokenRevoker) {
-	log.Info("token revoking job started")
-
-	go func() {
-		ticker := time.NewTicker(1 * time.Minute)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ctx.Done():
-				log.Info("token revoking job stopped", "reason", ctx.Err())
-				return
-			case <-ticker.C:
-				revoked, err := tokenRevoker.DeleteExpiredRefreshTokens()
-				if err != nil {
-					log.Error("failed to revoke expired refresh tokens", "error", err)
-					continue
-				}
-
-				log.Info("revoked expired refresh tokens", "count", revoked)
+type DeletedRecordsCounter interface {
+	AddDeletedRecords(record string, count int)
+}
+
+// cleanup is a single step of a job, it deletes outdated records and returns their count.
+// Record is a short label the count is reported under
+type cleanup struct {
+	record string
+	name   string
+	run    func(ctx context.Context) (int, error)
+}
+
+// registerJobs registers background jobs, their schedules come from config
+func registerJobs(s *scheduler.Scheduler, log *slog.Logger, cfg *config.Config, cleaner Cleaner, counter DeletedRecordsCounter) error {
+	const op = "app.registerJobs"
+
+	jobs := map[string]scheduler.Func{
+		"expired_tokens_cleanup": cleanupJob(log, counter,
+			cleanup{"refresh_tokens", "expired refresh tokens", cleaner.DeleteExpiredRefreshTokens},
+			cleanup{"revoked_access_tokens", "expired access tokens denylist", cleaner.DeleteExpiredRevokedAccessTokens},
+			cleanup{"one_time_tokens", "expired one-time tokens", cleaner.DeleteExpiredOneTimeTokens},
+			cleanup{"oidc_states", "expired oidc states", cleaner.DeleteExpiredOIDCStates},
+			cleanup{"webauthn_sessions", "expired webauthn sessions", cleaner.DeleteExpiredWebAuthnSessions},
+		),
+		"login_attempts_cleanup": cleanupJob(log, counter,
+			cleanup{"login_attempts", "stale login attempts", func(ctx context.Context) (int, error) {
+				return cleaner.DeleteStaleLoginAttempts(ctx, cfg.Authorization.LoginProtection.FailureWindow)
+			}},
+		),
+		"workspace_invitations_cleanup": cleanupJob(log, counter,
+			cleanup{"workspace_invitations", "expired workspace invitations", cleaner.DeleteExpiredWorkspaceInvitations},
+		),
+		"audit_retention": cleanupJob(log, counter,
+			cleanup{"audit_events", "old audit events", retention(cfg.Audit.Retention, cleaner.DeleteAuditEventsBefore)},
+		),
+		"job_history_retention": cleanupJob(log, counter,
+			cleanup{"job_runs", "old job runs", retention(cfg.Jobs.HistoryRetention, cleaner.DeleteJobRunsBefore)},
+		),
+	}
+
+	for name, job := range jobs {
+		if err := s.Register(name, job); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+// cleanupJob runs all steps, failed step doesn't stop the following ones
+func cleanupJob(log *slog.Logger, counter DeletedRecordsCounter, steps ...cleanup) scheduler.Func {
+	return func(ctx context.Context) error {
+		var failed []error
+
+		for _, step := range steps {
+			if err := ctx.Err(); err != nil {
+				return err
 			}
+
+			count, err := step.run(ctx)
+			if err != nil {
+				failed = append(failed, fmt.Errorf("failed to delete %s: %w", step.name, err))
+				continue
+			}
+
+			log.Info("deleted "+step.name, "count", count)
+			counter.AddDeletedRecords(step.record, count)
+		}
+
+		if len(failed) > 0 {
+			return fmt.Errorf("%d of %d steps failed: %v", len(failed), len(steps), failed)
 		}
-	}()
+
+		return nil
+	}
+}
+
+// retention deletes records older than period, zero period keeps them forever
+func retention(period time.Duration, deleteBefore func(ctx context.Context, before time.Time) (int, error)) func(ctx context.Context) (int, error) {
+	return func(ctx context.Context) (int, error) {
+		if period <= 0 {
+			return 0, nil
+		}
+
+		return deleteBefore(ctx, time.Now().Add(-period))
+	}
 }
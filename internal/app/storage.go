@@ -0,0 +1,105 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"main/internal/config"
+	"main/internal/router"
+	"main/internal/scheduler"
+	"main/internal/storage/cache"
+	"main/internal/storage/memory"
+	"main/internal/storage/migrator"
+	"main/internal/storage/postgres"
+	"main/internal/storage/sqlite"
+	"main/internal/webhook"
+	"path/filepath"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const cacheKeyPrefix = "cache:"
+
+// Storage is everything the app needs from a storage backend
+type Storage interface {
+	router.Storage
+	Cleaner
+	scheduler.Store
+	webhook.DeliveryStore
+	GrantRoleByEmails(ctx context.Context, emails []string, role string) (int, error)
+	CountActiveUsers(ctx context.Context) (int, error)
+	Close() error
+}
+
+// DBStatser is a storage backed by connection pool
+type DBStatser interface {
+	Stats() sql.DBStats
+}
+
+// newStorage opens storage of the configured driver, postgres is the default
+func newStorage(cfg *config.Config, log *slog.Logger) (Storage, error) {
+	const op = "app.newStorage"
+
+	switch cfg.Storage.Driver {
+	case "", "postgres":
+		return postgres.New(cfg, log)
+	case "sqlite":
+		return sqlite.New(cfg, log)
+	case "memory":
+		log.Warn("memory storage is used, data is lost on restart")
+
+		return memory.New(), nil
+	default:
+		return nil, fmt.Errorf("%s: unknown storage driver %q", op, cfg.Storage.Driver)
+	}
+}
+
+// NewMigrator opens database of the configured driver for migration commands
+func NewMigrator(cfg *config.Config, log *slog.Logger) (*migrator.Migrator, error) {
+	const op = "app.NewMigrator"
+
+	switch cfg.Storage.Driver {
+	case "", "postgres":
+		return postgres.NewMigrator(cfg, log)
+	case "sqlite":
+		return sqlite.NewMigrator(cfg, log)
+	case "memory":
+		return nil, fmt.Errorf("%s: memory storage has no schema to migrate", op)
+	default:
+		return nil, fmt.Errorf("%s: unknown storage driver %q", op, cfg.Storage.Driver)
+	}
+}
+
+// MigrationsDir returns source directory of migrations of the configured driver
+func MigrationsDir(cfg *config.Config) string {
+	if cfg.Storage.Driver == "sqlite" {
+		return filepath.Join(cfg.MigrationsPath, "sqlite")
+	}
+
+	return cfg.MigrationsPath
+}
+
+// newCache wraps storage of routes with cache of the configured backend
+func newCache(cfg *config.Config, storage router.Storage, observer cache.LookupObserver, log *slog.Logger) (*cache.Storage, error) {
+	const op = "app.newCache"
+
+	var backend cache.Backend
+
+	switch cfg.Cache.Backend {
+	case "", "memory":
+		backend = cache.NewLRU(cfg.Cache.Size, cfg.Cache.TTL)
+	case "redis":
+		client := redis.NewClient(&redis.Options{
+			Addr:     cfg.Redis.Address,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+
+		backend = cache.NewRedis(client, cacheKeyPrefix, cfg.Cache.TTL)
+	default:
+		return nil, fmt.Errorf("%s: unknown cache backend %q", op, cfg.Cache.Backend)
+	}
+
+	return cache.New(storage, backend, observer, log), nil
+}
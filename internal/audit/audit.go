@@ -0,0 +1,131 @@
+package audit
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"main/internal/http-server/api/request"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	ActionLoginSuccess        = "login.success"
+	ActionLoginFailure        = "login.failure"
+	ActionLoginLockout        = "login.lockout"
+	ActionTokenRefresh        = "token.refresh"
+	ActionTokenReuse          = "token.reuse"
+	ActionLogoutAll           = "logout.all"
+	ActionPasswordChange      = "password.change"
+	ActionPasswordReset       = "password.reset"
+	ActionEmailChange         = "email.change"
+	ActionTwoFactorEnable     = "two_factor.enable"
+	ActionTwoFactorDisable    = "two_factor.disable"
+	ActionChallengeRevoke     = "two_factor.challenge_revoke"
+	ActionTokenCreate         = "personal_token.create"
+	ActionTokenRevoke         = "personal_token.revoke"
+	ActionAccountDelete       = "account.delete"
+	ActionNoteDelete          = "note.delete"
+	ActionNoteArchive         = "note.archive"
+	ActionNoteUnarchive       = "note.unarchive"
+	ActionWorkspaceInvite     = "workspace.invite"
+	ActionWorkspaceJoin       = "workspace.join"
+	ActionWorkspaceRoleChange = "workspace.role_change"
+	ActionWorkspaceRemove     = "workspace.member_remove"
+	ActionWorkspaceDelete     = "workspace.delete"
+	ActionAdminDisableUser    = "admin.user_disable"
+	ActionAdminEnableUser     = "admin.user_enable"
+	ActionAdminLogoutUser     = "admin.user_logout"
+	ActionAdminResetTwoFactor = "admin.two_factor_reset"
+	ActionAdminSetRole        = "admin.role_change"
+)
+
+const (
+	TargetUser          = "user"
+	TargetNote          = "note"
+	TargetWorkspace     = "workspace"
+	TargetPersonalToken = "personal_token"
+)
+
+// Event is a single entry of the append-only security audit log
+type Event struct {
+	Id         int64     `json:"id"`
+	OccurredAt time.Time `json:"occurred_at" db:"occurred_at"`
+	Action     string    `json:"action"`
+	ActorId    *string   `json:"actor_id,omitempty" db:"actor_id"`
+	TargetType string    `json:"target_type,omitempty" db:"target_type"`
+	TargetId   string    `json:"target_id,omitempty" db:"target_id"`
+	IP         string    `json:"ip" db:"ip"`
+	UserAgent  string    `json:"user_agent" db:"user_agent"`
+	RequestId  string    `json:"request_id" db:"request_id"`
+	Details    Details   `json:"details,omitempty" db:"details"`
+}
+
+// Details are stored as a json object
+type Details map[string]string
+
+func (d Details) Value() (driver.Value, error) {
+	if d == nil {
+		return []byte("{}"), nil
+	}
+
+	return json.Marshal(d)
+}
+
+func (d *Details) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, d)
+	case string:
+		return json.Unmarshal([]byte(v), d)
+	case nil:
+		*d = nil
+		return nil
+	default:
+		return fmt.Errorf("unsupported details type %T", src)
+	}
+}
+
+// Filter narrows down audit log queries, zero fields are not applied.
+// UserId matches events made by the user or targeting the user
+type Filter struct {
+	UserId     string
+	ActorId    string
+	Action     string
+	TargetType string
+	TargetId   string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
+
+type Recorder interface {
+	RecordAuditEvent(ctx context.Context, event Event) error
+}
+
+// Record fills request metadata of the event and stores it. Failures are
+// only logged, so the audit log never changes the outcome of the request.
+// Event is stored even if the client has already gone
+func Record(r *http.Request, log *slog.Logger, recorder Recorder, event Event) {
+	event.IP = request.ClientIP(r)
+	event.UserAgent = r.UserAgent()
+	event.RequestId = middleware.GetReqID(r.Context())
+
+	if err := recorder.RecordAuditEvent(context.WithoutCancel(r.Context()), event); err != nil {
+		log.Error("failed to record audit event", slog.String("action", event.Action), "error", err)
+	}
+}
+
+// Actor returns pointer to user id for Event.ActorId, empty id means unknown actor
+func Actor(userId string) *string {
+	if userId == "" {
+		return nil
+	}
+
+	return &userId
+}
@@ -1,22 +1,33 @@
 package auth
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"main/internal/config"
+	"main/internal/models/user"
 	"time"
 
-	"github.com/go-chi/jwtauth/v5"
 	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 )
 
+// RoleClaim holds role of the user in access tokens
+const RoleClaim = "role"
+
+var ErrUserDisabled = errors.New("user is disabled")
+
 type RefreshToken struct {
-	Id        string    `json:"id"`
-	UserId    string    `json:"user_id" db:"user_id"`
-	TokenHash string    `json:"token_hash" db:"token_hash"`
-	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	Id         string     `json:"id"`
+	UserId     string     `json:"user_id" db:"user_id"`
+	FamilyId   string     `json:"family_id" db:"family_id"`
+	TokenHash  string     `json:"token_hash" db:"token_hash"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt     *time.Time `json:"used_at,omitempty" db:"used_at"`
+	ReplacedBy *string    `json:"replaced_by,omitempty" db:"replaced_by"`
 }
 
 type Tokens struct {
@@ -24,37 +35,89 @@ type Tokens struct {
 	AccessToken  string `json:"access_token"`
 }
 
+type TokenAuth interface {
+	Encode(claims map[string]interface{}) (jwt.Token, string, error)
+	Decode(tokenString string) (jwt.Token, error)
+}
+
 type RefreshTokenCreator interface {
-	CreateRefreshToken(id, userId, tokenHash string, expiresAt time.Time) error
+	CreateRefreshToken(ctx context.Context, id, userId, familyId, tokenHash string, expiresAt time.Time) error
+}
+
+type RefreshTokenRotator interface {
+	RotateRefreshToken(ctx context.Context, oldId, newId, userId, familyId, tokenHash string, expiresAt time.Time) error
+}
+
+type UserGetter interface {
+	GetUserById(ctx context.Context, id string) (user.User, error)
+}
+
+type TokensIssuer interface {
+	RefreshTokenCreator
+	UserGetter
 }
 
-func GenerateTokens(userId string, refreshTokenCreator RefreshTokenCreator, cfg *config.Config, tokenAuth *jwtauth.JWTAuth) (Tokens, error) {
+type TokensRotator interface {
+	RefreshTokenRotator
+	UserGetter
+}
+
+// GenerateTokens issues a new token pair and starts a new refresh token family.
+// Tokens are never issued to disabled users
+func GenerateTokens(ctx context.Context, userId string, tokensIssuer TokensIssuer, cfg *config.Config, tokenAuth TokenAuth) (Tokens, error) {
 	const op = "auth.GenerateTokens"
 
+	role, err := getActiveUserRole(ctx, userId, tokensIssuer)
+	if err != nil {
+		return Tokens{}, fmt.Errorf("%s: %w", op, err)
+	}
+
 	id := uuid.New().String()
+	familyId := uuid.New().String()
 
-	accessExp := time.Now().Add(cfg.Authorization.AccessTTL)
-	refreshExp := time.Now().Add(cfg.Authorization.RefreshTTL)
+	refreshToken, hashedRefreshToken, refreshExp, err := encodeRefreshToken(id, cfg, tokenAuth)
+	if err != nil {
+		return Tokens{}, fmt.Errorf("%s: %w", op, err)
+	}
 
-	_, refreshToken, err := tokenAuth.Encode(map[string]interface{}{
-		"token_id": id,
-		"exp":      refreshExp,
-	})
+	err = tokensIssuer.CreateRefreshToken(ctx, id, userId, familyId, hashedRefreshToken, refreshExp)
 	if err != nil {
 		return Tokens{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	hashedRefreshToken := HashRefreshToken(refreshToken, cfg.Authorization.Salt)
+	accessToken, err := encodeAccessToken(userId, role, cfg, tokenAuth)
+	if err != nil {
+		return Tokens{}, fmt.Errorf("%s: %w", op, err)
+	}
 
-	err = refreshTokenCreator.CreateRefreshToken(id, userId, hashedRefreshToken, refreshExp)
+	return Tokens{
+		RefreshToken: refreshToken,
+		AccessToken:  accessToken,
+	}, nil
+}
+
+// RotateTokens consumes the given refresh token and issues a new pair in the same family
+func RotateTokens(ctx context.Context, old RefreshToken, tokensRotator TokensRotator, cfg *config.Config, tokenAuth TokenAuth) (Tokens, error) {
+	const op = "auth.RotateTokens"
+
+	role, err := getActiveUserRole(ctx, old.UserId, tokensRotator)
 	if err != nil {
 		return Tokens{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	_, accessToken, err := tokenAuth.Encode(map[string]interface{}{
-		"user_id": userId,
-		"exp":     accessExp,
-	})
+	id := uuid.New().String()
+
+	refreshToken, hashedRefreshToken, refreshExp, err := encodeRefreshToken(id, cfg, tokenAuth)
+	if err != nil {
+		return Tokens{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = tokensRotator.RotateRefreshToken(ctx, old.Id, id, old.UserId, old.FamilyId, hashedRefreshToken, refreshExp)
+	if err != nil {
+		return Tokens{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	accessToken, err := encodeAccessToken(old.UserId, role, cfg, tokenAuth)
 	if err != nil {
 		return Tokens{}, fmt.Errorf("%s: %w", op, err)
 	}
@@ -66,7 +129,56 @@ func GenerateTokens(userId string, refreshTokenCreator RefreshTokenCreator, cfg
 }
 
 func HashRefreshToken(token, salt string) string {
+	return HashToken(token, salt)
+}
+
+func HashToken(token, salt string) string {
 	h := hmac.New(sha256.New, []byte(salt))
 	h.Write([]byte(token))
 	return hex.EncodeToString(h.Sum(nil))
 }
+
+func getActiveUserRole(ctx context.Context, userId string, userGetter UserGetter) (string, error) {
+	u, err := userGetter.GetUserById(ctx, userId)
+	if err != nil {
+		return "", err
+	}
+
+	if u.DisabledAt != nil {
+		return "", ErrUserDisabled
+	}
+
+	return u.Role, nil
+}
+
+func encodeRefreshToken(id string, cfg *config.Config, tokenAuth TokenAuth) (string, string, time.Time, error) {
+	refreshExp := time.Now().Add(cfg.Authorization.RefreshTTL)
+
+	_, refreshToken, err := tokenAuth.Encode(map[string]interface{}{
+		"token_id": id,
+		"exp":      refreshExp,
+	})
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+
+	return refreshToken, HashRefreshToken(refreshToken, cfg.Authorization.Salt), refreshExp, nil
+}
+
+func encodeAccessToken(userId, role string, cfg *config.Config, tokenAuth TokenAuth) (string, error) {
+	issuedAt := time.Now()
+	accessExp := issuedAt.Add(cfg.Authorization.AccessTTL)
+
+	_, accessToken, err := tokenAuth.Encode(map[string]interface{}{
+		"jti":     uuid.New().String(),
+		"user_id": userId,
+		RoleClaim: role,
+		"iat":     issuedAt,
+		"exp":     accessExp,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return accessToken, nil
+}
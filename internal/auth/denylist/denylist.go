@@ -0,0 +1,247 @@
+package denylist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main/internal/storage"
+	"sync"
+	"time"
+)
+
+type Store interface {
+	RevokeAccessToken(ctx context.Context, jti, userId string, expiresAt time.Time) error
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error)
+	GetUserState(ctx context.Context, userId string) (UserState, error)
+	SetTokensValidAfter(ctx context.Context, userId string, validAfter time.Time) error
+	DisableUser(ctx context.Context, userId string) error
+	EnableUser(ctx context.Context, userId string) error
+}
+
+// UserState is the part of user checked on every authenticated request
+type UserState struct {
+	TokensValidAfter *time.Time `db:"tokens_valid_after"`
+	DisabledAt       *time.Time `db:"disabled_at"`
+}
+
+type cachedState struct {
+	value     UserState
+	expiresAt time.Time
+}
+
+// Denylist keeps revoked access tokens in memory in front of the store.
+// Revocations are cached until the token expires; negative lookups and
+// per-user cutoffs and disabled flags are cached for ttl, so changes made
+// by another replica are picked up after at most ttl.
+type Denylist struct {
+	store Store
+	ttl   time.Duration
+
+	mu         sync.Mutex
+	revoked    map[string]time.Time
+	notRevoked map[string]time.Time
+	users      map[string]cachedState
+	lastSweep  time.Time
+}
+
+func New(store Store, ttl time.Duration) *Denylist {
+	return &Denylist{
+		store:      store,
+		ttl:        ttl,
+		revoked:    make(map[string]time.Time),
+		notRevoked: make(map[string]time.Time),
+		users:      make(map[string]cachedState),
+		lastSweep:  time.Now(),
+	}
+}
+
+// Revoke adds a single access token to the denylist until it expires
+func (d *Denylist) Revoke(ctx context.Context, jti, userId string, expiresAt time.Time) error {
+	const op = "auth.denylist.Revoke"
+
+	if err := d.store.RevokeAccessToken(ctx, jti, userId, expiresAt); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.revoked[jti] = expiresAt
+	delete(d.notRevoked, jti)
+
+	return nil
+}
+
+// RevokeAllBefore invalidates every access token of the user issued before t.
+// iat has second precision, so t is truncated to let tokens issued right
+// after the cutoff (e.g. after a password change) stay valid
+func (d *Denylist) RevokeAllBefore(ctx context.Context, userId string, t time.Time) error {
+	const op = "auth.denylist.RevokeAllBefore"
+
+	t = t.Truncate(time.Second)
+
+	if err := d.store.SetTokensValidAfter(ctx, userId, t); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	d.forget(userId)
+
+	return nil
+}
+
+// Disable blocks every request of the user until Enable is called
+func (d *Denylist) Disable(ctx context.Context, userId string) error {
+	const op = "auth.denylist.Disable"
+
+	if err := d.store.DisableUser(ctx, userId); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	d.forget(userId)
+
+	return nil
+}
+
+func (d *Denylist) Enable(ctx context.Context, userId string) error {
+	const op = "auth.denylist.Enable"
+
+	if err := d.store.EnableUser(ctx, userId); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	d.forget(userId)
+
+	return nil
+}
+
+// IsDisabled reports whether user is disabled. Deleted users are not
+// reported here, IsRevoked rejects their tokens
+func (d *Denylist) IsDisabled(ctx context.Context, userId string) (bool, error) {
+	const op = "auth.denylist.IsDisabled"
+
+	state, err := d.getUserState(ctx, userId)
+	if errors.Is(err, storage.ErrUserNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return state.DisabledAt != nil, nil
+}
+
+func (d *Denylist) IsRevoked(ctx context.Context, jti, userId string, issuedAt time.Time) (bool, error) {
+	const op = "auth.denylist.IsRevoked"
+
+	state, err := d.getUserState(ctx, userId)
+	if errors.Is(err, storage.ErrUserNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if state.TokensValidAfter != nil && issuedAt.Before(*state.TokensValidAfter) {
+		return true, nil
+	}
+
+	if jti == "" {
+		return false, nil
+	}
+
+	revoked, err := d.isJtiRevoked(ctx, jti)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return revoked, nil
+}
+
+func (d *Denylist) getUserState(ctx context.Context, userId string) (UserState, error) {
+	now := time.Now()
+
+	d.mu.Lock()
+	d.sweep(now)
+	cached, ok := d.users[userId]
+	d.mu.Unlock()
+
+	if ok && now.Before(cached.expiresAt) {
+		return cached.value, nil
+	}
+
+	state, err := d.store.GetUserState(ctx, userId)
+	if err != nil {
+		return UserState{}, err
+	}
+
+	d.mu.Lock()
+	d.users[userId] = cachedState{value: state, expiresAt: now.Add(d.ttl)}
+	d.mu.Unlock()
+
+	return state, nil
+}
+
+// forget drops cached state of the user, so the next check reads it from the store
+func (d *Denylist) forget(userId string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.users, userId)
+}
+
+func (d *Denylist) isJtiRevoked(ctx context.Context, jti string) (bool, error) {
+	now := time.Now()
+
+	d.mu.Lock()
+	_, revoked := d.revoked[jti]
+	checkedUntil, checked := d.notRevoked[jti]
+	d.mu.Unlock()
+
+	if revoked {
+		return true, nil
+	}
+	if checked && now.Before(checkedUntil) {
+		return false, nil
+	}
+
+	revoked, err := d.store.IsAccessTokenRevoked(ctx, jti)
+	if err != nil {
+		return false, err
+	}
+
+	d.mu.Lock()
+	if revoked {
+		// exact expiry is unknown here, keep the entry for a full ttl period
+		d.revoked[jti] = now.Add(d.ttl)
+	} else {
+		d.notRevoked[jti] = now.Add(d.ttl)
+	}
+	d.mu.Unlock()
+
+	return revoked, nil
+}
+
+// sweep drops stale cache entries, at most once per ttl; must be called with mu held
+func (d *Denylist) sweep(now time.Time) {
+	if now.Sub(d.lastSweep) < d.ttl {
+		return
+	}
+
+	for jti, expiresAt := range d.revoked {
+		if now.After(expiresAt) {
+			delete(d.revoked, jti)
+		}
+	}
+	for jti, expiresAt := range d.notRevoked {
+		if now.After(expiresAt) {
+			delete(d.notRevoked, jti)
+		}
+	}
+	for userId, cached := range d.users {
+		if now.After(cached.expiresAt) {
+			delete(d.users, userId)
+		}
+	}
+
+	d.lastSweep = now
+}
@@ -0,0 +1,199 @@
+package keyring
+
+import (
+	"errors"
+	"fmt"
+	"main/internal/config"
+	"os"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+const legacyKeyId = "default"
+
+var (
+	ErrNoActiveKey         = errors.New("active signing key is not configured")
+	ErrActiveKeyNotPrivate = errors.New("active signing key has no private key")
+	ErrUnsupportedAlg      = errors.New("unsupported signing algorithm")
+)
+
+// KeyRing signs tokens with the active key and verifies them with any
+// configured key, so older keys stay valid during a rotation window.
+type KeyRing struct {
+	alg       jwa.SignatureAlgorithm
+	signKey   jwk.Key
+	verifySet jwk.Set
+	publicSet jwk.Set
+}
+
+func New(cfg *config.Config) (*KeyRing, error) {
+	const op = "auth.keyring.New"
+
+	signing := cfg.Authorization.Signing
+
+	// fallback to the shared HS256 secret when no keys are configured
+	if len(signing.Keys) == 0 {
+		keyRing, err := newSymmetric(cfg.Authorization.JWTSecret)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return keyRing, nil
+	}
+
+	keyRing := &KeyRing{
+		verifySet: jwk.NewSet(),
+		publicSet: jwk.NewSet(),
+	}
+
+	for _, keyCfg := range signing.Keys {
+		privateKey, publicKey, err := loadKey(keyCfg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: key %q: %w", op, keyCfg.Id, err)
+		}
+
+		if err := keyRing.verifySet.AddKey(publicKey); err != nil {
+			return nil, fmt.Errorf("%s: key %q: %w", op, keyCfg.Id, err)
+		}
+		if err := keyRing.publicSet.AddKey(publicKey); err != nil {
+			return nil, fmt.Errorf("%s: key %q: %w", op, keyCfg.Id, err)
+		}
+
+		if keyCfg.Id != signing.ActiveKeyId {
+			continue
+		}
+		if privateKey == nil {
+			return nil, fmt.Errorf("%s: key %q: %w", op, keyCfg.Id, ErrActiveKeyNotPrivate)
+		}
+
+		keyRing.alg = jwa.SignatureAlgorithm(keyCfg.Algorithm)
+		keyRing.signKey = privateKey
+	}
+
+	if keyRing.signKey == nil {
+		return nil, fmt.Errorf("%s: %w", op, ErrNoActiveKey)
+	}
+
+	return keyRing, nil
+}
+
+func (k *KeyRing) Encode(claims map[string]interface{}) (jwt.Token, string, error) {
+	t := jwt.New()
+	for key, value := range claims {
+		if err := t.Set(key, value); err != nil {
+			return nil, "", err
+		}
+	}
+
+	payload, err := jwt.Sign(t, jwt.WithKey(k.alg, k.signKey))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return t, string(payload), nil
+}
+
+// Decode verifies the signature only, claims are validated by the caller
+func (k *KeyRing) Decode(tokenString string) (jwt.Token, error) {
+	return jwt.Parse(
+		[]byte(tokenString),
+		jwt.WithKeySet(k.verifySet, jws.WithUseDefault(true)),
+		jwt.WithValidate(false),
+	)
+}
+
+// PublicSet returns the public keys of every configured asymmetric key
+func (k *KeyRing) PublicSet() jwk.Set {
+	return k.publicSet
+}
+
+func newSymmetric(secret string) (*KeyRing, error) {
+	key, err := jwk.FromRaw([]byte(secret))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setKeyMeta(key, legacyKeyId, jwa.HS256); err != nil {
+		return nil, err
+	}
+
+	verifySet := jwk.NewSet()
+	if err := verifySet.AddKey(key); err != nil {
+		return nil, err
+	}
+
+	return &KeyRing{
+		alg:       jwa.HS256,
+		signKey:   key,
+		verifySet: verifySet,
+		publicSet: jwk.NewSet(),
+	}, nil
+}
+
+// loadKey reads a key pair from PEM files, the private key is optional for
+// keys kept only for verification
+func loadKey(keyCfg config.SigningKey) (jwk.Key, jwk.Key, error) {
+	alg := jwa.SignatureAlgorithm(keyCfg.Algorithm)
+	switch alg {
+	case jwa.RS256, jwa.RS384, jwa.RS512, jwa.PS256, jwa.EdDSA, jwa.ES256:
+	default:
+		return nil, nil, fmt.Errorf("%w: %q", ErrUnsupportedAlg, keyCfg.Algorithm)
+	}
+
+	var privateKey, publicKey jwk.Key
+
+	if keyCfg.PrivateKeyPath != "" {
+		key, err := parsePEMFile(keyCfg.PrivateKeyPath)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		publicKey, err = key.PublicKey()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		privateKey = key
+	} else {
+		key, err := parsePEMFile(keyCfg.PublicKeyPath)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		publicKey = key
+	}
+
+	if privateKey != nil {
+		if err := setKeyMeta(privateKey, keyCfg.Id, alg); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := setKeyMeta(publicKey, keyCfg.Id, alg); err != nil {
+		return nil, nil, err
+	}
+
+	return privateKey, publicKey, nil
+}
+
+func parsePEMFile(path string) (jwk.Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return jwk.ParseKey(data, jwk.WithPEM(true))
+}
+
+func setKeyMeta(key jwk.Key, id string, alg jwa.SignatureAlgorithm) error {
+	if err := key.Set(jwk.KeyIDKey, id); err != nil {
+		return err
+	}
+	if err := key.Set(jwk.AlgorithmKey, alg); err != nil {
+		return err
+	}
+
+	return key.Set(jwk.KeyUsageKey, jwk.ForSignature)
+}
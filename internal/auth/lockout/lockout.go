@@ -0,0 +1,291 @@
+package lockout
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"main/internal/config"
+	"strings"
+	"time"
+)
+
+type Attempt struct {
+	Key           string     `db:"key"`
+	Failures      int        `db:"failures"`
+	LastFailureAt time.Time  `db:"last_failure_at"`
+	LockedUntil   *time.Time `db:"locked_until"`
+}
+
+// Store keeps failed login attempts by key (account, user or challenge).
+// AddLoginFailure must increment atomically and start counting
+// from scratch when the last failure is older than window.
+// ReserveLoginAttempt must atomically set locked_until to until when the key
+// isn't locked, otherwise it returns current attempt and false.
+// Failures of ip keys are kept one by one, GetLoginFailures returns times of
+// the key's failures since since, oldest first
+type Store interface {
+	GetLoginAttempt(ctx context.Context, key string) (Attempt, error)
+	ReserveLoginAttempt(ctx context.Context, key string, until time.Time) (Attempt, bool, error)
+	AddLoginFailure(ctx context.Context, key string, window time.Duration) (Attempt, error)
+	LockLoginKey(ctx context.Context, key string, until time.Time) error
+	DeleteLoginAttempt(ctx context.Context, key string) error
+	RecordLoginFailure(ctx context.Context, key string, at time.Time) error
+	GetLoginFailures(ctx context.Context, key string, since time.Time) ([]time.Time, error)
+}
+
+// Guard throttles logins: every failure doubles the delay before the next
+// attempt for the same account, and after too many failures the account is
+// locked for lockout duration. Ip is only refused while it has max ip failures
+// within the failure window, its attempts run in parallel, so clients behind
+// one NAT don't wait for each other
+type Guard struct {
+	store Store
+	cfg   config.LoginProtection
+	log   *slog.Logger
+}
+
+// attemptHold is how long a reservation keeps the account key. It is longer than any
+// login takes, so keys of requests which died in between free themselves
+const attemptHold = 30 * time.Second
+
+func New(store Store, cfg config.LoginProtection, log *slog.Logger) *Guard {
+	return &Guard{
+		store: store,
+		cfg:   cfg,
+		log:   log.With(slog.String("component", "auth/lockout")),
+	}
+}
+
+func AccountKey(email string) string {
+	return "account:" + strings.ToLower(email)
+}
+
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
+
+func SecondFactorKey(userId string) string {
+	return "2fa:" + userId
+}
+
+func ChallengeKey(challengeId string) string {
+	return "challenge:" + challengeId
+}
+
+// limit is a key with the number of failures which locks it
+type limit struct {
+	key         string
+	maxFailures int
+}
+
+// Reserve takes the attempt for the account before password is verified, so
+// parallel requests can't all pass before a failure is counted, ip is only
+// checked for too many failures. It returns how long client has to wait when
+// the attempt isn't taken, zero if it may try now. Taken attempt has to be
+// finished with RegisterFailure, RegisterSuccess or Release
+func (g *Guard) Reserve(ctx context.Context, email, ip string) (time.Duration, error) {
+	const op = "auth.lockout.Guard.Reserve"
+
+	retryAfter, err := g.reserve(ctx, AccountKey(email), IPKey(ip))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return retryAfter, nil
+}
+
+// RegisterFailure counts failed attempt for both account and ip, the account
+// is locked when threshold is reached. It returns true when this failure locked the account
+func (g *Guard) RegisterFailure(ctx context.Context, email, ip string) (bool, error) {
+	const op = "auth.lockout.Guard.RegisterFailure"
+
+	locked, err := g.registerFailure(ctx, limit{AccountKey(email), g.cfg.MaxAccountFailures}, IPKey(ip))
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return locked, nil
+}
+
+// RegisterSuccess forgets failures of the account, ip failures are kept so
+// one valid account can't be used to reset the ip counter
+func (g *Guard) RegisterSuccess(ctx context.Context, email string) error {
+	const op = "auth.lockout.Guard.RegisterSuccess"
+
+	if err := g.store.DeleteLoginAttempt(ctx, AccountKey(email)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Release gives the reserved attempt back without counting it, for attempts
+// which ended before password was verified or failed for other reasons
+func (g *Guard) Release(ctx context.Context, email string) error {
+	const op = "auth.lockout.Guard.Release"
+
+	if err := g.store.LockLoginKey(ctx, AccountKey(email), time.Now()); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ReserveSecondFactor is Reserve for the second step of login, keyed by user
+// instead of email, the ip shares its failures with password attempts
+func (g *Guard) ReserveSecondFactor(ctx context.Context, userId, ip string) (time.Duration, error) {
+	const op = "auth.lockout.Guard.ReserveSecondFactor"
+
+	retryAfter, err := g.reserve(ctx, SecondFactorKey(userId), IPKey(ip))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return retryAfter, nil
+}
+
+// RegisterSecondFactorFailure counts wrong code for the user, ip and challenge.
+// It returns whether this failure locked the user and whether the challenge
+// reached max challenge failures and has to be revoked
+func (g *Guard) RegisterSecondFactorFailure(ctx context.Context, userId, challengeId, ip string) (locked, exhausted bool, err error) {
+	const op = "auth.lockout.Guard.RegisterSecondFactorFailure"
+
+	locked, err = g.registerFailure(ctx, limit{SecondFactorKey(userId), g.cfg.MaxAccountFailures}, IPKey(ip))
+	if err != nil {
+		return false, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// challenge is only counted, it is revoked instead of being delayed
+	attempt, err := g.store.AddLoginFailure(ctx, ChallengeKey(challengeId), g.cfg.FailureWindow)
+	if err != nil {
+		return false, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	exhausted = g.cfg.MaxChallengeFailures > 0 && attempt.Failures >= g.cfg.MaxChallengeFailures
+
+	return locked, exhausted, nil
+}
+
+// RegisterSecondFactorSuccess forgets second factor failures of the user and the challenge
+func (g *Guard) RegisterSecondFactorSuccess(ctx context.Context, userId, challengeId string) error {
+	const op = "auth.lockout.Guard.RegisterSecondFactorSuccess"
+
+	for _, key := range []string{ChallengeKey(challengeId), SecondFactorKey(userId)} {
+		if err := g.store.DeleteLoginAttempt(ctx, key); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+// ReleaseSecondFactor is Release for ReserveSecondFactor
+func (g *Guard) ReleaseSecondFactor(ctx context.Context, userId string) error {
+	const op = "auth.lockout.Guard.ReleaseSecondFactor"
+
+	if err := g.store.LockLoginKey(ctx, SecondFactorKey(userId), time.Now()); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// reserve refuses the attempt while the ip has too many failures, otherwise
+// it takes the user key exclusively
+func (g *Guard) reserve(ctx context.Context, userKey, ipKey string) (time.Duration, error) {
+	now := time.Now()
+
+	failures, err := g.store.GetLoginFailures(ctx, ipKey, now.Add(-g.cfg.FailureWindow))
+	if err != nil {
+		return 0, err
+	}
+	if retryAfter := g.ipWaitTime(failures, now); retryAfter > 0 {
+		return retryAfter, nil
+	}
+
+	attempt, ok, err := g.store.ReserveLoginAttempt(ctx, userKey, now.Add(attemptHold))
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return g.waitTime(attempt, now), nil
+	}
+
+	return 0, nil
+}
+
+// registerFailure replaces reservation of the user key with backoff delay, or
+// with lockout when it reached max failures, and records failure of the ip.
+// It returns whether the user key is locked
+func (g *Guard) registerFailure(ctx context.Context, l limit, ipKey string) (bool, error) {
+	now := time.Now()
+
+	if err := g.store.RecordLoginFailure(ctx, ipKey, now); err != nil {
+		return false, err
+	}
+
+	attempt, err := g.store.AddLoginFailure(ctx, l.key, g.cfg.FailureWindow)
+	if err != nil {
+		return false, err
+	}
+
+	lockedUntil := now.Add(g.backoff(attempt.Failures))
+
+	locked := l.maxFailures > 0 && attempt.Failures >= l.maxFailures
+	if locked {
+		lockedUntil = now.Add(g.cfg.LockoutDuration)
+	}
+
+	if err := g.store.LockLoginKey(ctx, l.key, lockedUntil); err != nil {
+		return false, err
+	}
+
+	if locked {
+		g.log.Warn("security audit: login locked",
+			slog.String("event", "login_locked"),
+			slog.String("key", l.key),
+			slog.Int("failures", attempt.Failures),
+			slog.Time("locked_until", lockedUntil),
+		)
+	}
+
+	return locked, nil
+}
+
+// ipWaitTime is time until failures of the ip, oldest first, drop below max ip
+// failures as they leave the window, zero if the ip may try now
+func (g *Guard) ipWaitTime(failures []time.Time, now time.Time) time.Duration {
+	if g.cfg.MaxIPFailures <= 0 || len(failures) < g.cfg.MaxIPFailures {
+		return 0
+	}
+
+	// the ip is free once every failure up to this one leaves the window
+	leaving := failures[len(failures)-g.cfg.MaxIPFailures]
+
+	return max(leaving.Add(g.cfg.FailureWindow).Sub(now), time.Second)
+}
+
+// waitTime is time until the key is free again. Key held by a reservation
+// which was just released is reported as free in a moment
+func (g *Guard) waitTime(attempt Attempt, now time.Time) time.Duration {
+	if attempt.LockedUntil != nil && attempt.LockedUntil.After(now) {
+		return attempt.LockedUntil.Sub(now)
+	}
+
+	return time.Second
+}
+
+// backoff is base delay doubled for every failure after the first one, capped by max delay
+func (g *Guard) backoff(failures int) time.Duration {
+	delay := g.cfg.BaseDelay
+
+	for i := 1; i < failures; i++ {
+		delay *= 2
+
+		if delay >= g.cfg.MaxDelay {
+			return g.cfg.MaxDelay
+		}
+	}
+
+	return min(delay, g.cfg.MaxDelay)
+}
@@ -0,0 +1,231 @@
+package lockout
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"main/internal/config"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newGuard(cfg config.LoginProtection) *Guard {
+	return New(NewMemoryStore(), cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+var testCfg = config.LoginProtection{
+	MaxAccountFailures: 3,
+	MaxIPFailures:      10,
+	BaseDelay:          time.Minute,
+	MaxDelay:           time.Hour,
+	LockoutDuration:    24 * time.Hour,
+	FailureWindow:      time.Hour,
+}
+
+func TestReserveIsExclusive(t *testing.T) {
+	g := newGuard(testCfg)
+	ctx := context.Background()
+
+	const concurrent = 20
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+
+	for range concurrent {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			retryAfter, err := g.Reserve(ctx, "user@example.com", "10.0.0.1")
+			if err != nil {
+				t.Errorf("Reserve: %v", err)
+				return
+			}
+
+			if retryAfter == 0 {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != 1 {
+		t.Fatalf("%d of %d parallel attempts were allowed, want 1", allowed, concurrent)
+	}
+}
+
+func TestFailureStartsBackoff(t *testing.T) {
+	g := newGuard(testCfg)
+	ctx := context.Background()
+
+	mustReserve(t, g, "user@example.com", "10.0.0.1")
+
+	if _, err := g.RegisterFailure(ctx, "user@example.com", "10.0.0.1"); err != nil {
+		t.Fatalf("RegisterFailure: %v", err)
+	}
+
+	retryAfter, err := g.Reserve(ctx, "user@example.com", "10.0.0.2")
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if retryAfter <= 0 || retryAfter > testCfg.BaseDelay {
+		t.Fatalf("Reserve after failure: retry after %v, want up to %v", retryAfter, testCfg.BaseDelay)
+	}
+
+	// account throttling doesn't hold the ip of the rejected attempt
+	mustReserve(t, g, "other@example.com", "10.0.0.2")
+}
+
+func TestLockoutAfterMaxFailures(t *testing.T) {
+	g := newGuard(testCfg)
+	ctx := context.Background()
+
+	for i := range testCfg.MaxAccountFailures {
+		// every attempt comes after the backoff of the previous one has passed
+		if i > 0 {
+			if err := g.store.LockLoginKey(ctx, AccountKey("user@example.com"), time.Now()); err != nil {
+				t.Fatalf("LockLoginKey: %v", err)
+			}
+		}
+
+		mustReserve(t, g, "user@example.com", "10.0.0.1")
+
+		locked, err := g.RegisterFailure(ctx, "user@example.com", "10.0.0.1")
+		if err != nil {
+			t.Fatalf("RegisterFailure: %v", err)
+		}
+
+		if want := i == testCfg.MaxAccountFailures-1; locked != want {
+			t.Fatalf("RegisterFailure %d: locked %v, want %v", i+1, locked, want)
+		}
+	}
+
+	retryAfter, err := g.Reserve(ctx, "user@example.com", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if retryAfter <= testCfg.MaxDelay {
+		t.Fatalf("Reserve of locked account: retry after %v, want lockout of %v", retryAfter, testCfg.LockoutDuration)
+	}
+}
+
+func TestSuccessAndReleaseFreeKeys(t *testing.T) {
+	g := newGuard(testCfg)
+	ctx := context.Background()
+
+	mustReserve(t, g, "user@example.com", "10.0.0.1")
+
+	if err := g.RegisterSuccess(ctx, "user@example.com"); err != nil {
+		t.Fatalf("RegisterSuccess: %v", err)
+	}
+
+	mustReserve(t, g, "user@example.com", "10.0.0.1")
+
+	if err := g.Release(ctx, "user@example.com"); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+
+	mustReserve(t, g, "user@example.com", "10.0.0.1")
+}
+
+// TestIPIsNotHeld covers users behind one NAT logging in at the same time
+func TestIPIsNotHeld(t *testing.T) {
+	g := newGuard(testCfg)
+
+	for _, email := range []string{"first@example.com", "second@example.com", "third@example.com"} {
+		mustReserve(t, g, email, "10.0.0.1")
+	}
+}
+
+func TestIPFailuresSlidingWindow(t *testing.T) {
+	cfg := testCfg
+	cfg.MaxIPFailures = 3
+
+	g := newGuard(cfg)
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, ago := range []time.Duration{70 * time.Minute, 50 * time.Minute, 10 * time.Minute} {
+		if err := g.store.RecordLoginFailure(ctx, IPKey("10.0.0.1"), now.Add(-ago)); err != nil {
+			t.Fatalf("RecordLoginFailure: %v", err)
+		}
+	}
+
+	// the failure out of window isn't counted
+	mustReserve(t, g, "first@example.com", "10.0.0.1")
+
+	if _, err := g.RegisterFailure(ctx, "first@example.com", "10.0.0.1"); err != nil {
+		t.Fatalf("RegisterFailure: %v", err)
+	}
+
+	// failures of any account count for the ip, it's free once the oldest leaves the window
+	retryAfter, err := g.Reserve(ctx, "second@example.com", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if retryAfter < 9*time.Minute || retryAfter > 10*time.Minute {
+		t.Fatalf("Reserve from ip with max failures: retry after %v, want about 10m", retryAfter)
+	}
+
+	// other ips are not affected
+	mustReserve(t, g, "second@example.com", "10.0.0.2")
+
+	// success of a valid account doesn't reset the ip
+	if err := g.RegisterSuccess(ctx, "second@example.com"); err != nil {
+		t.Fatalf("RegisterSuccess: %v", err)
+	}
+	if retryAfter, _ := g.Reserve(ctx, "third@example.com", "10.0.0.1"); retryAfter == 0 {
+		t.Fatal("Reserve after success of another account: ip isn't refused")
+	}
+}
+
+func mustReserve(t *testing.T, g *Guard, email, ip string) {
+	t.Helper()
+
+	retryAfter, err := g.Reserve(context.Background(), email, ip)
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if retryAfter != 0 {
+		t.Fatalf("Reserve of %s from %s: retry after %v, want it reserved", email, ip, retryAfter)
+	}
+}
+
+func TestSecondFactorChallengeExhausted(t *testing.T) {
+	cfg := testCfg
+	cfg.BaseDelay = 0
+	cfg.MaxChallengeFailures = 2
+	cfg.MaxAccountFailures = 10
+
+	g := newGuard(cfg)
+	ctx := context.Background()
+
+	for i := 1; i <= cfg.MaxChallengeFailures; i++ {
+		retryAfter, err := g.ReserveSecondFactor(ctx, "user-id", "10.0.0.1")
+		if err != nil || retryAfter != 0 {
+			t.Fatalf("ReserveSecondFactor: retry after %v, error %v", retryAfter, err)
+		}
+
+		_, exhausted, err := g.RegisterSecondFactorFailure(ctx, "user-id", "challenge-id", "10.0.0.1")
+		if err != nil {
+			t.Fatalf("RegisterSecondFactorFailure: %v", err)
+		}
+
+		if want := i == cfg.MaxChallengeFailures; exhausted != want {
+			t.Fatalf("RegisterSecondFactorFailure %d: exhausted %v, want %v", i, exhausted, want)
+		}
+	}
+
+	// other challenge of the same user is counted separately
+	_, exhausted, err := g.RegisterSecondFactorFailure(ctx, "user-id", "other-challenge-id", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("RegisterSecondFactorFailure: %v", err)
+	}
+	if exhausted {
+		t.Fatal("RegisterSecondFactorFailure of other challenge: exhausted")
+	}
+}
@@ -0,0 +1,156 @@
+package lockout
+
+import (
+	"context"
+	"main/internal/storage"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps attempts in process memory, it is enough for a single
+// instance and loses state on restart
+type MemoryStore struct {
+	mu        sync.Mutex
+	attempts  map[string]Attempt
+	failures  map[string][]time.Time
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		attempts:  make(map[string]Attempt),
+		failures:  make(map[string][]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *MemoryStore) GetLoginAttempt(_ context.Context, key string) (Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[key]
+	if !ok {
+		return Attempt{}, storage.ErrLoginAttemptNotFound
+	}
+
+	return attempt, nil
+}
+
+func (s *MemoryStore) ReserveLoginAttempt(_ context.Context, key string, until time.Time) (Attempt, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	attempt, ok := s.attempts[key]
+	if !ok {
+		attempt = Attempt{Key: key, LastFailureAt: now}
+	}
+
+	if attempt.LockedUntil != nil && attempt.LockedUntil.After(now) {
+		return attempt, false, nil
+	}
+
+	attempt.LockedUntil = &until
+
+	s.attempts[key] = attempt
+
+	return attempt, true, nil
+}
+
+func (s *MemoryStore) AddLoginFailure(_ context.Context, key string, window time.Duration) (Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now, window)
+
+	attempt, ok := s.attempts[key]
+	if !ok || now.Sub(attempt.LastFailureAt) > window {
+		attempt = Attempt{Key: key, LockedUntil: attempt.LockedUntil}
+	}
+
+	attempt.Failures++
+	attempt.LastFailureAt = now
+
+	s.attempts[key] = attempt
+
+	return attempt, nil
+}
+
+func (s *MemoryStore) LockLoginKey(_ context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[key]
+	if !ok {
+		attempt = Attempt{Key: key, LastFailureAt: time.Now()}
+	}
+
+	attempt.LockedUntil = &until
+
+	s.attempts[key] = attempt
+
+	return nil
+}
+
+func (s *MemoryStore) DeleteLoginAttempt(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+
+	return nil
+}
+
+func (s *MemoryStore) RecordLoginFailure(_ context.Context, key string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures[key] = append(s.failures[key], at)
+
+	return nil
+}
+
+func (s *MemoryStore) GetLoginFailures(_ context.Context, key string, since time.Time) ([]time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// failures older than since won't be counted again, so they are dropped
+	failures := slices.DeleteFunc(s.failures[key], func(at time.Time) bool {
+		return at.Before(since)
+	})
+	if len(failures) == 0 {
+		delete(s.failures, key)
+		return []time.Time{}, nil
+	}
+	s.failures[key] = failures
+
+	failures = slices.Clone(failures)
+	slices.SortFunc(failures, time.Time.Compare)
+
+	return failures, nil
+}
+
+// sweep drops stale attempts at most once per window, must be called with mu held
+func (s *MemoryStore) sweep(now time.Time, window time.Duration) {
+	if now.Sub(s.lastSweep) < window {
+		return
+	}
+
+	for key, attempt := range s.attempts {
+		locked := attempt.LockedUntil != nil && attempt.LockedUntil.After(now)
+		if !locked && now.Sub(attempt.LastFailureAt) > window {
+			delete(s.attempts, key)
+		}
+	}
+
+	for key, failures := range s.failures {
+		if now.Sub(failures[len(failures)-1]) > window {
+			delete(s.failures, key)
+		}
+	}
+
+	s.lastSweep = now
+}
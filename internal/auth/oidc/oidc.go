@@ -0,0 +1,300 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"main/internal/config"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"golang.org/x/oauth2"
+)
+
+const (
+	discoveryPath = "/.well-known/openid-configuration"
+
+	keySetMaxAge         = time.Hour
+	keySetMinRefreshTime = time.Minute
+)
+
+var (
+	ErrProviderNotFound = errors.New("oidc provider not found")
+	ErrIssuerMismatch   = errors.New("issuer in discovery document does not match configured issuer")
+	ErrNoIdToken        = errors.New("token response has no id_token")
+	ErrNonceMismatch    = errors.New("id token nonce mismatch")
+	ErrNoEmail          = errors.New("id token has no email")
+)
+
+// State keeps PKCE verifier and nonce between authorization request and callback
+type State struct {
+	State        string    `db:"state"`
+	Provider     string    `db:"provider"`
+	CodeVerifier string    `db:"code_verifier"`
+	Nonce        string    `db:"nonce"`
+	ExpiresAt    time.Time `db:"expires_at"`
+}
+
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type Provider struct {
+	name       string
+	issuer     string
+	jwksURI    string
+	oauth      oauth2.Config
+	httpClient *http.Client
+
+	mu              sync.Mutex
+	keySet          jwk.Set
+	keySetFetchedAt time.Time
+}
+
+// Registry discovers configured providers lazily, so an unavailable identity
+// provider doesn't prevent server from starting
+type Registry struct {
+	configs    map[string]config.OIDCProvider
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	providers map[string]*Provider
+}
+
+func NewRegistry(cfg config.OIDC, httpClient *http.Client) *Registry {
+	configs := make(map[string]config.OIDCProvider, len(cfg.Providers))
+	for _, providerCfg := range cfg.Providers {
+		configs[providerCfg.Name] = providerCfg
+	}
+
+	return &Registry{
+		configs:    configs,
+		httpClient: httpClient,
+		providers:  make(map[string]*Provider),
+	}
+}
+
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.configs))
+	for name := range r.configs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (r *Registry) Get(ctx context.Context, name string) (*Provider, error) {
+	const op = "auth.oidc.Registry.Get"
+
+	providerCfg, ok := r.configs[name]
+	if !ok {
+		return nil, ErrProviderNotFound
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if provider, ok := r.providers[name]; ok {
+		return provider, nil
+	}
+
+	provider, err := discover(ctx, providerCfg, r.httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	r.providers[name] = provider
+
+	return provider, nil
+}
+
+func discover(ctx context.Context, cfg config.OIDCProvider, httpClient *http.Client) (*Provider, error) {
+	issuer := strings.TrimSuffix(cfg.IssuerURL, "/")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+discoveryPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discovery request failed with status %d", res.StatusCode)
+	}
+
+	var doc discoveryDocument
+	if err := json.NewDecoder(res.Body).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSuffix(doc.Issuer, "/") != issuer {
+		return nil, ErrIssuerMismatch
+	}
+
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+
+	return &Provider{
+		name:    cfg.Name,
+		issuer:  doc.Issuer,
+		jwksURI: doc.JWKSURI,
+		oauth: oauth2.Config{
+			ClientID:     cfg.ClientId,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Scopes:       scopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  doc.AuthorizationEndpoint,
+				TokenURL: doc.TokenEndpoint,
+			},
+		},
+		httpClient: httpClient,
+	}, nil
+}
+
+func (p *Provider) AuthCodeURL(state, nonce, codeVerifier string) string {
+	return p.oauth.AuthCodeURL(
+		state,
+		oauth2.S256ChallengeOption(codeVerifier),
+		oauth2.SetAuthURLParam("nonce", nonce),
+	)
+}
+
+// Exchange redeems authorization code and returns identity from the validated id token
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (Identity, error) {
+	const op = "auth.oidc.Provider.Exchange"
+
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.httpClient)
+
+	token, err := p.oauth.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return Identity{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rawIdToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIdToken == "" {
+		return Identity{}, fmt.Errorf("%s: %w", op, ErrNoIdToken)
+	}
+
+	idToken, err := p.verifyIdToken(ctx, rawIdToken)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tokenNonce, _ := idToken.Get("nonce")
+	if tokenNonce != nonce {
+		return Identity{}, fmt.Errorf("%s: %w", op, ErrNonceMismatch)
+	}
+
+	email, _ := idToken.Get("email")
+	emailStr, _ := email.(string)
+	if emailStr == "" {
+		return Identity{}, fmt.Errorf("%s: %w", op, ErrNoEmail)
+	}
+
+	name, _ := idToken.Get("name")
+	nameStr, _ := name.(string)
+
+	return Identity{
+		Provider:      p.name,
+		Subject:       idToken.Subject(),
+		Email:         strings.ToLower(emailStr),
+		EmailVerified: isEmailVerified(idToken),
+		Name:          nameStr,
+	}, nil
+}
+
+func (p *Provider) verifyIdToken(ctx context.Context, rawIdToken string) (jwt.Token, error) {
+	keySet, err := p.getKeySet(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	idToken, err := p.parseIdToken(rawIdToken, keySet)
+	if err == nil {
+		return idToken, nil
+	}
+
+	// provider may have rotated its keys, retry once with fresh set
+	keySet, refreshErr := p.getKeySet(ctx, true)
+	if refreshErr != nil {
+		return nil, err
+	}
+
+	return p.parseIdToken(rawIdToken, keySet)
+}
+
+func (p *Provider) parseIdToken(rawIdToken string, keySet jwk.Set) (jwt.Token, error) {
+	return jwt.Parse(
+		[]byte(rawIdToken),
+		jwt.WithKeySet(keySet, jws.WithInferAlgorithmFromKey(true), jws.WithUseDefault(true)),
+		jwt.WithValidate(true),
+		jwt.WithIssuer(p.issuer),
+		jwt.WithAudience(p.oauth.ClientID),
+		jwt.WithAcceptableSkew(time.Minute),
+	)
+}
+
+func (p *Provider) getKeySet(ctx context.Context, forceRefresh bool) (jwk.Set, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	age := time.Since(p.keySetFetchedAt)
+
+	if p.keySet != nil && age < keySetMaxAge && (!forceRefresh || age < keySetMinRefreshTime) {
+		return p.keySet, nil
+	}
+
+	keySet, err := jwk.Fetch(ctx, p.jwksURI, jwk.WithHTTPClient(p.httpClient))
+	if err != nil {
+		return nil, err
+	}
+
+	p.keySet = keySet
+	p.keySetFetchedAt = time.Now()
+
+	return keySet, nil
+}
+
+// some providers send email_verified as a string
+func isEmailVerified(idToken jwt.Token) bool {
+	value, ok := idToken.Get("email_verified")
+	if !ok {
+		return false
+	}
+
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	default:
+		return false
+	}
+}
@@ -0,0 +1,53 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+type StateCreator interface {
+	CreateOIDCState(ctx context.Context, state State) error
+}
+
+// NewState generates random state, nonce and PKCE verifier and stores them for the callback
+func NewState(ctx context.Context, provider string, ttl time.Duration, stateCreator StateCreator) (State, error) {
+	const op = "auth.oidc.NewState"
+
+	stateValue, err := randomString()
+	if err != nil {
+		return State{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	nonce, err := randomString()
+	if err != nil {
+		return State{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	state := State{
+		State:        stateValue,
+		Provider:     provider,
+		CodeVerifier: oauth2.GenerateVerifier(),
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(ttl),
+	}
+
+	if err := stateCreator.CreateOIDCState(ctx, state); err != nil {
+		return State{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return state, nil
+}
+
+func randomString() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
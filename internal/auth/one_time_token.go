@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+const (
+	PurposePasswordReset     = "password_reset"
+	PurposeEmailVerification = "email_verification"
+	PurposeEmailChange       = "email_change"
+)
+
+type OneTimeTokenCreator interface {
+	CreateOneTimeToken(ctx context.Context, userId, purpose, tokenHash string, expiresAt time.Time) error
+}
+
+type OneTimeTokenConsumer interface {
+	ConsumeOneTimeToken(ctx context.Context, purpose, tokenHash string) (string, error)
+}
+
+// IssueOneTimeToken stores hash of a random single-use token and returns the token itself
+func IssueOneTimeToken(ctx context.Context, userId, purpose string, ttl time.Duration, salt string, creator OneTimeTokenCreator) (string, error) {
+	const op = "auth.IssueOneTimeToken"
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	token := hex.EncodeToString(buf)
+
+	err := creator.CreateOneTimeToken(ctx, userId, purpose, HashToken(token, salt), time.Now().Add(ttl))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return token, nil
+}
+
+// ConsumeOneTimeToken marks token as used and returns its owner id
+func ConsumeOneTimeToken(ctx context.Context, token, purpose, salt string, consumer OneTimeTokenConsumer) (string, error) {
+	const op = "auth.ConsumeOneTimeToken"
+
+	userId, err := consumer.ConsumeOneTimeToken(ctx, purpose, HashToken(token, salt))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userId, nil
+}
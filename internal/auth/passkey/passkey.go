@@ -0,0 +1,277 @@
+package passkey
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"main/internal/config"
+	"main/internal/models/user"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+const (
+	PurposeRegistration = "registration"
+	PurposeLogin        = "login"
+)
+
+var (
+	ErrInvalidCredential = errors.New("invalid webauthn credential")
+	ErrSessionMismatch   = errors.New("webauthn session does not belong to user")
+	ErrCloneDetected     = errors.New("passkey sign count did not increase, authenticator may be cloned")
+)
+
+type Passkey struct {
+	Id              string     `json:"id" db:"id"`
+	UserId          string     `json:"-" db:"user_id"`
+	Name            string     `json:"name" db:"name"`
+	CredentialId    []byte     `json:"-" db:"credential_id"`
+	PublicKey       []byte     `json:"-" db:"public_key"`
+	AttestationType string     `json:"-" db:"attestation_type"`
+	Transports      string     `json:"-" db:"transports"`
+	AAGUID          []byte     `json:"-" db:"aaguid"`
+	SignCount       int64      `json:"-" db:"sign_count"`
+	BackupEligible  bool       `json:"-" db:"backup_eligible"`
+	BackupState     bool       `json:"-" db:"backup_state"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt      *time.Time `json:"last_used_at" db:"last_used_at"`
+}
+
+// Session keeps challenge of a registration or login ceremony until it is finished
+type Session struct {
+	Id        string    `db:"id"`
+	UserId    *string   `db:"user_id"`
+	Purpose   string    `db:"purpose"`
+	Data      []byte    `db:"data"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
+
+type SessionStore interface {
+	CreateWebAuthnSession(ctx context.Context, session Session) error
+	ConsumeWebAuthnSession(ctx context.Context, id, purpose string) (Session, error)
+}
+
+type PasskeyCreator interface {
+	CreatePasskey(ctx context.Context, passkey Passkey) error
+}
+
+type PasskeyAuthenticator interface {
+	GetUserPasskeys(ctx context.Context, userId string) ([]Passkey, error)
+	UpdatePasskeyUsage(ctx context.Context, id string, signCount int64, backupState bool) error
+}
+
+type WebAuthn struct {
+	webauthn   *webauthn.WebAuthn
+	sessionTTL time.Duration
+}
+
+func New(cfg config.WebAuthn) (*WebAuthn, error) {
+	const op = "auth.passkey.New"
+
+	timeout := webauthn.TimeoutConfig{
+		Enforce:    true,
+		Timeout:    cfg.SessionTTL,
+		TimeoutUVD: cfg.SessionTTL,
+	}
+
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.RPID,
+		RPDisplayName: cfg.RPDisplayName,
+		RPOrigins:     cfg.RPOrigins,
+		// attestation statements are not verified, so don't ask authenticators for them
+		AttestationPreference: protocol.PreferNoAttestation,
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			ResidentKey:      protocol.ResidentKeyRequirementRequired,
+			UserVerification: protocol.VerificationPreferred,
+		},
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        timeout,
+			Registration: timeout,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &WebAuthn{webauthn: w, sessionTTL: cfg.SessionTTL}, nil
+}
+
+// BeginRegistration generates creation options for a new passkey, already registered
+// passkeys are excluded so the same authenticator can't be registered twice
+func (w *WebAuthn) BeginRegistration(ctx context.Context, u user.User, passkeys []Passkey, sessionStore SessionStore) (string, *protocol.CredentialCreation, error) {
+	const op = "auth.passkey.BeginRegistration"
+
+	owner := newCredentialOwner(u.ID, u.Email, u.Name, passkeys)
+
+	creation, sessionData, err := w.webauthn.BeginRegistration(
+		owner,
+		webauthn.WithExclusions(webauthn.Credentials(owner.credentials).CredentialDescriptors()),
+	)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sessionId, err := w.saveSession(ctx, &u.ID, PurposeRegistration, sessionData, sessionStore)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return sessionId, creation, nil
+}
+
+// FinishRegistration verifies authenticator response and returns passkey ready to be stored
+func (w *WebAuthn) FinishRegistration(ctx context.Context, u user.User, passkeys []Passkey, sessionId, name string, credential json.RawMessage, sessionStore SessionStore) (Passkey, error) {
+	const op = "auth.passkey.FinishRegistration"
+
+	sessionData, err := w.consumeSession(ctx, sessionId, PurposeRegistration, &u.ID, sessionStore)
+	if err != nil {
+		return Passkey{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(credential)
+	if err != nil {
+		return Passkey{}, fmt.Errorf("%s: %w: %w", op, ErrInvalidCredential, err)
+	}
+
+	created, err := w.webauthn.CreateCredential(newCredentialOwner(u.ID, u.Email, u.Name, passkeys), sessionData, parsed)
+	if err != nil {
+		return Passkey{}, fmt.Errorf("%s: %w: %w", op, ErrInvalidCredential, err)
+	}
+
+	transports := make([]string, 0, len(created.Transport))
+	for _, transport := range created.Transport {
+		transports = append(transports, string(transport))
+	}
+
+	return Passkey{
+		UserId:          u.ID,
+		Name:            name,
+		CredentialId:    created.ID,
+		PublicKey:       created.PublicKey,
+		AttestationType: created.AttestationType,
+		Transports:      strings.Join(transports, ","),
+		AAGUID:          created.Authenticator.AAGUID,
+		SignCount:       int64(created.Authenticator.SignCount),
+		BackupEligible:  created.Flags.BackupEligible,
+		BackupState:     created.Flags.BackupState,
+	}, nil
+}
+
+// BeginLogin generates assertion options for discoverable login, user is
+// identified later by the user handle returned from authenticator
+func (w *WebAuthn) BeginLogin(ctx context.Context, sessionStore SessionStore) (string, *protocol.CredentialAssertion, error) {
+	const op = "auth.passkey.BeginLogin"
+
+	assertion, sessionData, err := w.webauthn.BeginDiscoverableLogin()
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sessionId, err := w.saveSession(ctx, nil, PurposeLogin, sessionData, sessionStore)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return sessionId, assertion, nil
+}
+
+// FinishLogin verifies assertion signature and sign count and returns id of the authenticated user
+func (w *WebAuthn) FinishLogin(ctx context.Context, sessionId string, credential json.RawMessage, sessionStore SessionStore, passkeyAuthenticator PasskeyAuthenticator) (string, error) {
+	const op = "auth.passkey.FinishLogin"
+
+	sessionData, err := w.consumeSession(ctx, sessionId, PurposeLogin, nil, sessionStore)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(credential)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w: %w", op, ErrInvalidCredential, err)
+	}
+
+	var userPasskeys []Passkey
+
+	handler := func(_, userHandle []byte) (webauthn.User, error) {
+		passkeys, err := passkeyAuthenticator.GetUserPasskeys(ctx, string(userHandle))
+		if err != nil {
+			return nil, err
+		}
+
+		userPasskeys = passkeys
+
+		return newCredentialOwner(string(userHandle), "", "", passkeys), nil
+	}
+
+	owner, validated, err := w.webauthn.ValidatePasskeyLogin(handler, sessionData, parsed)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w: %w", op, ErrInvalidCredential, err)
+	}
+
+	if validated.Authenticator.CloneWarning {
+		return "", fmt.Errorf("%s: %w", op, ErrCloneDetected)
+	}
+
+	for _, passkey := range userPasskeys {
+		if !bytes.Equal(passkey.CredentialId, validated.ID) {
+			continue
+		}
+
+		err := passkeyAuthenticator.UpdatePasskeyUsage(ctx, passkey.Id, int64(validated.Authenticator.SignCount), validated.Flags.BackupState)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return string(owner.WebAuthnID()), nil
+}
+
+func (w *WebAuthn) saveSession(ctx context.Context, userId *string, purpose string, sessionData *webauthn.SessionData, sessionStore SessionStore) (string, error) {
+	data, err := json.Marshal(sessionData)
+	if err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	session := Session{
+		Id:        base64.RawURLEncoding.EncodeToString(buf),
+		UserId:    userId,
+		Purpose:   purpose,
+		Data:      data,
+		ExpiresAt: time.Now().Add(w.sessionTTL),
+	}
+
+	if err := sessionStore.CreateWebAuthnSession(ctx, session); err != nil {
+		return "", err
+	}
+
+	return session.Id, nil
+}
+
+func (w *WebAuthn) consumeSession(ctx context.Context, sessionId, purpose string, userId *string, sessionStore SessionStore) (webauthn.SessionData, error) {
+	session, err := sessionStore.ConsumeWebAuthnSession(ctx, sessionId, purpose)
+	if err != nil {
+		return webauthn.SessionData{}, err
+	}
+
+	if userId != nil && (session.UserId == nil || *session.UserId != *userId) {
+		return webauthn.SessionData{}, ErrSessionMismatch
+	}
+
+	var sessionData webauthn.SessionData
+	if err := json.Unmarshal(session.Data, &sessionData); err != nil {
+		return webauthn.SessionData{}, err
+	}
+
+	return sessionData, nil
+}
@@ -0,0 +1,71 @@
+package passkey
+
+import (
+	"strings"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// credentialOwner adapts our user and his passkeys to webauthn.User,
+// user id is used as the user handle
+type credentialOwner struct {
+	id          string
+	email       string
+	name        string
+	credentials []webauthn.Credential
+}
+
+func newCredentialOwner(id, email, name string, passkeys []Passkey) *credentialOwner {
+	credentials := make([]webauthn.Credential, 0, len(passkeys))
+	for _, passkey := range passkeys {
+		credentials = append(credentials, passkey.credential())
+	}
+
+	return &credentialOwner{
+		id:          id,
+		email:       email,
+		name:        name,
+		credentials: credentials,
+	}
+}
+
+func (u *credentialOwner) WebAuthnID() []byte {
+	return []byte(u.id)
+}
+
+func (u *credentialOwner) WebAuthnName() string {
+	return u.email
+}
+
+func (u *credentialOwner) WebAuthnDisplayName() string {
+	return u.name
+}
+
+func (u *credentialOwner) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+func (p Passkey) credential() webauthn.Credential {
+	var transports []protocol.AuthenticatorTransport
+	if p.Transports != "" {
+		for _, transport := range strings.Split(p.Transports, ",") {
+			transports = append(transports, protocol.AuthenticatorTransport(transport))
+		}
+	}
+
+	return webauthn.Credential{
+		ID:              p.CredentialId,
+		PublicKey:       p.PublicKey,
+		AttestationType: p.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			BackupEligible: p.BackupEligible,
+			BackupState:    p.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    p.AAGUID,
+			SignCount: uint32(p.SignCount),
+		},
+	}
+}
@@ -0,0 +1,159 @@
+package pat
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"main/internal/auth"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// Prefix makes personal access tokens recognizable, both for the
+	// authenticator and for secret scanners
+	Prefix = "pat_"
+
+	// displayedPrefixLen is how many characters of a token are kept in plain text to identify it in the list
+	displayedPrefixLen = len(Prefix) + 6
+)
+
+// claims set for requests authenticated with personal access token
+const (
+	TokenIdClaim = "pat_id"
+	ScopesClaim  = "pat_scopes"
+)
+
+const (
+	ScopeNotesRead   = "notes:read"
+	ScopeNotesWrite  = "notes:write"
+	ScopeImagesWrite = "images:write"
+)
+
+var AllScopes = []string{ScopeNotesRead, ScopeNotesWrite, ScopeImagesWrite}
+
+var (
+	ErrInvalidToken = errors.New("invalid personal access token")
+	ErrUnknownScope = errors.New("unknown scope")
+)
+
+type PersonalAccessToken struct {
+	Id         string     `json:"id" db:"id"`
+	UserId     string     `json:"-" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	Prefix     string     `json:"prefix" db:"token_prefix"`
+	TokenHash  string     `json:"-" db:"token_hash"`
+	Scopes     Scopes     `json:"scopes" db:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at" db:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Scopes are stored as a space separated string, like in OAuth scope parameter
+type Scopes []string
+
+func (s Scopes) Value() (driver.Value, error) {
+	return strings.Join(s, " "), nil
+}
+
+func (s *Scopes) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		*s = strings.Fields(v)
+	case []byte:
+		*s = strings.Fields(string(v))
+	case nil:
+		*s = Scopes{}
+	default:
+		return fmt.Errorf("unsupported scopes type %T", src)
+	}
+
+	return nil
+}
+
+func (s Scopes) Has(scope string) bool {
+	return slices.Contains(s, scope)
+}
+
+type Creator interface {
+	CreatePersonalAccessToken(ctx context.Context, token PersonalAccessToken) (PersonalAccessToken, error)
+}
+
+type Finder interface {
+	GetPersonalAccessTokenByHash(ctx context.Context, tokenHash string) (PersonalAccessToken, error)
+	TouchPersonalAccessToken(ctx context.Context, id string) error
+}
+
+// Generate creates a new token, only its hash is stored so the returned
+// plain token has to be shown to the user right away
+func Generate(ctx context.Context, userId, name string, scopes []string, expiresAt *time.Time, salt string, creator Creator) (string, PersonalAccessToken, error) {
+	const op = "auth.pat.Generate"
+
+	for _, scope := range scopes {
+		if !slices.Contains(AllScopes, scope) {
+			return "", PersonalAccessToken{}, fmt.Errorf("%s: %w: %s", op, ErrUnknownScope, scope)
+		}
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", PersonalAccessToken{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	token := Prefix + base64.RawURLEncoding.EncodeToString(buf)
+
+	created, err := creator.CreatePersonalAccessToken(ctx, PersonalAccessToken{
+		UserId:    userId,
+		Name:      name,
+		Prefix:    token[:displayedPrefixLen],
+		TokenHash: auth.HashToken(token, salt),
+		Scopes:    Scopes(slices.Compact(slices.Sorted(slices.Values(scopes)))),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return "", PersonalAccessToken{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return token, created, nil
+}
+
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, Prefix)
+}
+
+type Verifier struct {
+	finder Finder
+	salt   string
+}
+
+func NewVerifier(finder Finder, salt string) *Verifier {
+	return &Verifier{finder: finder, salt: salt}
+}
+
+// Authenticate finds not expired token and records its usage
+func (v *Verifier) Authenticate(ctx context.Context, token string) (PersonalAccessToken, error) {
+	const op = "auth.pat.Verifier.Authenticate"
+
+	if !IsPersonalAccessToken(token) {
+		return PersonalAccessToken{}, ErrInvalidToken
+	}
+
+	found, err := v.finder.GetPersonalAccessTokenByHash(ctx, auth.HashToken(token, v.salt))
+	if err != nil {
+		return PersonalAccessToken{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if found.ExpiresAt != nil && !found.ExpiresAt.After(time.Now()) {
+		return PersonalAccessToken{}, ErrInvalidToken
+	}
+
+	if err := v.finder.TouchPersonalAccessToken(ctx, found.Id); err != nil {
+		return PersonalAccessToken{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return found, nil
+}
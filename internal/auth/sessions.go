@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type SessionsRevoker interface {
+	DeleteUserRefreshTokens(ctx context.Context, userId string) (int, error)
+}
+
+type TokensInvalidator interface {
+	RevokeAllBefore(ctx context.Context, userId string, t time.Time) error
+}
+
+// RevokeAllSessions invalidates every access token issued so far and deletes all refresh tokens of the user
+func RevokeAllSessions(ctx context.Context, userId string, sessionsRevoker SessionsRevoker, tokensInvalidator TokensInvalidator) (int, error) {
+	const op = "auth.RevokeAllSessions"
+
+	err := tokensInvalidator.RevokeAllBefore(ctx, userId, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	revoked, err := sessionsRevoker.DeleteUserRefreshTokens(ctx, userId)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return revoked, nil
+}
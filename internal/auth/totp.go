@@ -0,0 +1,210 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"image/png"
+	"main/internal/config"
+	"main/internal/storage"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+const (
+	totpPeriod = 30
+	totpSkew   = 1
+
+	recoveryCodesCount = 10
+
+	challengePurpose = "2fa_challenge"
+	qrCodeSize       = 256
+)
+
+var ErrInvalidChallenge = errors.New("invalid two-factor challenge")
+
+type TOTP struct {
+	UserId       string     `json:"user_id" db:"user_id"`
+	Secret       string     `json:"-" db:"secret"`
+	LastUsedStep int64      `json:"-" db:"last_used_step"`
+	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+	QRCode []byte `json:"qr_code_png"`
+}
+
+func GenerateTOTP(issuer, accountName string) (TOTPEnrollment, error) {
+	const op = "auth.GenerateTOTP"
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      issuer,
+		AccountName: accountName,
+		Period:      totpPeriod,
+	})
+	if err != nil {
+		return TOTPEnrollment{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	img, err := key.Image(qrCodeSize, qrCodeSize)
+	if err != nil {
+		return TOTPEnrollment{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var qrCode bytes.Buffer
+	if err := png.Encode(&qrCode, img); err != nil {
+		return TOTPEnrollment{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return TOTPEnrollment{
+		Secret: key.Secret(),
+		URI:    key.URL(),
+		QRCode: qrCode.Bytes(),
+	}, nil
+}
+
+// ValidateTOTPCode checks code within allowed skew and returns its time step;
+// codes from steps not newer than lastUsedStep are rejected to prevent replays
+func ValidateTOTPCode(secret, code string, lastUsedStep int64, now time.Time) (int64, bool) {
+	currentStep := now.Unix() / totpPeriod
+
+	for skew := int64(-totpSkew); skew <= totpSkew; skew++ {
+		step := currentStep + skew
+		if step <= lastUsedStep {
+			continue
+		}
+
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(step*totpPeriod, 0), totp.ValidateOpts{
+			Period:    totpPeriod,
+			Digits:    otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err != nil {
+			return 0, false
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// GenerateRecoveryCodes returns plain codes to show the user once and their hashes to store
+func GenerateRecoveryCodes(salt string) ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodesCount)
+	hashes := make([]string, 0, recoveryCodesCount)
+
+	for range recoveryCodesCount {
+		buf := make([]byte, 6)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+
+		raw := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf))
+		code := raw[:5] + "-" + raw[5:]
+
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code, salt))
+	}
+
+	return codes, hashes, nil
+}
+
+func HashRecoveryCode(code, salt string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+
+	return HashToken(normalized, salt)
+}
+
+// GenerateTwoFactorChallenge issues a short-lived token proving that password was already checked
+func GenerateTwoFactorChallenge(userId string, cfg *config.Config, tokenAuth TokenAuth) (string, error) {
+	const op = "auth.GenerateTwoFactorChallenge"
+
+	issuedAt := time.Now()
+
+	_, challenge, err := tokenAuth.Encode(map[string]interface{}{
+		"jti":     uuid.New().String(),
+		"sub":     userId,
+		"purpose": challengePurpose,
+		"iat":     issuedAt,
+		"exp":     issuedAt.Add(cfg.Authorization.TwoFactorChallengeTTL),
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return challenge, nil
+}
+
+func ParseTwoFactorChallenge(challenge string, tokenAuth TokenAuth) (jwt.Token, error) {
+	const op = "auth.ParseTwoFactorChallenge"
+
+	token, err := tokenAuth.Decode(challenge)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %w", op, ErrInvalidChallenge, err)
+	}
+
+	if err := jwt.Validate(token); err != nil {
+		return nil, fmt.Errorf("%s: %w: %w", op, ErrInvalidChallenge, err)
+	}
+
+	purpose, _ := token.Get("purpose")
+	if purpose != challengePurpose || token.Subject() == "" {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidChallenge)
+	}
+
+	return token, nil
+}
+
+type SecondFactorVerifier interface {
+	UpdateTOTPLastUsedStep(ctx context.Context, userId string, step int64) error
+	UseRecoveryCode(ctx context.Context, userId, codeHash string) error
+}
+
+// VerifySecondFactor accepts either a current TOTP code or an unused recovery code
+func VerifySecondFactor(ctx context.Context, userTOTP TOTP, code, salt string, verifier SecondFactorVerifier) (bool, error) {
+	const op = "auth.VerifySecondFactor"
+
+	code = strings.TrimSpace(code)
+
+	if len(code) == otp.DigitsSix.Length() {
+		step, ok := ValidateTOTPCode(userTOTP.Secret, code, userTOTP.LastUsedStep, time.Now())
+		if !ok {
+			return false, nil
+		}
+
+		err := verifier.UpdateTOTPLastUsedStep(ctx, userTOTP.UserId, step)
+		if errors.Is(err, storage.ErrTOTPCodeAlreadyUsed) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return true, nil
+	}
+
+	err := verifier.UseRecoveryCode(ctx, userTOTP.UserId, HashRecoveryCode(code, salt))
+	if errors.Is(err, storage.ErrRecoveryCodeNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return true, nil
+}
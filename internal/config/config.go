@@ -13,11 +13,30 @@ import (
 type Config struct {
 	Env            string `mapstructure:"env"`
 	MigrationsPath string `mapstructure:"migrations_path"`
-	IsReload       bool   `mapstructure:"is_reload"`
+	AutoMigrate    bool   `mapstructure:"auto_migrate"`
+	Storage        `mapstructure:"storage"`
 	Postgres       `mapstructure:"postgres"`
+	SQLite         `mapstructure:"sqlite"`
+	Cache          `mapstructure:"cache"`
 	HTTPServer     `mapstructure:"http_server"`
 	Authorization  `mapstructure:"authorization"`
 	Image          `mapstructure:"image"`
+	Mail           `mapstructure:"mail"`
+	OIDC           `mapstructure:"oidc"`
+	WebAuthn       `mapstructure:"webauthn"`
+	Redis          `mapstructure:"redis"`
+	RateLimit      `mapstructure:"rate_limit"`
+	Workspaces     `mapstructure:"workspaces"`
+	Audit          `mapstructure:"audit"`
+	Webhooks       `mapstructure:"webhooks"`
+	Jobs           `mapstructure:"jobs"`
+	Metrics        `mapstructure:"metrics"`
+	Tracing        `mapstructure:"tracing"`
+}
+
+type Storage struct {
+	// Driver is "postgres", "sqlite" or "memory"
+	Driver string `mapstructure:"driver"`
 }
 
 type Postgres struct {
@@ -28,19 +47,99 @@ type Postgres struct {
 	Name     string `mapstructure:"name"`
 	Database string `mapstructure:"database"`
 	SSLMode  string `mapstructure:"ssl_mode"`
+	// connection pool limits, zero values keep database/sql defaults
+	MaxOpenConns    int           `mapstructure:"max_open_conns"`
+	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time"`
+	// ConnectAttempts is how many times database is pinged on startup before giving up
+	ConnectAttempts int `mapstructure:"connect_attempts"`
+	// ConnectBackoff is the delay after the first failed ping, it doubles up to ConnectBackoffMax
+	ConnectBackoff    time.Duration `mapstructure:"connect_backoff"`
+	ConnectBackoffMax time.Duration `mapstructure:"connect_backoff_max"`
+	// Replicas are DSNs of read replicas, reads which tolerate lag are spread between them
+	Replicas []string `mapstructure:"replicas"`
+	// ReadYourWritesWindow is how long reads of a client go to primary after the client
+	// writes, the client carries time of the write in a cookie between instances
+	ReadYourWritesWindow time.Duration `mapstructure:"read_your_writes_window"`
+	// QueryTimeout limits every storage call, zero disables the limit
+	QueryTimeout time.Duration `mapstructure:"query_timeout"`
+	// QueryTimeouts override QueryTimeout for storage methods, keyed by method name
+	QueryTimeouts map[string]time.Duration `mapstructure:"query_timeouts"`
+}
+
+type SQLite struct {
+	// Path is the database file, migrations are embedded in the binary
+	Path string `mapstructure:"path"`
+	// QueryTimeout limits every storage call, zero disables the limit
+	QueryTimeout time.Duration `mapstructure:"query_timeout"`
+	// QueryTimeouts override QueryTimeout for storage methods, keyed by method name
+	QueryTimeouts map[string]time.Duration `mapstructure:"query_timeouts"`
+}
+
+type Cache struct {
+	// Enabled turns on caching of full notes and note access checks
+	Enabled bool `mapstructure:"enabled"`
+	// Backend is either "memory" or "redis"
+	Backend string `mapstructure:"backend"`
+	// Size is the max number of entries kept by memory backend
+	Size int `mapstructure:"size"`
+	// TTL bounds staleness of entries, in case invalidation races with a read
+	TTL time.Duration `mapstructure:"ttl"`
 }
 
 type HTTPServer struct {
 	Address     string        `mapstructure:"address"`
 	Timeout     time.Duration `mapstructure:"timeout"`
 	IdleTimeout time.Duration `mapstructure:"idle_timeout"`
+	// ShutdownTimeout is how long requests in flight are waited for on shutdown
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	// TrustedProxies are CIDRs or addresses of reverse proxies whose
+	// X-Forwarded-For is believed. Empty means the peer address is the client
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 type Authorization struct {
-	JWTSecret  string        `mapstructure:"secret"`
-	AccessTTL  time.Duration `mapstructure:"access_ttl"`
-	RefreshTTL time.Duration `mapstructure:"refresh_ttl"`
-	Salt       string        `mapstructure:"salt"`
+	JWTSecret             string          `mapstructure:"secret"`
+	AccessTTL             time.Duration   `mapstructure:"access_ttl"`
+	RefreshTTL            time.Duration   `mapstructure:"refresh_ttl"`
+	RefreshReuseGrace     time.Duration   `mapstructure:"refresh_reuse_grace"`
+	DenylistCacheTTL      time.Duration   `mapstructure:"denylist_cache_ttl"`
+	PasswordResetTTL      time.Duration   `mapstructure:"password_reset_ttl"`
+	VerificationTTL       time.Duration   `mapstructure:"email_verification_ttl"`
+	TOTPIssuer            string          `mapstructure:"totp_issuer"`
+	TwoFactorChallengeTTL time.Duration   `mapstructure:"two_factor_challenge_ttl"`
+	Salt                  string          `mapstructure:"salt"`
+	Signing               Signing         `mapstructure:"signing"`
+	LoginProtection       LoginProtection `mapstructure:"login_protection"`
+	// AdminEmails are granted admin role on startup, if such users exist
+	AdminEmails []string `mapstructure:"admin_emails"`
+}
+
+type LoginProtection struct {
+	// Store is either "postgres" or "memory"
+	Store              string `mapstructure:"store"`
+	MaxAccountFailures int    `mapstructure:"max_account_failures"`
+	// MaxIPFailures refuses logins from the ip while it has that many failures within FailureWindow
+	MaxIPFailures   int           `mapstructure:"max_ip_failures"`
+	BaseDelay       time.Duration `mapstructure:"base_delay"`
+	MaxDelay        time.Duration `mapstructure:"max_delay"`
+	LockoutDuration time.Duration `mapstructure:"lockout_duration"`
+	FailureWindow   time.Duration `mapstructure:"failure_window"`
+	// MaxChallengeFailures is how many wrong codes revoke a two-factor challenge
+	MaxChallengeFailures int `mapstructure:"max_challenge_failures"`
+}
+
+type Signing struct {
+	ActiveKeyId string       `mapstructure:"active_key_id"`
+	Keys        []SigningKey `mapstructure:"keys"`
+}
+
+type SigningKey struct {
+	Id             string `mapstructure:"id"`
+	Algorithm      string `mapstructure:"algorithm"`
+	PrivateKeyPath string `mapstructure:"private_key_path"`
+	PublicKeyPath  string `mapstructure:"public_key_path"`
 }
 
 type Image struct {
@@ -49,6 +148,60 @@ type Image struct {
 	MaxWidth  uint   `mapstructure:"max_width"`
 }
 
+type Mail struct {
+	Driver  string `mapstructure:"driver"`
+	From    string `mapstructure:"from"`
+	BaseURL string `mapstructure:"base_url"`
+	FileDir string `mapstructure:"file_dir"`
+	SMTP    SMTP   `mapstructure:"smtp"`
+}
+
+type SMTP struct {
+	Host     string `mapstructure:"host"`
+	Port     string `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+type OIDC struct {
+	StateTTL  time.Duration  `mapstructure:"state_ttl"`
+	Providers []OIDCProvider `mapstructure:"providers"`
+}
+
+type OIDCProvider struct {
+	Name         string   `mapstructure:"name"`
+	IssuerURL    string   `mapstructure:"issuer_url"`
+	ClientId     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"`
+	RedirectURL  string   `mapstructure:"redirect_url"`
+	Scopes       []string `mapstructure:"scopes"`
+}
+
+type WebAuthn struct {
+	RPID          string        `mapstructure:"rp_id"`
+	RPDisplayName string        `mapstructure:"rp_display_name"`
+	RPOrigins     []string      `mapstructure:"rp_origins"`
+	SessionTTL    time.Duration `mapstructure:"session_ttl"`
+}
+
+type Redis struct {
+	Address  string `mapstructure:"address"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db"`
+}
+
+type RateLimit struct {
+	// Backend is either "memory" or "redis"
+	Backend string                    `mapstructure:"backend"`
+	Groups  map[string]RateLimitGroup `mapstructure:"groups"`
+}
+
+type RateLimitGroup struct {
+	Requests int           `mapstructure:"requests"`
+	Period   time.Duration `mapstructure:"period"`
+	Burst    int           `mapstructure:"burst"`
+}
+
 func MustLoad() *Config {
 	var cfgPath string
 
@@ -81,3 +234,60 @@ func MustLoad() *Config {
 
 	return cfg
 }
+
+type Workspaces struct {
+	InvitationTTL time.Duration `mapstructure:"invitation_ttl"`
+}
+
+type Audit struct {
+	// Retention is how long audit events are kept
+	Retention time.Duration `mapstructure:"retention"`
+}
+
+type Webhooks struct {
+	Timeout      time.Duration `mapstructure:"timeout"`
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	BatchSize    int           `mapstructure:"batch_size"`
+	// MaxAttempts is how many times delivery is tried before it's marked as failed
+	MaxAttempts int           `mapstructure:"max_attempts"`
+	BackoffBase time.Duration `mapstructure:"backoff_base"`
+	BackoffMax  time.Duration `mapstructure:"backoff_max"`
+	// AllowPrivateAddresses lets webhooks target loopback and private networks, for local development only
+	AllowPrivateAddresses bool `mapstructure:"allow_private_addresses"`
+}
+
+type Jobs struct {
+	// HistoryRetention is how long job runs are kept
+	HistoryRetention time.Duration `mapstructure:"history_retention"`
+	// Schedules are keyed by job name, jobs without schedule don't run
+	Schedules map[string]JobSchedule `mapstructure:"schedules"`
+}
+
+type JobSchedule struct {
+	// Schedule is a cron expression, "@daily" like shorthand or "@every 10m" interval
+	Schedule   string        `mapstructure:"schedule"`
+	Timeout    time.Duration `mapstructure:"timeout"`
+	Retries    int           `mapstructure:"retries"`
+	RetryDelay time.Duration `mapstructure:"retry_delay"`
+}
+
+type Metrics struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Address of a separate admin listener, empty address serves metrics on the main one
+	Address string `mapstructure:"address"`
+	Path    string `mapstructure:"path"`
+}
+
+type Tracing struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	ServiceName string `mapstructure:"service_name"`
+	// Exporter is one of "otlp-grpc", "otlp-http", "stdout" or "file"
+	Exporter string `mapstructure:"exporter"`
+	// Endpoint is host:port of OTLP collector
+	Endpoint string `mapstructure:"endpoint"`
+	Insecure bool   `mapstructure:"insecure"`
+	// FilePath is where "file" exporter writes spans, one json object per line
+	FilePath string `mapstructure:"file_path"`
+	// SampleRatio is share of new traces recorded, incoming sampled traces are always recorded
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
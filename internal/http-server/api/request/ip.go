@@ -0,0 +1,18 @@
+package request
+
+import (
+	"net"
+	"net/http"
+)
+
+// ClientIP returns ip of the remote peer without port. RemoteAddr is used
+// as is, behind a reverse proxy it is the client address only when the proxy
+// is listed in http_server.trusted_proxies, otherwise all clients share the proxy ip
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
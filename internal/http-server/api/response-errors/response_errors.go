@@ -8,17 +8,51 @@ var (
 	ErrUserNotOwner        = errors.New("user is not owner or note is not exists")
 	ErrInvalidRequestBody  = errors.New("invalid request body")
 	ErrInternalServerError = errors.New("internal server error")
+	ErrRequestTimeout      = errors.New("request timed out")
+	ErrRateLimitExceeded   = errors.New("rate limit exceeded, try again later")
 
 	ErrUserUnauthorized = errors.New("user unauthorized")
 
 	ErrAccessTokenExpired  = errors.New("access token expired")
 	ErrInvalidAccessToken  = errors.New("invalid access token")
+	ErrAccessTokenRevoked  = errors.New("access token revoked")
 	ErrRefreshTokenExpired = errors.New("refresh token expired")
 	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
 
-	ErrUserDoesNotExist    = errors.New("user does not exist")
-	ErrUserIsAlreadyExists = errors.New("user is already exists")
-	ErrInvalidPassword     = errors.New("invalid password")
+	ErrUserDoesNotExist     = errors.New("user does not exist")
+	ErrUserIsAlreadyExists  = errors.New("user is already exists")
+	ErrInvalidPassword      = errors.New("invalid password")
+	ErrTooManyLoginAttempts = errors.New("too many failed login attempts, try again later")
+	ErrInvalidOneTimeToken  = errors.New("token is invalid or expired")
+	ErrEmailAlreadyVerified = errors.New("email is already verified")
+	ErrSameEmail            = errors.New("new email is the same as current")
+	ErrAccountDisabled      = errors.New("account is disabled")
+
+	ErrInvalidTwoFactorCode      = errors.New("invalid two-factor code")
+	ErrInvalidTwoFactorChallenge = errors.New("invalid or expired two-factor challenge")
+	ErrTwoFactorAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled       = errors.New("two-factor authentication is not enabled")
+
+	ErrOIDCProviderNotFound       = errors.New("oidc provider not found")
+	ErrInvalidOIDCState           = errors.New("invalid or expired oidc state")
+	ErrOIDCLoginFailed            = errors.New("failed to login with oidc provider")
+	ErrEmailNotVerifiedByProvider = errors.New("email is not verified by provider, it can not be used to sign in")
+	ErrAccountEmailNotVerified    = errors.New("email of existing account is not verified, log in with password and verify it to link the account")
+
+	ErrInvalidWebAuthnSession   = errors.New("invalid or expired webauthn session")
+	ErrInvalidPasskeyCredential = errors.New("invalid passkey credential")
+	ErrPasskeyDoesNotExist      = errors.New("passkey does not exist")
+	ErrPasskeyIsAlreadyExists   = errors.New("passkey is already registered")
+
+	ErrInsufficientScope               = errors.New("token does not have required scope")
+	ErrUnknownScope                    = errors.New("unknown scope")
+	ErrPersonalAccessTokenDoesNotExist = errors.New("personal access token does not exist")
+
+	ErrInsufficientRole  = errors.New("user does not have required role")
+	ErrCannotManageSelf  = errors.New("administrators can't disable or demote themselves")
+	ErrInvalidPagination = errors.New("invalid 'limit' or 'offset' param")
+	ErrInvalidTimeRange  = errors.New("invalid 'from' or 'to' param, RFC 3339 time expected")
 
 	ErrFailedToAddNoteNode          = errors.New("failed to add note node")
 	ErrFailedToDeleteNode           = errors.New("failed to delete node")
@@ -43,4 +77,19 @@ var (
 	ErrFailedToUpdateNodesOrder = errors.New("failed to update nodes order")
 	ErrFailedToUpdateNoteTitle  = errors.New("failed to update note title")
 	ErrFailedToGetNoteNodes     = errors.New("failed to get note nodes")
+
+	ErrWorkspaceDoesNotExist       = errors.New("workspace does not exist")
+	ErrInsufficientWorkspaceRole   = errors.New("not enough rights in workspace")
+	ErrWorkspaceMemberDoesNotExist = errors.New("workspace member does not exist")
+	ErrLastWorkspaceOwner          = errors.New("workspace must have at least one owner")
+	ErrSoleWorkspaceOwner          = errors.New("account is the only owner of a workspace, transfer ownership or delete the workspace first")
+	ErrAlreadyWorkspaceMember      = errors.New("user is already workspace member")
+	ErrInvitationDoesNotExist      = errors.New("invitation does not exist or expired")
+	ErrInvitationIsAlreadyExists   = errors.New("invitation is already sent")
+	ErrEmailNotVerified            = errors.New("email is not verified")
+
+	ErrWebhookDoesNotExist         = errors.New("webhook does not exist")
+	ErrWebhookDeliveryDoesNotExist = errors.New("webhook delivery does not exist")
+	ErrUnknownWebhookEvent         = errors.New("unknown webhook event")
+	ErrForbiddenWebhookURL         = errors.New("webhook url must point to a public address")
 )
@@ -1,12 +1,18 @@
 package validate
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"main/internal/audit"
 	resp "main/internal/http-server/api/response"
 	resperrors "main/internal/http-server/api/response-errors"
 	"main/internal/models/note"
+	"main/internal/storage"
+	"main/internal/webhook"
 	"net/http"
 	"strconv"
+	"time"
 
 	"log/slog"
 
@@ -14,19 +20,42 @@ import (
 	"github.com/go-chi/jwtauth/v5"
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 )
 
+// StatusClientClosedRequest is a non-standard status of requests abandoned by the client
+const StatusClientClosedRequest = 499
+
+// InternalError replies to request which failed with err. Requests abandoned by the
+// client get 499 and timed out queries get 504, other failures get 500 with respErr
+func InternalError(w http.ResponseWriter, r *http.Request, err error, respErr error) {
+	switch {
+	case r.Context().Err() != nil:
+		w.WriteHeader(StatusClientClosedRequest)
+		render.JSON(w, r, resp.Error(resperrors.ErrRequestTimeout))
+	case storage.IsCanceled(err):
+		w.WriteHeader(http.StatusGatewayTimeout)
+		render.JSON(w, r, resp.Error(resperrors.ErrRequestTimeout))
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		render.JSON(w, r, resp.Error(respErr))
+	}
+}
+
+// UserVerifier checks access to notes. Personal notes are available to their owner
+// only, workspace notes to workspace members according to their role
 type UserVerifier interface {
-	IsUserNoteOwner(userId string, noteId int) (bool, error)
-	IsUserNoteNodeOwner(userId string, noteNodeId int) (bool, error)
+	CanUserEditNote(ctx context.Context, userId string, noteId int) (bool, error)
+	CanUserEditNoteNode(ctx context.Context, userId string, noteNodeId int) (bool, error)
+	CanUserReadNote(ctx context.Context, userId string, noteId int) (bool, error)
+	CanUserReadNoteNode(ctx context.Context, userId string, noteNodeId int) (bool, error)
 }
 
 func DecodeRequestJson[T any](dest *T, w http.ResponseWriter, r *http.Request, log *slog.Logger) error {
 	if err := render.DecodeJSON(r.Body, dest); err != nil {
 		log.Error("failed to decode request body", "error", err)
 
-		w.WriteHeader(http.StatusInternalServerError)
-		render.JSON(w, r, resp.Error(resperrors.ErrInternalServerError))
+		InternalError(w, r, err, resperrors.ErrInternalServerError)
 
 		return err
 	}
@@ -44,8 +73,7 @@ func DecodeAndValidateRequestJson[T any](dest *T, w http.ResponseWriter, r *http
 	if err := val.RegisterValidation("custom_url", categoryValidator); err != nil {
 		log.Error("validator init error", "error", err)
 
-		w.WriteHeader(http.StatusInternalServerError)
-		render.JSON(w, r, resp.Error(resperrors.ErrInternalServerError))
+		InternalError(w, r, err, resperrors.ErrInternalServerError)
 
 		return err
 	}
@@ -80,50 +108,222 @@ func GetIntURLParam(paramName string, w http.ResponseWriter, r *http.Request, lo
 	return intParam, nil
 }
 
-func VerifyUserNote(id int, userVerifier UserVerifier, w http.ResponseWriter, r *http.Request, log *slog.Logger) error {
+// GetUserIdURLParam reads user id from 'id' URL param, malformed ids are reported as missing users
+func GetUserIdURLParam(w http.ResponseWriter, r *http.Request, log *slog.Logger) (string, error) {
+	userId := chi.URLParam(r, "id")
+
+	if _, err := uuid.Parse(userId); err != nil {
+		log.Error("invalid user id", slog.String("id", userId))
+
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, resp.Error(resperrors.ErrUserDoesNotExist))
+
+		return "", err
+	}
+
+	return userId, nil
+}
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 100
+)
+
+// GetPagination reads optional 'limit' and 'offset' query params
+func GetPagination(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int, int, error) {
+	limit, offset := DefaultPageLimit, 0
+
+	var err error
+
+	if strLimit := r.URL.Query().Get("limit"); strLimit != "" {
+		limit, err = strconv.Atoi(strLimit)
+		if err == nil && (limit <= 0 || limit > MaxPageLimit) {
+			err = fmt.Errorf("limit must be between 1 and %d", MaxPageLimit)
+		}
+	}
+
+	if strOffset := r.URL.Query().Get("offset"); err == nil && strOffset != "" {
+		offset, err = strconv.Atoi(strOffset)
+		if err == nil && offset < 0 {
+			err = fmt.Errorf("offset must not be negative")
+		}
+	}
+
+	if err != nil {
+		log.Error("invalid pagination params", "error", err)
+
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, resp.Error(resperrors.ErrInvalidPagination))
+
+		return 0, 0, err
+	}
+
+	return limit, offset, nil
+}
+
+type WebhookGetter interface {
+	GetWebhook(ctx context.Context, id, userId string) (webhook.Webhook, error)
+}
+
+// VerifyUserWebhook reads webhook from 'id' URL param, webhooks of other users are reported as missing
+func VerifyUserWebhook(webhookGetter WebhookGetter, w http.ResponseWriter, r *http.Request, log *slog.Logger) (webhook.Webhook, error) {
 	_, claims, _ := jwtauth.FromContext(r.Context())
 
 	userId, _ := claims["user_id"].(string)
 
-	isOwner, err := userVerifier.IsUserNoteOwner(userId, id)
+	webhookId := chi.URLParam(r, "id")
+
+	if _, err := uuid.Parse(webhookId); err != nil {
+		log.Error("invalid webhook id", slog.String("webhook_id", webhookId))
+
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, resp.Error(resperrors.ErrWebhookDoesNotExist))
+
+		return webhook.Webhook{}, err
+	}
+
+	found, err := webhookGetter.GetWebhook(r.Context(), webhookId, userId)
+	if errors.Is(err, storage.ErrWebhookNotFound) {
+		log.Error("webhook not found", slog.String("webhook_id", webhookId))
+
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, resp.Error(resperrors.ErrWebhookDoesNotExist))
+
+		return webhook.Webhook{}, err
+	}
 	if err != nil {
-		log.Error("failed to check note owner", "error", err)
+		log.Error("failed to get webhook", "error", err)
 
-		w.WriteHeader(http.StatusInternalServerError)
-		render.JSON(w, r, resp.Error(resperrors.ErrInternalServerError))
+		InternalError(w, r, err, resperrors.ErrInternalServerError)
 
-		return err
+		return webhook.Webhook{}, err
 	}
 
-	if !isOwner {
-		log.Error("user is not note owner", "error", resperrors.ErrUserNotOwner, "user_id", userId, "note_id", id)
+	return found, nil
+}
 
-		w.WriteHeader(http.StatusUnauthorized)
-		render.JSON(w, r, resp.Error(resperrors.ErrUserNotOwner))
+// GetAuditFilter reads optional audit log filters and pagination from query params
+func GetAuditFilter(w http.ResponseWriter, r *http.Request, log *slog.Logger) (audit.Filter, error) {
+	limit, offset, err := GetPagination(w, r, log)
+	if err != nil {
+		return audit.Filter{}, err
+	}
 
-		return resperrors.ErrUserNotOwner
+	query := r.URL.Query()
+
+	filter := audit.Filter{
+		Action:     query.Get("action"),
+		TargetType: query.Get("target_type"),
+		TargetId:   query.Get("target_id"),
+		Limit:      limit,
+		Offset:     offset,
 	}
 
-	return nil
+	filter.From, err = parseTimeParam(query.Get("from"))
+	if err == nil {
+		filter.To, err = parseTimeParam(query.Get("to"))
+	}
+	if err != nil {
+		log.Error("invalid time range params", "error", err)
+
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, resp.Error(resperrors.ErrInvalidTimeRange))
+
+		return audit.Filter{}, err
+	}
+
+	return filter, nil
+}
+
+func parseTimeParam(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// VerifyUserNote rejects request if user is not allowed to change the note
+func VerifyUserNote(id int, userVerifier UserVerifier, w http.ResponseWriter, r *http.Request, log *slog.Logger) error {
+	return verifyAccess(userVerifier.CanUserEditNote, "note_id", id, w, r, log)
 }
 
+// VerifyUserNoteNode rejects request if user is not allowed to change the note node
 func VerifyUserNoteNode(id int, userVerifier UserVerifier, w http.ResponseWriter, r *http.Request, log *slog.Logger) error {
+	return verifyAccess(userVerifier.CanUserEditNoteNode, "note_node_id", id, w, r, log)
+}
+
+// VerifyUserNoteRead rejects request if user is not allowed to see the note
+func VerifyUserNoteRead(id int, userVerifier UserVerifier, w http.ResponseWriter, r *http.Request, log *slog.Logger) error {
+	return verifyAccess(userVerifier.CanUserReadNote, "note_id", id, w, r, log)
+}
+
+// VerifyUserNoteNodeRead rejects request if user is not allowed to see the note node
+func VerifyUserNoteNodeRead(id int, userVerifier UserVerifier, w http.ResponseWriter, r *http.Request, log *slog.Logger) error {
+	return verifyAccess(userVerifier.CanUserReadNoteNode, "note_node_id", id, w, r, log)
+}
+
+type WorkspaceRoleGetter interface {
+	GetWorkspaceRole(ctx context.Context, workspaceId, userId string) (string, error)
+}
+
+// VerifyWorkspaceMember returns role of the user in the workspace. Workspaces
+// of other users are reported as missing
+func VerifyWorkspaceMember(workspaceId string, roleGetter WorkspaceRoleGetter, w http.ResponseWriter, r *http.Request, log *slog.Logger) (string, error) {
 	_, claims, _ := jwtauth.FromContext(r.Context())
 
 	userId, _ := claims["user_id"].(string)
 
-	isOwner, err := userVerifier.IsUserNoteNodeOwner(userId, id)
+	if _, err := uuid.Parse(workspaceId); err != nil {
+		log.Error("invalid workspace id", slog.String("workspace_id", workspaceId))
+
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, resp.Error(resperrors.ErrWorkspaceDoesNotExist))
+
+		return "", err
+	}
+
+	role, err := roleGetter.GetWorkspaceRole(r.Context(), workspaceId, userId)
+	if errors.Is(err, storage.ErrWorkspaceMemberNotFound) {
+		log.Error("user is not workspace member", slog.String("user_id", userId), slog.String("workspace_id", workspaceId))
+
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, resp.Error(resperrors.ErrWorkspaceDoesNotExist))
+
+		return "", err
+	}
 	if err != nil {
-		log.Error("failed to check note node owner", "error", err)
+		log.Error("failed to get workspace role", "error", err)
 
-		w.WriteHeader(http.StatusInternalServerError)
-		render.JSON(w, r, resp.Error(resperrors.ErrInternalServerError))
+		InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+		return "", err
+	}
+
+	return role, nil
+}
+
+func verifyAccess(check func(ctx context.Context, userId string, id int) (bool, error), idKey string, id int, w http.ResponseWriter, r *http.Request, log *slog.Logger) error {
+	_, claims, _ := jwtauth.FromContext(r.Context())
+
+	userId, _ := claims["user_id"].(string)
+
+	allowed, err := check(r.Context(), userId, id)
+	if err != nil {
+		log.Error("failed to check note access", "error", err)
+
+		InternalError(w, r, err, resperrors.ErrInternalServerError)
 
 		return err
 	}
 
-	if !isOwner {
-		log.Error("user is not note node owner", "error", resperrors.ErrUserNotOwner, "user_id", userId, "note_node_id", id)
+	if !allowed {
+		log.Error("user has no access to note", "error", resperrors.ErrUserNotOwner, "user_id", userId, idKey, id)
 
 		w.WriteHeader(http.StatusUnauthorized)
 		render.JSON(w, r, resp.Error(resperrors.ErrUserNotOwner))
@@ -0,0 +1,137 @@
+package getuser
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"main/internal/config"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	uploadimage "main/internal/http-server/handler/node/upload-image"
+	"main/internal/models/user"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+	"path/filepath"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	resp.Response
+	User  user.Account `json:"data"`
+	Usage user.Usage   `json:"usage"`
+}
+
+type AccountGetter interface {
+	GetAccount(ctx context.Context, id string) (user.Account, error)
+	GetUserUsage(ctx context.Context, id string) (user.Usage, error)
+	GetUserNoteIds(ctx context.Context, userId string) ([]int, error)
+}
+
+func New(cfg *config.Config, log *slog.Logger, accountGetter AccountGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.admin.getuser.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		userId, err := validate.GetUserIdURLParam(w, r, log)
+		if err != nil {
+			return
+		}
+
+		account, err := accountGetter.GetAccount(r.Context(), userId)
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Error("user not found", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrUserDoesNotExist))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get user", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		usage, err := accountGetter.GetUserUsage(r.Context(), userId)
+		if err != nil {
+			log.Error("failed to get user usage", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		noteIds, err := accountGetter.GetUserNoteIds(r.Context(), userId)
+		if err != nil {
+			log.Error("failed to get user notes", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		// images are stored on disk only, so their size is not known to the database
+		for _, noteId := range noteIds {
+			dir := filepath.Join(cfg.Image.ImagesDir, uploadimage.HashNoteId(noteId, cfg.Image.ImageSalt))
+
+			size, err := dirSize(dir)
+			if err != nil {
+				log.Error("failed to get note images size", slog.Int("note_id", noteId), "error", err)
+				continue
+			}
+
+			usage.ImageBytes += size
+		}
+
+		log.Info("got user", slog.String("user_id", userId))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			User:     account,
+			Usage:    usage,
+		})
+	}
+}
+
+// dirSize sums sizes of regular files in dir, missing dir has zero size
+func dirSize(dir string) (int64, error) {
+	var size int64
+
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		size += info.Size()
+
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+
+	return size, err
+}
@@ -0,0 +1,68 @@
+package listjobs
+
+import (
+	"context"
+	"log/slog"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/scheduler"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	resp.Response
+	Runs   []scheduler.Run `json:"data"`
+	Limit  int             `json:"limit"`
+	Offset int             `json:"offset"`
+}
+
+type JobRunsGetter interface {
+	GetJobRuns(ctx context.Context, job, status string, limit, offset int) ([]scheduler.Run, error)
+}
+
+// New lists history of background job runs, newest first. Optional 'job'
+// and 'status' params narrow it down to a single job or outcome
+func New(log *slog.Logger, jobRunsGetter JobRunsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.admin.listjobs.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		limit, offset, err := validate.GetPagination(w, r, log)
+		if err != nil {
+			return
+		}
+
+		job := r.URL.Query().Get("job")
+		status := r.URL.Query().Get("status")
+
+		runs, err := jobRunsGetter.GetJobRuns(r.Context(), job, status, limit, offset)
+		if err != nil {
+			log.Error("failed to get job runs", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("listed job runs", slog.Int("count", len(runs)))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Runs:     runs,
+			Limit:    limit,
+			Offset:   offset,
+		})
+	}
+}
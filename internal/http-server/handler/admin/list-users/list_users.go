@@ -0,0 +1,67 @@
+package listusers
+
+import (
+	"context"
+	"log/slog"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/models/user"
+	"main/internal/tracing"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	resp.Response
+	Users  []user.Account `json:"data"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
+
+type AccountsLister interface {
+	ListAccounts(ctx context.Context, query string, limit, offset int) ([]user.Account, error)
+}
+
+// New lists users page by page, optional 'query' param matches part of email or name
+func New(log *slog.Logger, accountsLister AccountsLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.admin.listusers.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		limit, offset, err := validate.GetPagination(w, r, log)
+		if err != nil {
+			return
+		}
+
+		query := strings.TrimSpace(r.URL.Query().Get("query"))
+
+		accounts, err := accountsLister.ListAccounts(r.Context(), query, limit, offset)
+		if err != nil {
+			log.Error("failed to list users", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("listed users", slog.Int("count", len(accounts)))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Users:    accounts,
+			Limit:    limit,
+			Offset:   offset,
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package logoutuser
+
+import (
+	"errors"
+	"log/slog"
+	"main/internal/audit"
+	"main/internal/auth"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type SessionsRevoker interface {
+	auth.SessionsRevoker
+	audit.Recorder
+}
+
+func New(log *slog.Logger, sessionsRevoker SessionsRevoker, tokensInvalidator auth.TokensInvalidator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.admin.logoutuser.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		adminId, _ := claims["user_id"].(string)
+
+		userId, err := validate.GetUserIdURLParam(w, r, log)
+		if err != nil {
+			return
+		}
+
+		revoked, err := auth.RevokeAllSessions(r.Context(), userId, sessionsRevoker, tokensInvalidator)
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Error("user not found", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrUserDoesNotExist))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to revoke sessions", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Warn("security audit: user logged out by admin",
+			slog.String("admin_id", adminId),
+			slog.String("user_id", userId),
+			slog.Int("revoked_sessions", revoked),
+		)
+
+		audit.Record(r, log, sessionsRevoker, audit.Event{
+			Action:     audit.ActionAdminLogoutUser,
+			ActorId:    audit.Actor(adminId),
+			TargetType: audit.TargetUser,
+			TargetId:   userId,
+		})
+
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -0,0 +1,89 @@
+package resettwofactor
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"main/internal/audit"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/models/user"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type TwoFactorResetter interface {
+	GetUserById(ctx context.Context, id string) (user.User, error)
+	DeleteTOTP(ctx context.Context, userId string) error
+	audit.Recorder
+}
+
+// New removes totp secret and recovery codes of the user who lost access to the authenticator
+func New(log *slog.Logger, twoFactorResetter TwoFactorResetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.admin.resettwofactor.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		adminId, _ := claims["user_id"].(string)
+
+		userId, err := validate.GetUserIdURLParam(w, r, log)
+		if err != nil {
+			return
+		}
+
+		_, err = twoFactorResetter.GetUserById(r.Context(), userId)
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Error("user not found", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrUserDoesNotExist))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get user", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		err = twoFactorResetter.DeleteTOTP(r.Context(), userId)
+		if err != nil {
+			log.Error("failed to delete totp", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Warn("security audit: two-factor reset by admin",
+			slog.String("admin_id", adminId),
+			slog.String("user_id", userId),
+		)
+
+		audit.Record(r, log, twoFactorResetter, audit.Event{
+			Action:     audit.ActionAdminResetTwoFactor,
+			ActorId:    audit.Actor(adminId),
+			TargetType: audit.TargetUser,
+			TargetId:   userId,
+		})
+
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -0,0 +1,110 @@
+package setrole
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"main/internal/audit"
+	"main/internal/auth"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/models/user"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type Request struct {
+	Role string `json:"role" validate:"required,oneof=user admin"`
+}
+
+type RoleSetter interface {
+	SetUserRole(ctx context.Context, id, role string) error
+	audit.Recorder
+}
+
+// New changes role of the user. Role is carried in access tokens, so they are
+// revoked and the next refresh issues tokens with the new role
+func New(log *slog.Logger, roleSetter RoleSetter, tokensInvalidator auth.TokensInvalidator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.admin.setrole.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		adminId, _ := claims["user_id"].(string)
+
+		userId, err := validate.GetUserIdURLParam(w, r, log)
+		if err != nil {
+			return
+		}
+
+		var req Request
+		if err := validate.DecodeAndValidateRequestJson(&req, w, r, log); err != nil {
+			return
+		}
+
+		if userId == adminId && req.Role != user.RoleAdmin {
+			log.Error("admin tried to demote own account", slog.String("user_id", adminId))
+
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resperrors.ErrCannotManageSelf))
+
+			return
+		}
+
+		err = roleSetter.SetUserRole(r.Context(), userId, req.Role)
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Error("user not found", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrUserDoesNotExist))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to set user role", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		if err := tokensInvalidator.RevokeAllBefore(r.Context(), userId, time.Now()); err != nil {
+			log.Error("failed to revoke access tokens", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Warn("security audit: user role changed",
+			slog.String("admin_id", adminId),
+			slog.String("user_id", userId),
+			slog.String("role", req.Role),
+		)
+
+		audit.Record(r, log, roleSetter, audit.Event{
+			Action:     audit.ActionAdminSetRole,
+			ActorId:    audit.Actor(adminId),
+			TargetType: audit.TargetUser,
+			TargetId:   userId,
+			Details:    audit.Details{"role": req.Role},
+		})
+
+		render.JSON(w, r, resp.OK())
+	}
+}
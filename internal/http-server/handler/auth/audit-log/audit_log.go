@@ -0,0 +1,71 @@
+package auditlog
+
+import (
+	"context"
+	"log/slog"
+	"main/internal/audit"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	resp.Response
+	Events []audit.Event `json:"data"`
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+}
+
+type AuditEventsGetter interface {
+	GetAuditEvents(ctx context.Context, filter audit.Filter) ([]audit.Event, error)
+}
+
+// New lists events made by the user or targeting the user, newest first
+func New(log *slog.Logger, auditEventsGetter AuditEventsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.auth.auditlog.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		filter, err := validate.GetAuditFilter(w, r, log)
+		if err != nil {
+			return
+		}
+
+		filter.UserId = userId
+
+		events, err := auditEventsGetter.GetAuditEvents(r.Context(), filter)
+		if err != nil {
+			log.Error("failed to get audit events", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("listed audit events", slog.Int("count", len(events)))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Events:   events,
+			Limit:    filter.Limit,
+			Offset:   filter.Offset,
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package changeemail
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"main/internal/auth"
+	"main/internal/config"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/mailer"
+	"main/internal/models/user"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type Request struct {
+	NewEmail        string `json:"new_email" validate:"required,email"`
+	CurrentPassword string `json:"current_password" validate:"required"`
+}
+
+type EmailChanger interface {
+	GetUser(ctx context.Context, email string) (user.User, error)
+	GetUserById(ctx context.Context, id string) (user.User, error)
+	SetPendingEmail(ctx context.Context, id, email string) error
+	auth.OneTimeTokenCreator
+}
+
+// New keeps new email as pending until it is confirmed with the token sent to it,
+// so a typo or someone else's address can't lock the user out of the account
+func New(cfg *config.Config, log *slog.Logger, emailChanger EmailChanger, mail mailer.Mailer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.auth.changeemail.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := validate.DecodeAndValidateRequestJson(&req, w, r, log); err != nil {
+			return
+		}
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		userFromDb, err := emailChanger.GetUserById(r.Context(), userId)
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Error("user not found", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrUserDoesNotExist))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get user", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		if !auth.CheckPassword(req.CurrentPassword, userFromDb.PasswordHash) {
+			log.Error("invalid current password", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusUnauthorized)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvalidPassword))
+
+			return
+		}
+
+		if strings.EqualFold(req.NewEmail, userFromDb.Email) {
+			log.Error("new email is the same as current", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resperrors.ErrSameEmail))
+
+			return
+		}
+
+		_, err = emailChanger.GetUser(r.Context(), req.NewEmail)
+		if err == nil {
+			log.Error("email is already taken", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusConflict)
+			render.JSON(w, r, resp.Error(resperrors.ErrUserIsAlreadyExists))
+
+			return
+		}
+		if !errors.Is(err, storage.ErrUserNotFound) {
+			log.Error("failed to check email", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		err = emailChanger.SetPendingEmail(r.Context(), userId, req.NewEmail)
+		if err != nil {
+			log.Error("failed to set pending email", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		token, err := auth.IssueOneTimeToken(r.Context(), userId, auth.PurposeEmailChange, cfg.Authorization.VerificationTTL, cfg.Authorization.Salt, emailChanger)
+		if err != nil {
+			log.Error("failed to issue email change token", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		err = mail.Send(mailer.EmailChangeMessage(req.NewEmail, cfg.Mail.BaseURL, token))
+		if err != nil {
+			log.Error("failed to send email change confirmation", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("email change requested", slog.String("user_id", userId))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
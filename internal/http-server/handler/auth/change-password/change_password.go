@@ -0,0 +1,144 @@
+package changepassword
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"main/internal/audit"
+	"main/internal/auth"
+	"main/internal/config"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/models/user"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type Request struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8,max=255"`
+}
+
+type Response struct {
+	resp.Response
+	Tokens auth.Tokens `json:"tokens"`
+}
+
+type PasswordChanger interface {
+	GetUserById(ctx context.Context, id string) (user.User, error)
+	UpdateUserPassword(ctx context.Context, id, passwordHash string) error
+	auth.SessionsRevoker
+	auth.TokensIssuer
+	audit.Recorder
+}
+
+func New(cfg *config.Config, log *slog.Logger, passwordChanger PasswordChanger, tokensInvalidator auth.TokensInvalidator, tokenAuth auth.TokenAuth) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.auth.changepassword.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := validate.DecodeAndValidateRequestJson(&req, w, r, log); err != nil {
+			return
+		}
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		userFromDb, err := passwordChanger.GetUserById(r.Context(), userId)
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Error("user not found", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrUserDoesNotExist))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get user", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		if !auth.CheckPassword(req.CurrentPassword, userFromDb.PasswordHash) {
+			log.Error("invalid current password", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusUnauthorized)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvalidPassword))
+
+			return
+		}
+
+		hashedPassword, err := auth.HashPassword(req.NewPassword)
+		if err != nil {
+			log.Error("failed to hash password", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		err = passwordChanger.UpdateUserPassword(r.Context(), userId, hashedPassword)
+		if err != nil {
+			log.Error("failed to update password", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		revoked, err := auth.RevokeAllSessions(r.Context(), userId, passwordChanger, tokensInvalidator)
+		if err != nil {
+			log.Error("failed to revoke sessions", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		// keep current client signed in with a fresh pair
+		tokens, err := auth.GenerateTokens(r.Context(), userId, passwordChanger, cfg, tokenAuth)
+		if errors.Is(err, auth.ErrUserDisabled) {
+			log.Error("user is disabled", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, resp.Error(resperrors.ErrAccountDisabled))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to generate tokens", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("password changed", slog.String("user_id", userId), slog.Int("revoked_sessions", revoked))
+
+		audit.Record(r, log, passwordChanger, audit.Event{
+			Action:     audit.ActionPasswordChange,
+			ActorId:    audit.Actor(userId),
+			TargetType: audit.TargetUser,
+			TargetId:   userId,
+		})
+
+		render.JSON(w, r, Response{resp.OK(), tokens})
+	}
+}
@@ -0,0 +1,101 @@
+package confirmemailchange
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"main/internal/audit"
+	"main/internal/auth"
+	"main/internal/config"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Request struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type EmailChangeConfirmer interface {
+	ConfirmEmailChange(ctx context.Context, id string) (string, error)
+	auth.OneTimeTokenConsumer
+	audit.Recorder
+}
+
+func New(cfg *config.Config, log *slog.Logger, emailChangeConfirmer EmailChangeConfirmer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.auth.confirmemailchange.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := validate.DecodeAndValidateRequestJson(&req, w, r, log); err != nil {
+			return
+		}
+
+		userId, err := auth.ConsumeOneTimeToken(r.Context(), req.Token, auth.PurposeEmailChange, cfg.Authorization.Salt, emailChangeConfirmer)
+		if errors.Is(err, storage.ErrOneTimeTokenNotFound) {
+			log.Error("invalid email change token")
+
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvalidOneTimeToken))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to consume email change token", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		_, err = emailChangeConfirmer.ConfirmEmailChange(r.Context(), userId)
+		if errors.Is(err, storage.ErrNoPendingEmail) {
+			log.Error("no pending email change", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvalidOneTimeToken))
+
+			return
+		}
+		if errors.Is(err, storage.ErrUserAlreadyExists) {
+			log.Error("email was taken before confirmation", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusConflict)
+			render.JSON(w, r, resp.Error(resperrors.ErrUserIsAlreadyExists))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to confirm email change", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("email changed", slog.String("user_id", userId))
+
+		audit.Record(r, log, emailChangeConfirmer, audit.Event{
+			Action:     audit.ActionEmailChange,
+			ActorId:    audit.Actor(userId),
+			TargetType: audit.TargetUser,
+			TargetId:   userId,
+		})
+
+		render.JSON(w, r, resp.OK())
+	}
+}
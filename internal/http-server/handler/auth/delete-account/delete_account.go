@@ -0,0 +1,161 @@
+package deleteaccount
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"main/internal/audit"
+	"main/internal/auth"
+	"main/internal/config"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	uploadimage "main/internal/http-server/handler/node/upload-image"
+	"main/internal/models/user"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type Request struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type AccountDeleter interface {
+	GetUserById(ctx context.Context, id string) (user.User, error)
+	GetUserNoteIds(ctx context.Context, userId string) ([]int, error)
+	GetSolelyOwnedWorkspaceIds(ctx context.Context, userId string) ([]string, error)
+	DeleteUser(ctx context.Context, id string) error
+	audit.Recorder
+}
+
+func New(cfg *config.Config, log *slog.Logger, accountDeleter AccountDeleter, tokensInvalidator auth.TokensInvalidator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.auth.deleteaccount.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := validate.DecodeAndValidateRequestJson(&req, w, r, log); err != nil {
+			return
+		}
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		userFromDb, err := accountDeleter.GetUserById(r.Context(), userId)
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Error("user not found", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrUserDoesNotExist))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get user", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		if !auth.CheckPassword(req.Password, userFromDb.PasswordHash) {
+			log.Error("invalid password", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusUnauthorized)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvalidPassword))
+
+			return
+		}
+
+		// workspaces would be left without owner, checked before tokens are revoked,
+		// so refused deletion doesn't sign the user out
+		workspaceIds, err := accountDeleter.GetSolelyOwnedWorkspaceIds(r.Context(), userId)
+		if err != nil {
+			log.Error("failed to get owned workspaces", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+		if len(workspaceIds) > 0 {
+			log.Error("user is the only owner of workspaces", slog.String("user_id", userId), slog.Any("workspace_ids", workspaceIds))
+
+			w.WriteHeader(http.StatusConflict)
+			render.JSON(w, r, resp.Error(resperrors.ErrSoleWorkspaceOwner))
+
+			return
+		}
+
+		// note ids are needed to find image directories after notes are gone
+		noteIds, err := accountDeleter.GetUserNoteIds(r.Context(), userId)
+		if err != nil {
+			log.Error("failed to get user notes", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		// reject already issued access tokens right away
+		if err := tokensInvalidator.RevokeAllBefore(r.Context(), userId, time.Now()); err != nil {
+			log.Error("failed to revoke access tokens", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		// notes, nodes, refresh tokens and the rest are deleted by cascade
+		err = accountDeleter.DeleteUser(r.Context(), userId)
+		if errors.Is(err, storage.ErrSoleWorkspaceOwner) {
+			log.Error("user became the only owner of a workspace", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusConflict)
+			render.JSON(w, r, resp.Error(resperrors.ErrSoleWorkspaceOwner))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to delete user", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		for _, noteId := range noteIds {
+			dir := filepath.Join(cfg.Image.ImagesDir, uploadimage.HashNoteId(noteId, cfg.Image.ImageSalt))
+
+			if err := os.RemoveAll(dir); err != nil {
+				log.Error("failed to remove note images", slog.Int("note_id", noteId), "error", err)
+			}
+		}
+
+		log.Info("account deleted", slog.String("user_id", userId), slog.Int("notes", len(noteIds)))
+
+		audit.Record(r, log, accountDeleter, audit.Event{
+			Action:     audit.ActionAccountDelete,
+			ActorId:    audit.Actor(userId),
+			TargetType: audit.TargetUser,
+			TargetId:   userId,
+		})
+
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -0,0 +1,87 @@
+package forgotpassword
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"main/internal/auth"
+	"main/internal/config"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/mailer"
+	"main/internal/models/user"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Request struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type PasswordResetRequester interface {
+	GetUser(ctx context.Context, email string) (user.User, error)
+	auth.OneTimeTokenCreator
+}
+
+func New(cfg *config.Config, log *slog.Logger, resetRequester PasswordResetRequester, mail mailer.Mailer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.auth.forgotpassword.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := validate.DecodeAndValidateRequestJson(&req, w, r, log); err != nil {
+			return
+		}
+
+		userFromDb, err := resetRequester.GetUser(r.Context(), req.Email)
+		if errors.Is(err, storage.ErrUserNotFound) {
+			// same response as for existing users, so emails can't be enumerated
+			log.Info("password reset requested for unknown email", slog.String("email", req.Email))
+
+			render.JSON(w, r, resp.OK())
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get user", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		token, err := auth.IssueOneTimeToken(r.Context(), userFromDb.ID, auth.PurposePasswordReset, cfg.Authorization.PasswordResetTTL, cfg.Authorization.Salt, resetRequester)
+		if err != nil {
+			log.Error("failed to issue password reset token", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		err = mail.Send(mailer.PasswordResetMessage(userFromDb.Email, cfg.Mail.BaseURL, token))
+		if err != nil {
+			log.Error("failed to send password reset email", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("password reset email sent", slog.String("user_id", userFromDb.ID))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
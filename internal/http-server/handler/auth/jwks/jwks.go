@@ -0,0 +1,36 @@
+package jwks
+
+import (
+	"log/slog"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+)
+
+type KeySetProvider interface {
+	PublicSet() jwk.Set
+}
+
+func New(log *slog.Logger, keySetProvider KeySetProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.auth.jwks.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		keySet := keySetProvider.PublicSet()
+
+		log.Debug("jwks served", slog.Int("keys", keySet.Len()))
+
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		render.JSON(w, r, keySet)
+	}
+}
@@ -1,21 +1,26 @@
 package login
 
 import (
+	"context"
 	"errors"
 	"log/slog"
+	"main/internal/audit"
 	"main/internal/auth"
 	"main/internal/config"
+	"main/internal/http-server/api/request"
 	resp "main/internal/http-server/api/response"
 	resperrors "main/internal/http-server/api/response-errors"
 	"main/internal/http-server/api/validate"
 	"main/internal/models/user"
 	"main/internal/storage"
+	"main/internal/tracing"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/jwtauth/v5"
 	"github.com/go-chi/render"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type Request struct {
@@ -25,18 +30,34 @@ type Request struct {
 
 type Response struct {
 	resp.Response
-	Tokens auth.Tokens `json:"tokens"`
+	Tokens            *auth.Tokens `json:"tokens,omitempty"`
+	TwoFactorRequired bool         `json:"two_factor_required,omitempty"`
+	ChallengeToken    string       `json:"challenge_token,omitempty"`
 }
 
 type Loginer interface {
-	GetUser(email string) (user.User, error)
-	auth.RefreshTokenCreator
+	GetUser(ctx context.Context, email string) (user.User, error)
+	IsTwoFactorEnabled(ctx context.Context, userId string) (bool, error)
+	auth.TokensIssuer
+	audit.Recorder
 }
 
-func New(cfg *config.Config, log *slog.Logger, loginer Loginer, tokenAuth *jwtauth.JWTAuth) http.HandlerFunc {
+// LoginGuard reserves the attempt before password is checked, reserved
+// attempt is finished with RegisterFailure, RegisterSuccess or Release
+type LoginGuard interface {
+	Reserve(ctx context.Context, email, ip string) (time.Duration, error)
+	RegisterFailure(ctx context.Context, email, ip string) (bool, error)
+	RegisterSuccess(ctx context.Context, email string) error
+	Release(ctx context.Context, email string) error
+}
+
+func New(cfg *config.Config, log *slog.Logger, loginer Loginer, loginGuard LoginGuard, tokenAuth auth.TokenAuth) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handler.auth.login.New"
 
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
 		log = log.With(
 			slog.String("op", op),
 			slog.String("request-id", middleware.GetReqID(r.Context())),
@@ -48,10 +69,34 @@ func New(cfg *config.Config, log *slog.Logger, loginer Loginer, tokenAuth *jwtau
 			return
 		}
 
-		userFromDb, err := loginer.GetUser(req.Email)
+		ip := request.ClientIP(r)
+
+		retryAfter, err := loginGuard.Reserve(r.Context(), req.Email, ip)
+		if err != nil {
+			log.Error("failed to reserve login attempt", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		if retryAfter > 0 {
+			logFailure(log, r, loginer, "", req.Email, ip, "throttled")
+
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			w.WriteHeader(http.StatusTooManyRequests)
+			render.JSON(w, r, resp.Error(resperrors.ErrTooManyLoginAttempts))
+
+			return
+		}
+
+		userFromDb, err := loginer.GetUser(r.Context(), req.Email)
 		if errors.Is(err, storage.ErrUserNotFound) {
 			log.Error("user not found", slog.Attr{Key: "email", Value: slog.StringValue(req.Email)})
 
+			logFailure(log, r, loginer, "", req.Email, ip, "user_not_found")
+			registerFailure(r, log, loginGuard, loginer, "", req.Email, ip)
+
 			w.WriteHeader(http.StatusNotFound)
 			render.JSON(w, r, resp.Error(resperrors.ErrUserDoesNotExist))
 
@@ -60,37 +105,156 @@ func New(cfg *config.Config, log *slog.Logger, loginer Loginer, tokenAuth *jwtau
 		if err != nil {
 			log.Error("failed to get user", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrInternalServerError))
+			release(r.Context(), log, loginGuard, req.Email)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
 
 			return
 		}
 
-		if !checkPassword(req.Password, userFromDb.PasswordHash) {
+		if !auth.CheckPassword(req.Password, userFromDb.PasswordHash) {
 			log.Error("invalid password", slog.Attr{Key: "email", Value: slog.StringValue(req.Email)})
 
+			logFailure(log, r, loginer, userFromDb.ID, req.Email, ip, "invalid_password")
+			registerFailure(r, log, loginGuard, loginer, userFromDb.ID, req.Email, ip)
+
 			w.WriteHeader(http.StatusUnauthorized)
 			render.JSON(w, r, resp.Error(resperrors.ErrInvalidPassword))
 
 			return
 		}
 
-		tokens, err := auth.GenerateTokens(userFromDb.ID, loginer, cfg, tokenAuth)
+		// disabled status is revealed only to those who know the password
+		if userFromDb.DisabledAt != nil {
+			log.Error("user is disabled", slog.String("user_id", userFromDb.ID))
+
+			logFailure(log, r, loginer, userFromDb.ID, req.Email, ip, "account_disabled")
+			release(r.Context(), log, loginGuard, req.Email)
+
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, resp.Error(resperrors.ErrAccountDisabled))
+
+			return
+		}
+
+		if err := loginGuard.RegisterSuccess(context.WithoutCancel(r.Context()), req.Email); err != nil {
+			log.Error("failed to reset login attempts", "error", err)
+		}
+
+		twoFactorEnabled, err := loginer.IsTwoFactorEnabled(r.Context(), userFromDb.ID)
+		if err != nil {
+			log.Error("failed to check two-factor", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		if twoFactorEnabled {
+			challenge, err := auth.GenerateTwoFactorChallenge(userFromDb.ID, cfg, tokenAuth)
+			if err != nil {
+				log.Error("failed to generate two-factor challenge", "error", err)
+
+				validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+				return
+			}
+
+			log.Info("two-factor required", slog.Attr{Key: "email", Value: slog.StringValue(req.Email)})
+
+			render.JSON(w, r, Response{
+				Response:          resp.OK(),
+				TwoFactorRequired: true,
+				ChallengeToken:    challenge,
+			})
+
+			return
+		}
+
+		tokens, err := auth.GenerateTokens(r.Context(), userFromDb.ID, loginer, cfg, tokenAuth)
+		if errors.Is(err, auth.ErrUserDisabled) {
+			log.Error("user is disabled", slog.String("user_id", userFromDb.ID))
+
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, resp.Error(resperrors.ErrAccountDisabled))
+
+			return
+		}
 		if err != nil {
 			log.Error("failed to generate tokens", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrInternalServerError))
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
 
 			return
 		}
 
 		log.Info("success login", slog.Attr{Key: "email", Value: slog.StringValue(req.Email)})
 
-		render.JSON(w, r, Response{resp.OK(), tokens})
+		audit.Record(r, log, loginer, audit.Event{
+			Action:     audit.ActionLoginSuccess,
+			ActorId:    audit.Actor(userFromDb.ID),
+			TargetType: audit.TargetUser,
+			TargetId:   userFromDb.ID,
+			Details:    audit.Details{"method": "password"},
+		})
+
+		render.JSON(w, r, Response{Response: resp.OK(), Tokens: &tokens})
+	}
+}
+
+// logFailure writes failed login to security audit trail, userId is empty
+// when the email doesn't belong to any account
+func logFailure(log *slog.Logger, r *http.Request, recorder audit.Recorder, userId, email, ip, reason string) {
+	log.Warn("security audit: login failed",
+		slog.String("event", "login_failed"),
+		slog.String("email", email),
+		slog.String("ip", ip),
+		slog.String("user_agent", r.UserAgent()),
+		slog.String("reason", reason),
+	)
+
+	event := audit.Event{
+		Action:  audit.ActionLoginFailure,
+		ActorId: audit.Actor(userId),
+		Details: audit.Details{"email": email, "reason": reason},
+	}
+	if userId != "" {
+		event.TargetType = audit.TargetUser
+		event.TargetId = userId
 	}
+
+	audit.Record(r, log, recorder, event)
 }
 
-func checkPassword(password, hashedPassword string) bool {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password)) == nil
+// registerFailure counts failed attempt, error is only logged so storage
+// problems don't change the response an attacker sees. Attempt is counted
+// even if the client disconnects without waiting for the response
+func registerFailure(r *http.Request, log *slog.Logger, loginGuard LoginGuard, recorder audit.Recorder, userId, email, ip string) {
+	locked, err := loginGuard.RegisterFailure(context.WithoutCancel(r.Context()), email, ip)
+	if err != nil {
+		log.Error("failed to register login failure", "error", err)
+		return
+	}
+
+	if !locked {
+		return
+	}
+
+	event := audit.Event{
+		Action:  audit.ActionLoginLockout,
+		Details: audit.Details{"email": email, "method": "password"},
+	}
+	if userId != "" {
+		event.TargetType = audit.TargetUser
+		event.TargetId = userId
+	}
+
+	audit.Record(r, log, recorder, event)
+}
+
+// release gives the attempt back when it ended for reasons other than a wrong password
+func release(ctx context.Context, log *slog.Logger, loginGuard LoginGuard, email string) {
+	if err := loginGuard.Release(context.WithoutCancel(ctx), email); err != nil {
+		log.Error("failed to release login attempt", "error", err)
+	}
 }
@@ -0,0 +1,59 @@
+package logoutall
+
+import (
+	"log/slog"
+	"main/internal/audit"
+	"main/internal/auth"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type SessionsRevoker interface {
+	auth.SessionsRevoker
+	audit.Recorder
+}
+
+func New(log *slog.Logger, sessionsRevoker SessionsRevoker, tokensInvalidator auth.TokensInvalidator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.auth.logoutall.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		revoked, err := auth.RevokeAllSessions(r.Context(), userId, sessionsRevoker, tokensInvalidator)
+		if err != nil {
+			log.Error("failed to revoke sessions", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("user logged out from all sessions", slog.String("user_id", userId), slog.Int("revoked", revoked))
+
+		audit.Record(r, log, sessionsRevoker, audit.Event{
+			Action:     audit.ActionLogoutAll,
+			ActorId:    audit.Actor(userId),
+			TargetType: audit.TargetUser,
+			TargetId:   userId,
+		})
+
+		render.JSON(w, r, resp.OK())
+	}
+}
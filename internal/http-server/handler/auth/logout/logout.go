@@ -0,0 +1,60 @@
+package logout
+
+import (
+	"context"
+	"log/slog"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/tracing"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type TokenRevoker interface {
+	Revoke(ctx context.Context, jti, userId string, expiresAt time.Time) error
+}
+
+func New(log *slog.Logger, tokenRevoker TokenRevoker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.auth.logout.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		token, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		if token.JwtID() == "" {
+			log.Error("access token has no jti", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvalidAccessToken))
+
+			return
+		}
+
+		err := tokenRevoker.Revoke(r.Context(), token.JwtID(), userId, token.Expiration())
+		if err != nil {
+			log.Error("failed to revoke access token", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("user logged out", slog.String("user_id", userId))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
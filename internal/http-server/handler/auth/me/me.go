@@ -1,31 +1,71 @@
 package me
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	resp "main/internal/http-server/api/response"
 	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/models/user"
+	"main/internal/storage"
+	"main/internal/tracing"
 	"net/http"
 
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/jwtauth/v5"
 	"github.com/go-chi/render"
 )
 
 type Response struct {
 	resp.Response
-	UserId string `json:"user_id"`
+	UserId  string       `json:"user_id"`
+	Profile user.Profile `json:"data"`
 }
 
-func New(log *slog.Logger, tokenAuth *jwtauth.JWTAuth) http.HandlerFunc {
+type ProfileGetter interface {
+	GetUserById(ctx context.Context, id string) (user.User, error)
+}
+
+func New(log *slog.Logger, profileGetter ProfileGetter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.auth.me.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
 		_, claims, _ := jwtauth.FromContext(r.Context())
 
 		userId, ok := claims["user_id"].(string)
-
 		if !ok {
 			w.WriteHeader(http.StatusUnauthorized)
 			render.JSON(w, r, resp.Error(resperrors.ErrUserUnauthorized))
+
+			return
+		}
+
+		userFromDb, err := profileGetter.GetUserById(r.Context(), userId)
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Error("user not found", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrUserDoesNotExist))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get user", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
 		}
 
-		render.JSON(w, r, Response{resp.OK(), userId})
+		render.JSON(w, r, Response{resp.OK(), userId, userFromDb.Profile()})
 	}
 }
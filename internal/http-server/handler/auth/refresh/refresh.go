@@ -1,17 +1,21 @@
 package refresh
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"main/internal/audit"
 	"main/internal/auth"
 	"main/internal/config"
 	resp "main/internal/http-server/api/response"
 	resperrors "main/internal/http-server/api/response-errors"
 	"main/internal/http-server/api/validate"
+	"main/internal/storage"
+	"main/internal/tracing"
 	"net/http"
 	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/jwtauth/v5"
 	"github.com/go-chi/render"
 )
 
@@ -21,17 +25,23 @@ type Request struct {
 
 type Response struct {
 	resp.Response
-	AccessToken string `json:"access_token"`
+	Tokens auth.Tokens `json:"tokens"`
 }
 
 type RefreshTokener interface {
-	GetRefreshTokenById(id string) (auth.RefreshToken, error)
+	GetRefreshTokenById(ctx context.Context, id string) (auth.RefreshToken, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyId string) (int, error)
+	auth.TokensRotator
+	audit.Recorder
 }
 
-func New(cfg *config.Config, log *slog.Logger, refreshTokener RefreshTokener, tokenAuth *jwtauth.JWTAuth) http.HandlerFunc {
+func New(cfg *config.Config, log *slog.Logger, refreshTokener RefreshTokener, tokenAuth auth.TokenAuth) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handler.auth.refresh.New"
 
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
 		log = log.With(
 			slog.String("op", op),
 			slog.String("request-id", middleware.GetReqID(r.Context())),
@@ -47,8 +57,7 @@ func New(cfg *config.Config, log *slog.Logger, refreshTokener RefreshTokener, to
 		if err != nil {
 			log.Error("failed to decode token", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrInternalServerError))
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
 
 			return
 		}
@@ -73,12 +82,19 @@ func New(cfg *config.Config, log *slog.Logger, refreshTokener RefreshTokener, to
 			return
 		}
 
-		refreshToken, err := refreshTokener.GetRefreshTokenById(tokenIdStr)
+		refreshToken, err := refreshTokener.GetRefreshTokenById(r.Context(), tokenIdStr)
+		if errors.Is(err, storage.ErrRefreshTokenNotFound) {
+			log.Error("refresh token not found", slog.String("token_id", tokenIdStr))
+
+			w.WriteHeader(http.StatusUnauthorized)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvalidRefreshToken))
+
+			return
+		}
 		if err != nil {
 			log.Error("failed to get user id", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrInternalServerError))
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
 
 			return
 		}
@@ -101,26 +117,79 @@ func New(cfg *config.Config, log *slog.Logger, refreshTokener RefreshTokener, to
 			return
 		}
 
-		accessExp := time.Now().Add(cfg.Authorization.AccessTTL)
+		if refreshToken.UsedAt != nil {
+			if time.Since(*refreshToken.UsedAt) > cfg.Authorization.RefreshReuseGrace {
+				revoked, err := refreshTokener.RevokeRefreshTokenFamily(r.Context(), refreshToken.FamilyId)
+				if err != nil {
+					log.Error("failed to revoke refresh token family", "error", err)
 
-		_, accessToken, err := tokenAuth.Encode(map[string]interface{}{
-			"user_id": refreshToken.UserId,
-			"exp":     accessExp,
-		})
+					validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+					return
+				}
+
+				log.Warn("refresh token reuse detected, possible token theft",
+					slog.String("user_id", refreshToken.UserId),
+					slog.String("token_id", refreshToken.Id),
+					slog.String("family_id", refreshToken.FamilyId),
+					slog.String("remote_addr", r.RemoteAddr),
+					slog.Int("revoked", revoked),
+				)
+
+				audit.Record(r, log, refreshTokener, audit.Event{
+					Action:     audit.ActionTokenReuse,
+					ActorId:    audit.Actor(refreshToken.UserId),
+					TargetType: audit.TargetUser,
+					TargetId:   refreshToken.UserId,
+					Details:    audit.Details{"family_id": refreshToken.FamilyId},
+				})
+
+				w.WriteHeader(http.StatusUnauthorized)
+				render.JSON(w, r, resp.Error(resperrors.ErrRefreshTokenReused))
+
+				return
+			}
+
+			log.Info("refresh token reused within grace window", slog.String("token_id", refreshToken.Id))
+		}
+
+		tokens, err := auth.RotateTokens(r.Context(), refreshToken, refreshTokener, cfg, tokenAuth)
+		if errors.Is(err, auth.ErrUserDisabled) {
+			log.Error("user is disabled", slog.String("user_id", refreshToken.UserId))
+
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, resp.Error(resperrors.ErrAccountDisabled))
+
+			return
+		}
+		if errors.Is(err, storage.ErrRefreshTokenNotFound) {
+			log.Error("refresh token revoked during rotation", slog.String("token_id", refreshToken.Id))
+
+			w.WriteHeader(http.StatusUnauthorized)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvalidRefreshToken))
+
+			return
+		}
 		if err != nil {
-			log.Error("failed to encode access token", "error", err)
+			log.Error("failed to rotate tokens", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrInternalServerError))
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
 
 			return
 		}
 
 		log.Info("token refreshed", slog.String("user_id", refreshToken.UserId))
 
+		audit.Record(r, log, refreshTokener, audit.Event{
+			Action:     audit.ActionTokenRefresh,
+			ActorId:    audit.Actor(refreshToken.UserId),
+			TargetType: audit.TargetUser,
+			TargetId:   refreshToken.UserId,
+		})
+
 		render.JSON(w, r, Response{
 			resp.OK(),
-			accessToken,
+			tokens,
 		})
 	}
 }
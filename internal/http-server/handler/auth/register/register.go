@@ -1,6 +1,7 @@
 package register
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 	"main/internal/auth"
@@ -8,13 +9,13 @@ import (
 	resp "main/internal/http-server/api/response"
 	resperrors "main/internal/http-server/api/response-errors"
 	"main/internal/http-server/api/validate"
+	"main/internal/mailer"
 	"main/internal/storage"
+	"main/internal/tracing"
 	"net/http"
 
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/jwtauth/v5"
 	"github.com/go-chi/render"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type Request struct {
@@ -29,14 +30,18 @@ type Response struct {
 }
 
 type Register interface {
-	CreateUser(email, name, password string) (string, error)
-	auth.RefreshTokenCreator
+	CreateUser(ctx context.Context, email, name, password string) (string, error)
+	auth.TokensIssuer
+	auth.OneTimeTokenCreator
 }
 
-func New(cfg *config.Config, log *slog.Logger, register Register, tokenAuth *jwtauth.JWTAuth) http.HandlerFunc {
+func New(cfg *config.Config, log *slog.Logger, register Register, tokenAuth auth.TokenAuth, mail mailer.Mailer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handler.auth.register.New"
 
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
 		log = log.With(
 			slog.String("op", op),
 			slog.String("request-id", middleware.GetReqID(r.Context())),
@@ -48,17 +53,16 @@ func New(cfg *config.Config, log *slog.Logger, register Register, tokenAuth *jwt
 			return
 		}
 
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		hashedPassword, err := auth.HashPassword(req.Password)
 		if err != nil {
 			log.Error("failed to hash password", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrInternalServerError))
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
 
 			return
 		}
 
-		userID, err := register.CreateUser(req.Email, req.Name, string(hashedPassword))
+		userID, err := register.CreateUser(r.Context(), req.Email, req.Name, hashedPassword)
 		if errors.Is(err, storage.ErrUserAlreadyExists) {
 			log.Error("user already exists", "error", err)
 
@@ -69,22 +73,33 @@ func New(cfg *config.Config, log *slog.Logger, register Register, tokenAuth *jwt
 		if err != nil {
 			log.Error("failed to create user", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrInternalServerError))
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
 
 			return
 		}
 
-		tokens, err := auth.GenerateTokens(userID, register, cfg, tokenAuth)
+		tokens, err := auth.GenerateTokens(r.Context(), userID, register, cfg, tokenAuth)
+		if errors.Is(err, auth.ErrUserDisabled) {
+			log.Error("user is disabled", slog.String("user_id", userID))
+
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, resp.Error(resperrors.ErrAccountDisabled))
+
+			return
+		}
 		if err != nil {
 			log.Error("failed to generate tokens", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrInternalServerError))
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
 
 			return
 		}
 
+		// registration succeeds even if email can't be sent, user may request it again
+		if err := sendEmailVerification(r.Context(), cfg, userID, req.Email, register, mail); err != nil {
+			log.Error("failed to send email verification", "error", err)
+		}
+
 		log.Info("user registered", slog.String("user_id", userID))
 
 		render.JSON(w, r, Response{
@@ -93,3 +108,12 @@ func New(cfg *config.Config, log *slog.Logger, register Register, tokenAuth *jwt
 		})
 	}
 }
+
+func sendEmailVerification(ctx context.Context, cfg *config.Config, userId, email string, tokenCreator auth.OneTimeTokenCreator, mail mailer.Mailer) error {
+	token, err := auth.IssueOneTimeToken(ctx, userId, auth.PurposeEmailVerification, cfg.Authorization.VerificationTTL, cfg.Authorization.Salt, tokenCreator)
+	if err != nil {
+		return err
+	}
+
+	return mail.Send(mailer.EmailVerificationMessage(email, cfg.Mail.BaseURL, token))
+}
@@ -0,0 +1,92 @@
+package resendverification
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"main/internal/auth"
+	"main/internal/config"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/mailer"
+	"main/internal/models/user"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type VerificationResender interface {
+	GetUserById(ctx context.Context, id string) (user.User, error)
+	auth.OneTimeTokenCreator
+}
+
+func New(cfg *config.Config, log *slog.Logger, verificationResender VerificationResender, mail mailer.Mailer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.auth.resendverification.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		userFromDb, err := verificationResender.GetUserById(r.Context(), userId)
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Error("user not found", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrUserDoesNotExist))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get user", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		if userFromDb.EmailVerifiedAt != nil {
+			log.Error("email is already verified", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusConflict)
+			render.JSON(w, r, resp.Error(resperrors.ErrEmailAlreadyVerified))
+
+			return
+		}
+
+		token, err := auth.IssueOneTimeToken(r.Context(), userId, auth.PurposeEmailVerification, cfg.Authorization.VerificationTTL, cfg.Authorization.Salt, verificationResender)
+		if err != nil {
+			log.Error("failed to issue email verification token", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		err = mail.Send(mailer.EmailVerificationMessage(userFromDb.Email, cfg.Mail.BaseURL, token))
+		if err != nil {
+			log.Error("failed to send email verification", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("email verification sent", slog.String("user_id", userId))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -0,0 +1,105 @@
+package resetpassword
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"main/internal/audit"
+	"main/internal/auth"
+	"main/internal/config"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Request struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8,max=255"`
+}
+
+type PasswordResetter interface {
+	UpdateUserPassword(ctx context.Context, id, passwordHash string) error
+	auth.OneTimeTokenConsumer
+	auth.SessionsRevoker
+	audit.Recorder
+}
+
+func New(cfg *config.Config, log *slog.Logger, passwordResetter PasswordResetter, tokensInvalidator auth.TokensInvalidator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.auth.resetpassword.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := validate.DecodeAndValidateRequestJson(&req, w, r, log); err != nil {
+			return
+		}
+
+		hashedPassword, err := auth.HashPassword(req.NewPassword)
+		if err != nil {
+			log.Error("failed to hash password", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		userId, err := auth.ConsumeOneTimeToken(r.Context(), req.Token, auth.PurposePasswordReset, cfg.Authorization.Salt, passwordResetter)
+		if errors.Is(err, storage.ErrOneTimeTokenNotFound) {
+			log.Error("invalid password reset token")
+
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvalidOneTimeToken))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to consume password reset token", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		err = passwordResetter.UpdateUserPassword(r.Context(), userId, hashedPassword)
+		if err != nil {
+			log.Error("failed to update password", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		revoked, err := auth.RevokeAllSessions(r.Context(), userId, passwordResetter, tokensInvalidator)
+		if err != nil {
+			log.Error("failed to revoke sessions", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("password reset", slog.String("user_id", userId), slog.Int("revoked_sessions", revoked))
+
+		audit.Record(r, log, passwordResetter, audit.Event{
+			Action:     audit.ActionPasswordReset,
+			ActorId:    audit.Actor(userId),
+			TargetType: audit.TargetUser,
+			TargetId:   userId,
+		})
+
+		render.JSON(w, r, resp.OK())
+	}
+}
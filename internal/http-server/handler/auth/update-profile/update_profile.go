@@ -0,0 +1,85 @@
+package updateprofile
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/models/user"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type Request struct {
+	Name string `json:"name" validate:"required,max=255"`
+}
+
+type Response struct {
+	resp.Response
+	Profile user.Profile `json:"data"`
+}
+
+type ProfileUpdater interface {
+	UpdateUserName(ctx context.Context, id, name string) error
+	GetUserById(ctx context.Context, id string) (user.User, error)
+}
+
+func New(log *slog.Logger, profileUpdater ProfileUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.auth.updateprofile.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := validate.DecodeAndValidateRequestJson(&req, w, r, log); err != nil {
+			return
+		}
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		err := profileUpdater.UpdateUserName(r.Context(), userId, req.Name)
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Error("user not found", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrUserDoesNotExist))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to update user name", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		userFromDb, err := profileUpdater.GetUserById(r.Context(), userId)
+		if err != nil {
+			log.Error("failed to get user", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("profile updated", slog.String("user_id", userId))
+
+		render.JSON(w, r, Response{resp.OK(), userFromDb.Profile()})
+	}
+}
@@ -0,0 +1,76 @@
+package verifyemail
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"main/internal/auth"
+	"main/internal/config"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Request struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type EmailVerifier interface {
+	SetEmailVerified(ctx context.Context, id string) error
+	auth.OneTimeTokenConsumer
+}
+
+func New(cfg *config.Config, log *slog.Logger, emailVerifier EmailVerifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.auth.verifyemail.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := validate.DecodeAndValidateRequestJson(&req, w, r, log); err != nil {
+			return
+		}
+
+		userId, err := auth.ConsumeOneTimeToken(r.Context(), req.Token, auth.PurposeEmailVerification, cfg.Authorization.Salt, emailVerifier)
+		if errors.Is(err, storage.ErrOneTimeTokenNotFound) {
+			log.Error("invalid email verification token")
+
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvalidOneTimeToken))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to consume email verification token", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		err = emailVerifier.SetEmailVerified(r.Context(), userId)
+		if err != nil {
+			log.Error("failed to set email verified", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("email verified", slog.String("user_id", userId))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -1,11 +1,13 @@
 package add
 
 import (
+	"context"
 	"log/slog"
 	resp "main/internal/http-server/api/response"
 	resperrors "main/internal/http-server/api/response-errors"
 	"main/internal/http-server/api/validate"
 	"main/internal/models/note"
+	"main/internal/tracing"
 	"net/http"
 
 	"github.com/go-chi/chi/v5/middleware"
@@ -24,7 +26,7 @@ type Response struct {
 }
 
 type NodeAdder interface {
-	AddNoteNode(noteId int, contentType string, content string) (int, error)
+	AddNoteNode(ctx context.Context, noteId int, contentType string, content string) (int, error)
 	validate.UserVerifier
 }
 
@@ -32,6 +34,9 @@ func New(log *slog.Logger, noteAdder NodeAdder) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handler.node.add.New"
 
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
 		log = log.With(
 			slog.String("op", op),
 			slog.String("request-id", middleware.GetReqID(r.Context())),
@@ -56,12 +61,11 @@ func New(log *slog.Logger, noteAdder NodeAdder) http.HandlerFunc {
 			content = ""
 		}
 
-		id, err := noteAdder.AddNoteNode(noteId, contentType, content)
+		id, err := noteAdder.AddNoteNode(r.Context(), noteId, contentType, content)
 		if err != nil {
 			log.Error("failed to add note node", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrFailedToAddNoteNode))
+			validate.InternalError(w, r, err, resperrors.ErrFailedToAddNoteNode)
 
 			return
 		}
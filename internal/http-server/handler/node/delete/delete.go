@@ -1,12 +1,14 @@
 package delete
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 	resp "main/internal/http-server/api/response"
 	resperrors "main/internal/http-server/api/response-errors"
 	"main/internal/http-server/api/validate"
 	"main/internal/storage"
+	"main/internal/tracing"
 	"net/http"
 
 	"github.com/go-chi/chi/v5/middleware"
@@ -14,7 +16,7 @@ import (
 )
 
 type NodeDeleter interface {
-	DeleteNoteNode(int) error
+	DeleteNoteNode(context.Context, int) error
 	validate.UserVerifier
 }
 
@@ -22,6 +24,9 @@ func New(log *slog.Logger, nodeDeleter NodeDeleter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handler.node.delete.New"
 
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
 		log = log.With(
 			slog.String("op", op),
 			slog.String("request-id", middleware.GetReqID(r.Context())),
@@ -37,7 +42,7 @@ func New(log *slog.Logger, nodeDeleter NodeDeleter) http.HandlerFunc {
 			return
 		}
 
-		err = nodeDeleter.DeleteNoteNode(id)
+		err = nodeDeleter.DeleteNoteNode(r.Context(), id)
 		if errors.Is(err, storage.ErrNoteNodeNotFound) {
 			log.Error("not found note node", "error", err)
 
@@ -49,8 +54,7 @@ func New(log *slog.Logger, nodeDeleter NodeDeleter) http.HandlerFunc {
 		if err != nil {
 			log.Error("failed to delete note node", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrFailedToDeleteNode))
+			validate.InternalError(w, r, err, resperrors.ErrFailedToDeleteNode)
 
 			return
 		}
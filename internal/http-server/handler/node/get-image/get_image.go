@@ -1,11 +1,13 @@
 package getimage
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 	"main/internal/http-server/api/validate"
 	"main/internal/models/note"
 	"main/internal/storage"
+	"main/internal/tracing"
 	"mime"
 	"net/http"
 	"os"
@@ -19,7 +21,7 @@ import (
 )
 
 type ImageGetter interface {
-	GetNodeById(id int) (note.NoteNode, error)
+	GetNodeById(ctx context.Context, id int) (note.NoteNode, error)
 	validate.UserVerifier
 }
 
@@ -27,6 +29,9 @@ func New(log *slog.Logger, imageGetter ImageGetter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handler.node.getimage.New"
 
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
 		log = log.With(
 			slog.String("op", op),
 			slog.String("request-id", middleware.GetReqID(r.Context())),
@@ -37,12 +42,12 @@ func New(log *slog.Logger, imageGetter ImageGetter) http.HandlerFunc {
 			return
 		}
 
-		err = validate.VerifyUserNoteNode(id, imageGetter, w, r, log)
+		err = validate.VerifyUserNoteNodeRead(id, imageGetter, w, r, log)
 		if err != nil {
 			return
 		}
 
-		node, err := imageGetter.GetNodeById(id)
+		node, err := imageGetter.GetNodeById(r.Context(), id)
 		if errors.Is(err, storage.ErrNoteNodeNotFound) {
 			log.Error("note node not found", "error", err)
 
@@ -54,8 +59,7 @@ func New(log *slog.Logger, imageGetter ImageGetter) http.HandlerFunc {
 		if err != nil {
 			log.Error("failed to get note id", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrInternalServerError))
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
 
 			return
 		}
@@ -72,8 +76,7 @@ func New(log *slog.Logger, imageGetter ImageGetter) http.HandlerFunc {
 		file, err := os.Open(node.Content)
 		if err != nil {
 			log.Error("failed to open image file", slog.String("error", err.Error()))
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrInternalServerError))
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
 			return
 		}
 		defer file.Close()
@@ -81,8 +84,7 @@ func New(log *slog.Logger, imageGetter ImageGetter) http.HandlerFunc {
 		fileInfo, err := file.Stat()
 		if err != nil {
 			log.Error("failed to get file info", slog.String("error", err.Error()))
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrInternalServerError))
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
 			return
 		}
 
@@ -1,6 +1,7 @@
 package updatecontent
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 	resp "main/internal/http-server/api/response"
@@ -8,6 +9,7 @@ import (
 	"main/internal/http-server/api/validate"
 	"main/internal/models/note"
 	"main/internal/storage"
+	"main/internal/tracing"
 	"net/http"
 
 	"github.com/go-chi/chi/v5/middleware"
@@ -19,8 +21,8 @@ type Request struct {
 }
 
 type NodeUpdater interface {
-	UpdateNoteNodeContent(id int, content string) error
-	GetNodeById(id int) (note.NoteNode, error)
+	UpdateNoteNodeContent(ctx context.Context, id int, content string) error
+	GetNodeById(ctx context.Context, id int) (note.NoteNode, error)
 	validate.UserVerifier
 }
 
@@ -28,6 +30,9 @@ func New(log *slog.Logger, nodeUpdater NodeUpdater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handler.node.updatecontent.New"
 
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
 		log = log.With(
 			slog.String("op", op),
 			slog.String("request-id", middleware.GetReqID(r.Context())),
@@ -48,7 +53,7 @@ func New(log *slog.Logger, nodeUpdater NodeUpdater) http.HandlerFunc {
 			return
 		}
 
-		node, err := nodeUpdater.GetNodeById(nodeId)
+		node, err := nodeUpdater.GetNodeById(r.Context(), nodeId)
 		if errors.Is(err, storage.ErrNoteNodeNotFound) {
 			log.Error("note node not found", "error", err)
 
@@ -60,8 +65,7 @@ func New(log *slog.Logger, nodeUpdater NodeUpdater) http.HandlerFunc {
 		if err != nil {
 			log.Error("failed to get note node", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrFailedToGetNoteNode))
+			validate.InternalError(w, r, err, resperrors.ErrFailedToGetNoteNode)
 
 			return
 		}
@@ -75,7 +79,7 @@ func New(log *slog.Logger, nodeUpdater NodeUpdater) http.HandlerFunc {
 			return
 		}
 
-		err = nodeUpdater.UpdateNoteNodeContent(nodeId, req.Content)
+		err = nodeUpdater.UpdateNoteNodeContent(r.Context(), nodeId, req.Content)
 		if errors.Is(err, storage.ErrNoteNodeNotFound) {
 			log.Error("note node not found", "error", err)
 
@@ -87,8 +91,7 @@ func New(log *slog.Logger, nodeUpdater NodeUpdater) http.HandlerFunc {
 		if err != nil {
 			log.Error("failed to update note node content", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrFailedToUpdateNodeContent))
+			validate.InternalError(w, r, err, resperrors.ErrFailedToUpdateNodeContent)
 
 			return
 		}
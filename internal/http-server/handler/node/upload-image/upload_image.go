@@ -1,6 +1,7 @@
 package uploadimage
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
@@ -10,17 +11,18 @@ import (
 	"image/png"
 	"log/slog"
 	"main/internal/config"
-	"main/internal/http-server/api/response"
 	resp "main/internal/http-server/api/response"
 	resperrors "main/internal/http-server/api/response-errors"
 	"main/internal/http-server/api/validate"
 	"main/internal/models/note"
 	"main/internal/storage"
+	"main/internal/tracing"
 	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
@@ -29,15 +31,22 @@ import (
 )
 
 type ImageUploader interface {
-	UpdateNoteNodeContent(id int, content string) error
-	GetNodeById(id int) (note.NoteNode, error)
+	UpdateNoteNodeContent(ctx context.Context, id int, content string) error
+	GetNodeById(ctx context.Context, id int) (note.NoteNode, error)
 	validate.UserVerifier
 }
 
-func New(cfg *config.Config, log *slog.Logger, imageUploader ImageUploader) http.HandlerFunc {
+type UploadObserver interface {
+	ObserveImageUpload(format string, uploadedBytes, storedBytes int64, processing time.Duration)
+}
+
+func New(cfg *config.Config, log *slog.Logger, imageUploader ImageUploader, uploadObserver UploadObserver) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handler.node.uploadimage.New"
 
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
 		log = log.With(
 			slog.String("op", op),
 			slog.String("request-id", middleware.GetReqID(r.Context())),
@@ -53,7 +62,7 @@ func New(cfg *config.Config, log *slog.Logger, imageUploader ImageUploader) http
 			return
 		}
 
-		noteFromDB, err := imageUploader.GetNodeById(id)
+		noteFromDB, err := imageUploader.GetNodeById(r.Context(), id)
 		if errors.Is(err, storage.ErrNoteNodeNotFound) {
 			log.Error("note node not found", "error", err)
 
@@ -65,8 +74,7 @@ func New(cfg *config.Config, log *slog.Logger, imageUploader ImageUploader) http
 		if err != nil {
 			log.Error("failed to get note id", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrInternalServerError))
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
 
 			return
 		}
@@ -84,8 +92,7 @@ func New(cfg *config.Config, log *slog.Logger, imageUploader ImageUploader) http
 		if err != nil {
 			log.Error("failed to parse multipart form", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrInternalServerError))
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
 
 			return
 		}
@@ -94,8 +101,7 @@ func New(cfg *config.Config, log *slog.Logger, imageUploader ImageUploader) http
 		if err != nil {
 			log.Error("failed to get image", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrInternalServerError))
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
 
 			return
 		}
@@ -125,8 +131,7 @@ func New(cfg *config.Config, log *slog.Logger, imageUploader ImageUploader) http
 		if err := os.RemoveAll(dirName); err != nil {
 			log.Error("failed to remove existing directory", slog.String("error", err.Error()))
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, response.Error(resperrors.ErrInternalServerError))
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
 
 			return
 		}
@@ -134,8 +139,7 @@ func New(cfg *config.Config, log *slog.Logger, imageUploader ImageUploader) http
 		if err := os.MkdirAll(dirName, os.ModePerm); err != nil {
 			log.Error("failed to create image dir", slog.String("error", err.Error()))
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, response.Error(resperrors.ErrInternalServerError))
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
 
 			return
 		}
@@ -143,33 +147,33 @@ func New(cfg *config.Config, log *slog.Logger, imageUploader ImageUploader) http
 		outFile, err := os.Create(imagePath)
 		if err != nil {
 			log.Error("failed to create image file", slog.String("error", err.Error()))
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, response.Error(resperrors.ErrInternalServerError))
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
 			return
 		}
 		defer outFile.Close()
 
+		processingStart := time.Now()
+
 		err = compressImage(imageFile, imageType, outFile.Name(), cfg.Image.MaxWidth)
 		if err != nil {
 			log.Error("failed to compress image", slog.String("error", err.Error()))
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, response.Error(resperrors.ErrInternalServerError))
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
 			return
 		}
 
-		err = imageUploader.UpdateNoteNodeContent(id, imagePath)
+		processing := time.Since(processingStart)
+
+		err = imageUploader.UpdateNoteNodeContent(r.Context(), id, imagePath)
 		if err != nil {
 			log.Error("failed to update note node content", slog.String("error", err.Error()))
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, response.Error(resperrors.ErrInternalServerError))
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
 			return
 		}
 
 		file, err := os.Open(imagePath)
 		if err != nil {
 			log.Error("failed to open image file", slog.String("error", err.Error()))
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, response.Error(resperrors.ErrInternalServerError))
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
 			return
 		}
 		defer file.Close()
@@ -177,11 +181,12 @@ func New(cfg *config.Config, log *slog.Logger, imageUploader ImageUploader) http
 		fileInfo, err := file.Stat()
 		if err != nil {
 			log.Error("failed to get file info", slog.String("error", err.Error()))
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, response.Error(resperrors.ErrInternalServerError))
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
 			return
 		}
 
+		uploadObserver.ObserveImageUpload(exp[1:], format.Size, fileInfo.Size(), processing)
+
 		w.Header().Set("Content-Disposition", "inline; filename="+fileName)
 		w.Header().Set("Content-Type", "image/"+imageType)
 
@@ -1,10 +1,13 @@
 package create
 
 import (
+	"context"
 	"log/slog"
 	resp "main/internal/http-server/api/response"
 	resperrors "main/internal/http-server/api/response-errors"
 	"main/internal/http-server/api/validate"
+	"main/internal/models/workspace"
+	"main/internal/tracing"
 	"net/http"
 
 	"github.com/go-chi/chi/v5/middleware"
@@ -14,6 +17,8 @@ import (
 
 type Request struct {
 	Title string `json:"title" validate:"required,max=31"`
+	// WorkspaceId is empty for personal notes
+	WorkspaceId string `json:"workspace_id,omitempty"`
 }
 
 type Response struct {
@@ -22,13 +27,18 @@ type Response struct {
 }
 
 type NoteCreator interface {
-	CreateNote(noteTitle string, userId string) (int, error)
+	CreateNote(ctx context.Context, noteTitle string, userId string) (int, error)
+	CreateWorkspaceNote(ctx context.Context, noteTitle string, workspaceId string) (int, error)
+	validate.WorkspaceRoleGetter
 }
 
 func New(log *slog.Logger, noteCreator NoteCreator) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handler.note.create.New"
 
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
 		log = log.With(
 			slog.String("op", op),
 			slog.String("request-id", middleware.GetReqID(r.Context())),
@@ -45,12 +55,34 @@ func New(log *slog.Logger, noteCreator NoteCreator) http.HandlerFunc {
 
 		userId, _ := claims["user_id"].(string)
 
-		id, err := noteCreator.CreateNote(title, userId)
+		var id int
+		var err error
+
+		if req.WorkspaceId != "" {
+			var role string
+
+			role, err = validate.VerifyWorkspaceMember(req.WorkspaceId, noteCreator, w, r, log)
+			if err != nil {
+				return
+			}
+
+			if !workspace.CanEditNotes(role) {
+				log.Error("user can't create workspace notes", slog.String("user_id", userId), slog.String("role", role))
+
+				w.WriteHeader(http.StatusForbidden)
+				render.JSON(w, r, resp.Error(resperrors.ErrInsufficientWorkspaceRole))
+
+				return
+			}
+
+			id, err = noteCreator.CreateWorkspaceNote(r.Context(), title, req.WorkspaceId)
+		} else {
+			id, err = noteCreator.CreateNote(r.Context(), title, userId)
+		}
 		if err != nil {
 			log.Error("failed to create note", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrFailedToCreateNote))
+			validate.InternalError(w, r, err, resperrors.ErrFailedToCreateNote)
 
 			return
 		}
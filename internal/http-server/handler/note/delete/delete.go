@@ -1,27 +1,36 @@
 package delete
 
 import (
+	"context"
 	"errors"
 	"log/slog"
+	"main/internal/audit"
 	resp "main/internal/http-server/api/response"
 	resperrors "main/internal/http-server/api/response-errors"
 	"main/internal/http-server/api/validate"
 	"main/internal/storage"
+	"main/internal/tracing"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
 	"github.com/go-chi/render"
 )
 
 type NoteDeleter interface {
-	DeleteNote(id int) error
+	DeleteNote(ctx context.Context, id int) error
 	validate.UserVerifier
+	audit.Recorder
 }
 
 func New(log *slog.Logger, noteDeleter NoteDeleter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handler.note.delete.New"
 
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
 		log = log.With(
 			slog.String("op", op),
 			slog.String("request-id", middleware.GetReqID(r.Context())),
@@ -37,7 +46,7 @@ func New(log *slog.Logger, noteDeleter NoteDeleter) http.HandlerFunc {
 			return
 		}
 
-		err = noteDeleter.DeleteNote(id)
+		err = noteDeleter.DeleteNote(r.Context(), id)
 		if errors.Is(err, storage.ErrNoteNotFound) {
 			log.Error("note not found", "error", err)
 
@@ -49,14 +58,24 @@ func New(log *slog.Logger, noteDeleter NoteDeleter) http.HandlerFunc {
 		if err != nil {
 			log.Error("failed to delete note", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrFailedToDeleteNote))
+			validate.InternalError(w, r, err, resperrors.ErrFailedToDeleteNote)
 
 			return
 		}
 
 		log.Info("note deleted")
 
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		audit.Record(r, log, noteDeleter, audit.Event{
+			Action:     audit.ActionNoteDelete,
+			ActorId:    audit.Actor(userId),
+			TargetType: audit.TargetNote,
+			TargetId:   strconv.Itoa(id),
+		})
+
 		render.JSON(w, r, resp.OK())
 	}
 }
@@ -1,6 +1,7 @@
 package getnote
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 	resp "main/internal/http-server/api/response"
@@ -8,6 +9,7 @@ import (
 	"main/internal/http-server/api/validate"
 	"main/internal/models/note"
 	"main/internal/storage"
+	"main/internal/tracing"
 	"net/http"
 
 	"github.com/go-chi/chi/v5/middleware"
@@ -20,8 +22,8 @@ type Response struct {
 }
 
 type NoteGetter interface {
-	GetNoteById(id int) (note.Note, error)
-	GetAllNotesNodes(noteId int) ([]note.NoteNode, error)
+	GetNoteById(ctx context.Context, id int) (note.Note, error)
+	GetAllNotesNodes(ctx context.Context, noteId int) ([]note.NoteNode, error)
 	validate.UserVerifier
 }
 
@@ -29,6 +31,9 @@ func New(log *slog.Logger, noteGetter NoteGetter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handler.note.get.New"
 
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
 		log = log.With(
 			slog.String("op", op),
 			slog.String("request-id", middleware.GetReqID(r.Context())),
@@ -39,7 +44,7 @@ func New(log *slog.Logger, noteGetter NoteGetter) http.HandlerFunc {
 			return
 		}
 
-		noteFromDB, err := noteGetter.GetNoteById(id)
+		noteFromDB, err := noteGetter.GetNoteById(r.Context(), id)
 		if errors.Is(err, storage.ErrNoteNotFound) {
 			log.Error("note not found", "error", err)
 
@@ -51,18 +56,17 @@ func New(log *slog.Logger, noteGetter NoteGetter) http.HandlerFunc {
 		if err != nil {
 			log.Error("failed to get note", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrFailedToGetNote))
+			validate.InternalError(w, r, err, resperrors.ErrFailedToGetNote)
 
 			return
 		}
 
-		err = validate.VerifyUserNote(noteFromDB.Id, noteGetter, w, r, log)
+		err = validate.VerifyUserNoteRead(noteFromDB.Id, noteGetter, w, r, log)
 		if err != nil {
 			return
 		}
 
-		nodes, err := noteGetter.GetAllNotesNodes(noteFromDB.Id)
+		nodes, err := noteGetter.GetAllNotesNodes(r.Context(), noteFromDB.Id)
 		if errors.Is(err, storage.ErrNoteNodeNotFound) {
 			log.Error("note nodes not found", "error", err)
 
@@ -71,8 +75,7 @@ func New(log *slog.Logger, noteGetter NoteGetter) http.HandlerFunc {
 		if err != nil {
 			log.Error("failed to get note nodes", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrFailedToGetNoteNodes))
+			validate.InternalError(w, r, err, resperrors.ErrFailedToGetNoteNodes)
 
 			return
 		}
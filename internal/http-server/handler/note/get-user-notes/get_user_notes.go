@@ -1,12 +1,15 @@
 package getusernotes
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 	resp "main/internal/http-server/api/response"
 	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
 	"main/internal/models/note"
 	"main/internal/storage"
+	"main/internal/tracing"
 	"net/http"
 
 	"github.com/go-chi/chi/v5/middleware"
@@ -20,13 +23,18 @@ type Response struct {
 }
 
 type NotesGetter interface {
-	GetUserNotes(userId string) ([]note.NotePreview, error)
+	GetUserNotes(ctx context.Context, userId string) ([]note.NotePreview, error)
+	GetWorkspaceNotes(ctx context.Context, workspaceId string) ([]note.NotePreview, error)
+	validate.WorkspaceRoleGetter
 }
 
 func New(log *slog.Logger, notesGetter NotesGetter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handler.note.get.New"
 
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
 		log = log.With(
 			slog.String("op", op),
 			slog.String("request-id", middleware.GetReqID(r.Context())),
@@ -35,12 +43,23 @@ func New(log *slog.Logger, notesGetter NotesGetter) http.HandlerFunc {
 		_, claims, _ := jwtauth.FromContext(r.Context())
 		userId, _ := claims["user_id"].(string)
 
-		notes, err := notesGetter.GetUserNotes(userId)
+		var notes []note.NotePreview
+		var err error
+
+		// without workspace_id personal notes are listed along with notes of every user's workspace
+		if workspaceId := r.URL.Query().Get("workspace_id"); workspaceId != "" {
+			if _, err := validate.VerifyWorkspaceMember(workspaceId, notesGetter, w, r, log); err != nil {
+				return
+			}
+
+			notes, err = notesGetter.GetWorkspaceNotes(r.Context(), workspaceId)
+		} else {
+			notes, err = notesGetter.GetUserNotes(r.Context(), userId)
+		}
 		if err != nil && !errors.Is(err, storage.ErrNoteNotFound) {
 			log.Error("failed to get notes", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrFailedToGetUsersNote))
+			validate.InternalError(w, r, err, resperrors.ErrFailedToGetUsersNote)
 
 			return
 		}
@@ -1,27 +1,36 @@
 package unarchive
 
 import (
+	"context"
 	"errors"
 	"log/slog"
+	"main/internal/audit"
 	resp "main/internal/http-server/api/response"
 	resperrors "main/internal/http-server/api/response-errors"
 	"main/internal/http-server/api/validate"
 	"main/internal/storage"
+	"main/internal/tracing"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
 	"github.com/go-chi/render"
 )
 
 type NoteUnarchiver interface {
-	UnarchiveNote(id int) error
+	UnarchiveNote(ctx context.Context, id int) error
 	validate.UserVerifier
+	audit.Recorder
 }
 
 func New(log *slog.Logger, noteUnarchiver NoteUnarchiver) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handler.note.unarchive.New"
 
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
 		log = log.With(
 			slog.String("op", op),
 			slog.String("request-id", middleware.GetReqID(r.Context())),
@@ -37,7 +46,7 @@ func New(log *slog.Logger, noteUnarchiver NoteUnarchiver) http.HandlerFunc {
 			return
 		}
 
-		err = noteUnarchiver.UnarchiveNote(id)
+		err = noteUnarchiver.UnarchiveNote(r.Context(), id)
 		if errors.Is(err, storage.ErrNoteNotFound) {
 			log.Error("note not found", "error", err)
 
@@ -49,14 +58,24 @@ func New(log *slog.Logger, noteUnarchiver NoteUnarchiver) http.HandlerFunc {
 		if err != nil {
 			log.Error("failed to unarchive note", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrFailedToUnarchiveNote))
+			validate.InternalError(w, r, err, resperrors.ErrFailedToUnarchiveNote)
 
 			return
 		}
 
 		log.Info("note unarchived", slog.Int("id", id))
 
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		audit.Record(r, log, noteUnarchiver, audit.Event{
+			Action:     audit.ActionNoteUnarchive,
+			ActorId:    audit.Actor(userId),
+			TargetType: audit.TargetNote,
+			TargetId:   strconv.Itoa(id),
+		})
+
 		render.JSON(w, r, resp.OK())
 	}
 }
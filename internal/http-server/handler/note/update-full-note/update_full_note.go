@@ -1,11 +1,13 @@
 package updatefullnote
 
 import (
+	"context"
 	"log/slog"
 	resp "main/internal/http-server/api/response"
 	resperrors "main/internal/http-server/api/response-errors"
 	"main/internal/http-server/api/validate"
 	"main/internal/models/note"
+	"main/internal/tracing"
 	"net/http"
 
 	"github.com/go-chi/chi/v5/middleware"
@@ -17,7 +19,7 @@ type Request struct {
 }
 
 type NoteFUllUpdater interface {
-	UpdateFullNote(id int, note note.Note) (int, error)
+	UpdateFullNote(ctx context.Context, id int, note note.Note) (int, error)
 	validate.UserVerifier
 }
 
@@ -25,6 +27,9 @@ func New(log *slog.Logger, noteUpdater NoteFUllUpdater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handler.note.updatefullnote.New"
 
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
 		log = log.With(
 			slog.String("op", op),
 			slog.String("request-id", middleware.GetReqID(r.Context())),
@@ -45,12 +50,11 @@ func New(log *slog.Logger, noteUpdater NoteFUllUpdater) http.HandlerFunc {
 			return
 		}
 
-		rows, err := noteUpdater.UpdateFullNote(id, req.Note)
+		rows, err := noteUpdater.UpdateFullNote(r.Context(), id, req.Note)
 		if err != nil {
 			log.Error("failed to update note", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrFailedToUpdateFullNote))
+			validate.InternalError(w, r, err, resperrors.ErrFailedToUpdateFullNote)
 
 			return
 		}
@@ -1,12 +1,14 @@
 package updateorder
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 	resp "main/internal/http-server/api/response"
 	resperrors "main/internal/http-server/api/response-errors"
 	"main/internal/http-server/api/validate"
 	"main/internal/storage"
+	"main/internal/tracing"
 	"net/http"
 
 	"github.com/go-chi/chi/v5/middleware"
@@ -19,7 +21,7 @@ type Request struct {
 }
 
 type NoteOrderUpdater interface {
-	UpdateNoteNodeOrder(noteId int, oldOrder int, newOrder int) error
+	UpdateNoteNodeOrder(ctx context.Context, noteId int, oldOrder int, newOrder int) error
 	validate.UserVerifier
 }
 
@@ -27,6 +29,9 @@ func New(log *slog.Logger, noteUpdater NoteOrderUpdater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handler.note.updateorder.New"
 
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
 		log = log.With(
 			slog.String("op", op),
 			slog.String("request-id", middleware.GetReqID(r.Context())),
@@ -47,7 +52,7 @@ func New(log *slog.Logger, noteUpdater NoteOrderUpdater) http.HandlerFunc {
 			return
 		}
 
-		err = noteUpdater.UpdateNoteNodeOrder(id, req.OldOrder, req.NewOrder)
+		err = noteUpdater.UpdateNoteNodeOrder(r.Context(), id, req.OldOrder, req.NewOrder)
 		if errors.Is(err, storage.ErrNoteNodeNotFound) {
 			log.Error("note node not found", "error", err)
 
@@ -59,8 +64,7 @@ func New(log *slog.Logger, noteUpdater NoteOrderUpdater) http.HandlerFunc {
 		if err != nil {
 			log.Error("failed to update note node order", "error", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error(resperrors.ErrFailedToUpdateNodesOrder))
+			validate.InternalError(w, r, err, resperrors.ErrFailedToUpdateNodesOrder)
 
 			return
 		}
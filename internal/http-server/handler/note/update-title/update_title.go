@@ -1,12 +1,14 @@
 package updatetitle
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 	resp "main/internal/http-server/api/response"
 	resperrors "main/internal/http-server/api/response-errors"
 	"main/internal/http-server/api/validate"
 	"main/internal/storage"
+	"main/internal/tracing"
 	"net/http"
 
 	"github.com/go-chi/chi/v5/middleware"
@@ -18,7 +20,7 @@ type Request struct {
 }
 
 type NoteTitleUpdater interface {
-	UpdateNoteTitle(id int, title string) error
+	UpdateNoteTitle(ctx context.Context, id int, title string) error
 	validate.UserVerifier
 }
 
@@ -26,6 +28,9 @@ func New(log *slog.Logger, noteTitleUpdater NoteTitleUpdater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handler.note.updatetitle.New"
 
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
 		log = log.With(
 			slog.String("op", op),
 			slog.String("request-id", middleware.GetReqID(r.Context())),
@@ -48,7 +53,7 @@ func New(log *slog.Logger, noteTitleUpdater NoteTitleUpdater) http.HandlerFunc {
 
 		title := req.Title
 
-		err = noteTitleUpdater.UpdateNoteTitle(id, title)
+		err = noteTitleUpdater.UpdateNoteTitle(r.Context(), id, title)
 		if errors.Is(err, storage.ErrNoteNotFound) {
 			log.Error("note not found", "error", err)
 
@@ -0,0 +1,77 @@
+package authorize
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"main/internal/auth/oidc"
+	"main/internal/config"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	resp.Response
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+type ProviderGetter interface {
+	Get(ctx context.Context, name string) (*oidc.Provider, error)
+}
+
+func New(cfg *config.Config, log *slog.Logger, stateCreator oidc.StateCreator, providerGetter ProviderGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.oidc.authorize.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		providerName := chi.URLParam(r, "provider")
+
+		provider, err := providerGetter.Get(r.Context(), providerName)
+		if errors.Is(err, oidc.ErrProviderNotFound) {
+			log.Error("oidc provider not found", slog.String("provider", providerName))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrOIDCProviderNotFound))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get oidc provider", "error", err)
+
+			w.WriteHeader(http.StatusBadGateway)
+			render.JSON(w, r, resp.Error(resperrors.ErrOIDCLoginFailed))
+
+			return
+		}
+
+		state, err := oidc.NewState(r.Context(), providerName, cfg.OIDC.StateTTL, stateCreator)
+		if err != nil {
+			log.Error("failed to create oidc state", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("oidc authorization started", slog.String("provider", providerName))
+
+		render.JSON(w, r, Response{
+			Response:         resp.OK(),
+			AuthorizationURL: provider.AuthCodeURL(state.State, state.Nonce, state.CodeVerifier),
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"main/internal/audit"
+	"main/internal/auth"
+	"main/internal/auth/oidc"
+	"main/internal/config"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	authorize "main/internal/http-server/handler/oidc/authorize"
+	"main/internal/models/user"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Request struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
+
+type Response struct {
+	resp.Response
+	Tokens            *auth.Tokens `json:"tokens,omitempty"`
+	TwoFactorRequired bool         `json:"two_factor_required,omitempty"`
+	ChallengeToken    string       `json:"challenge_token,omitempty"`
+}
+
+type OIDCLoginer interface {
+	ConsumeOIDCState(ctx context.Context, state string) (oidc.State, error)
+	GetUserIdByIdentity(ctx context.Context, provider, subject string) (string, error)
+	GetUser(ctx context.Context, email string) (user.User, error)
+	LinkUserIdentity(ctx context.Context, userId string, identity oidc.Identity) error
+	CreateUserWithIdentity(ctx context.Context, identity oidc.Identity) (string, error)
+	IsTwoFactorEnabled(ctx context.Context, userId string) (bool, error)
+	auth.TokensIssuer
+	audit.Recorder
+}
+
+var (
+	errEmailNotVerified      = errors.New("email is not verified by provider")
+	errLocalEmailNotVerified = errors.New("email of existing account is not verified")
+)
+
+func New(cfg *config.Config, log *slog.Logger, oidcLoginer OIDCLoginer, providerGetter authorize.ProviderGetter, tokenAuth auth.TokenAuth) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.oidc.callback.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := validate.DecodeAndValidateRequestJson(&req, w, r, log); err != nil {
+			return
+		}
+
+		providerName := chi.URLParam(r, "provider")
+
+		// state is consumed before anything else so it can not be replayed
+		state, err := oidcLoginer.ConsumeOIDCState(r.Context(), req.State)
+		if errors.Is(err, storage.ErrOIDCStateNotFound) || (err == nil && state.Provider != providerName) {
+			log.Error("invalid oidc state", slog.String("provider", providerName))
+
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvalidOIDCState))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to consume oidc state", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		provider, err := providerGetter.Get(r.Context(), providerName)
+		if errors.Is(err, oidc.ErrProviderNotFound) {
+			log.Error("oidc provider not found", slog.String("provider", providerName))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrOIDCProviderNotFound))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get oidc provider", "error", err)
+
+			w.WriteHeader(http.StatusBadGateway)
+			render.JSON(w, r, resp.Error(resperrors.ErrOIDCLoginFailed))
+
+			return
+		}
+
+		identity, err := provider.Exchange(r.Context(), req.Code, state.CodeVerifier, state.Nonce)
+		if err != nil {
+			log.Error("failed to exchange oidc code", "error", err)
+
+			w.WriteHeader(http.StatusUnauthorized)
+			render.JSON(w, r, resp.Error(resperrors.ErrOIDCLoginFailed))
+
+			return
+		}
+
+		userId, err := resolveUser(r.Context(), identity, oidcLoginer)
+		if errors.Is(err, errEmailNotVerified) {
+			log.Error("refusing identity with email unverified by provider", slog.String("provider", providerName))
+
+			w.WriteHeader(http.StatusConflict)
+			render.JSON(w, r, resp.Error(resperrors.ErrEmailNotVerifiedByProvider))
+
+			return
+		}
+		if errors.Is(err, errLocalEmailNotVerified) {
+			log.Error("refusing to link identity to account with unverified email", slog.String("provider", providerName))
+
+			w.WriteHeader(http.StatusConflict)
+			render.JSON(w, r, resp.Error(resperrors.ErrAccountEmailNotVerified))
+
+			return
+		}
+		if errors.Is(err, storage.ErrUserAlreadyExists) {
+			log.Error("user already exists", slog.String("provider", providerName))
+
+			w.WriteHeader(http.StatusConflict)
+			render.JSON(w, r, resp.Error(resperrors.ErrUserIsAlreadyExists))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to resolve oidc user", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		twoFactorEnabled, err := oidcLoginer.IsTwoFactorEnabled(r.Context(), userId)
+		if err != nil {
+			log.Error("failed to check two-factor", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		if twoFactorEnabled {
+			challenge, err := auth.GenerateTwoFactorChallenge(userId, cfg, tokenAuth)
+			if err != nil {
+				log.Error("failed to generate two-factor challenge", "error", err)
+
+				validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+				return
+			}
+
+			log.Info("two-factor required", slog.String("provider", providerName))
+
+			render.JSON(w, r, Response{
+				Response:          resp.OK(),
+				TwoFactorRequired: true,
+				ChallengeToken:    challenge,
+			})
+
+			return
+		}
+
+		tokens, err := auth.GenerateTokens(r.Context(), userId, oidcLoginer, cfg, tokenAuth)
+		if errors.Is(err, auth.ErrUserDisabled) {
+			log.Error("user is disabled", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, resp.Error(resperrors.ErrAccountDisabled))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to generate tokens", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("success oidc login", slog.String("provider", providerName))
+
+		audit.Record(r, log, oidcLoginer, audit.Event{
+			Action:     audit.ActionLoginSuccess,
+			ActorId:    audit.Actor(userId),
+			TargetType: audit.TargetUser,
+			TargetId:   userId,
+			Details:    audit.Details{"method": "oidc", "provider": providerName},
+		})
+
+		render.JSON(w, r, Response{Response: resp.OK(), Tokens: &tokens})
+	}
+}
+
+// resolveUser finds user by linked identity, links identity to existing user
+// when both sides verified the same email or creates a new user with email
+// verified by provider
+func resolveUser(ctx context.Context, identity oidc.Identity, oidcLoginer OIDCLoginer) (string, error) {
+	const op = "handler.oidc.callback.resolveUser"
+
+	userId, err := oidcLoginer.GetUserIdByIdentity(ctx, identity.Provider, identity.Subject)
+	if err == nil {
+		return userId, nil
+	}
+	if !errors.Is(err, storage.ErrIdentityNotFound) {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	// provider has to vouch for the email both to link it and to create account
+	// with it, otherwise anyone could take over the account by signing in with
+	// its email, or claim the email before its owner registers and keep access
+	// after the owner recovers it with password reset
+	if !identity.EmailVerified {
+		return "", errEmailNotVerified
+	}
+
+	existingUser, err := oidcLoginer.GetUser(ctx, identity.Email)
+	if errors.Is(err, storage.ErrUserNotFound) {
+		userId, err := oidcLoginer.CreateUserWithIdentity(ctx, identity)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+
+		return userId, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	// local account has to own the email too, otherwise whoever registered it
+	// before the real owner keeps the password to the linked account
+	if existingUser.EmailVerifiedAt == nil {
+		return "", errLocalEmailNotVerified
+	}
+
+	if err := oidcLoginer.LinkUserIdentity(ctx, existingUser.ID, identity); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return existingUser.ID, nil
+}
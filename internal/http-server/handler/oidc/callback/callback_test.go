@@ -0,0 +1,286 @@
+package callback_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"main/internal/auth/oidc"
+	"main/internal/config"
+	"main/internal/http-server/handler/oidc/callback"
+	"main/internal/storage"
+	"main/internal/storage/memory"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+const (
+	providerName = "mock"
+	clientId     = "client"
+	nonce        = "nonce"
+)
+
+// provider is an identity provider which issues id token with its current claims for any code
+type provider struct {
+	server *httptest.Server
+	key    jwk.Key
+	claims map[string]any
+}
+
+func newProvider(t *testing.T) *provider {
+	t.Helper()
+
+	raw, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	key, err := jwk.FromRaw(raw)
+	if err != nil {
+		t.Fatalf("FromRaw: %v", err)
+	}
+	_ = key.Set(jwk.KeyIDKey, "test")
+	_ = key.Set(jwk.AlgorithmKey, jwa.RS256)
+
+	p := &provider{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.server.URL,
+			"authorization_endpoint": p.server.URL + "/authorize",
+			"token_endpoint":         p.server.URL + "/token",
+			"jwks_uri":               p.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		public, _ := p.key.PublicKey()
+
+		set := jwk.NewSet()
+		_ = set.AddKey(public)
+
+		_ = json.NewEncoder(w).Encode(set)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     p.idToken(t),
+		})
+	})
+
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+
+	return p
+}
+
+func (p *provider) idToken(t *testing.T) string {
+	token := jwt.New()
+	_ = token.Set(jwt.IssuerKey, p.server.URL)
+	_ = token.Set(jwt.AudienceKey, clientId)
+	_ = token.Set(jwt.IssuedAtKey, time.Now())
+	_ = token.Set(jwt.ExpirationKey, time.Now().Add(time.Minute))
+	_ = token.Set("nonce", nonce)
+
+	for name, value := range p.claims {
+		_ = token.Set(name, value)
+	}
+
+	signed, err := jwt.Sign(token, jwt.WithKey(jwa.RS256, p.key))
+	if err != nil {
+		t.Errorf("Sign: %v", err)
+	}
+
+	return string(signed)
+}
+
+type env struct {
+	provider *provider
+	storage  *memory.Storage
+	handler  http.HandlerFunc
+}
+
+func newEnv(t *testing.T) env {
+	t.Helper()
+
+	p := newProvider(t)
+	s := memory.New()
+
+	cfg := &config.Config{}
+	cfg.Authorization.AccessTTL = time.Minute
+	cfg.Authorization.RefreshTTL = time.Hour
+	cfg.Authorization.Salt = "salt"
+
+	registry := oidc.NewRegistry(config.OIDC{Providers: []config.OIDCProvider{{
+		Name:        providerName,
+		IssuerURL:   p.server.URL,
+		ClientId:    clientId,
+		RedirectURL: "http://localhost/callback",
+	}}}, p.server.Client())
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	return env{
+		provider: p,
+		storage:  s,
+		handler:  callback.New(cfg, log, s, registry, jwtauth.New("HS256", []byte("secret"), nil)),
+	}
+}
+
+// login runs the callback as the provider's user with the email
+func (e env) login(t *testing.T, subject, email string, emailVerified bool) int {
+	t.Helper()
+
+	ctx := context.Background()
+
+	state := subject + "-state"
+	err := e.storage.CreateOIDCState(ctx, oidc.State{
+		State:        state,
+		Provider:     providerName,
+		CodeVerifier: "verifier",
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("CreateOIDCState: %v", err)
+	}
+
+	e.provider.claims = map[string]any{
+		jwt.SubjectKey:   subject,
+		"email":          email,
+		"email_verified": emailVerified,
+	}
+
+	body, _ := json.Marshal(callback.Request{Code: "code", State: state})
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("provider", providerName)
+
+	r := httptest.NewRequest(http.MethodPost, "/oidc/"+providerName+"/callback", bytes.NewReader(body))
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+	w := httptest.NewRecorder()
+
+	e.handler(w, r)
+
+	return w.Code
+}
+
+func (e env) linkedUser(t *testing.T, subject string) (string, bool) {
+	t.Helper()
+
+	userId, err := e.storage.GetUserIdByIdentity(context.Background(), providerName, subject)
+	if errors.Is(err, storage.ErrIdentityNotFound) {
+		return "", false
+	}
+	if err != nil {
+		t.Fatalf("GetUserIdByIdentity: %v", err)
+	}
+
+	return userId, true
+}
+
+func TestNewUserIsCreated(t *testing.T) {
+	e := newEnv(t)
+
+	if code := e.login(t, "subject", "new@example.com", true); code != http.StatusOK {
+		t.Fatalf("login: got status %d, want %d", code, http.StatusOK)
+	}
+
+	if _, ok := e.linkedUser(t, "subject"); !ok {
+		t.Fatal("identity of the new user isn't linked")
+	}
+}
+
+func TestVerifiedAccountIsLinked(t *testing.T) {
+	e := newEnv(t)
+	ctx := context.Background()
+
+	userId, err := e.storage.CreateUser(ctx, "owner@example.com", "Owner", "hash")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if err := e.storage.SetEmailVerified(ctx, userId); err != nil {
+		t.Fatalf("SetEmailVerified: %v", err)
+	}
+
+	if code := e.login(t, "subject", "owner@example.com", true); code != http.StatusOK {
+		t.Fatalf("login: got status %d, want %d", code, http.StatusOK)
+	}
+
+	if linked, ok := e.linkedUser(t, "subject"); !ok || linked != userId {
+		t.Fatalf("identity is linked to %q, want %q", linked, userId)
+	}
+}
+
+// TestUnverifiedAccountIsNotLinked covers account registered with someone
+// else's email before its owner signs in with the provider
+func TestUnverifiedAccountIsNotLinked(t *testing.T) {
+	e := newEnv(t)
+
+	if _, err := e.storage.CreateUser(context.Background(), "victim@example.com", "Attacker", "hash"); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	if code := e.login(t, "subject", "victim@example.com", true); code != http.StatusConflict {
+		t.Fatalf("login: got status %d, want %d", code, http.StatusConflict)
+	}
+
+	if _, ok := e.linkedUser(t, "subject"); ok {
+		t.Fatal("identity is linked to account with unverified email")
+	}
+}
+
+func TestEmailUnverifiedByProviderIsNotLinked(t *testing.T) {
+	e := newEnv(t)
+	ctx := context.Background()
+
+	userId, err := e.storage.CreateUser(ctx, "owner@example.com", "Owner", "hash")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if err := e.storage.SetEmailVerified(ctx, userId); err != nil {
+		t.Fatalf("SetEmailVerified: %v", err)
+	}
+
+	if code := e.login(t, "subject", "owner@example.com", false); code != http.StatusConflict {
+		t.Fatalf("login: got status %d, want %d", code, http.StatusConflict)
+	}
+
+	if _, ok := e.linkedUser(t, "subject"); ok {
+		t.Fatal("identity with email unverified by provider is linked")
+	}
+}
+
+// TestEmailUnverifiedByProviderCreatesNoAccount covers email claimed through
+// a provider which doesn't verify it before its owner registers
+func TestEmailUnverifiedByProviderCreatesNoAccount(t *testing.T) {
+	e := newEnv(t)
+
+	if code := e.login(t, "subject", "victim@example.com", false); code != http.StatusConflict {
+		t.Fatalf("login: got status %d, want %d", code, http.StatusConflict)
+	}
+
+	if _, ok := e.linkedUser(t, "subject"); ok {
+		t.Fatal("identity with email unverified by provider is linked")
+	}
+
+	_, err := e.storage.GetUser(context.Background(), "victim@example.com")
+	if !errors.Is(err, storage.ErrUserNotFound) {
+		t.Fatalf("GetUser: got %v, want %v", err, storage.ErrUserNotFound)
+	}
+}
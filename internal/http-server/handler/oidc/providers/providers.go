@@ -0,0 +1,40 @@
+package providers
+
+import (
+	"log/slog"
+	resp "main/internal/http-server/api/response"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	resp.Response
+	Providers []string `json:"providers"`
+}
+
+type ProvidersLister interface {
+	Names() []string
+}
+
+func New(log *slog.Logger, providersLister ProvidersLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.oidc.providers.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		names := providersLister.Names()
+
+		log.Debug("oidc providers listed", slog.Int("count", len(names)))
+
+		render.JSON(w, r, Response{Response: resp.OK(), Providers: names})
+	}
+}
@@ -0,0 +1,72 @@
+package delete
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+)
+
+type PasskeyDeleter interface {
+	DeletePasskey(ctx context.Context, id, userId string) error
+}
+
+func New(log *slog.Logger, passkeyDeleter PasskeyDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.passkey.delete.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		passkeyId := chi.URLParam(r, "id")
+		if _, err := uuid.Parse(passkeyId); err != nil {
+			log.Error("invalid passkey id", slog.String("id", passkeyId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrPasskeyDoesNotExist))
+
+			return
+		}
+
+		err := passkeyDeleter.DeletePasskey(r.Context(), passkeyId, userId)
+		if errors.Is(err, storage.ErrPasskeyNotFound) {
+			log.Error("passkey not found", slog.String("id", passkeyId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrPasskeyDoesNotExist))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to delete passkey", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("passkey deleted", slog.String("user_id", userId), slog.String("id", passkeyId))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -0,0 +1,56 @@
+package list
+
+import (
+	"context"
+	"log/slog"
+	"main/internal/auth/passkey"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	resp.Response
+	Passkeys []passkey.Passkey `json:"passkeys"`
+}
+
+type PasskeysGetter interface {
+	GetUserPasskeys(ctx context.Context, userId string) ([]passkey.Passkey, error)
+}
+
+func New(log *slog.Logger, passkeysGetter PasskeysGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.passkey.list.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		passkeys, err := passkeysGetter.GetUserPasskeys(r.Context(), userId)
+		if err != nil {
+			log.Error("failed to get passkeys", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Debug("passkeys listed", slog.String("user_id", userId), slog.Int("count", len(passkeys)))
+
+		render.JSON(w, r, Response{Response: resp.OK(), Passkeys: passkeys})
+	}
+}
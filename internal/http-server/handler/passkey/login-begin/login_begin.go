@@ -0,0 +1,48 @@
+package loginbegin
+
+import (
+	"log/slog"
+	"main/internal/auth/passkey"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+type Response struct {
+	resp.Response
+	SessionId string                        `json:"session_id"`
+	Options   *protocol.CredentialAssertion `json:"options"`
+}
+
+func New(log *slog.Logger, sessionStore passkey.SessionStore, webAuthn *passkey.WebAuthn) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.passkey.loginbegin.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		sessionId, options, err := webAuthn.BeginLogin(r.Context(), sessionStore)
+		if err != nil {
+			log.Error("failed to begin passkey login", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Debug("passkey login started")
+
+		render.JSON(w, r, Response{Response: resp.OK(), SessionId: sessionId, Options: options})
+	}
+}
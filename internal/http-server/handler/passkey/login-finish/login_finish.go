@@ -0,0 +1,110 @@
+package loginfinish
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"main/internal/audit"
+	"main/internal/auth"
+	"main/internal/auth/passkey"
+	"main/internal/config"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Request struct {
+	SessionId  string          `json:"session_id" validate:"required"`
+	Credential json.RawMessage `json:"credential" validate:"required"`
+}
+
+type Response struct {
+	resp.Response
+	Tokens auth.Tokens `json:"tokens"`
+}
+
+type PasskeyLoginer interface {
+	passkey.SessionStore
+	passkey.PasskeyAuthenticator
+	auth.TokensIssuer
+	audit.Recorder
+}
+
+func New(cfg *config.Config, log *slog.Logger, passkeyLoginer PasskeyLoginer, webAuthn *passkey.WebAuthn, tokenAuth auth.TokenAuth) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.passkey.loginfinish.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := validate.DecodeAndValidateRequestJson(&req, w, r, log); err != nil {
+			return
+		}
+
+		userId, err := webAuthn.FinishLogin(r.Context(), req.SessionId, req.Credential, passkeyLoginer, passkeyLoginer)
+		if errors.Is(err, storage.ErrWebAuthnSessionNotFound) {
+			log.Error("invalid webauthn session")
+
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvalidWebAuthnSession))
+
+			return
+		}
+		if errors.Is(err, passkey.ErrInvalidCredential) || errors.Is(err, passkey.ErrCloneDetected) {
+			log.Error("passkey assertion rejected", "error", err)
+
+			w.WriteHeader(http.StatusUnauthorized)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvalidPasskeyCredential))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to finish passkey login", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		tokens, err := auth.GenerateTokens(r.Context(), userId, passkeyLoginer, cfg, tokenAuth)
+		if errors.Is(err, auth.ErrUserDisabled) {
+			log.Error("user is disabled", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, resp.Error(resperrors.ErrAccountDisabled))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to generate tokens", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("success passkey login", slog.String("user_id", userId))
+
+		audit.Record(r, log, passkeyLoginer, audit.Event{
+			Action:     audit.ActionLoginSuccess,
+			ActorId:    audit.Actor(userId),
+			TargetType: audit.TargetUser,
+			TargetId:   userId,
+			Details:    audit.Details{"method": "passkey"},
+		})
+
+		render.JSON(w, r, Response{Response: resp.OK(), Tokens: tokens})
+	}
+}
@@ -0,0 +1,89 @@
+package registerbegin
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"main/internal/auth/passkey"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/models/user"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+type Response struct {
+	resp.Response
+	SessionId string                       `json:"session_id"`
+	Options   *protocol.CredentialCreation `json:"options"`
+}
+
+type RegistrationBeginner interface {
+	GetUserById(ctx context.Context, id string) (user.User, error)
+	GetUserPasskeys(ctx context.Context, userId string) ([]passkey.Passkey, error)
+	passkey.SessionStore
+}
+
+func New(log *slog.Logger, registrationBeginner RegistrationBeginner, webAuthn *passkey.WebAuthn) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.passkey.registerbegin.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		userFromDb, err := registrationBeginner.GetUserById(r.Context(), userId)
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Error("user not found", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrUserDoesNotExist))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get user", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		passkeys, err := registrationBeginner.GetUserPasskeys(r.Context(), userId)
+		if err != nil {
+			log.Error("failed to get passkeys", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		sessionId, options, err := webAuthn.BeginRegistration(r.Context(), userFromDb, passkeys, registrationBeginner)
+		if err != nil {
+			log.Error("failed to begin passkey registration", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("passkey registration started", slog.String("user_id", userId))
+
+		render.JSON(w, r, Response{Response: resp.OK(), SessionId: sessionId, Options: options})
+	}
+}
@@ -0,0 +1,128 @@
+package registerfinish
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"main/internal/auth/passkey"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/models/user"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type Request struct {
+	SessionId  string          `json:"session_id" validate:"required"`
+	Name       string          `json:"name" validate:"required,max=255"`
+	Credential json.RawMessage `json:"credential" validate:"required"`
+}
+
+type RegistrationFinisher interface {
+	GetUserById(ctx context.Context, id string) (user.User, error)
+	GetUserPasskeys(ctx context.Context, userId string) ([]passkey.Passkey, error)
+	passkey.SessionStore
+	passkey.PasskeyCreator
+}
+
+func New(log *slog.Logger, registrationFinisher RegistrationFinisher, webAuthn *passkey.WebAuthn) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.passkey.registerfinish.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := validate.DecodeAndValidateRequestJson(&req, w, r, log); err != nil {
+			return
+		}
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		userFromDb, err := registrationFinisher.GetUserById(r.Context(), userId)
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Error("user not found", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrUserDoesNotExist))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get user", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		passkeys, err := registrationFinisher.GetUserPasskeys(r.Context(), userId)
+		if err != nil {
+			log.Error("failed to get passkeys", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		newPasskey, err := webAuthn.FinishRegistration(r.Context(), userFromDb, passkeys, req.SessionId, req.Name, req.Credential, registrationFinisher)
+		if errors.Is(err, storage.ErrWebAuthnSessionNotFound) || errors.Is(err, passkey.ErrSessionMismatch) {
+			log.Error("invalid webauthn session", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvalidWebAuthnSession))
+
+			return
+		}
+		if errors.Is(err, passkey.ErrInvalidCredential) {
+			log.Error("invalid passkey credential", "error", err)
+
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvalidPasskeyCredential))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to finish passkey registration", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		err = registrationFinisher.CreatePasskey(r.Context(), newPasskey)
+		if errors.Is(err, storage.ErrPasskeyAlreadyExists) {
+			log.Error("passkey already registered", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusConflict)
+			render.JSON(w, r, resp.Error(resperrors.ErrPasskeyIsAlreadyExists))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to save passkey", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("passkey registered", slog.String("user_id", userId))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -0,0 +1,99 @@
+package create
+
+import (
+	"errors"
+	"log/slog"
+	"main/internal/audit"
+	"main/internal/auth/pat"
+	"main/internal/config"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/tracing"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type Request struct {
+	Name      string     `json:"name" validate:"required,max=255"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type Response struct {
+	resp.Response
+	// Token is shown only once, only its hash is stored
+	Token               string                  `json:"token"`
+	PersonalAccessToken pat.PersonalAccessToken `json:"data"`
+}
+
+type TokenCreator interface {
+	pat.Creator
+	audit.Recorder
+}
+
+func New(cfg *config.Config, log *slog.Logger, creator TokenCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.pat.create.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := validate.DecodeAndValidateRequestJson(&req, w, r, log); err != nil {
+			return
+		}
+
+		if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+			log.Error("expiration time is in the past")
+
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvalidRequestBody))
+
+			return
+		}
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		token, created, err := pat.Generate(r.Context(), userId, req.Name, req.Scopes, req.ExpiresAt, cfg.Authorization.Salt, creator)
+		if errors.Is(err, pat.ErrUnknownScope) {
+			log.Error("unknown scope", "error", err)
+
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resperrors.ErrUnknownScope))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to create personal access token", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("personal access token created", slog.String("user_id", userId), slog.String("id", created.Id))
+
+		audit.Record(r, log, creator, audit.Event{
+			Action:     audit.ActionTokenCreate,
+			ActorId:    audit.Actor(userId),
+			TargetType: audit.TargetPersonalToken,
+			TargetId:   created.Id,
+			Details:    audit.Details{"scopes": strings.Join(req.Scopes, ",")},
+		})
+
+		render.JSON(w, r, Response{Response: resp.OK(), Token: token, PersonalAccessToken: created})
+	}
+}
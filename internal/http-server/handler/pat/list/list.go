@@ -0,0 +1,56 @@
+package list
+
+import (
+	"context"
+	"log/slog"
+	"main/internal/auth/pat"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	resp.Response
+	PersonalAccessTokens []pat.PersonalAccessToken `json:"tokens"`
+}
+
+type TokensGetter interface {
+	GetUserPersonalAccessTokens(ctx context.Context, userId string) ([]pat.PersonalAccessToken, error)
+}
+
+func New(log *slog.Logger, tokensGetter TokensGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.pat.list.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		tokens, err := tokensGetter.GetUserPersonalAccessTokens(r.Context(), userId)
+		if err != nil {
+			log.Error("failed to get personal access tokens", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Debug("personal access tokens listed", slog.String("user_id", userId), slog.Int("count", len(tokens)))
+
+		render.JSON(w, r, Response{Response: resp.OK(), PersonalAccessTokens: tokens})
+	}
+}
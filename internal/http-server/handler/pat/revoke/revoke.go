@@ -0,0 +1,81 @@
+package revoke
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"main/internal/audit"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+)
+
+type TokenRevoker interface {
+	DeletePersonalAccessToken(ctx context.Context, id, userId string) error
+	audit.Recorder
+}
+
+func New(log *slog.Logger, tokenRevoker TokenRevoker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.pat.revoke.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		tokenId := chi.URLParam(r, "id")
+		if _, err := uuid.Parse(tokenId); err != nil {
+			log.Error("invalid personal access token id", slog.String("id", tokenId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrPersonalAccessTokenDoesNotExist))
+
+			return
+		}
+
+		err := tokenRevoker.DeletePersonalAccessToken(r.Context(), tokenId, userId)
+		if errors.Is(err, storage.ErrPersonalAccessTokenNotFound) {
+			log.Error("personal access token not found", slog.String("id", tokenId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrPersonalAccessTokenDoesNotExist))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to revoke personal access token", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("personal access token revoked", slog.String("user_id", userId), slog.String("id", tokenId))
+
+		audit.Record(r, log, tokenRevoker, audit.Event{
+			Action:     audit.ActionTokenRevoke,
+			ActorId:    audit.Actor(userId),
+			TargetType: audit.TargetPersonalToken,
+			TargetId:   tokenId,
+		})
+
+		render.JSON(w, r, resp.OK())
+	}
+}
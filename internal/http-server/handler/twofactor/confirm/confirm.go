@@ -0,0 +1,132 @@
+package confirm
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"main/internal/audit"
+	"main/internal/auth"
+	"main/internal/config"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type Request struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type Response struct {
+	resp.Response
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TOTPConfirmer interface {
+	GetTOTP(ctx context.Context, userId string) (auth.TOTP, error)
+	ConfirmTOTP(ctx context.Context, userId string, step int64, recoveryCodeHashes []string) error
+	audit.Recorder
+}
+
+func New(cfg *config.Config, log *slog.Logger, totpConfirmer TOTPConfirmer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.twofactor.confirm.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := validate.DecodeAndValidateRequestJson(&req, w, r, log); err != nil {
+			return
+		}
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		userTOTP, err := totpConfirmer.GetTOTP(r.Context(), userId)
+		if errors.Is(err, storage.ErrTOTPNotFound) {
+			log.Error("totp enrollment not started", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrTwoFactorNotEnabled))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get totp", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		if userTOTP.ConfirmedAt != nil {
+			log.Error("two-factor is already enabled", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusConflict)
+			render.JSON(w, r, resp.Error(resperrors.ErrTwoFactorAlreadyEnabled))
+
+			return
+		}
+
+		step, ok := auth.ValidateTOTPCode(userTOTP.Secret, req.Code, userTOTP.LastUsedStep, time.Now())
+		if !ok {
+			log.Error("invalid totp code", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusUnauthorized)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvalidTwoFactorCode))
+
+			return
+		}
+
+		codes, hashes, err := auth.GenerateRecoveryCodes(cfg.Authorization.Salt)
+		if err != nil {
+			log.Error("failed to generate recovery codes", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		err = totpConfirmer.ConfirmTOTP(r.Context(), userId, step, hashes)
+		if errors.Is(err, storage.ErrTOTPAlreadyEnabled) {
+			log.Error("two-factor is already enabled", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusConflict)
+			render.JSON(w, r, resp.Error(resperrors.ErrTwoFactorAlreadyEnabled))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to confirm totp", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("two-factor enabled", slog.String("user_id", userId))
+
+		audit.Record(r, log, totpConfirmer, audit.Event{
+			Action:     audit.ActionTwoFactorEnable,
+			ActorId:    audit.Actor(userId),
+			TargetType: audit.TargetUser,
+			TargetId:   userId,
+		})
+
+		render.JSON(w, r, Response{resp.OK(), codes})
+	}
+}
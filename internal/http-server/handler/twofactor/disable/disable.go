@@ -0,0 +1,129 @@
+package disable
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"main/internal/audit"
+	"main/internal/auth"
+	"main/internal/config"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/models/user"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type Request struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type TOTPDisabler interface {
+	GetUserById(ctx context.Context, id string) (user.User, error)
+	GetTOTP(ctx context.Context, userId string) (auth.TOTP, error)
+	DeleteTOTP(ctx context.Context, userId string) error
+	auth.SecondFactorVerifier
+	audit.Recorder
+}
+
+func New(cfg *config.Config, log *slog.Logger, totpDisabler TOTPDisabler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.twofactor.disable.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := validate.DecodeAndValidateRequestJson(&req, w, r, log); err != nil {
+			return
+		}
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		userFromDb, err := totpDisabler.GetUserById(r.Context(), userId)
+		if err != nil {
+			log.Error("failed to get user", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		if !auth.CheckPassword(req.Password, userFromDb.PasswordHash) {
+			log.Error("invalid password", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusUnauthorized)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvalidPassword))
+
+			return
+		}
+
+		userTOTP, err := totpDisabler.GetTOTP(r.Context(), userId)
+		if errors.Is(err, storage.ErrTOTPNotFound) || (err == nil && userTOTP.ConfirmedAt == nil) {
+			log.Error("two-factor is not enabled", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resperrors.ErrTwoFactorNotEnabled))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get totp", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		ok, err := auth.VerifySecondFactor(r.Context(), userTOTP, req.Code, cfg.Authorization.Salt, totpDisabler)
+		if err != nil {
+			log.Error("failed to verify two-factor code", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+		if !ok {
+			log.Error("invalid two-factor code", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusUnauthorized)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvalidTwoFactorCode))
+
+			return
+		}
+
+		err = totpDisabler.DeleteTOTP(r.Context(), userId)
+		if err != nil {
+			log.Error("failed to delete totp", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("two-factor disabled", slog.String("user_id", userId))
+
+		audit.Record(r, log, totpDisabler, audit.Event{
+			Action:     audit.ActionTwoFactorDisable,
+			ActorId:    audit.Actor(userId),
+			TargetType: audit.TargetUser,
+			TargetId:   userId,
+		})
+
+		render.JSON(w, r, resp.OK())
+	}
+}
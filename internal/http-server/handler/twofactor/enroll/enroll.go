@@ -0,0 +1,95 @@
+package enroll
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"main/internal/auth"
+	"main/internal/config"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/models/user"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	resp.Response
+	Enrollment auth.TOTPEnrollment `json:"data"`
+}
+
+type TOTPEnroller interface {
+	GetUserById(ctx context.Context, id string) (user.User, error)
+	SaveTOTPSecret(ctx context.Context, userId, secret string) error
+}
+
+func New(cfg *config.Config, log *slog.Logger, totpEnroller TOTPEnroller) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.twofactor.enroll.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		userFromDb, err := totpEnroller.GetUserById(r.Context(), userId)
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Error("user not found", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrUserDoesNotExist))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get user", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		enrollment, err := auth.GenerateTOTP(cfg.Authorization.TOTPIssuer, userFromDb.Email)
+		if err != nil {
+			log.Error("failed to generate totp secret", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		err = totpEnroller.SaveTOTPSecret(r.Context(), userId, enrollment.Secret)
+		if errors.Is(err, storage.ErrTOTPAlreadyEnabled) {
+			log.Error("two-factor is already enabled", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusConflict)
+			render.JSON(w, r, resp.Error(resperrors.ErrTwoFactorAlreadyEnabled))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to save totp secret", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("totp enrollment started", slog.String("user_id", userId))
+
+		render.JSON(w, r, Response{resp.OK(), enrollment})
+	}
+}
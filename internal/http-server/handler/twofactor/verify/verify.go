@@ -0,0 +1,270 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"main/internal/audit"
+	"main/internal/auth"
+	"main/internal/config"
+	"main/internal/http-server/api/request"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+type Request struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+}
+
+type Response struct {
+	resp.Response
+	Tokens auth.Tokens `json:"tokens"`
+}
+
+type TwoFactorVerifier interface {
+	GetTOTP(ctx context.Context, userId string) (auth.TOTP, error)
+	auth.SecondFactorVerifier
+	auth.TokensIssuer
+	audit.Recorder
+}
+
+// SecondFactorGuard reserves the attempt before the code is checked, reserved
+// attempt is finished with RegisterSecondFactorFailure, RegisterSecondFactorSuccess
+// or ReleaseSecondFactor
+type SecondFactorGuard interface {
+	ReserveSecondFactor(ctx context.Context, userId, ip string) (time.Duration, error)
+	RegisterSecondFactorFailure(ctx context.Context, userId, challengeId, ip string) (locked, exhausted bool, err error)
+	RegisterSecondFactorSuccess(ctx context.Context, userId, challengeId string) error
+	ReleaseSecondFactor(ctx context.Context, userId string) error
+}
+
+type ChallengeRevoker interface {
+	IsRevoked(ctx context.Context, jti, userId string, issuedAt time.Time) (bool, error)
+	Revoke(ctx context.Context, jti, userId string, expiresAt time.Time) error
+}
+
+func New(cfg *config.Config, log *slog.Logger, twoFactorVerifier TwoFactorVerifier, challengeRevoker ChallengeRevoker, guard SecondFactorGuard, tokenAuth auth.TokenAuth) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.twofactor.verify.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := validate.DecodeAndValidateRequestJson(&req, w, r, log); err != nil {
+			return
+		}
+
+		challenge, err := auth.ParseTwoFactorChallenge(req.ChallengeToken, tokenAuth)
+		if err != nil {
+			log.Error("invalid two-factor challenge", "error", err)
+
+			w.WriteHeader(http.StatusUnauthorized)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvalidTwoFactorChallenge))
+
+			return
+		}
+
+		userId := challenge.Subject()
+
+		// challenge can be exchanged only once
+		revoked, err := challengeRevoker.IsRevoked(r.Context(), challenge.JwtID(), userId, challenge.IssuedAt())
+		if err != nil {
+			log.Error("failed to check challenge revocation", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+		if revoked {
+			log.Error("two-factor challenge already used", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusUnauthorized)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvalidTwoFactorChallenge))
+
+			return
+		}
+
+		ip := request.ClientIP(r)
+
+		retryAfter, err := guard.ReserveSecondFactor(r.Context(), userId, ip)
+		if err != nil {
+			log.Error("failed to reserve two-factor attempt", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		if retryAfter > 0 {
+			log.Warn("two-factor attempt throttled", slog.String("user_id", userId), slog.String("ip", ip))
+
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			w.WriteHeader(http.StatusTooManyRequests)
+			render.JSON(w, r, resp.Error(resperrors.ErrTooManyLoginAttempts))
+
+			return
+		}
+
+		userTOTP, err := twoFactorVerifier.GetTOTP(r.Context(), userId)
+		if errors.Is(err, storage.ErrTOTPNotFound) || (err == nil && userTOTP.ConfirmedAt == nil) {
+			log.Error("two-factor is not enabled", slog.String("user_id", userId))
+
+			release(r.Context(), log, guard, userId)
+
+			w.WriteHeader(http.StatusUnauthorized)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvalidTwoFactorChallenge))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get totp", "error", err)
+
+			release(r.Context(), log, guard, userId)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		ok, err := auth.VerifySecondFactor(r.Context(), userTOTP, req.Code, cfg.Authorization.Salt, twoFactorVerifier)
+		if err != nil {
+			log.Error("failed to verify two-factor code", "error", err)
+
+			release(r.Context(), log, guard, userId)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+		if !ok {
+			log.Error("invalid two-factor code", slog.String("user_id", userId))
+
+			registerFailure(r, log, guard, challengeRevoker, twoFactorVerifier, challenge, ip)
+
+			w.WriteHeader(http.StatusUnauthorized)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvalidTwoFactorCode))
+
+			return
+		}
+
+		if err := guard.RegisterSecondFactorSuccess(context.WithoutCancel(r.Context()), userId, challenge.JwtID()); err != nil {
+			log.Error("failed to reset two-factor attempts", "error", err)
+		}
+
+		err = challengeRevoker.Revoke(r.Context(), challenge.JwtID(), userId, challenge.Expiration())
+		if err != nil {
+			log.Error("failed to revoke two-factor challenge", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		tokens, err := auth.GenerateTokens(r.Context(), userId, twoFactorVerifier, cfg, tokenAuth)
+		if errors.Is(err, auth.ErrUserDisabled) {
+			log.Error("user is disabled", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, resp.Error(resperrors.ErrAccountDisabled))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to generate tokens", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("success two-factor login", slog.String("user_id", userId))
+
+		audit.Record(r, log, twoFactorVerifier, audit.Event{
+			Action:     audit.ActionLoginSuccess,
+			ActorId:    audit.Actor(userId),
+			TargetType: audit.TargetUser,
+			TargetId:   userId,
+			Details:    audit.Details{"method": "two_factor"},
+		})
+
+		render.JSON(w, r, Response{resp.OK(), tokens})
+	}
+}
+
+// registerFailure counts wrong code and revokes the challenge once it had too
+// many of them, so one challenge can't be used to try codes until it expires.
+// Errors are only logged, the client gets invalid code either way
+func registerFailure(r *http.Request, log *slog.Logger, guard SecondFactorGuard, challengeRevoker ChallengeRevoker, recorder audit.Recorder, challenge jwt.Token, ip string) {
+	ctx := context.WithoutCancel(r.Context())
+	userId := challenge.Subject()
+
+	locked, exhausted, err := guard.RegisterSecondFactorFailure(ctx, userId, challenge.JwtID(), ip)
+	if err != nil {
+		log.Error("failed to register two-factor failure", "error", err)
+		return
+	}
+
+	if locked {
+		audit.Record(r, log, recorder, audit.Event{
+			Action:     audit.ActionLoginLockout,
+			TargetType: audit.TargetUser,
+			TargetId:   userId,
+			Details:    audit.Details{"method": "two_factor"},
+		})
+	}
+
+	// locked user can't finish the challenge before it expires anyway
+	if !exhausted && !locked {
+		return
+	}
+
+	if err := challengeRevoker.Revoke(ctx, challenge.JwtID(), userId, challenge.Expiration()); err != nil {
+		log.Error("failed to revoke two-factor challenge", "error", err)
+		return
+	}
+
+	reason := "too_many_failures"
+	if !exhausted {
+		reason = "user_locked"
+	}
+
+	log.Warn("security audit: two-factor challenge revoked",
+		slog.String("event", "two_factor_challenge_revoked"),
+		slog.String("user_id", userId),
+		slog.String("ip", ip),
+		slog.String("reason", reason),
+	)
+
+	audit.Record(r, log, recorder, audit.Event{
+		Action:     audit.ActionChallengeRevoke,
+		ActorId:    audit.Actor(userId),
+		TargetType: audit.TargetUser,
+		TargetId:   userId,
+		Details:    audit.Details{"challenge_id": challenge.JwtID(), "reason": reason},
+	})
+}
+
+// release gives the attempt back when it ended for reasons other than a wrong code
+func release(ctx context.Context, log *slog.Logger, guard SecondFactorGuard, userId string) {
+	if err := guard.ReleaseSecondFactor(context.WithoutCancel(ctx), userId); err != nil {
+		log.Error("failed to release two-factor attempt", "error", err)
+	}
+}
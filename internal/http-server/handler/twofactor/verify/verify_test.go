@@ -0,0 +1,195 @@
+package verify_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"main/internal/audit"
+	"main/internal/auth"
+	"main/internal/auth/lockout"
+	"main/internal/config"
+	"main/internal/http-server/handler/twofactor/verify"
+	"main/internal/storage/memory"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/jwtauth/v5"
+)
+
+const recoveryCode = "recovery-code-1"
+
+type revoker struct {
+	mu      sync.Mutex
+	revoked map[string]bool
+}
+
+func (r *revoker) IsRevoked(_ context.Context, jti, _ string, _ time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.revoked[jti], nil
+}
+
+func (r *revoker) Revoke(_ context.Context, jti, _ string, _ time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.revoked[jti] = true
+
+	return nil
+}
+
+type env struct {
+	handler   http.HandlerFunc
+	cfg       *config.Config
+	tokenAuth auth.TokenAuth
+	storage   *memory.Storage
+	userId    string
+}
+
+func newEnv(t *testing.T, protection config.LoginProtection) env {
+	t.Helper()
+
+	cfg := &config.Config{}
+	cfg.Authorization.Salt = "salt"
+	cfg.Authorization.AccessTTL = time.Minute
+	cfg.Authorization.RefreshTTL = time.Hour
+	cfg.Authorization.TwoFactorChallengeTTL = 5 * time.Minute
+	cfg.Authorization.LoginProtection = protection
+
+	ctx := context.Background()
+	s := memory.New()
+
+	userId, err := s.CreateUser(ctx, "user@example.com", "User", "hash")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if err := s.SaveTOTPSecret(ctx, userId, "JBSWY3DPEHPK3PXP"); err != nil {
+		t.Fatalf("SaveTOTPSecret: %v", err)
+	}
+	if err := s.ConfirmTOTP(ctx, userId, 0, []string{auth.HashRecoveryCode(recoveryCode, cfg.Authorization.Salt)}); err != nil {
+		t.Fatalf("ConfirmTOTP: %v", err)
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+	guard := lockout.New(lockout.NewMemoryStore(), protection, log)
+
+	return env{
+		handler:   verify.New(cfg, log, s, &revoker{revoked: make(map[string]bool)}, guard, tokenAuth),
+		cfg:       cfg,
+		tokenAuth: tokenAuth,
+		storage:   s,
+		userId:    userId,
+	}
+}
+
+func (e env) challenge(t *testing.T) string {
+	t.Helper()
+
+	challenge, err := auth.GenerateTwoFactorChallenge(e.userId, e.cfg, e.tokenAuth)
+	if err != nil {
+		t.Fatalf("GenerateTwoFactorChallenge: %v", err)
+	}
+
+	return challenge
+}
+
+func (e env) verify(t *testing.T, challenge, code, ip string) int {
+	t.Helper()
+
+	body, _ := json.Marshal(verify.Request{ChallengeToken: challenge, Code: code})
+
+	r := httptest.NewRequest(http.MethodPost, "/login/2fa", bytes.NewReader(body))
+	r.RemoteAddr = ip + ":1234"
+	w := httptest.NewRecorder()
+
+	e.handler(w, r)
+
+	return w.Code
+}
+
+// no backoff, so only the counters decide
+var protection = config.LoginProtection{
+	MaxAccountFailures:   10,
+	MaxIPFailures:        100,
+	LockoutDuration:      time.Hour,
+	FailureWindow:        time.Hour,
+	MaxChallengeFailures: 3,
+}
+
+func TestChallengeIsRevokedAfterMaxFailures(t *testing.T) {
+	e := newEnv(t, protection)
+	challenge := e.challenge(t)
+
+	for i := range protection.MaxChallengeFailures {
+		if code := e.verify(t, challenge, "wrong-code", "203.0.113.1"); code != http.StatusUnauthorized {
+			t.Fatalf("wrong code %d: got status %d, want %d", i+1, code, http.StatusUnauthorized)
+		}
+	}
+
+	// even the right code doesn't work with revoked challenge
+	if code := e.verify(t, challenge, recoveryCode, "203.0.113.1"); code != http.StatusUnauthorized {
+		t.Fatalf("right code with revoked challenge: got status %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	if code := e.verify(t, e.challenge(t), recoveryCode, "203.0.113.1"); code != http.StatusOK {
+		t.Fatalf("right code with new challenge: got status %d, want %d", code, http.StatusOK)
+	}
+
+	events, err := e.storage.GetAuditEvents(context.Background(), audit.Filter{Action: audit.ActionChallengeRevoke, Limit: 10})
+	if err != nil {
+		t.Fatalf("GetAuditEvents: %v", err)
+	}
+	if len(events) != 1 || events[0].TargetId != e.userId || events[0].IP != "203.0.113.1" {
+		t.Fatalf("challenge revoke events: got %+v, want one for user %s from 203.0.113.1", events, e.userId)
+	}
+}
+
+func TestUserIsLockedAcrossChallenges(t *testing.T) {
+	cfg := protection
+	cfg.MaxAccountFailures = 2
+
+	e := newEnv(t, cfg)
+
+	// new challenge for every code doesn't reset the user counter
+	for i := range cfg.MaxAccountFailures {
+		if code := e.verify(t, e.challenge(t), "wrong-code", "203.0.113.1"); code != http.StatusUnauthorized {
+			t.Fatalf("wrong code %d: got status %d, want %d", i+1, code, http.StatusUnauthorized)
+		}
+	}
+
+	if code := e.verify(t, e.challenge(t), recoveryCode, "198.51.100.1"); code != http.StatusTooManyRequests {
+		t.Fatalf("right code of locked user: got status %d, want %d", code, http.StatusTooManyRequests)
+	}
+
+	events, err := e.storage.GetAuditEvents(context.Background(), audit.Filter{Action: audit.ActionLoginLockout, Limit: 10})
+	if err != nil {
+		t.Fatalf("GetAuditEvents: %v", err)
+	}
+	if len(events) != 1 || events[0].TargetId != e.userId || events[0].IP != "203.0.113.1" {
+		t.Fatalf("lockout events: got %+v, want one for user %s from 203.0.113.1", events, e.userId)
+	}
+}
+
+func TestFailureDelaysNextAttempt(t *testing.T) {
+	cfg := protection
+	cfg.BaseDelay = time.Minute
+	cfg.MaxDelay = time.Hour
+
+	e := newEnv(t, cfg)
+	challenge := e.challenge(t)
+
+	if code := e.verify(t, challenge, "wrong-code", "203.0.113.1"); code != http.StatusUnauthorized {
+		t.Fatalf("wrong code: got status %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	if code := e.verify(t, challenge, recoveryCode, "198.51.100.1"); code != http.StatusTooManyRequests {
+		t.Fatalf("code during backoff: got status %d, want %d", code, http.StatusTooManyRequests)
+	}
+}
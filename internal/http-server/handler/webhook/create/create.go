@@ -0,0 +1,98 @@
+package create
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"main/internal/config"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/tracing"
+	"main/internal/webhook"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type Request struct {
+	URL    string   `json:"url" validate:"required,http_url,max=2048"`
+	Events []string `json:"events" validate:"required,min=1"`
+}
+
+type Response struct {
+	resp.Response
+	// Secret signs deliveries, it's shown only once
+	Secret  string          `json:"secret"`
+	Webhook webhook.Webhook `json:"data"`
+}
+
+type WebhookCreator interface {
+	CreateWebhook(ctx context.Context, userId, url string, events []string, secret string) (webhook.Webhook, error)
+}
+
+func New(cfg *config.Config, log *slog.Logger, webhookCreator WebhookCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.webhook.create.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := validate.DecodeAndValidateRequestJson(&req, w, r, log); err != nil {
+			return
+		}
+
+		if err := webhook.ValidateEvents(req.Events); errors.Is(err, webhook.ErrUnknownEvent) {
+			log.Error("unknown webhook event", "error", err)
+
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resperrors.ErrUnknownWebhookEvent))
+
+			return
+		}
+
+		// the dispatcher checks the address again on delivery, this only rejects obvious targets early
+		if err := webhook.ValidateURL(r.Context(), req.URL, cfg.Webhooks.AllowPrivateAddresses); err != nil {
+			log.Error("forbidden webhook url", "error", err)
+
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resperrors.ErrForbiddenWebhookURL))
+
+			return
+		}
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		secret, err := webhook.NewSecret()
+		if err != nil {
+			log.Error("failed to generate webhook secret", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		created, err := webhookCreator.CreateWebhook(r.Context(), userId, req.URL, req.Events, secret)
+		if err != nil {
+			log.Error("failed to create webhook", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("webhook created", slog.String("user_id", userId), slog.String("id", created.Id))
+
+		render.JSON(w, r, Response{Response: resp.OK(), Secret: secret, Webhook: created})
+	}
+}
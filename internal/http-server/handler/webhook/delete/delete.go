@@ -0,0 +1,72 @@
+package delete
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+)
+
+type WebhookDeleter interface {
+	DeleteWebhook(ctx context.Context, id, userId string) error
+}
+
+func New(log *slog.Logger, webhookDeleter WebhookDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.webhook.delete.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		webhookId := chi.URLParam(r, "id")
+		if _, err := uuid.Parse(webhookId); err != nil {
+			log.Error("invalid webhook id", slog.String("id", webhookId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrWebhookDoesNotExist))
+
+			return
+		}
+
+		err := webhookDeleter.DeleteWebhook(r.Context(), webhookId, userId)
+		if errors.Is(err, storage.ErrWebhookNotFound) {
+			log.Error("webhook not found", slog.String("id", webhookId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrWebhookDoesNotExist))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to delete webhook", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("webhook deleted", slog.String("user_id", userId), slog.String("id", webhookId))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -0,0 +1,68 @@
+package listdeliveries
+
+import (
+	"context"
+	"log/slog"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/tracing"
+	"main/internal/webhook"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	resp.Response
+	Deliveries []webhook.Delivery `json:"data"`
+	Limit      int                `json:"limit"`
+	Offset     int                `json:"offset"`
+}
+
+type DeliveriesGetter interface {
+	GetWebhookDeliveries(ctx context.Context, webhookId string, limit, offset int) ([]webhook.Delivery, error)
+	validate.WebhookGetter
+}
+
+// New lists delivery log of the webhook, newest first
+func New(log *slog.Logger, deliveriesGetter DeliveriesGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.webhook.listdeliveries.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		hook, err := validate.VerifyUserWebhook(deliveriesGetter, w, r, log)
+		if err != nil {
+			return
+		}
+
+		limit, offset, err := validate.GetPagination(w, r, log)
+		if err != nil {
+			return
+		}
+
+		deliveries, err := deliveriesGetter.GetWebhookDeliveries(r.Context(), hook.Id, limit, offset)
+		if err != nil {
+			log.Error("failed to get webhook deliveries", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		render.JSON(w, r, Response{
+			Response:   resp.OK(),
+			Deliveries: deliveries,
+			Limit:      limit,
+			Offset:     offset,
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package list
+
+import (
+	"context"
+	"log/slog"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/tracing"
+	"main/internal/webhook"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	resp.Response
+	Webhooks []webhook.Webhook `json:"data"`
+}
+
+type WebhooksGetter interface {
+	GetUserWebhooks(ctx context.Context, userId string) ([]webhook.Webhook, error)
+}
+
+func New(log *slog.Logger, webhooksGetter WebhooksGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.webhook.list.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		webhooks, err := webhooksGetter.GetUserWebhooks(r.Context(), userId)
+		if err != nil {
+			log.Error("failed to get webhooks", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		render.JSON(w, r, Response{Response: resp.OK(), Webhooks: webhooks})
+	}
+}
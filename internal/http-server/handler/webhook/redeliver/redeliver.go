@@ -0,0 +1,72 @@
+package redeliver
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	resp.Response
+	Id int64 `json:"id"`
+}
+
+type Redeliverer interface {
+	RedeliverWebhookDelivery(ctx context.Context, id int64, webhookId string) (int64, error)
+	validate.WebhookGetter
+}
+
+// New queues the same payload once again as a new delivery, the dispatcher sends it on the next poll
+func New(log *slog.Logger, redeliverer Redeliverer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.webhook.redeliver.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		hook, err := validate.VerifyUserWebhook(redeliverer, w, r, log)
+		if err != nil {
+			return
+		}
+
+		deliveryId, err := validate.GetIntURLParam("deliveryId", w, r, log)
+		if err != nil {
+			return
+		}
+
+		id, err := redeliverer.RedeliverWebhookDelivery(r.Context(), int64(deliveryId), hook.Id)
+		if errors.Is(err, storage.ErrWebhookDeliveryNotFound) {
+			log.Error("webhook delivery not found", slog.Int("delivery_id", deliveryId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrWebhookDeliveryDoesNotExist))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to redeliver webhook delivery", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("webhook delivery queued again", slog.Int("delivery_id", deliveryId), slog.Int64("id", id))
+
+		render.JSON(w, r, Response{Response: resp.OK(), Id: id})
+	}
+}
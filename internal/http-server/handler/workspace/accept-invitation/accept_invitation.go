@@ -0,0 +1,120 @@
+package acceptinvitation
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"main/internal/audit"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/models/user"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+)
+
+type Response struct {
+	resp.Response
+	WorkspaceId string `json:"workspace_id"`
+}
+
+type InvitationAccepter interface {
+	GetUserById(ctx context.Context, id string) (user.User, error)
+	AcceptWorkspaceInvitation(ctx context.Context, id, email, userId string) (string, error)
+	audit.Recorder
+}
+
+// New adds user to the workspace. Only verified email proves that the
+// invitation was meant for this account
+func New(log *slog.Logger, invitationAccepter InvitationAccepter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.workspace.acceptinvitation.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		invitationId := chi.URLParam(r, "id")
+		if _, err := uuid.Parse(invitationId); err != nil {
+			log.Error("invalid invitation id", slog.String("invitation_id", invitationId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvitationDoesNotExist))
+
+			return
+		}
+
+		userFromDb, err := invitationAccepter.GetUserById(r.Context(), userId)
+		if err != nil {
+			log.Error("failed to get user", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		if userFromDb.EmailVerifiedAt == nil {
+			log.Error("email is not verified", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, resp.Error(resperrors.ErrEmailNotVerified))
+
+			return
+		}
+
+		workspaceId, err := invitationAccepter.AcceptWorkspaceInvitation(r.Context(), invitationId, userFromDb.Email, userId)
+		if errors.Is(err, storage.ErrInvitationNotFound) {
+			log.Error("invitation not found", slog.String("invitation_id", invitationId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvitationDoesNotExist))
+
+			return
+		}
+		if errors.Is(err, storage.ErrWorkspaceMemberAlreadyExists) {
+			log.Error("user is already workspace member", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusConflict)
+			render.JSON(w, r, resp.Error(resperrors.ErrAlreadyWorkspaceMember))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to accept invitation", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("workspace invitation accepted", slog.String("workspace_id", workspaceId), slog.String("user_id", userId))
+
+		audit.Record(r, log, invitationAccepter, audit.Event{
+			Action:     audit.ActionWorkspaceJoin,
+			ActorId:    audit.Actor(userId),
+			TargetType: audit.TargetWorkspace,
+			TargetId:   workspaceId,
+			Details:    audit.Details{"invitation_id": invitationId},
+		})
+
+		render.JSON(w, r, Response{
+			Response:    resp.OK(),
+			WorkspaceId: workspaceId,
+		})
+	}
+}
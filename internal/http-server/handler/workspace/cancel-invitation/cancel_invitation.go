@@ -0,0 +1,85 @@
+package cancelinvitation
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/models/workspace"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+)
+
+type InvitationCanceller interface {
+	DeleteWorkspaceInvitation(ctx context.Context, id, workspaceId string) error
+	validate.WorkspaceRoleGetter
+}
+
+func New(log *slog.Logger, invitationCanceller InvitationCanceller) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.workspace.cancelinvitation.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		workspaceId := chi.URLParam(r, "id")
+		invitationId := chi.URLParam(r, "invitationId")
+
+		role, err := validate.VerifyWorkspaceMember(workspaceId, invitationCanceller, w, r, log)
+		if err != nil {
+			return
+		}
+
+		if !workspace.CanManage(role, workspace.RoleGuest) {
+			log.Error("user can't cancel invitations", slog.String("role", role))
+
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, resp.Error(resperrors.ErrInsufficientWorkspaceRole))
+
+			return
+		}
+
+		if _, err := uuid.Parse(invitationId); err != nil {
+			log.Error("invalid invitation id", slog.String("invitation_id", invitationId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvitationDoesNotExist))
+
+			return
+		}
+
+		err = invitationCanceller.DeleteWorkspaceInvitation(r.Context(), invitationId, workspaceId)
+		if errors.Is(err, storage.ErrInvitationNotFound) {
+			log.Error("invitation not found", slog.String("invitation_id", invitationId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvitationDoesNotExist))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to cancel invitation", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("workspace invitation cancelled", slog.String("invitation_id", invitationId))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -0,0 +1,67 @@
+package create
+
+import (
+	"context"
+	"log/slog"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type Request struct {
+	Name string `json:"name" validate:"required,max=255"`
+}
+
+type Response struct {
+	resp.Response
+	Id string `json:"workspace_id"`
+}
+
+type WorkspaceCreator interface {
+	CreateWorkspace(ctx context.Context, name, ownerId string) (string, error)
+}
+
+func New(log *slog.Logger, workspaceCreator WorkspaceCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.workspace.create.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := validate.DecodeAndValidateRequestJson(&req, w, r, log); err != nil {
+			return
+		}
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		id, err := workspaceCreator.CreateWorkspace(r.Context(), req.Name, userId)
+		if err != nil {
+			log.Error("failed to create workspace", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("workspace created", slog.String("workspace_id", id), slog.String("user_id", userId))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Id:       id,
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package declineinvitation
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/models/user"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+)
+
+type InvitationDecliner interface {
+	GetUserById(ctx context.Context, id string) (user.User, error)
+	DeclineWorkspaceInvitation(ctx context.Context, id, email string) error
+}
+
+func New(log *slog.Logger, invitationDecliner InvitationDecliner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.workspace.declineinvitation.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		invitationId := chi.URLParam(r, "id")
+		if _, err := uuid.Parse(invitationId); err != nil {
+			log.Error("invalid invitation id", slog.String("invitation_id", invitationId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvitationDoesNotExist))
+
+			return
+		}
+
+		userFromDb, err := invitationDecliner.GetUserById(r.Context(), userId)
+		if err != nil {
+			log.Error("failed to get user", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		if userFromDb.EmailVerifiedAt == nil {
+			log.Error("email is not verified", slog.String("user_id", userId))
+
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, resp.Error(resperrors.ErrEmailNotVerified))
+
+			return
+		}
+
+		err = invitationDecliner.DeclineWorkspaceInvitation(r.Context(), invitationId, userFromDb.Email)
+		if errors.Is(err, storage.ErrInvitationNotFound) {
+			log.Error("invitation not found", slog.String("invitation_id", invitationId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvitationDoesNotExist))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to decline invitation", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("workspace invitation declined", slog.String("invitation_id", invitationId), slog.String("user_id", userId))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -0,0 +1,102 @@
+package delete
+
+import (
+	"context"
+	"log/slog"
+	"main/internal/audit"
+	"main/internal/config"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	uploadimage "main/internal/http-server/handler/node/upload-image"
+	"main/internal/models/workspace"
+	"main/internal/tracing"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type WorkspaceDeleter interface {
+	GetWorkspaceNoteIds(ctx context.Context, workspaceId string) ([]int, error)
+	DeleteWorkspace(ctx context.Context, id string) error
+	validate.WorkspaceRoleGetter
+	audit.Recorder
+}
+
+// New deletes workspace with all its notes, only owners are allowed to do it
+func New(cfg *config.Config, log *slog.Logger, workspaceDeleter WorkspaceDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.workspace.delete.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		workspaceId := chi.URLParam(r, "id")
+
+		role, err := validate.VerifyWorkspaceMember(workspaceId, workspaceDeleter, w, r, log)
+		if err != nil {
+			return
+		}
+
+		if role != workspace.RoleOwner {
+			log.Error("user can't delete workspace", slog.String("role", role))
+
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, resp.Error(resperrors.ErrInsufficientWorkspaceRole))
+
+			return
+		}
+
+		// note ids are needed to find image directories after notes are gone
+		noteIds, err := workspaceDeleter.GetWorkspaceNoteIds(r.Context(), workspaceId)
+		if err != nil {
+			log.Error("failed to get workspace notes", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		err = workspaceDeleter.DeleteWorkspace(r.Context(), workspaceId)
+		if err != nil {
+			log.Error("failed to delete workspace", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		for _, noteId := range noteIds {
+			dir := filepath.Join(cfg.Image.ImagesDir, uploadimage.HashNoteId(noteId, cfg.Image.ImageSalt))
+
+			if err := os.RemoveAll(dir); err != nil {
+				log.Error("failed to remove note images", slog.Int("note_id", noteId), "error", err)
+			}
+		}
+
+		log.Info("workspace deleted", slog.String("workspace_id", workspaceId), slog.Int("notes", len(noteIds)))
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		audit.Record(r, log, workspaceDeleter, audit.Event{
+			Action:     audit.ActionWorkspaceDelete,
+			ActorId:    audit.Actor(userId),
+			TargetType: audit.TargetWorkspace,
+			TargetId:   workspaceId,
+		})
+
+		render.JSON(w, r, resp.OK())
+	}
+}
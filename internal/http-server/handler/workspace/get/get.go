@@ -0,0 +1,77 @@
+package get
+
+import (
+	"context"
+	"log/slog"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/models/workspace"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	resp.Response
+	Workspace workspace.Workspace `json:"data"`
+	Role      string              `json:"role"`
+	Members   []workspace.Member  `json:"members"`
+}
+
+type WorkspaceGetter interface {
+	GetWorkspace(ctx context.Context, id string) (workspace.Workspace, error)
+	GetWorkspaceMembers(ctx context.Context, workspaceId string) ([]workspace.Member, error)
+	validate.WorkspaceRoleGetter
+}
+
+func New(log *slog.Logger, workspaceGetter WorkspaceGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.workspace.get.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		workspaceId := chi.URLParam(r, "id")
+
+		role, err := validate.VerifyWorkspaceMember(workspaceId, workspaceGetter, w, r, log)
+		if err != nil {
+			return
+		}
+
+		ws, err := workspaceGetter.GetWorkspace(r.Context(), workspaceId)
+		if err != nil {
+			log.Error("failed to get workspace", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		members, err := workspaceGetter.GetWorkspaceMembers(r.Context(), workspaceId)
+		if err != nil {
+			log.Error("failed to get workspace members", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("got workspace", slog.String("workspace_id", workspaceId))
+
+		render.JSON(w, r, Response{
+			Response:  resp.OK(),
+			Workspace: ws,
+			Role:      role,
+			Members:   members,
+		})
+	}
+}
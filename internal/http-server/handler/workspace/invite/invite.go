@@ -0,0 +1,151 @@
+package invite
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"main/internal/audit"
+	"main/internal/config"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/mailer"
+	"main/internal/models/workspace"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type Request struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required,oneof=admin member guest"`
+}
+
+type Response struct {
+	resp.Response
+	Id string `json:"invitation_id"`
+}
+
+type Inviter interface {
+	GetWorkspace(ctx context.Context, id string) (workspace.Workspace, error)
+	IsWorkspaceMemberEmail(ctx context.Context, workspaceId, email string) (bool, error)
+	CreateWorkspaceInvitation(ctx context.Context, workspaceId, email, role, invitedBy string, expiresAt time.Time) (string, error)
+	validate.WorkspaceRoleGetter
+	audit.Recorder
+}
+
+// New invites email to the workspace. Invitation is accepted by the account
+// with this email, so nothing secret is sent in the email itself
+func New(cfg *config.Config, log *slog.Logger, inviter Inviter, mail mailer.Mailer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.workspace.invite.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		workspaceId := chi.URLParam(r, "id")
+
+		role, err := validate.VerifyWorkspaceMember(workspaceId, inviter, w, r, log)
+		if err != nil {
+			return
+		}
+
+		var req Request
+		if err := validate.DecodeAndValidateRequestJson(&req, w, r, log); err != nil {
+			return
+		}
+
+		if !workspace.CanManage(role, req.Role) {
+			log.Error("user can't invite with this role", slog.String("role", role), slog.String("invited_role", req.Role))
+
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, resp.Error(resperrors.ErrInsufficientWorkspaceRole))
+
+			return
+		}
+
+		isMember, err := inviter.IsWorkspaceMemberEmail(r.Context(), workspaceId, req.Email)
+		if err != nil {
+			log.Error("failed to check workspace member", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+		if isMember {
+			log.Error("invited user is already member", slog.String("workspace_id", workspaceId))
+
+			w.WriteHeader(http.StatusConflict)
+			render.JSON(w, r, resp.Error(resperrors.ErrAlreadyWorkspaceMember))
+
+			return
+		}
+
+		ws, err := inviter.GetWorkspace(r.Context(), workspaceId)
+		if err != nil {
+			log.Error("failed to get workspace", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		expiresAt := time.Now().Add(cfg.Workspaces.InvitationTTL)
+
+		id, err := inviter.CreateWorkspaceInvitation(r.Context(), workspaceId, req.Email, req.Role, userId, expiresAt)
+		if errors.Is(err, storage.ErrInvitationAlreadyExists) {
+			log.Error("invitation is already sent", slog.String("workspace_id", workspaceId))
+
+			w.WriteHeader(http.StatusConflict)
+			render.JSON(w, r, resp.Error(resperrors.ErrInvitationIsAlreadyExists))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to create invitation", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		// invitation is also listed in the app, so failed email doesn't fail the request
+		if err := mail.Send(mailer.WorkspaceInvitationMessage(req.Email, cfg.Mail.BaseURL, ws.Name)); err != nil {
+			log.Error("failed to send invitation email", "error", err)
+		}
+
+		log.Info("workspace invitation created",
+			slog.String("workspace_id", workspaceId),
+			slog.String("invitation_id", id),
+			slog.String("role", req.Role),
+		)
+
+		audit.Record(r, log, inviter, audit.Event{
+			Action:     audit.ActionWorkspaceInvite,
+			ActorId:    audit.Actor(userId),
+			TargetType: audit.TargetWorkspace,
+			TargetId:   workspaceId,
+			Details:    audit.Details{"invitation_id": id, "email": req.Email, "role": req.Role},
+		})
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Id:       id,
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package listinvitations
+
+import (
+	"context"
+	"log/slog"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/models/workspace"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	resp.Response
+	Invitations []workspace.Invitation `json:"data"`
+}
+
+type InvitationsGetter interface {
+	GetWorkspaceInvitations(ctx context.Context, workspaceId string) ([]workspace.Invitation, error)
+	validate.WorkspaceRoleGetter
+}
+
+// New lists pending invitations of the workspace to those who can invite
+func New(log *slog.Logger, invitationsGetter InvitationsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.workspace.listinvitations.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		workspaceId := chi.URLParam(r, "id")
+
+		role, err := validate.VerifyWorkspaceMember(workspaceId, invitationsGetter, w, r, log)
+		if err != nil {
+			return
+		}
+
+		if !workspace.CanManage(role, workspace.RoleGuest) {
+			log.Error("user can't see invitations", slog.String("role", role))
+
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, resp.Error(resperrors.ErrInsufficientWorkspaceRole))
+
+			return
+		}
+
+		invitations, err := invitationsGetter.GetWorkspaceInvitations(r.Context(), workspaceId)
+		if err != nil {
+			log.Error("failed to get invitations", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("got workspace invitations", slog.Int("count", len(invitations)))
+
+		render.JSON(w, r, Response{resp.OK(), invitations})
+	}
+}
@@ -0,0 +1,56 @@
+package list
+
+import (
+	"context"
+	"log/slog"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/models/workspace"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	resp.Response
+	Workspaces []workspace.Membership `json:"data"`
+}
+
+type WorkspacesGetter interface {
+	GetUserWorkspaces(ctx context.Context, userId string) ([]workspace.Membership, error)
+}
+
+func New(log *slog.Logger, workspacesGetter WorkspacesGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.workspace.list.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		workspaces, err := workspacesGetter.GetUserWorkspaces(r.Context(), userId)
+		if err != nil {
+			log.Error("failed to get workspaces", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("got workspaces", slog.Int("count", len(workspaces)))
+
+		render.JSON(w, r, Response{resp.OK(), workspaces})
+	}
+}
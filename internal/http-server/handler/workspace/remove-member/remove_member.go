@@ -0,0 +1,133 @@
+package removemember
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"main/internal/audit"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/models/workspace"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+)
+
+type MemberRemover interface {
+	RemoveWorkspaceMember(ctx context.Context, workspaceId, userId string) error
+	validate.WorkspaceRoleGetter
+	audit.Recorder
+}
+
+// New removes member from the workspace, members may also remove themselves to leave it.
+// Access to workspace notes is checked on every request, so it's lost right away
+func New(log *slog.Logger, memberRemover MemberRemover) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.workspace.removemember.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		workspaceId := chi.URLParam(r, "id")
+		memberId := chi.URLParam(r, "userId")
+
+		role, err := validate.VerifyWorkspaceMember(workspaceId, memberRemover, w, r, log)
+		if err != nil {
+			return
+		}
+
+		if _, err := uuid.Parse(memberId); err != nil {
+			log.Error("invalid member id", slog.String("member_id", memberId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrWorkspaceMemberDoesNotExist))
+
+			return
+		}
+
+		if memberId != userId {
+			memberRole, err := memberRemover.GetWorkspaceRole(r.Context(), workspaceId, memberId)
+			if errors.Is(err, storage.ErrWorkspaceMemberNotFound) {
+				log.Error("workspace member not found", slog.String("member_id", memberId))
+
+				w.WriteHeader(http.StatusNotFound)
+				render.JSON(w, r, resp.Error(resperrors.ErrWorkspaceMemberDoesNotExist))
+
+				return
+			}
+			if err != nil {
+				log.Error("failed to get member role", "error", err)
+
+				validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+				return
+			}
+
+			if !workspace.CanManage(role, memberRole) {
+				log.Error("user can't remove member", slog.String("role", role), slog.String("member_role", memberRole))
+
+				w.WriteHeader(http.StatusForbidden)
+				render.JSON(w, r, resp.Error(resperrors.ErrInsufficientWorkspaceRole))
+
+				return
+			}
+		}
+
+		err = memberRemover.RemoveWorkspaceMember(r.Context(), workspaceId, memberId)
+		if errors.Is(err, storage.ErrWorkspaceMemberNotFound) {
+			log.Error("workspace member not found", slog.String("member_id", memberId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrWorkspaceMemberDoesNotExist))
+
+			return
+		}
+		if errors.Is(err, storage.ErrLastWorkspaceOwner) {
+			log.Error("can't remove last workspace owner", slog.String("member_id", memberId))
+
+			w.WriteHeader(http.StatusConflict)
+			render.JSON(w, r, resp.Error(resperrors.ErrLastWorkspaceOwner))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to remove member", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("workspace member removed",
+			slog.String("workspace_id", workspaceId),
+			slog.String("member_id", memberId),
+			slog.String("removed_by", userId),
+		)
+
+		audit.Record(r, log, memberRemover, audit.Event{
+			Action:     audit.ActionWorkspaceRemove,
+			ActorId:    audit.Actor(userId),
+			TargetType: audit.TargetWorkspace,
+			TargetId:   workspaceId,
+			Details:    audit.Details{"member_id": memberId},
+		})
+
+		render.JSON(w, r, resp.OK())
+	}
+}
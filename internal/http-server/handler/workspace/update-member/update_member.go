@@ -0,0 +1,140 @@
+package updatemember
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"main/internal/audit"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/models/workspace"
+	"main/internal/storage"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+)
+
+type Request struct {
+	Role string `json:"role" validate:"required,oneof=owner admin member guest"`
+}
+
+type MemberUpdater interface {
+	SetWorkspaceMemberRole(ctx context.Context, workspaceId, userId, role string) error
+	validate.WorkspaceRoleGetter
+	audit.Recorder
+}
+
+// New changes role of a workspace member. Both current and new role of
+// the member have to be manageable by the user
+func New(log *slog.Logger, memberUpdater MemberUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.workspace.updatemember.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		workspaceId := chi.URLParam(r, "id")
+		memberId := chi.URLParam(r, "userId")
+
+		role, err := validate.VerifyWorkspaceMember(workspaceId, memberUpdater, w, r, log)
+		if err != nil {
+			return
+		}
+
+		if _, err := uuid.Parse(memberId); err != nil {
+			log.Error("invalid member id", slog.String("member_id", memberId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrWorkspaceMemberDoesNotExist))
+
+			return
+		}
+
+		var req Request
+		if err := validate.DecodeAndValidateRequestJson(&req, w, r, log); err != nil {
+			return
+		}
+
+		memberRole, err := memberUpdater.GetWorkspaceRole(r.Context(), workspaceId, memberId)
+		if errors.Is(err, storage.ErrWorkspaceMemberNotFound) {
+			log.Error("workspace member not found", slog.String("member_id", memberId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrWorkspaceMemberDoesNotExist))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get member role", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		if !workspace.CanManage(role, memberRole) || !workspace.CanManage(role, req.Role) {
+			log.Error("user can't change member role", slog.String("role", role), slog.String("member_role", memberRole))
+
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, resp.Error(resperrors.ErrInsufficientWorkspaceRole))
+
+			return
+		}
+
+		err = memberUpdater.SetWorkspaceMemberRole(r.Context(), workspaceId, memberId, req.Role)
+		if errors.Is(err, storage.ErrWorkspaceMemberNotFound) {
+			log.Error("workspace member not found", slog.String("member_id", memberId))
+
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resperrors.ErrWorkspaceMemberDoesNotExist))
+
+			return
+		}
+		if errors.Is(err, storage.ErrLastWorkspaceOwner) {
+			log.Error("can't demote last workspace owner", slog.String("member_id", memberId))
+
+			w.WriteHeader(http.StatusConflict)
+			render.JSON(w, r, resp.Error(resperrors.ErrLastWorkspaceOwner))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to change member role", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("workspace member role changed",
+			slog.String("workspace_id", workspaceId),
+			slog.String("member_id", memberId),
+			slog.String("role", req.Role),
+		)
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		audit.Record(r, log, memberUpdater, audit.Event{
+			Action:     audit.ActionWorkspaceRoleChange,
+			ActorId:    audit.Actor(userId),
+			TargetType: audit.TargetWorkspace,
+			TargetId:   workspaceId,
+			Details:    audit.Details{"member_id": memberId, "role": req.Role},
+		})
+
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -0,0 +1,73 @@
+package update
+
+import (
+	"context"
+	"log/slog"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/models/workspace"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Request struct {
+	Name string `json:"name" validate:"required,max=255"`
+}
+
+type WorkspaceUpdater interface {
+	UpdateWorkspaceName(ctx context.Context, id, name string) error
+	validate.WorkspaceRoleGetter
+}
+
+func New(log *slog.Logger, workspaceUpdater WorkspaceUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.workspace.update.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		workspaceId := chi.URLParam(r, "id")
+
+		role, err := validate.VerifyWorkspaceMember(workspaceId, workspaceUpdater, w, r, log)
+		if err != nil {
+			return
+		}
+
+		if role != workspace.RoleOwner && role != workspace.RoleAdmin {
+			log.Error("user can't rename workspace", slog.String("role", role))
+
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, resp.Error(resperrors.ErrInsufficientWorkspaceRole))
+
+			return
+		}
+
+		var req Request
+		if err := validate.DecodeAndValidateRequestJson(&req, w, r, log); err != nil {
+			return
+		}
+
+		err = workspaceUpdater.UpdateWorkspaceName(r.Context(), workspaceId, req.Name)
+		if err != nil {
+			log.Error("failed to update workspace", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("workspace renamed", slog.String("workspace_id", workspaceId))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -0,0 +1,68 @@
+package userinvitations
+
+import (
+	"context"
+	"log/slog"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/models/user"
+	"main/internal/models/workspace"
+	"main/internal/tracing"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	resp.Response
+	Invitations []workspace.Invitation `json:"data"`
+}
+
+type InvitationsGetter interface {
+	GetUserById(ctx context.Context, id string) (user.User, error)
+	GetUserInvitations(ctx context.Context, email string) ([]workspace.Invitation, error)
+}
+
+// New lists pending invitations sent to email of the user
+func New(log *slog.Logger, invitationsGetter InvitationsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.workspace.userinvitations.New"
+
+		r, span := tracing.StartRequest(r, op)
+		defer span.End()
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request-id", middleware.GetReqID(r.Context())),
+		)
+
+		_, claims, _ := jwtauth.FromContext(r.Context())
+
+		userId, _ := claims["user_id"].(string)
+
+		userFromDb, err := invitationsGetter.GetUserById(r.Context(), userId)
+		if err != nil {
+			log.Error("failed to get user", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		invitations, err := invitationsGetter.GetUserInvitations(r.Context(), userFromDb.Email)
+		if err != nil {
+			log.Error("failed to get invitations", "error", err)
+
+			validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+			return
+		}
+
+		log.Info("got user invitations", slog.Int("count", len(invitations)))
+
+		render.JSON(w, r, Response{resp.OK(), invitations})
+	}
+}
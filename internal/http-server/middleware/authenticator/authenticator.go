@@ -1,18 +1,37 @@
 package authenticator
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"main/internal/auth"
+	"main/internal/auth/pat"
 	resp "main/internal/http-server/api/response"
 	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/http-server/api/validate"
+	"main/internal/storage"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/jwtauth/v5"
 	"github.com/go-chi/render"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 )
 
-func Authenticator(ja *jwtauth.JWTAuth, log *slog.Logger) func(http.Handler) http.Handler {
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, jti, userId string, issuedAt time.Time) (bool, error)
+	IsDisabled(ctx context.Context, userId string) (bool, error)
+}
+
+type PersonalAccessTokenVerifier interface {
+	Authenticate(ctx context.Context, token string) (pat.PersonalAccessToken, error)
+}
+
+// Authenticator accepts access tokens and, when patVerifier is not nil, personal access tokens.
+// Requests with personal access tokens get claims with user id and token scopes.
+// Requests of disabled users are rejected with either kind of token
+func Authenticator(ja auth.TokenAuth, revocationChecker RevocationChecker, patVerifier PersonalAccessTokenVerifier, log *slog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		log = log.With(
 			slog.String("component", "middleware/authenticator"),
@@ -21,7 +40,12 @@ func Authenticator(ja *jwtauth.JWTAuth, log *slog.Logger) func(http.Handler) htt
 		log.Info("authenticator middleware enabled")
 
 		fn := func(w http.ResponseWriter, r *http.Request) {
-			token, _, err := jwtauth.FromContext(r.Context())
+			if rawToken := jwtauth.TokenFromHeader(r); pat.IsPersonalAccessToken(rawToken) {
+				authenticatePersonalAccessToken(w, r, next, rawToken, patVerifier, revocationChecker, log)
+				return
+			}
+
+			token, claims, err := jwtauth.FromContext(r.Context())
 
 			if err != nil {
 				log.Error("auth context error", "error", fmt.Sprintf("%+v", err))
@@ -46,9 +70,106 @@ func Authenticator(ja *jwtauth.JWTAuth, log *slog.Logger) func(http.Handler) htt
 				return
 			}
 
-			next.ServeHTTP(w, r)
+			userId, _ := claims["user_id"].(string)
+
+			if !checkUserEnabled(w, r, userId, revocationChecker, log) {
+				return
+			}
+
+			revoked, err := revocationChecker.IsRevoked(r.Context(), token.JwtID(), userId, token.IssuedAt())
+			if err != nil {
+				log.Error("failed to check token revocation", "error", err)
+
+				validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+				return
+			}
+
+			if revoked {
+				log.Error("access token revoked", slog.String("user_id", userId), slog.String("jti", token.JwtID()))
+
+				w.WriteHeader(http.StatusUnauthorized)
+				render.JSON(w, r, resp.Error(resperrors.ErrAccessTokenRevoked))
+
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(storage.WithUser(r.Context(), userId)))
 		}
 
 		return http.HandlerFunc(fn)
 	}
 }
+
+func authenticatePersonalAccessToken(w http.ResponseWriter, r *http.Request, next http.Handler, rawToken string, patVerifier PersonalAccessTokenVerifier, revocationChecker RevocationChecker, log *slog.Logger) {
+	if patVerifier == nil {
+		log.Error("personal access token is not allowed here")
+
+		w.WriteHeader(http.StatusUnauthorized)
+		render.JSON(w, r, resp.Error(resperrors.ErrInvalidAccessToken))
+
+		return
+	}
+
+	personalToken, err := patVerifier.Authenticate(r.Context(), rawToken)
+	if errors.Is(err, pat.ErrInvalidToken) || errors.Is(err, storage.ErrPersonalAccessTokenNotFound) {
+		log.Error("invalid personal access token")
+
+		w.WriteHeader(http.StatusUnauthorized)
+		render.JSON(w, r, resp.Error(resperrors.ErrInvalidAccessToken))
+
+		return
+	}
+	if err != nil {
+		log.Error("failed to authenticate personal access token", "error", err)
+
+		validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+		return
+	}
+
+	if !checkUserEnabled(w, r, personalToken.UserId, revocationChecker, log) {
+		return
+	}
+
+	// handlers read user from jwtauth context, so token is presented to them as claims
+	token, err := jwt.NewBuilder().
+		Claim("user_id", personalToken.UserId).
+		Claim(pat.TokenIdClaim, personalToken.Id).
+		Claim(pat.ScopesClaim, []string(personalToken.Scopes)).
+		Build()
+	if err != nil {
+		log.Error("failed to build personal access token claims", "error", err)
+
+		validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+		return
+	}
+
+	ctx := jwtauth.NewContext(r.Context(), token, nil)
+	ctx = storage.WithUser(ctx, personalToken.UserId)
+
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+func checkUserEnabled(w http.ResponseWriter, r *http.Request, userId string, revocationChecker RevocationChecker, log *slog.Logger) bool {
+	disabled, err := revocationChecker.IsDisabled(r.Context(), userId)
+	if err != nil {
+		log.Error("failed to check if user is disabled", "error", err)
+
+		validate.InternalError(w, r, err, resperrors.ErrInternalServerError)
+
+		return false
+	}
+
+	if disabled {
+		log.Error("user is disabled", slog.String("user_id", userId))
+
+		w.WriteHeader(http.StatusForbidden)
+		render.JSON(w, r, resp.Error(resperrors.ErrAccountDisabled))
+
+		return false
+	}
+
+	return true
+}
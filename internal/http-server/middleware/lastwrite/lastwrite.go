@@ -0,0 +1,89 @@
+package lastwrite
+
+import (
+	"main/internal/storage"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// CookieName is the cookie which carries time of the client's last write, in unix milliseconds
+const CookieName = "last_write"
+
+// New passes time of the client's last write from the cookie to storage and
+// sets the cookie when the request writes, so reads following the write go to
+// the primary database whichever instance serves them. Cookie lives for window,
+// replicas are expected to catch up by then
+func New(window time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			var receivedAt time.Time
+			if cookie, err := r.Cookie(CookieName); err == nil {
+				if ms, err := strconv.ParseInt(cookie.Value, 10, 64); err == nil {
+					receivedAt = time.UnixMilli(ms)
+				}
+			}
+
+			lastWrite := storage.NewLastWrite(receivedAt)
+			ww := &writer{
+				ResponseWriter: w,
+				lastWrite:      lastWrite,
+				receivedAt:     receivedAt,
+				window:         window,
+			}
+
+			next.ServeHTTP(ww, r.WithContext(storage.WithLastWrite(r.Context(), lastWrite)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// writer sets the cookie right before the header is written, when
+// the handler is done with storage
+type writer struct {
+	http.ResponseWriter
+	lastWrite   *storage.LastWrite
+	receivedAt  time.Time
+	window      time.Duration
+	wroteHeader bool
+}
+
+func (w *writer) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.setCookie()
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *writer) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *writer) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// setCookie sets the cookie if the request wrote
+func (w *writer) setCookie() {
+	at := w.lastWrite.Time()
+	if at.Equal(w.receivedAt) {
+		return
+	}
+
+	http.SetCookie(w.ResponseWriter, &http.Cookie{
+		Name:     CookieName,
+		Value:    strconv.FormatInt(at.UnixMilli(), 10),
+		Path:     "/",
+		MaxAge:   int(math.Ceil(w.window.Seconds())),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
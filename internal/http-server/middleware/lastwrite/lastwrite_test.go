@@ -0,0 +1,79 @@
+package lastwrite
+
+import (
+	"main/internal/storage"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func serve(t *testing.T, cookie *http.Cookie, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodPost, "/notes", nil)
+	if cookie != nil {
+		r.AddCookie(cookie)
+	}
+
+	w := httptest.NewRecorder()
+	New(5*time.Second)(handler).ServeHTTP(w, r)
+
+	return w
+}
+
+func TestWriteSetsCookie(t *testing.T) {
+	wroteAt := time.UnixMilli(time.Now().UnixMilli())
+
+	w := serve(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		lastWrite, ok := storage.LastWriteFromContext(r.Context())
+		if !ok {
+			t.Fatal("request context doesn't carry last write")
+		}
+		lastWrite.Set(wroteAt)
+
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != CookieName {
+		t.Fatalf("cookies: got %v", cookies)
+	}
+	if cookies[0].Value != strconv.FormatInt(wroteAt.UnixMilli(), 10) {
+		t.Fatalf("cookie value: got %q", cookies[0].Value)
+	}
+	if cookies[0].MaxAge != 5 || !cookies[0].HttpOnly {
+		t.Fatalf("cookie attributes: max age %d, http only %v", cookies[0].MaxAge, cookies[0].HttpOnly)
+	}
+}
+
+func TestCookieIsPassedToStorage(t *testing.T) {
+	wroteAt := time.UnixMilli(time.Now().UnixMilli())
+	cookie := &http.Cookie{Name: CookieName, Value: strconv.FormatInt(wroteAt.UnixMilli(), 10)}
+
+	w := serve(t, cookie, func(w http.ResponseWriter, r *http.Request) {
+		lastWrite, _ := storage.LastWriteFromContext(r.Context())
+		if !lastWrite.Time().Equal(wroteAt) {
+			t.Fatalf("last write: got %v, want %v", lastWrite.Time(), wroteAt)
+		}
+
+		_, _ = w.Write([]byte("{}"))
+	})
+
+	// reads don't renew the cookie, it expires a window after the write
+	if cookies := w.Result().Cookies(); len(cookies) != 0 {
+		t.Fatalf("read set cookies: %v", cookies)
+	}
+}
+
+func TestMalformedCookie(t *testing.T) {
+	cookie := &http.Cookie{Name: CookieName, Value: "yesterday"}
+
+	serve(t, cookie, func(w http.ResponseWriter, r *http.Request) {
+		lastWrite, _ := storage.LastWriteFromContext(r.Context())
+		if !lastWrite.Time().IsZero() {
+			t.Fatalf("last write: got %v, want zero", lastWrite.Time())
+		}
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func New(log *slog.Logger) func(next http.Handler) http.Handler {
@@ -24,6 +25,12 @@ func New(log *slog.Logger) func(next http.Handler) http.Handler {
 				slog.String("user_agent", r.UserAgent()),
 				slog.String("request_id", middleware.GetReqID(r.Context())),
 			)
+			if spanCtx := trace.SpanContextFromContext(r.Context()); spanCtx.IsValid() {
+				entry = entry.With(
+					slog.String("trace_id", spanCtx.TraceID().String()),
+					slog.String("span_id", spanCtx.SpanID().String()),
+				)
+			}
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
 
 			t1 := time.Now()
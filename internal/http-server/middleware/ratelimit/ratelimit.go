@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"fmt"
+	"log/slog"
+	"main/internal/http-server/api/request"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/ratelimit"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+// New limits requests of the route group by user_id from claims, so it has to run after
+// authenticator on protected routes, and by client ip when request is anonymous
+func New(limiter ratelimit.Limiter, group string, limit ratelimit.Limit, log *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/ratelimit"),
+			slog.String("group", group),
+		)
+
+		if !limit.Enabled() {
+			log.Info("rate limit disabled")
+			return next
+		}
+
+		log.Info("rate limit middleware enabled", slog.Int("requests", limit.Requests), slog.Duration("period", limit.Period))
+
+		policy := fmt.Sprintf("%d;w=%d", limit.Requests, int(limit.Period.Seconds()))
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			key := "ip:" + request.ClientIP(r)
+
+			if _, claims, err := jwtauth.FromContext(r.Context()); err == nil {
+				if userId, ok := claims["user_id"].(string); ok && userId != "" {
+					key = "user:" + userId
+				}
+			}
+
+			result, err := limiter.Allow(r.Context(), group+":"+key, limit)
+			if err != nil {
+				// limiter outage shouldn't take the api down
+				log.Error("failed to check rate limit", "error", err)
+
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Policy", policy)
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(seconds(result.ResetAfter)))
+
+			if !result.Allowed {
+				log.Warn("rate limit exceeded", slog.String("key", key))
+
+				w.Header().Set("Retry-After", strconv.Itoa(seconds(result.RetryAfter)))
+				w.WriteHeader(http.StatusTooManyRequests)
+				render.JSON(w, r, resp.Error(resperrors.ErrRateLimitExceeded))
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
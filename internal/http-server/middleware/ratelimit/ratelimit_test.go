@@ -0,0 +1,141 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	resperrors "main/internal/http-server/api/response-errors"
+	"main/internal/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newHandler(t *testing.T, limiter ratelimit.Limiter, limit ratelimit.Limit) http.Handler {
+	t.Helper()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return New(limiter, "notes", limit, log)(next)
+}
+
+func serve(h http.Handler, remoteAddr string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/notes", nil)
+	r.RemoteAddr = remoteAddr
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w
+}
+
+func redisLimiter(t *testing.T) (*ratelimit.RedisLimiter, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	limiter := ratelimit.NewRedisLimiter(redis.NewClient(&redis.Options{Addr: server.Addr()}), "test:")
+	t.Cleanup(func() { _ = limiter.Close() })
+
+	return limiter, server
+}
+
+func TestTooManyRequests(t *testing.T) {
+	limit := ratelimit.Limit{Requests: 2, Period: time.Minute}
+
+	limiters := map[string]func(t *testing.T) ratelimit.Limiter{
+		"memory": func(t *testing.T) ratelimit.Limiter { return ratelimit.NewMemoryLimiter() },
+		"redis": func(t *testing.T) ratelimit.Limiter {
+			limiter, _ := redisLimiter(t)
+			return limiter
+		},
+	}
+
+	for name, limiter := range limiters {
+		t.Run(name, func(t *testing.T) {
+			h := newHandler(t, limiter(t), limit)
+
+			for want := 1; want >= 0; want-- {
+				w := serve(h, "203.0.113.5:1234")
+				if w.Code != http.StatusNoContent {
+					t.Fatalf("status: got %d, want %d", w.Code, http.StatusNoContent)
+				}
+				if got := w.Header().Get("RateLimit-Remaining"); got != strconv.Itoa(want) {
+					t.Fatalf("RateLimit-Remaining: got %q, want %d", got, want)
+				}
+			}
+
+			w := serve(h, "203.0.113.5:1234")
+			if w.Code != http.StatusTooManyRequests {
+				t.Fatalf("status: got %d, want %d", w.Code, http.StatusTooManyRequests)
+			}
+
+			headers := map[string]string{
+				"RateLimit-Policy":    "2;w=60",
+				"RateLimit-Limit":     "2",
+				"RateLimit-Remaining": "0",
+				"RateLimit-Reset":     "60",
+				"Retry-After":         "30",
+			}
+			for header, want := range headers {
+				if got := w.Header().Get(header); got != want {
+					t.Errorf("%s: got %q, want %q", header, got, want)
+				}
+			}
+
+			var body struct {
+				Error string `json:"error"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if body.Error != resperrors.ErrRateLimitExceeded.Error() {
+				t.Fatalf("error: got %q", body.Error)
+			}
+
+			// other clients have their own buckets
+			if w := serve(h, "198.51.100.1:1234"); w.Code != http.StatusNoContent {
+				t.Fatalf("status of another client: got %d, want %d", w.Code, http.StatusNoContent)
+			}
+		})
+	}
+}
+
+func TestLimiterOutage(t *testing.T) {
+	limiter, server := redisLimiter(t)
+	server.Close()
+
+	h := newHandler(t, limiter, ratelimit.Limit{Requests: 1, Period: time.Minute})
+
+	for range 3 {
+		w := serve(h, "203.0.113.5:1234")
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("status: got %d, want %d", w.Code, http.StatusNoContent)
+		}
+		if w.Header().Get("RateLimit-Limit") != "" {
+			t.Fatal("rate limit headers are set without limiter result")
+		}
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	limiter, server := redisLimiter(t)
+	h := newHandler(t, limiter, ratelimit.Limit{})
+
+	for range 3 {
+		if w := serve(h, "203.0.113.5:1234"); w.Code != http.StatusNoContent {
+			t.Fatalf("status: got %d, want %d", w.Code, http.StatusNoContent)
+		}
+	}
+
+	if keys := server.Keys(); len(keys) != 0 {
+		t.Fatalf("disabled limit touched redis: %v", keys)
+	}
+}
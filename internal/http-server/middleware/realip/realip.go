@@ -0,0 +1,103 @@
+package realip
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ParseTrusted parses trusted proxies given as CIDRs or single addresses
+func ParseTrusted(proxies []string) ([]netip.Prefix, error) {
+	const op = "middleware.realip.ParseTrusted"
+
+	trusted := make([]netip.Prefix, 0, len(proxies))
+
+	for _, proxy := range proxies {
+		if addr, err := netip.ParseAddr(proxy); err == nil {
+			trusted = append(trusted, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		trusted = append(trusted, prefix.Masked())
+	}
+
+	return trusted, nil
+}
+
+// New replaces RemoteAddr with the client address from X-Forwarded-For when
+// the request came from a trusted proxy. The header is read from the right and
+// the first address not belonging to a trusted proxy is taken, so a client
+// can't pick its ip by sending the header itself. Requests from other peers
+// are left as they are
+func New(trusted []netip.Prefix) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if len(trusted) > 0 && isTrusted(trusted, peerAddr(r.RemoteAddr)) {
+				if ip := forwardedFor(trusted, r.Header.Values("X-Forwarded-For")); ip != "" {
+					r.RemoteAddr = ip
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// forwardedFor returns the rightmost untrusted address of the header, empty if there is none
+func forwardedFor(trusted []netip.Prefix, values []string) string {
+	var hops []string
+	for _, value := range values {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			return ""
+		}
+
+		addr = addr.Unmap()
+		if !isTrusted(trusted, addr) {
+			return addr.String()
+		}
+	}
+
+	return ""
+}
+
+func peerAddr(remoteAddr string) netip.Addr {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}
+	}
+
+	return addr.Unmap()
+}
+
+func isTrusted(trusted []netip.Prefix, addr netip.Addr) bool {
+	if !addr.IsValid() {
+		return false
+	}
+
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
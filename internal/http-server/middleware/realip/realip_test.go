@@ -0,0 +1,58 @@
+package realip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	trusted, err := ParseTrusted([]string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatalf("ParseTrusted: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		remoteAddr     string
+		forwardedFor   []string
+		wantRemoteAddr string
+	}{
+		{"no proxy", "203.0.113.5:1234", nil, "203.0.113.5:1234"},
+		{"untrusted peer can't spoof", "203.0.113.5:1234", []string{"198.51.100.1"}, "203.0.113.5:1234"},
+		{"trusted proxy", "10.1.2.3:1234", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"chain of trusted proxies", "10.1.2.3:1234", []string{"198.51.100.1, 192.168.1.1"}, "198.51.100.1"},
+		{"client prepended address is ignored", "10.1.2.3:1234", []string{"1.1.1.1, 198.51.100.1"}, "198.51.100.1"},
+		{"several headers", "10.1.2.3:1234", []string{"1.1.1.1", "198.51.100.1"}, "198.51.100.1"},
+		{"garbage keeps peer", "10.1.2.3:1234", []string{"not-an-ip"}, "10.1.2.3:1234"},
+		{"only proxies keeps peer", "10.1.2.3:1234", []string{"10.0.0.1"}, "10.1.2.3:1234"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+
+			handler := New(trusted)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwardedFor {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			if got != tt.wantRemoteAddr {
+				t.Fatalf("RemoteAddr: got %q, want %q", got, tt.wantRemoteAddr)
+			}
+		})
+	}
+}
+
+func TestParseTrustedRejectsInvalid(t *testing.T) {
+	if _, err := ParseTrusted([]string{"10.0.0.0/33"}); err == nil {
+		t.Fatal("ParseTrusted: want error for invalid cidr")
+	}
+}
@@ -0,0 +1,45 @@
+package role
+
+import (
+	"log/slog"
+	"main/internal/auth"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+// Require lets through only requests whose access token carries one of roles.
+// Personal access tokens have no role, so they are always rejected
+func Require(log *slog.Logger, roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/role"),
+		)
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			_, claims, _ := jwtauth.FromContext(r.Context())
+
+			userRole, _ := claims[auth.RoleClaim].(string)
+
+			if !slices.Contains(roles, userRole) {
+				log.Error("user has insufficient role",
+					slog.Any("user_id", claims["user_id"]),
+					slog.String("role", userRole),
+				)
+
+				w.WriteHeader(http.StatusForbidden)
+				render.JSON(w, r, resp.Error(resperrors.ErrInsufficientRole))
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
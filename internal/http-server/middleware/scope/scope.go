@@ -0,0 +1,71 @@
+package scope
+
+import (
+	"log/slog"
+	"main/internal/auth/pat"
+	resp "main/internal/http-server/api/response"
+	resperrors "main/internal/http-server/api/response-errors"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+// Require rejects requests made with personal access tokens missing any of scopes,
+// regular access tokens are not limited by scopes
+func Require(log *slog.Logger, scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/scope"),
+		)
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			_, claims, _ := jwtauth.FromContext(r.Context())
+
+			rawScopes, ok := claims[pat.ScopesClaim]
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			granted := toStrings(rawScopes)
+
+			for _, scope := range scopes {
+				if !slices.Contains(granted, scope) {
+					log.Error("personal access token has insufficient scope",
+						slog.Any("pat_id", claims[pat.TokenIdClaim]),
+						slog.String("required", scope),
+					)
+
+					w.WriteHeader(http.StatusForbidden)
+					render.JSON(w, r, resp.Error(resperrors.ErrInsufficientScope))
+
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func toStrings(value any) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []any:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+
+		return result
+	default:
+		return nil
+	}
+}
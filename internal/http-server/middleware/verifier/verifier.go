@@ -0,0 +1,46 @@
+package verifier
+
+import (
+	"main/internal/auth"
+	"net/http"
+
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// New works like jwtauth.Verifier but decodes tokens with any auth.TokenAuth,
+// so keys can be rotated; results are stored in the jwtauth context
+func New(tokenAuth auth.TokenAuth) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			token, err := verifyRequest(tokenAuth, r)
+
+			ctx := jwtauth.NewContext(r.Context(), token, err)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func verifyRequest(tokenAuth auth.TokenAuth, r *http.Request) (jwt.Token, error) {
+	tokenString := jwtauth.TokenFromHeader(r)
+	if tokenString == "" {
+		tokenString = jwtauth.TokenFromCookie(r)
+	}
+	if tokenString == "" {
+		return nil, jwtauth.ErrNoTokenFound
+	}
+
+	token, err := tokenAuth.Decode(tokenString)
+	if err != nil {
+		return token, jwtauth.ErrorReason(err)
+	}
+
+	if err := jwt.Validate(token); err != nil {
+		return token, jwtauth.ErrorReason(err)
+	}
+
+	return token, nil
+}
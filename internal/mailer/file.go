@@ -0,0 +1,53 @@
+package mailer
+
+import (
+	"fmt"
+	"log/slog"
+	"main/internal/config"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// File writes every message into its own .eml file inside dir, or only logs
+// it when dir is empty; useful for development and tests
+type File struct {
+	dir  string
+	from string
+	log  *slog.Logger
+}
+
+func NewFile(cfg config.Mail, log *slog.Logger) *File {
+	return &File{
+		dir:  cfg.FileDir,
+		from: cfg.From,
+		log:  log.With(slog.String("component", "mailer/file")),
+	}
+}
+
+func (f *File) Send(msg Message) error {
+	const op = "mailer.File.Send"
+
+	if f.dir == "" {
+		f.log.Info("mail sent", slog.String("to", msg.To), slog.String("subject", msg.Subject), slog.String("body", msg.Body))
+
+		return nil
+	}
+
+	if err := os.MkdirAll(f.dir, os.ModePerm); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	fileName := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405"), uuid.New().String())
+	path := filepath.Join(f.dir, fileName)
+
+	if err := os.WriteFile(path, msg.bytes(f.from), 0o644); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	f.log.Info("mail written", slog.String("to", msg.To), slog.String("subject", msg.Subject), slog.String("path", path))
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"main/internal/config"
+	"strings"
+	"time"
+)
+
+const (
+	DriverSMTP = "smtp"
+	DriverFile = "file"
+)
+
+var ErrUnknownDriver = errors.New("unknown mail driver")
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// New creates mailer by driver field from config
+func New(cfg *config.Config, log *slog.Logger) (Mailer, error) {
+	const op = "mailer.New"
+
+	switch cfg.Mail.Driver {
+	case DriverSMTP:
+		return NewSMTP(cfg.Mail), nil
+	case DriverFile, "":
+		return NewFile(cfg.Mail, log), nil
+	default:
+		return nil, fmt.Errorf("%s: %w: %q", op, ErrUnknownDriver, cfg.Mail.Driver)
+	}
+}
+
+func (m Message) bytes(from string) []byte {
+	var b strings.Builder
+
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + m.To + "\r\n")
+	b.WriteString("Subject: " + m.Subject + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(m.Body)
+
+	return []byte(b.String())
+}
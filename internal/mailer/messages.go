@@ -0,0 +1,52 @@
+package mailer
+
+import (
+	"fmt"
+	"net/url"
+)
+
+func PasswordResetMessage(to, baseURL, token string) Message {
+	link := fmt.Sprintf("%s/reset-password?token=%s", baseURL, url.QueryEscape(token))
+
+	return Message{
+		To:      to,
+		Subject: "Reset your password",
+		Body: "Someone requested a password reset for your account.\r\n\r\n" +
+			"Follow the link to choose a new password:\r\n" + link + "\r\n\r\n" +
+			"If it wasn't you, just ignore this email.\r\n",
+	}
+}
+
+func EmailVerificationMessage(to, baseURL, token string) Message {
+	link := fmt.Sprintf("%s/verify-email?token=%s", baseURL, url.QueryEscape(token))
+
+	return Message{
+		To:      to,
+		Subject: "Confirm your email",
+		Body: "Thanks for signing up!\r\n\r\n" +
+			"Follow the link to confirm your email address:\r\n" + link + "\r\n",
+	}
+}
+
+func EmailChangeMessage(to, baseURL, token string) Message {
+	link := fmt.Sprintf("%s/confirm-email-change?token=%s", baseURL, url.QueryEscape(token))
+
+	return Message{
+		To:      to,
+		Subject: "Confirm your new email",
+		Body: "Someone asked to use this address for a Notes account.\r\n\r\n" +
+			"Follow the link to confirm the change:\r\n" + link + "\r\n\r\n" +
+			"If it wasn't you, just ignore this email.\r\n",
+	}
+}
+
+func WorkspaceInvitationMessage(to, baseURL, workspaceName string) Message {
+	link := fmt.Sprintf("%s/invitations", baseURL)
+
+	return Message{
+		To:      to,
+		Subject: fmt.Sprintf("You are invited to %s", workspaceName),
+		Body: fmt.Sprintf("You were invited to join the \"%s\" workspace.\r\n\r\n", workspaceName) +
+			"Sign in with this email to accept or decline the invitation:\r\n" + link + "\r\n",
+	}
+}
@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"fmt"
+	"main/internal/config"
+	"net"
+	"net/mail"
+	"net/smtp"
+)
+
+type SMTP struct {
+	addr         string
+	from         string
+	envelopeFrom string
+	auth         smtp.Auth
+}
+
+func NewSMTP(cfg config.Mail) *SMTP {
+	var auth smtp.Auth
+	if cfg.SMTP.Username != "" {
+		auth = smtp.PlainAuth("", cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.Host)
+	}
+
+	// envelope sender must be a bare address, while header may contain a name
+	envelopeFrom := cfg.From
+	if address, err := mail.ParseAddress(cfg.From); err == nil {
+		envelopeFrom = address.Address
+	}
+
+	return &SMTP{
+		addr:         net.JoinHostPort(cfg.SMTP.Host, cfg.SMTP.Port),
+		from:         cfg.From,
+		envelopeFrom: envelopeFrom,
+		auth:         auth,
+	}
+}
+
+func (s *SMTP) Send(msg Message) error {
+	const op = "mailer.SMTP.Send"
+
+	err := smtp.SendMail(s.addr, s.auth, s.envelopeFrom, []string{msg.To}, msg.bytes(s.from))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "notes"
+
+// Metrics keeps collectors in its own registry, so only metrics of the app are exposed
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	imageBytes      *prometheus.HistogramVec
+	imageProcessing prometheus.Histogram
+	deletedRecords  *prometheus.CounterVec
+	cacheLookups    *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of handled HTTP requests.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of HTTP requests.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		imageBytes: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "image_upload_size_bytes",
+			Help:      "Size of uploaded images, before and after compression.",
+			Buckets:   prometheus.ExponentialBuckets(16<<10, 2, 10),
+		}, []string{"format", "stage"}),
+		imageProcessing: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "image_processing_duration_seconds",
+			Help:      "Time spent decoding, resizing and encoding uploaded images.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 10),
+		}),
+		deletedRecords: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "background_deleted_records_total",
+			Help:      "Number of expired records, like refresh tokens, deleted by background jobs.",
+		}, []string{"record"}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_lookups_total",
+			Help:      "Number of cache lookups of notes and note access checks, by result.",
+		}, []string{"entry", "result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.imageBytes,
+		m.imageProcessing,
+		m.deletedRecords,
+		m.cacheLookups,
+	)
+
+	return m
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	m.requests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.requestDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObserveImageUpload(format string, uploadedBytes, storedBytes int64, processing time.Duration) {
+	m.imageBytes.WithLabelValues(format, "uploaded").Observe(float64(uploadedBytes))
+	m.imageBytes.WithLabelValues(format, "stored").Observe(float64(storedBytes))
+	m.imageProcessing.Observe(processing.Seconds())
+}
+
+func (m *Metrics) AddDeletedRecords(record string, count int) {
+	m.deletedRecords.WithLabelValues(record).Add(float64(count))
+}
+
+func (m *Metrics) ObserveCacheLookup(entry string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+
+	m.cacheLookups.WithLabelValues(entry, result).Inc()
+}
+
+// RegisterDBStats exposes connection pool stats, they are read on every scrape
+func (m *Metrics) RegisterDBStats(stats func() sql.DBStats) {
+	gauge := func(name, help string, value func(s sql.DBStats) float64) prometheus.Collector {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "db_pool",
+			Name:      name,
+			Help:      help,
+		}, func() float64 { return value(stats()) })
+	}
+	counter := func(name, help string, value func(s sql.DBStats) float64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "db_pool",
+			Name:      name,
+			Help:      help,
+		}, func() float64 { return value(stats()) })
+	}
+
+	m.registry.MustRegister(
+		gauge("max_open_connections", "Maximum number of open connections.", func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }),
+		gauge("open_connections", "Number of established connections.", func(s sql.DBStats) float64 { return float64(s.OpenConnections) }),
+		gauge("in_use_connections", "Number of connections in use.", func(s sql.DBStats) float64 { return float64(s.InUse) }),
+		gauge("idle_connections", "Number of idle connections.", func(s sql.DBStats) float64 { return float64(s.Idle) }),
+		counter("wait_count_total", "Number of connections waited for.", func(s sql.DBStats) float64 { return float64(s.WaitCount) }),
+		counter("wait_duration_seconds_total", "Time blocked waiting for a connection.", func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }),
+		counter("max_idle_closed_total", "Connections closed due to max idle limit.", func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) }),
+		counter("max_lifetime_closed_total", "Connections closed due to max lifetime.", func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) }),
+	)
+}
+
+// RegisterActiveUsers exposes number of users with an active session, it's counted on every scrape
+func (m *Metrics) RegisterActiveUsers(count func(ctx context.Context) (int, error), timeout time.Duration, log *slog.Logger) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_users",
+		Help:      "Number of users with an active session.",
+	}, func() float64 {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		users, err := count(ctx)
+		if err != nil {
+			log.Error("failed to count active users", "error", err)
+			return 0
+		}
+
+		return float64(users)
+	}))
+}
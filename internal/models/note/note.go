@@ -12,13 +12,14 @@ const (
 )
 
 type Note struct {
-	Id         int        `json:"id"`
-	UserId     string     `json:"user_id" db:"user_id"`
-	Title      string     `json:"title" validate:"max=31"`
-	Nodes      []NoteNode `json:"nodes"`
-	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
-	ArchivedAt *time.Time `json:"archived_at,omitempty" db:"archived_at"`
+	Id          int        `json:"id"`
+	UserId      *string    `json:"user_id,omitempty" db:"user_id"`
+	WorkspaceId *string    `json:"workspace_id,omitempty" db:"workspace_id"`
+	Title       string     `json:"title" validate:"max=31"`
+	Nodes       []NoteNode `json:"nodes"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty" db:"archived_at"`
 }
 
 type NoteNode struct {
@@ -31,10 +32,11 @@ type NoteNode struct {
 }
 
 type NotePreview struct {
-	Id         int        `json:"id"`
-	UserId     string     `json:"user_id" db:"user_id"`
-	Title      string     `json:"title" validate:"max=31"`
-	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
-	ArchivedAt *time.Time `json:"archived_at,omitempty" db:"archived_at"`
+	Id          int        `json:"id"`
+	UserId      *string    `json:"user_id,omitempty" db:"user_id"`
+	WorkspaceId *string    `json:"workspace_id,omitempty" db:"workspace_id"`
+	Title       string     `json:"title" validate:"max=31"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty" db:"archived_at"`
 }
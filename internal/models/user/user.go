@@ -1,12 +1,49 @@
 package user
 
+import "time"
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
-	ID           string `json:"id"`
-	Email        string `json:"email"`
-	Name         string `json:"name"`
-	PasswordHash string `json:"password_hash" db:"password_hash"`
-	CreatedAt    string `json:"created_at" db:"created_at"`
-	UpdatedAt    string `json:"updated_at" db:"updated_at"`
+	ID               string     `json:"id"`
+	Email            string     `json:"email"`
+	Name             string     `json:"name"`
+	PasswordHash     string     `json:"password_hash" db:"password_hash"`
+	CreatedAt        string     `json:"created_at" db:"created_at"`
+	UpdatedAt        string     `json:"updated_at" db:"updated_at"`
+	TokensValidAfter *time.Time `json:"-" db:"tokens_valid_after"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at,omitempty" db:"email_verified_at"`
+	PendingEmail     *string    `json:"-" db:"pending_email"`
+	Role             string     `json:"role"`
+	DisabledAt       *time.Time `json:"disabled_at,omitempty" db:"disabled_at"`
+}
+
+// Profile is the part of user which is shown to its owner
+type Profile struct {
+	ID              string     `json:"id"`
+	Email           string     `json:"email"`
+	Name            string     `json:"name"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	PendingEmail    *string    `json:"pending_email,omitempty"`
+	Role            string     `json:"role"`
+	CreatedAt       string     `json:"created_at"`
+	UpdatedAt       string     `json:"updated_at"`
+}
+
+func (u User) Profile() Profile {
+	return Profile{
+		ID:              u.ID,
+		Email:           u.Email,
+		Name:            u.Name,
+		EmailVerifiedAt: u.EmailVerifiedAt,
+		PendingEmail:    u.PendingEmail,
+		Role:            u.Role,
+		CreatedAt:       u.CreatedAt,
+		UpdatedAt:       u.UpdatedAt,
+	}
 }
 
 type UserPreview struct {
@@ -14,3 +51,22 @@ type UserPreview struct {
 	Email string `json:"email"`
 	Name  string `json:"name"`
 }
+
+// Account is the user as seen by administrators
+type Account struct {
+	ID               string     `json:"id"`
+	Email            string     `json:"email"`
+	Name             string     `json:"name"`
+	Role             string     `json:"role"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at,omitempty" db:"email_verified_at"`
+	DisabledAt       *time.Time `json:"disabled_at,omitempty" db:"disabled_at"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled" db:"two_factor_enabled"`
+	CreatedAt        string     `json:"created_at" db:"created_at"`
+}
+
+// Usage is the amount of data stored by the user
+type Usage struct {
+	Notes      int   `json:"notes" db:"notes"`
+	Nodes      int   `json:"nodes" db:"nodes"`
+	ImageBytes int64 `json:"image_bytes" db:"-"`
+}
@@ -0,0 +1,66 @@
+package workspace
+
+import "time"
+
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+	RoleGuest  = "guest"
+)
+
+type Workspace struct {
+	Id        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Membership is a workspace as seen by one of its members
+type Membership struct {
+	Id        string    `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type Member struct {
+	UserId   string    `json:"user_id" db:"user_id"`
+	Email    string    `json:"email"`
+	Name     string    `json:"name"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at" db:"joined_at"`
+}
+
+type Invitation struct {
+	Id            string    `json:"id"`
+	WorkspaceId   string    `json:"workspace_id" db:"workspace_id"`
+	WorkspaceName string    `json:"workspace_name" db:"workspace_name"`
+	Email         string    `json:"email"`
+	Role          string    `json:"role"`
+	InvitedBy     *string   `json:"invited_by,omitempty" db:"invited_by"`
+	ExpiresAt     time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+var roleRanks = map[string]int{
+	RoleGuest:  0,
+	RoleMember: 1,
+	RoleAdmin:  2,
+	RoleOwner:  3,
+}
+
+// CanEditNotes reports whether role allows creating and changing workspace notes
+func CanEditNotes(role string) bool {
+	return role != RoleGuest
+}
+
+// CanManage reports whether role allows inviting, removing and changing role of
+// members with target role. Owners manage everyone, admins manage members and guests
+func CanManage(role, target string) bool {
+	if role == RoleOwner {
+		return true
+	}
+
+	return role == RoleAdmin && roleRanks[target] < roleRanks[RoleAdmin]
+}
@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit describes a token bucket: Burst tokens at most, refilled
+// at Requests per Period
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration
+	RetryAfter time.Duration
+}
+
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+func (l Limit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+
+	return float64(l.Requests)
+}
+
+// ratePerSecond is how many tokens are added to the bucket every second
+func (l Limit) ratePerSecond() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Enabled reports whether limit is configured, zero limit lets everything through
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+// refill returns tokens in bucket after elapsed time
+func refill(tokens float64, elapsed time.Duration, limit Limit) float64 {
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	return math.Min(limit.capacity(), tokens+elapsed.Seconds()*limit.ratePerSecond())
+}
+
+// newResult builds result from tokens left in the bucket after the request
+func newResult(allowed bool, tokens float64, limit Limit) Result {
+	rate := limit.ratePerSecond()
+
+	result := Result{
+		Allowed:    allowed,
+		Limit:      int(limit.capacity()),
+		Remaining:  int(math.Floor(tokens)),
+		ResetAfter: secondsToDuration((limit.capacity() - tokens) / rate),
+	}
+
+	if !allowed {
+		result.RetryAfter = secondsToDuration((1 - tokens) / rate)
+	}
+
+	return result
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
@@ -0,0 +1,188 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// backends returns limiters of every backend, all of them read time from c
+func backends(t *testing.T, c *clock) map[string]Limiter {
+	memory := NewMemoryLimiter()
+	memory.now = c.Now
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	shared := NewRedisLimiter(client, "test:")
+	shared.now = c.Now
+
+	return map[string]Limiter{
+		"memory": memory,
+		"redis":  shared,
+	}
+}
+
+func allow(t *testing.T, limiter Limiter, key string, limit Limit) Result {
+	t.Helper()
+
+	result, err := limiter.Allow(context.Background(), key, limit)
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+
+	return result
+}
+
+func TestBurst(t *testing.T) {
+	limit := Limit{Requests: 1, Period: time.Second, Burst: 3}
+
+	for name, limiter := range backends(t, &clock{now: time.Now()}) {
+		t.Run(name, func(t *testing.T) {
+			for want := 2; want >= 0; want-- {
+				result := allow(t, limiter, "burst", limit)
+				if !result.Allowed {
+					t.Fatalf("request within burst denied, remaining %d", want)
+				}
+				if result.Limit != 3 || result.Remaining != want {
+					t.Fatalf("limit/remaining: got %d/%d, want 3/%d", result.Limit, result.Remaining, want)
+				}
+			}
+
+			result := allow(t, limiter, "burst", limit)
+			if result.Allowed {
+				t.Fatal("request over burst allowed")
+			}
+			if result.RetryAfter != time.Second {
+				t.Fatalf("retry after: got %v, want 1s", result.RetryAfter)
+			}
+			if result.ResetAfter != 3*time.Second {
+				t.Fatalf("reset after: got %v, want 3s", result.ResetAfter)
+			}
+		})
+	}
+}
+
+func TestRefill(t *testing.T) {
+	limit := Limit{Requests: 2, Period: time.Second}
+
+	c := &clock{now: time.Now()}
+
+	for name, limiter := range backends(t, c) {
+		t.Run(name, func(t *testing.T) {
+			allow(t, limiter, "refill", limit)
+			allow(t, limiter, "refill", limit)
+
+			if allow(t, limiter, "refill", limit).Allowed {
+				t.Fatal("request over limit allowed")
+			}
+
+			// 1.2 tokens are added, so exactly one request passes
+			c.Advance(600 * time.Millisecond)
+
+			if !allow(t, limiter, "refill", limit).Allowed {
+				t.Fatal("request after refill denied")
+			}
+			if allow(t, limiter, "refill", limit).Allowed {
+				t.Fatal("second request after partial refill allowed")
+			}
+
+			// bucket doesn't grow over its capacity
+			c.Advance(time.Minute)
+
+			for i := range 2 {
+				if !allow(t, limiter, "refill", limit).Allowed {
+					t.Fatalf("request %d after full refill denied", i)
+				}
+			}
+			if allow(t, limiter, "refill", limit).Allowed {
+				t.Fatal("refill exceeded bucket capacity")
+			}
+		})
+	}
+}
+
+func TestKeysAreIndependent(t *testing.T) {
+	limit := Limit{Requests: 1, Period: time.Minute}
+
+	for name, limiter := range backends(t, &clock{now: time.Now()}) {
+		t.Run(name, func(t *testing.T) {
+			if !allow(t, limiter, "first", limit).Allowed {
+				t.Fatal("first request of first key denied")
+			}
+			if !allow(t, limiter, "second", limit).Allowed {
+				t.Fatal("first request of second key denied")
+			}
+			if allow(t, limiter, "first", limit).Allowed {
+				t.Fatal("exhausted key allowed")
+			}
+		})
+	}
+}
+
+func TestRedisBucketIsShared(t *testing.T) {
+	server := miniredis.RunT(t)
+	c := &clock{now: time.Now()}
+
+	instance := func() *RedisLimiter {
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { _ = client.Close() })
+
+		limiter := NewRedisLimiter(client, "test:")
+		limiter.now = c.Now
+
+		return limiter
+	}
+
+	limit := Limit{Requests: 1, Period: time.Minute}
+
+	if !allow(t, instance(), "shared", limit).Allowed {
+		t.Fatal("first request denied")
+	}
+	if allow(t, instance(), "shared", limit).Allowed {
+		t.Fatal("bucket isn't shared between instances")
+	}
+
+	if !server.Exists("test:shared") {
+		t.Fatal("bucket is stored without prefix")
+	}
+	if ttl := server.TTL("test:shared"); ttl <= 0 || ttl > time.Minute+time.Second {
+		t.Fatalf("bucket ttl: got %v", ttl)
+	}
+}
+
+func TestMemorySweep(t *testing.T) {
+	c := &clock{now: time.Now()}
+
+	limiter := NewMemoryLimiter()
+	limiter.now = c.Now
+
+	limit := Limit{Requests: 10, Period: time.Second}
+	allow(t, limiter, "idle", limit)
+
+	c.Advance(sweepInterval + time.Second)
+	allow(t, limiter, "active", limit)
+
+	if _, ok := limiter.buckets["idle"]; ok {
+		t.Fatal("full bucket isn't swept")
+	}
+	if _, ok := limiter.buckets["active"]; !ok {
+		t.Fatal("active bucket is swept")
+	}
+}
@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript refills and takes a token atomically. Current time is
+// passed by the caller, so instances have to keep their clocks in sync
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil((capacity - tokens) / rate) + 1000)
+
+return {allowed, tostring(tokens)}
+`)
+
+// RedisLimiter shares buckets between instances
+type RedisLimiter struct {
+	client redis.UniversalClient
+	prefix string
+	now    func() time.Time
+}
+
+func NewRedisLimiter(client redis.UniversalClient, prefix string) *RedisLimiter {
+	return &RedisLimiter{client: client, prefix: prefix, now: time.Now}
+}
+
+func (l *RedisLimiter) Close() error {
+	return l.client.Close()
+}
+
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	const op = "ratelimit.RedisLimiter.Allow"
+
+	// rate in tokens per millisecond, time in milliseconds
+	rate := limit.ratePerSecond() / 1000
+	now := l.now().UnixMilli()
+
+	res, err := tokenBucketScript.Run(ctx, l.client, []string{l.prefix + key},
+		limit.capacity(), rate, now,
+	).Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(res) != 2 {
+		return Result{}, fmt.Errorf("%s: unexpected script result %v", op, res)
+	}
+
+	allowed, _ := res[0].(int64)
+	rawTokens, _ := res[1].(string)
+
+	tokens, err := strconv.ParseFloat(rawTokens, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return newResult(allowed == 1, tokens, limit), nil
+}
@@ -0,0 +1,56 @@
+package router
+
+import (
+	"log/slog"
+	"main/internal/config"
+	auditlog "main/internal/http-server/handler/admin/audit-log"
+	disableuser "main/internal/http-server/handler/admin/disable-user"
+	enableuser "main/internal/http-server/handler/admin/enable-user"
+	getuser "main/internal/http-server/handler/admin/get-user"
+	listjobs "main/internal/http-server/handler/admin/list-jobs"
+	listusers "main/internal/http-server/handler/admin/list-users"
+	logoutuser "main/internal/http-server/handler/admin/logout-user"
+	resettwofactor "main/internal/http-server/handler/admin/reset-two-factor"
+	setrole "main/internal/http-server/handler/admin/set-role"
+	"main/internal/http-server/middleware/authenticator"
+	"main/internal/http-server/middleware/role"
+	"main/internal/http-server/middleware/verifier"
+	"main/internal/models/user"
+
+	"github.com/go-chi/chi"
+)
+
+type Administrator interface {
+	listusers.AccountsLister
+	getuser.AccountGetter
+	resettwofactor.TwoFactorResetter
+	setrole.RoleSetter
+	disableuser.SessionsRevoker
+	logoutuser.SessionsRevoker
+	auditlog.AuditEventsGetter
+	listjobs.JobRunsGetter
+}
+
+func (r *Router) InitAdminRoutes(storage Storage, logger *slog.Logger, cfg *config.Config) {
+	// admin routes, personal access tokens have no role and are rejected
+	r.Route("/admin", func(adminRouter chi.Router) {
+		adminRouter.Use(verifier.New(r.tokenAuth))
+		adminRouter.Use(authenticator.Authenticator(r.tokenAuth, r.denylist, nil, logger))
+		adminRouter.Use(role.Require(logger, user.RoleAdmin))
+		adminRouter.Use(r.rateLimit("account", logger, cfg))
+
+		adminRouter.Route("/users", func(usersRouter chi.Router) {
+			usersRouter.Get("/", listusers.New(logger, storage))
+			usersRouter.Get("/{id}", getuser.New(cfg, logger, storage))
+
+			usersRouter.Post("/{id}/disable", disableuser.New(logger, storage, r.denylist))
+			usersRouter.Post("/{id}/enable", enableuser.New(logger, storage, r.denylist))
+			usersRouter.Post("/{id}/logout", logoutuser.New(logger, storage, r.denylist))
+			usersRouter.Delete("/{id}/two-factor", resettwofactor.New(logger, storage))
+			usersRouter.Put("/{id}/role", setrole.New(logger, storage, r.denylist))
+		})
+
+		adminRouter.Get("/audit", auditlog.New(logger, storage))
+		adminRouter.Get("/jobs", listjobs.New(logger, storage))
+	})
+}
@@ -2,39 +2,94 @@ package router
 
 import (
 	"log/slog"
+	"main/internal/auth/denylist"
 	"main/internal/config"
+	auditlog "main/internal/http-server/handler/auth/audit-log"
+	changeemail "main/internal/http-server/handler/auth/change-email"
+	changepassword "main/internal/http-server/handler/auth/change-password"
+	confirmemailchange "main/internal/http-server/handler/auth/confirm-email-change"
+	deleteaccount "main/internal/http-server/handler/auth/delete-account"
+	forgotpassword "main/internal/http-server/handler/auth/forgot-password"
+	"main/internal/http-server/handler/auth/jwks"
 	"main/internal/http-server/handler/auth/login"
+	"main/internal/http-server/handler/auth/logout"
+	logoutall "main/internal/http-server/handler/auth/logout-all"
 	"main/internal/http-server/handler/auth/me"
 	"main/internal/http-server/handler/auth/refresh"
 	"main/internal/http-server/handler/auth/register"
+	resendverification "main/internal/http-server/handler/auth/resend-verification"
+	resetpassword "main/internal/http-server/handler/auth/reset-password"
+	updateprofile "main/internal/http-server/handler/auth/update-profile"
+	verifyemail "main/internal/http-server/handler/auth/verify-email"
 	"main/internal/http-server/middleware/authenticator"
+	"main/internal/http-server/middleware/verifier"
 
 	"github.com/go-chi/chi"
-	"github.com/go-chi/jwtauth/v5"
 )
 
 type Authorizer interface {
 	register.Register
 	login.Loginer
 	refresh.RefreshTokener
+	logoutall.SessionsRevoker
+	denylist.Store
+	changepassword.PasswordChanger
+	forgotpassword.PasswordResetRequester
+	resetpassword.PasswordResetter
+	verifyemail.EmailVerifier
+	resendverification.VerificationResender
+	me.ProfileGetter
+	updateprofile.ProfileUpdater
+	changeemail.EmailChanger
+	confirmemailchange.EmailChangeConfirmer
+	deleteaccount.AccountDeleter
+	auditlog.AuditEventsGetter
 }
 
 func (r *Router) InitAuthRoutes(storage Storage, logger *slog.Logger, cfg *config.Config) {
+	// public keys for verifying tokens in other services
+	r.Get("/.well-known/jwks.json", jwks.New(logger, r.tokenAuth))
+
 	r.Route("/user", func(userRouter chi.Router) {
-		userRouter.Post("/register", register.New(cfg, logger, storage, r.jwtauth))
-		userRouter.Post("/login", login.New(cfg, logger, storage, r.jwtauth))
-		userRouter.Post("/refresh", refresh.New(cfg, logger, storage, r.jwtauth))
+		userRouter.Group(func(public chi.Router) {
+			// anonymous requests are limited by client ip
+			public.Use(r.rateLimit("public", logger, cfg))
+
+			public.Post("/register", register.New(cfg, logger, storage, r.tokenAuth, r.mailer))
+			public.Post("/login", login.New(cfg, logger, storage, r.guard, r.tokenAuth))
+			r.initTwoFactorLoginRoute(public, storage, logger, cfg)
+			public.Post("/refresh", refresh.New(cfg, logger, storage, r.tokenAuth))
+			r.initOIDCRoutes(public, storage, logger, cfg)
+			r.initPasskeyLoginRoutes(public, storage, logger, cfg)
+
+			// password recovery and email verification
+			public.Post("/password/forgot", forgotpassword.New(cfg, logger, storage, r.mailer))
+			public.Post("/password/reset", resetpassword.New(cfg, logger, storage, r.denylist))
+			public.Post("/email/verify", verifyemail.New(cfg, logger, storage))
+			public.Post("/email/change/confirm", confirmemailchange.New(cfg, logger, storage))
+		})
 
 		userRouter.Group(func(protected chi.Router) {
-			protected.Use(jwtauth.Verifier(r.jwtauth))
-			protected.Use(authenticator.Authenticator(r.jwtauth, logger))
+			protected.Use(verifier.New(r.tokenAuth))
+			// account routes are not available with personal access tokens
+			protected.Use(authenticator.Authenticator(r.tokenAuth, r.denylist, nil, logger))
+			protected.Use(r.rateLimit("account", logger, cfg))
 
-			protected.Get("/me", me.New(logger, r.jwtauth))
+			// profile
+			protected.Get("/me", me.New(logger, storage))
+			protected.Patch("/me", updateprofile.New(logger, storage))
+			protected.Delete("/me", deleteaccount.New(cfg, logger, storage, r.denylist))
+			protected.Post("/email/change", changeemail.New(cfg, logger, storage, r.mailer))
+			protected.Get("/audit", auditlog.New(logger, storage))
+
+			protected.Post("/logout", logout.New(logger, r.denylist))
+			protected.Post("/logout-all", logoutall.New(logger, storage, r.denylist))
+			protected.Post("/password/change", changepassword.New(cfg, logger, storage, r.denylist, r.tokenAuth))
+			protected.Post("/email/resend", resendverification.New(cfg, logger, storage, r.mailer))
+
+			r.initTwoFactorRoutes(protected, storage, logger, cfg)
+			r.initPasskeyRoutes(protected, storage, logger)
+			r.initPersonalAccessTokenRoutes(protected, storage, logger, cfg)
 		})
 	})
 }
-
-func generateAuthToken(cfg *config.Config) *jwtauth.JWTAuth {
-	tokenAuth := jwtauth.New("HS256", []byte(cfg.JWTSecret), nil)
-	return tokenAuth
-}
@@ -2,6 +2,7 @@ package router
 
 import (
 	"log/slog"
+	"main/internal/auth/pat"
 	"main/internal/config"
 	"main/internal/http-server/handler/note/archive"
 	"main/internal/http-server/handler/note/create"
@@ -13,9 +14,10 @@ import (
 	updateorder "main/internal/http-server/handler/note/update-order"
 	updatetitle "main/internal/http-server/handler/note/update-title"
 	"main/internal/http-server/middleware/authenticator"
+	"main/internal/http-server/middleware/scope"
+	"main/internal/http-server/middleware/verifier"
 
 	"github.com/go-chi/chi"
-	"github.com/go-chi/jwtauth/v5"
 )
 
 type Noter interface {
@@ -33,24 +35,28 @@ type Noter interface {
 func (r *Router) InitNotesRoutes(storage Storage, logger *slog.Logger, cfg *config.Config) {
 	// note routes
 	r.Route("/note", func(noteRouter chi.Router) {
-		noteRouter.Use(jwtauth.Verifier(r.jwtauth))
-		noteRouter.Use(authenticator.Authenticator(r.jwtauth, logger))
+		noteRouter.Use(verifier.New(r.tokenAuth))
+		noteRouter.Use(authenticator.Authenticator(r.tokenAuth, r.denylist, r.pats, logger))
+		noteRouter.Use(r.rateLimit("notes", logger, cfg))
+
+		canRead := noteRouter.With(scope.Require(logger, pat.ScopeNotesRead))
+		canWrite := noteRouter.With(scope.Require(logger, pat.ScopeNotesWrite))
 
 		// create
-		noteRouter.Post("/create", create.New(logger, storage))
+		canWrite.Post("/create", create.New(logger, storage))
 
 		// read
-		noteRouter.Get("/{id}", getnote.New(logger, storage))
-		noteRouter.Get("/list", getusernotes.New(logger, storage))
+		canRead.Get("/{id}", getnote.New(logger, storage))
+		canRead.Get("/list", getusernotes.New(logger, storage))
 
 		// update
-		noteRouter.Put("/{id}", updatefullnote.New(logger, storage))
-		noteRouter.Patch("/{id}", updatetitle.New(logger, storage))
-		noteRouter.Patch("/{id}/order", updateorder.New(logger, storage))
+		canWrite.Put("/{id}", updatefullnote.New(logger, storage))
+		canWrite.Patch("/{id}", updatetitle.New(logger, storage))
+		canWrite.Patch("/{id}/order", updateorder.New(logger, storage))
 
 		// delete (and archive)
-		noteRouter.Patch("/{id}/archive", archive.New(logger, storage))
-		noteRouter.Patch("/{id}/unarchive", unarchive.New(logger, storage))
-		noteRouter.Delete("/{id}", deleteNote.New(logger, storage))
+		canWrite.Patch("/{id}/archive", archive.New(logger, storage))
+		canWrite.Patch("/{id}/unarchive", unarchive.New(logger, storage))
+		canWrite.Delete("/{id}", deleteNote.New(logger, storage))
 	})
 }
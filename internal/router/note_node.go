@@ -2,6 +2,7 @@ package router
 
 import (
 	"log/slog"
+	"main/internal/auth/pat"
 	"main/internal/config"
 	"main/internal/http-server/handler/node/add"
 	deleteNode "main/internal/http-server/handler/node/delete"
@@ -9,9 +10,10 @@ import (
 	updatecontent "main/internal/http-server/handler/node/update-content"
 	uploadimage "main/internal/http-server/handler/node/upload-image"
 	"main/internal/http-server/middleware/authenticator"
+	"main/internal/http-server/middleware/scope"
+	"main/internal/http-server/middleware/verifier"
 
 	"github.com/go-chi/chi"
-	"github.com/go-chi/jwtauth/v5"
 )
 
 type NoteNoder interface {
@@ -25,20 +27,25 @@ type NoteNoder interface {
 func (r *Router) InitNoteNodesRoutes(storage Storage, logger *slog.Logger, cfg *config.Config) {
 	// node routes
 	r.Route("/node", func(nodeRouter chi.Router) {
-		nodeRouter.Use(jwtauth.Verifier(r.jwtauth))
-		nodeRouter.Use(authenticator.Authenticator(r.jwtauth, logger))
+		nodeRouter.Use(verifier.New(r.tokenAuth))
+		nodeRouter.Use(authenticator.Authenticator(r.tokenAuth, r.denylist, r.pats, logger))
+		nodeRouter.Use(r.rateLimit("nodes", logger, cfg))
+
+		canRead := nodeRouter.With(scope.Require(logger, pat.ScopeNotesRead))
+		canWrite := nodeRouter.With(scope.Require(logger, pat.ScopeNotesWrite))
+		canUploadImages := nodeRouter.With(scope.Require(logger, pat.ScopeImagesWrite))
 
 		// create
-		nodeRouter.Post("/", add.New(logger, storage))
+		canWrite.Post("/", add.New(logger, storage))
 
 		// read
-		nodeRouter.Get("/{id}/image", getimage.New(logger, storage))
+		canRead.Get("/{id}/image", getimage.New(logger, storage))
 
 		// update
-		nodeRouter.Patch("/{id}", updatecontent.New(logger, storage))
-		nodeRouter.Patch("/{id}/image", uploadimage.New(cfg, logger, storage))
+		canWrite.Patch("/{id}", updatecontent.New(logger, storage))
+		canUploadImages.Patch("/{id}/image", uploadimage.New(cfg, logger, storage, r.metrics))
 
 		// delete
-		nodeRouter.Delete("/{id}", deleteNode.New(logger, storage))
+		canWrite.Delete("/{id}", deleteNode.New(logger, storage))
 	})
 }
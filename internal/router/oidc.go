@@ -0,0 +1,25 @@
+package router
+
+import (
+	"log/slog"
+	"main/internal/auth/oidc"
+	"main/internal/config"
+	"main/internal/http-server/handler/oidc/authorize"
+	"main/internal/http-server/handler/oidc/callback"
+	"main/internal/http-server/handler/oidc/providers"
+
+	"github.com/go-chi/chi"
+)
+
+type OIDCer interface {
+	oidc.StateCreator
+	callback.OIDCLoginer
+}
+
+func (r *Router) initOIDCRoutes(userRouter chi.Router, storage Storage, logger *slog.Logger, cfg *config.Config) {
+	userRouter.Route("/oidc", func(oidcRouter chi.Router) {
+		oidcRouter.Get("/providers", providers.New(logger, r.oidc))
+		oidcRouter.Get("/{provider}/authorize", authorize.New(cfg, logger, storage, r.oidc))
+		oidcRouter.Post("/{provider}/callback", callback.New(cfg, logger, storage, r.oidc, r.tokenAuth))
+	})
+}
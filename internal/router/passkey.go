@@ -0,0 +1,36 @@
+package router
+
+import (
+	"log/slog"
+	"main/internal/config"
+	deletePasskey "main/internal/http-server/handler/passkey/delete"
+	"main/internal/http-server/handler/passkey/list"
+	loginbegin "main/internal/http-server/handler/passkey/login-begin"
+	loginfinish "main/internal/http-server/handler/passkey/login-finish"
+	registerbegin "main/internal/http-server/handler/passkey/register-begin"
+	registerfinish "main/internal/http-server/handler/passkey/register-finish"
+
+	"github.com/go-chi/chi"
+)
+
+type Passkeyer interface {
+	registerbegin.RegistrationBeginner
+	registerfinish.RegistrationFinisher
+	loginfinish.PasskeyLoginer
+	deletePasskey.PasskeyDeleter
+}
+
+func (r *Router) initPasskeyLoginRoutes(userRouter chi.Router, storage Storage, logger *slog.Logger, cfg *config.Config) {
+	// passwordless login with discoverable credentials
+	userRouter.Post("/passkeys/login/begin", loginbegin.New(logger, storage, r.webAuthn))
+	userRouter.Post("/passkeys/login/finish", loginfinish.New(cfg, logger, storage, r.webAuthn, r.tokenAuth))
+}
+
+func (r *Router) initPasskeyRoutes(protected chi.Router, storage Storage, logger *slog.Logger) {
+	protected.Route("/passkeys", func(passkeyRouter chi.Router) {
+		passkeyRouter.Get("/", list.New(logger, storage))
+		passkeyRouter.Post("/register/begin", registerbegin.New(logger, storage, r.webAuthn))
+		passkeyRouter.Post("/register/finish", registerfinish.New(logger, storage, r.webAuthn))
+		passkeyRouter.Delete("/{id}", deletePasskey.New(logger, storage))
+	})
+}
@@ -0,0 +1,27 @@
+package router
+
+import (
+	"log/slog"
+	"main/internal/auth/pat"
+	"main/internal/config"
+	createPAT "main/internal/http-server/handler/pat/create"
+	listPAT "main/internal/http-server/handler/pat/list"
+	"main/internal/http-server/handler/pat/revoke"
+
+	"github.com/go-chi/chi"
+)
+
+type PersonalAccessTokener interface {
+	createPAT.TokenCreator
+	pat.Finder
+	listPAT.TokensGetter
+	revoke.TokenRevoker
+}
+
+func (r *Router) initPersonalAccessTokenRoutes(protected chi.Router, storage Storage, logger *slog.Logger, cfg *config.Config) {
+	protected.Route("/tokens", func(tokenRouter chi.Router) {
+		tokenRouter.Post("/", createPAT.New(cfg, logger, storage))
+		tokenRouter.Get("/", listPAT.New(logger, storage))
+		tokenRouter.Delete("/{id}", revoke.New(logger, storage))
+	})
+}
@@ -0,0 +1,44 @@
+package router
+
+import (
+	"fmt"
+	"log/slog"
+	"main/internal/config"
+	ratelimitMiddleware "main/internal/http-server/middleware/ratelimit"
+	"main/internal/ratelimit"
+	"net/http"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const rateLimitKeyPrefix = "ratelimit:"
+
+func newLimiter(cfg *config.Config) (ratelimit.Limiter, error) {
+	switch cfg.RateLimit.Backend {
+	case "", "memory":
+		return ratelimit.NewMemoryLimiter(), nil
+	case "redis":
+		client := redis.NewClient(&redis.Options{
+			Addr:     cfg.Redis.Address,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+
+		return ratelimit.NewRedisLimiter(client, rateLimitKeyPrefix), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit backend %q", cfg.RateLimit.Backend)
+	}
+}
+
+// rateLimit returns middleware limiting route group with limits from config
+func (r *Router) rateLimit(group string, logger *slog.Logger, cfg *config.Config) func(http.Handler) http.Handler {
+	groupCfg := cfg.RateLimit.Groups[group]
+
+	limit := ratelimit.Limit{
+		Requests: groupCfg.Requests,
+		Period:   groupCfg.Period,
+		Burst:    groupCfg.Burst,
+	}
+
+	return ratelimitMiddleware.New(r.limiter, group, limit, logger)
+}
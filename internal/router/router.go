@@ -1,40 +1,115 @@
 package router
 
 import (
+	"fmt"
+	"io"
 	"log/slog"
+	"main/internal/auth/denylist"
+	"main/internal/auth/keyring"
+	"main/internal/auth/lockout"
+	"main/internal/auth/oidc"
+	"main/internal/auth/passkey"
+	"main/internal/auth/pat"
 	"main/internal/config"
+	"main/internal/mailer"
+	"main/internal/metrics"
+	"main/internal/ratelimit"
 	"net/http"
+	"time"
 
 	resp "main/internal/http-server/api/response"
+	"main/internal/http-server/middleware/lastwrite"
 	loggerMiddleware "main/internal/http-server/middleware/logger"
+	metricsMiddleware "main/internal/http-server/middleware/metrics"
+	"main/internal/http-server/middleware/realip"
+	tracingMiddleware "main/internal/http-server/middleware/tracing"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
-	"github.com/go-chi/jwtauth/v5"
 	"github.com/go-chi/render"
 )
 
 type Router struct {
 	*chi.Mux
-	jwtauth *jwtauth.JWTAuth
+	tokenAuth *keyring.KeyRing
+	denylist  *denylist.Denylist
+	mailer    mailer.Mailer
+	oidc      *oidc.Registry
+	webAuthn  *passkey.WebAuthn
+	pats      *pat.Verifier
+	guard     *lockout.Guard
+	limiter   ratelimit.Limiter
+	metrics   *metrics.Metrics
 }
 
 type Storage interface {
 	Noter
 	NoteNoder
 	Authorizer
+	TwoFactorer
+	OIDCer
+	Passkeyer
+	PersonalAccessTokener
+	Administrator
+	Workspacer
+	Webhooker
+	lockout.Store
 }
 
-func New(cfg *config.Config, log *slog.Logger) *Router {
+func New(cfg *config.Config, log *slog.Logger, m *metrics.Metrics) (*Router, error) {
+	const op = "router.New"
+
+	// init token signing keys
+	tokenAuth, err := keyring.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// init mailer
+	mail, err := mailer.New(cfg, log)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// init oidc providers, discovery is done lazily on first use
+	oidcRegistry := oidc.NewRegistry(cfg.OIDC, &http.Client{Timeout: 10 * time.Second})
+
+	// init webauthn relying party
+	webAuthn, err := passkey.New(cfg.WebAuthn)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// init rate limiter backend
+	limiter, err := newLimiter(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// parse reverse proxies whose forwarded client ip is believed
+	trustedProxies, err := realip.ParseTrusted(cfg.HTTPServer.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
 	// init chi router
 	router := chi.NewRouter()
 
 	// add middlewares
+	router.Use(realip.New(trustedProxies))
 	router.Use(middleware.RequestID)
+	router.Use(tracingMiddleware.New())
+	if cfg.Metrics.Enabled {
+		router.Use(metricsMiddleware.New(m))
+	}
 	router.Use(loggerMiddleware.New(log))
 	router.Use(middleware.Recoverer)
 	router.Use(middleware.URLFormat)
+	if cfg.Storage.Driver == "postgres" && len(cfg.Postgres.Replicas) > 0 {
+		// clients carry their last write, so reads after it skip replicas on every instance
+		router.Use(lastwrite.New(cfg.Postgres.ReadYourWritesWindow))
+	}
 	router.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
@@ -43,18 +118,53 @@ func New(cfg *config.Config, log *slog.Logger) *Router {
 	}))
 
 	return &Router{
-		router,
-		generateAuthToken(cfg),
+		Mux:       router,
+		tokenAuth: tokenAuth,
+		mailer:    mail,
+		oidc:      oidcRegistry,
+		webAuthn:  webAuthn,
+		limiter:   limiter,
+		metrics:   m,
+	}, nil
+}
+
+// Close releases connections held by the router, like redis client of the rate limiter
+func (r *Router) Close() error {
+	if closer, ok := r.limiter.(io.Closer); ok {
+		return closer.Close()
 	}
+
+	return nil
 }
 
 func (r *Router) InitRoutes(storage Storage, logger *slog.Logger, cfg *config.Config) {
+	// init access tokens denylist
+	r.denylist = denylist.New(storage, cfg.Authorization.DenylistCacheTTL)
+
+	// init personal access tokens verifier
+	r.pats = pat.NewVerifier(storage, cfg.Authorization.Salt)
+
+	// init login brute-force protection
+	var attemptsStore lockout.Store = storage
+	if cfg.Authorization.LoginProtection.Store == "memory" {
+		attemptsStore = lockout.NewMemoryStore()
+	}
+	r.guard = lockout.New(attemptsStore, cfg.Authorization.LoginProtection, logger)
+
 	// health check route
 	r.Get("/ping", func(w http.ResponseWriter, r *http.Request) {
 		render.JSON(w, r, resp.OK())
 	})
 
+	// metrics are served here only if they aren't bound to a separate listener
+	if cfg.Metrics.Enabled && cfg.Metrics.Address == "" {
+		r.Method(http.MethodGet, cfg.Metrics.Path, r.metrics.Handler())
+	}
+
 	r.InitAuthRoutes(storage, logger, cfg)
 	r.InitNotesRoutes(storage, logger, cfg)
 	r.InitNoteNodesRoutes(storage, logger, cfg)
+	r.InitWorkspacesRoutes(storage, logger, cfg)
+	r.InitWebhooksRoutes(storage, logger, cfg)
+	r.InitAdminRoutes(storage, logger, cfg)
 }
@@ -0,0 +1,32 @@
+package router
+
+import (
+	"log/slog"
+	"main/internal/config"
+	"main/internal/http-server/handler/twofactor/confirm"
+	"main/internal/http-server/handler/twofactor/disable"
+	"main/internal/http-server/handler/twofactor/enroll"
+	"main/internal/http-server/handler/twofactor/verify"
+
+	"github.com/go-chi/chi"
+)
+
+type TwoFactorer interface {
+	enroll.TOTPEnroller
+	confirm.TOTPConfirmer
+	disable.TOTPDisabler
+	verify.TwoFactorVerifier
+}
+
+func (r *Router) initTwoFactorLoginRoute(userRouter chi.Router, storage Storage, logger *slog.Logger, cfg *config.Config) {
+	// exchange challenge from login and totp (or recovery) code for tokens
+	userRouter.Post("/login/2fa", verify.New(cfg, logger, storage, r.denylist, r.guard, r.tokenAuth))
+}
+
+func (r *Router) initTwoFactorRoutes(protected chi.Router, storage Storage, logger *slog.Logger, cfg *config.Config) {
+	protected.Route("/2fa", func(twoFactorRouter chi.Router) {
+		twoFactorRouter.Post("/enroll", enroll.New(cfg, logger, storage))
+		twoFactorRouter.Post("/confirm", confirm.New(cfg, logger, storage))
+		twoFactorRouter.Post("/disable", disable.New(cfg, logger, storage))
+	})
+}
@@ -0,0 +1,40 @@
+package router
+
+import (
+	"log/slog"
+	"main/internal/config"
+	createWebhook "main/internal/http-server/handler/webhook/create"
+	deleteWebhook "main/internal/http-server/handler/webhook/delete"
+	listWebhooks "main/internal/http-server/handler/webhook/list"
+	listdeliveries "main/internal/http-server/handler/webhook/list-deliveries"
+	"main/internal/http-server/handler/webhook/redeliver"
+	"main/internal/http-server/middleware/authenticator"
+	"main/internal/http-server/middleware/verifier"
+
+	"github.com/go-chi/chi"
+)
+
+type Webhooker interface {
+	createWebhook.WebhookCreator
+	listWebhooks.WebhooksGetter
+	deleteWebhook.WebhookDeleter
+	listdeliveries.DeliveriesGetter
+	redeliver.Redeliverer
+}
+
+func (r *Router) InitWebhooksRoutes(storage Storage, logger *slog.Logger, cfg *config.Config) {
+	// webhook routes, endpoints are managed with regular access tokens only
+	r.Route("/webhooks", func(webhookRouter chi.Router) {
+		webhookRouter.Use(verifier.New(r.tokenAuth))
+		webhookRouter.Use(authenticator.Authenticator(r.tokenAuth, r.denylist, nil, logger))
+		webhookRouter.Use(r.rateLimit("account", logger, cfg))
+
+		webhookRouter.Post("/", createWebhook.New(cfg, logger, storage))
+		webhookRouter.Get("/", listWebhooks.New(logger, storage))
+		webhookRouter.Delete("/{id}", deleteWebhook.New(logger, storage))
+
+		// delivery log
+		webhookRouter.Get("/{id}/deliveries", listdeliveries.New(logger, storage))
+		webhookRouter.Post("/{id}/deliveries/{deliveryId}/redeliver", redeliver.New(logger, storage))
+	})
+}
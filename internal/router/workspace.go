@@ -0,0 +1,69 @@
+package router
+
+import (
+	"log/slog"
+	"main/internal/config"
+	acceptinvitation "main/internal/http-server/handler/workspace/accept-invitation"
+	cancelinvitation "main/internal/http-server/handler/workspace/cancel-invitation"
+	createWorkspace "main/internal/http-server/handler/workspace/create"
+	declineinvitation "main/internal/http-server/handler/workspace/decline-invitation"
+	deleteWorkspace "main/internal/http-server/handler/workspace/delete"
+	getWorkspace "main/internal/http-server/handler/workspace/get"
+	"main/internal/http-server/handler/workspace/invite"
+	listWorkspaces "main/internal/http-server/handler/workspace/list"
+	listinvitations "main/internal/http-server/handler/workspace/list-invitations"
+	removemember "main/internal/http-server/handler/workspace/remove-member"
+	updateWorkspace "main/internal/http-server/handler/workspace/update"
+	updatemember "main/internal/http-server/handler/workspace/update-member"
+	userinvitations "main/internal/http-server/handler/workspace/user-invitations"
+	"main/internal/http-server/middleware/authenticator"
+	"main/internal/http-server/middleware/verifier"
+
+	"github.com/go-chi/chi"
+)
+
+type Workspacer interface {
+	createWorkspace.WorkspaceCreator
+	listWorkspaces.WorkspacesGetter
+	getWorkspace.WorkspaceGetter
+	updateWorkspace.WorkspaceUpdater
+	deleteWorkspace.WorkspaceDeleter
+	updatemember.MemberUpdater
+	removemember.MemberRemover
+	invite.Inviter
+	listinvitations.InvitationsGetter
+	cancelinvitation.InvitationCanceller
+	userinvitations.InvitationsGetter
+	acceptinvitation.InvitationAccepter
+	declineinvitation.InvitationDecliner
+}
+
+func (r *Router) InitWorkspacesRoutes(storage Storage, logger *slog.Logger, cfg *config.Config) {
+	// workspace routes, membership is managed with regular access tokens only
+	r.Route("/workspaces", func(workspaceRouter chi.Router) {
+		workspaceRouter.Use(verifier.New(r.tokenAuth))
+		workspaceRouter.Use(authenticator.Authenticator(r.tokenAuth, r.denylist, nil, logger))
+		workspaceRouter.Use(r.rateLimit("account", logger, cfg))
+
+		workspaceRouter.Post("/", createWorkspace.New(logger, storage))
+		workspaceRouter.Get("/", listWorkspaces.New(logger, storage))
+
+		// invitations sent to the user
+		workspaceRouter.Get("/invitations", userinvitations.New(logger, storage))
+		workspaceRouter.Post("/invitations/{id}/accept", acceptinvitation.New(logger, storage))
+		workspaceRouter.Post("/invitations/{id}/decline", declineinvitation.New(logger, storage))
+
+		workspaceRouter.Get("/{id}", getWorkspace.New(logger, storage))
+		workspaceRouter.Patch("/{id}", updateWorkspace.New(logger, storage))
+		workspaceRouter.Delete("/{id}", deleteWorkspace.New(cfg, logger, storage))
+
+		// members
+		workspaceRouter.Patch("/{id}/members/{userId}", updatemember.New(logger, storage))
+		workspaceRouter.Delete("/{id}/members/{userId}", removemember.New(logger, storage))
+
+		// invitations sent from the workspace
+		workspaceRouter.Post("/{id}/invitations", invite.New(cfg, logger, storage, r.mailer))
+		workspaceRouter.Get("/{id}/invitations", listinvitations.New(logger, storage))
+		workspaceRouter.Delete("/{id}/invitations/{invitationId}", cancelinvitation.New(logger, storage))
+	})
+}
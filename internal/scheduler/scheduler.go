@@ -0,0 +1,240 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"main/internal/config"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Run is a single recorded run of a job, retries are counted as attempts of the same run
+type Run struct {
+	Id          int64      `json:"id" db:"id"`
+	Job         string     `json:"job" db:"job"`
+	ScheduledAt time.Time  `json:"scheduled_at" db:"scheduled_at"`
+	StartedAt   time.Time  `json:"started_at" db:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at" db:"finished_at"`
+	DurationMs  *int64     `json:"duration_ms" db:"duration_ms"`
+	Status      string     `json:"status" db:"status"`
+	Attempts    int        `json:"attempts" db:"attempts"`
+	Error       string     `json:"error,omitempty" db:"error"`
+	Instance    string     `json:"instance" db:"instance"`
+}
+
+type Store interface {
+	// TryJobLock takes lock shared by all replicas, it's held until unlock is called
+	TryJobLock(ctx context.Context, job string) (unlock func(), acquired bool, err error)
+	// StartJobRun records run of the scheduled time, false means it's already done by another replica
+	StartJobRun(ctx context.Context, job string, scheduledAt time.Time, instance string) (int64, bool, error)
+	FinishJobRun(ctx context.Context, id int64, status string, attempts int, runErr string) error
+}
+
+type Func func(ctx context.Context) error
+
+type job struct {
+	name     string
+	run      Func
+	schedule Schedule
+	cfg      config.JobSchedule
+}
+
+type Scheduler struct {
+	store    Store
+	cfg      config.Jobs
+	log      *slog.Logger
+	instance string
+	jobs     []job
+}
+
+func New(store Store, cfg config.Jobs, log *slog.Logger) *Scheduler {
+	instance, err := os.Hostname()
+	if err != nil {
+		instance = "unknown"
+	}
+
+	return &Scheduler{
+		store:    store,
+		cfg:      cfg,
+		log:      log.With(slog.String("op", "scheduler.Scheduler")),
+		instance: instance,
+	}
+}
+
+// Register adds job with schedule from config. Jobs missing in config are disabled
+func (s *Scheduler) Register(name string, run Func) error {
+	const op = "scheduler.Register"
+
+	jobCfg, ok := s.cfg.Schedules[name]
+	if !ok || jobCfg.Schedule == "" {
+		s.log.Warn("job has no schedule and is disabled", slog.String("job", name))
+		return nil
+	}
+
+	schedule, err := Parse(jobCfg.Schedule)
+	if err != nil {
+		return fmt.Errorf("%s: job %s: %w", op, name, err)
+	}
+
+	s.jobs = append(s.jobs, job{name: name, run: run, schedule: schedule, cfg: jobCfg})
+
+	return nil
+}
+
+// Run starts jobs on their schedules until ctx is cancelled, then waits for running jobs.
+// Every job runs in its own goroutine, so a slow job doesn't delay the others
+func (s *Scheduler) Run(ctx context.Context) {
+	s.log.Info("scheduler started", slog.Int("jobs", len(s.jobs)))
+
+	var running sync.WaitGroup
+	defer running.Wait()
+
+	next := make([]time.Time, len(s.jobs))
+	for i, j := range s.jobs {
+		next[i] = j.schedule.Next(time.Now())
+	}
+
+	for {
+		at, ok := earliest(next)
+		if !ok {
+			<-ctx.Done()
+			s.log.Info("scheduler stopped", "reason", ctx.Err())
+			return
+		}
+
+		timer := time.NewTimer(time.Until(at))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.log.Info("scheduler stopped", "reason", ctx.Err())
+			return
+		case <-timer.C:
+		}
+
+		now := time.Now()
+
+		for i, j := range s.jobs {
+			if next[i].IsZero() || next[i].After(now) {
+				continue
+			}
+
+			running.Add(1)
+			go func(j job, scheduledAt time.Time) {
+				defer running.Done()
+
+				s.execute(ctx, j, scheduledAt)
+			}(j, next[i])
+
+			next[i] = j.schedule.Next(now)
+		}
+	}
+}
+
+func earliest(times []time.Time) (time.Time, bool) {
+	var first time.Time
+
+	for _, t := range times {
+		if !t.IsZero() && (first.IsZero() || t.Before(first)) {
+			first = t
+		}
+	}
+
+	return first, !first.IsZero()
+}
+
+// execute runs job unless another replica is running it or has already run it for scheduledAt
+func (s *Scheduler) execute(ctx context.Context, j job, scheduledAt time.Time) {
+	log := s.log.With(slog.String("job", j.name), slog.Time("scheduled_at", scheduledAt))
+
+	unlock, acquired, err := s.store.TryJobLock(ctx, j.name)
+	if err != nil {
+		log.Error("failed to take job lock", "error", err)
+		return
+	}
+	if !acquired {
+		log.Debug("job is running on another replica")
+		return
+	}
+	defer unlock()
+
+	runId, started, err := s.store.StartJobRun(ctx, j.name, scheduledAt, s.instance)
+	if err != nil {
+		log.Error("failed to record job run", "error", err)
+		return
+	}
+	if !started {
+		log.Debug("job has already run on another replica")
+		return
+	}
+
+	start := time.Now()
+
+	attempts, runErr := s.runWithRetries(ctx, j, log)
+
+	status, errText := StatusSucceeded, ""
+	if runErr != nil {
+		status, errText = StatusFailed, runErr.Error()
+	}
+
+	// result is recorded even if the job was cancelled by shutdown
+	if err := s.store.FinishJobRun(context.WithoutCancel(ctx), runId, status, attempts, errText); err != nil {
+		log.Error("failed to record job result", "error", err)
+	}
+
+	if runErr != nil {
+		log.Error("job failed", slog.Int("attempts", attempts), slog.Duration("duration", time.Since(start)), "error", runErr)
+		return
+	}
+
+	log.Info("job succeeded", slog.Int("attempts", attempts), slog.Duration("duration", time.Since(start)))
+}
+
+// runWithRetries retries failed job with doubling delay, it gives up early on shutdown
+func (s *Scheduler) runWithRetries(ctx context.Context, j job, log *slog.Logger) (int, error) {
+	delay := j.cfg.RetryDelay
+
+	for attempt := 1; ; attempt++ {
+		err := runOnce(ctx, j)
+		if err == nil || attempt > j.cfg.Retries || ctx.Err() != nil {
+			return attempt, err
+		}
+
+		log.Warn("job attempt failed, retrying", slog.Int("attempt", attempt), slog.Duration("delay", delay), "error", err)
+
+		select {
+		case <-ctx.Done():
+			return attempt, errors.Join(err, ctx.Err())
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+	}
+}
+
+func runOnce(ctx context.Context, j job) (err error) {
+	if j.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, j.cfg.Timeout)
+		defer cancel()
+	}
+
+	// panicking job fails its run instead of the whole app
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return j.run(ctx)
+}
@@ -0,0 +1,371 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"main/internal/models/note"
+	"main/internal/router"
+	"main/internal/storage"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// Backend keeps serialized entries, it evicts them after its ttl.
+// Add sets value only when the key is missing and reports whether it did
+type Backend interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte) error
+	Add(ctx context.Context, key string, value []byte) (bool, error)
+	Close() error
+}
+
+// LookupObserver counts cache hits and misses by entry
+type LookupObserver interface {
+	ObserveCacheLookup(entry string, hit bool)
+}
+
+// cached entries, they are named in metrics
+const (
+	entryNote   = "note"
+	entryNodes  = "nodes"
+	entryAccess = "access"
+)
+
+// access checks, they are part of entry keys
+const (
+	checkRead = "read"
+	checkEdit = "edit"
+)
+
+// Storage caches full notes and note access checks of the wrapped storage.
+// Calls which change notes, their nodes or who can access them invalidate
+// entries of the affected notes, other calls go to the storage as is.
+// Cache failures are logged, the storage is used instead.
+//
+// Entries of a note are keyed by its generation, and invalidation replaces the
+// generation instead of deleting entries. Generation is taken before the storage
+// is read, so a result read before a change and stored after its invalidation
+// lands under the old generation, where nobody looks it up. Cached results are
+// read from the primary, replicas may not have the change yet
+type Storage struct {
+	router.Storage
+	backend  Backend
+	observer LookupObserver
+	log      *slog.Logger
+}
+
+func New(storage router.Storage, backend Backend, observer LookupObserver, log *slog.Logger) *Storage {
+	return &Storage{
+		Storage:  storage,
+		backend:  backend,
+		observer: observer,
+		log:      log.With(slog.String("op", "cache.Storage")),
+	}
+}
+
+// Close releases connections of the backend
+func (s *Storage) Close() error {
+	return s.backend.Close()
+}
+
+func (s *Storage) GetNoteById(ctx context.Context, id int) (note.Note, error) {
+	return cached(ctx, s, entryNote, id, "", func(ctx context.Context) (note.Note, error) {
+		return s.Storage.GetNoteById(ctx, id)
+	})
+}
+
+func (s *Storage) GetAllNotesNodes(ctx context.Context, noteId int) ([]note.NoteNode, error) {
+	return cached(ctx, s, entryNodes, noteId, "nodes", func(ctx context.Context) ([]note.NoteNode, error) {
+		return s.Storage.GetAllNotesNodes(ctx, noteId)
+	})
+}
+
+// CanUserReadNote caches every check under its own key, so concurrent checks
+// of different users don't overwrite each other
+func (s *Storage) CanUserReadNote(ctx context.Context, userId string, noteId int) (bool, error) {
+	return cached(ctx, s, entryAccess, noteId, "access:"+checkRead+":"+userId, func(ctx context.Context) (bool, error) {
+		return s.Storage.CanUserReadNote(ctx, userId, noteId)
+	})
+}
+
+func (s *Storage) CanUserEditNote(ctx context.Context, userId string, noteId int) (bool, error) {
+	return cached(ctx, s, entryAccess, noteId, "access:"+checkEdit+":"+userId, func(ctx context.Context) (bool, error) {
+		return s.Storage.CanUserEditNote(ctx, userId, noteId)
+	})
+}
+
+// CreateNote drops checks of the new note id, they may be cached as denied before the note existed
+func (s *Storage) CreateNote(ctx context.Context, noteTitle string, userId string) (int, error) {
+	id, err := s.Storage.CreateNote(ctx, noteTitle, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	s.invalidate(ctx, id)
+
+	return id, nil
+}
+
+func (s *Storage) CreateWorkspaceNote(ctx context.Context, noteTitle string, workspaceId string) (int, error) {
+	id, err := s.Storage.CreateWorkspaceNote(ctx, noteTitle, workspaceId)
+	if err != nil {
+		return 0, err
+	}
+
+	s.invalidate(ctx, id)
+
+	return id, nil
+}
+
+func (s *Storage) UpdateNoteTitle(ctx context.Context, id int, title string) error {
+	defer s.invalidate(ctx, id)
+
+	return s.Storage.UpdateNoteTitle(ctx, id, title)
+}
+
+func (s *Storage) ArchiveNote(ctx context.Context, id int) error {
+	defer s.invalidate(ctx, id)
+
+	return s.Storage.ArchiveNote(ctx, id)
+}
+
+func (s *Storage) UnarchiveNote(ctx context.Context, id int) error {
+	defer s.invalidate(ctx, id)
+
+	return s.Storage.UnarchiveNote(ctx, id)
+}
+
+func (s *Storage) DeleteNote(ctx context.Context, id int) error {
+	defer s.invalidate(ctx, id)
+
+	return s.Storage.DeleteNote(ctx, id)
+}
+
+func (s *Storage) UpdateFullNote(ctx context.Context, id int, n note.Note) (int, error) {
+	defer s.invalidate(ctx, id)
+
+	return s.Storage.UpdateFullNote(ctx, id, n)
+}
+
+func (s *Storage) UpdateNoteNodeOrder(ctx context.Context, noteId int, oldOrder int, newOrder int) error {
+	defer s.invalidate(ctx, noteId)
+
+	return s.Storage.UpdateNoteNodeOrder(ctx, noteId, oldOrder, newOrder)
+}
+
+func (s *Storage) AddNoteNode(ctx context.Context, noteId int, contentType string, content string) (int, error) {
+	defer s.invalidate(ctx, noteId)
+
+	return s.Storage.AddNoteNode(ctx, noteId, contentType, content)
+}
+
+func (s *Storage) DeleteNoteNode(ctx context.Context, id int) error {
+	defer s.invalidate(ctx, s.nodeNoteIds(ctx, id)...)
+
+	return s.Storage.DeleteNoteNode(ctx, id)
+}
+
+func (s *Storage) UpdateNoteNodeContent(ctx context.Context, id int, content string) error {
+	defer s.invalidate(ctx, s.nodeNoteIds(ctx, id)...)
+
+	return s.Storage.UpdateNoteNodeContent(ctx, id, content)
+}
+
+func (s *Storage) DeleteWorkspace(ctx context.Context, id string) error {
+	defer s.invalidate(ctx, s.workspaceNoteIds(ctx, id)...)
+
+	return s.Storage.DeleteWorkspace(ctx, id)
+}
+
+// AcceptWorkspaceInvitation gives the user access to notes of the workspace
+func (s *Storage) AcceptWorkspaceInvitation(ctx context.Context, id string, email string, userId string) (string, error) {
+	workspaceId, err := s.Storage.AcceptWorkspaceInvitation(ctx, id, email, userId)
+	if err != nil {
+		return "", err
+	}
+
+	s.invalidate(ctx, s.workspaceNoteIds(ctx, workspaceId)...)
+
+	return workspaceId, nil
+}
+
+func (s *Storage) SetWorkspaceMemberRole(ctx context.Context, workspaceId string, userId string, role string) error {
+	defer s.invalidate(ctx, s.workspaceNoteIds(ctx, workspaceId)...)
+
+	return s.Storage.SetWorkspaceMemberRole(ctx, workspaceId, userId, role)
+}
+
+func (s *Storage) RemoveWorkspaceMember(ctx context.Context, workspaceId string, userId string) error {
+	defer s.invalidate(ctx, s.workspaceNoteIds(ctx, workspaceId)...)
+
+	return s.Storage.RemoveWorkspaceMember(ctx, workspaceId, userId)
+}
+
+// DeleteUser deletes notes of the user and access to notes of user's workspaces
+func (s *Storage) DeleteUser(ctx context.Context, id string) error {
+	noteIds := s.userNoteIds(ctx, id)
+
+	memberships, err := s.Storage.GetUserWorkspaces(ctx, id)
+	if err != nil {
+		s.log.Error("failed to get workspaces of deleted user", "error", err)
+	}
+	for _, membership := range memberships {
+		noteIds = append(noteIds, s.workspaceNoteIds(ctx, membership.Id)...)
+	}
+
+	defer s.invalidate(ctx, noteIds...)
+
+	return s.Storage.DeleteUser(ctx, id)
+}
+
+// cached returns entry of the note from cache, or loads it from the primary
+// and caches it. Entry names it in metrics, suffix tells entries of a note apart
+func cached[T any](ctx context.Context, s *Storage, entry string, noteId int, suffix string, load func(ctx context.Context) (T, error)) (T, error) {
+	generation, ok := s.generation(ctx, noteId)
+	if !ok {
+		return load(ctx)
+	}
+
+	key := entryKey(noteId, generation, suffix)
+
+	if value, ok := lookup[T](ctx, s, entry, key); ok {
+		return value, nil
+	}
+
+	value, err := load(storage.WithPrimary(ctx))
+	if err != nil {
+		return value, err
+	}
+
+	s.store(ctx, key, value)
+
+	return value, nil
+}
+
+// generation returns the current generation of entries of the note, false if
+// the cache can't be used. Missing generation, never set or evicted, is
+// created, entries of the lost one can't be found anymore
+func (s *Storage) generation(ctx context.Context, noteId int) (string, bool) {
+	key := generationKey(noteId)
+
+	raw, ok, err := s.backend.Get(ctx, key)
+	if err != nil {
+		s.log.Error("failed to get cache generation", slog.String("key", key), "error", err)
+
+		return "", false
+	}
+	if ok {
+		return string(raw), true
+	}
+
+	generation := uuid.NewString()
+
+	added, err := s.backend.Add(ctx, key, []byte(generation))
+	if err != nil {
+		s.log.Error("failed to add cache generation", slog.String("key", key), "error", err)
+
+		return "", false
+	}
+	if added {
+		return generation, true
+	}
+
+	// other call created it first
+	raw, ok, err = s.backend.Get(ctx, key)
+	if err != nil || !ok {
+		return "", false
+	}
+
+	return string(raw), true
+}
+
+// lookup returns cached entry of key, entry names it in metrics
+func lookup[T any](ctx context.Context, s *Storage, entry, key string) (T, bool) {
+	var value T
+
+	raw, ok, err := s.backend.Get(ctx, key)
+	if err != nil {
+		s.log.Error("failed to get cache entry", slog.String("key", key), "error", err)
+	}
+	if ok {
+		if err := json.Unmarshal(raw, &value); err != nil {
+			s.log.Error("failed to decode cache entry", slog.String("key", key), "error", err)
+
+			ok = false
+		}
+	}
+
+	s.observer.ObserveCacheLookup(entry, ok)
+
+	return value, ok
+}
+
+func (s *Storage) store(ctx context.Context, key string, value any) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		s.log.Error("failed to encode cache entry", slog.String("key", key), "error", err)
+
+		return
+	}
+
+	if err := s.backend.Set(ctx, key, raw); err != nil {
+		s.log.Error("failed to set cache entry", slog.String("key", key), "error", err)
+	}
+}
+
+// invalidate replaces generation of the notes, so their entries aren't found
+// anymore and expire with ttl of the backend. Failed invalidation is logged,
+// entries stay until they expire
+func (s *Storage) invalidate(ctx context.Context, noteIds ...int) {
+	// generations have to be replaced even if the call was cancelled after the change
+	ctx = context.WithoutCancel(ctx)
+
+	for _, id := range noteIds {
+		if err := s.backend.Set(ctx, generationKey(id), []byte(uuid.NewString())); err != nil {
+			s.log.Error("failed to invalidate cache entries", slog.Int("note_id", id), "error", err)
+		}
+	}
+}
+
+// nodeNoteIds returns note of the node, it's looked up before the node changes
+func (s *Storage) nodeNoteIds(ctx context.Context, nodeId int) []int {
+	node, err := s.Storage.GetNodeById(ctx, nodeId)
+	if err != nil {
+		return nil
+	}
+
+	return []int{node.NoteId}
+}
+
+func (s *Storage) workspaceNoteIds(ctx context.Context, workspaceId string) []int {
+	ids, err := s.Storage.GetWorkspaceNoteIds(ctx, workspaceId)
+	if err != nil {
+		s.log.Error("failed to get notes of workspace", slog.String("workspace_id", workspaceId), "error", err)
+	}
+
+	return ids
+}
+
+func (s *Storage) userNoteIds(ctx context.Context, userId string) []int {
+	ids, err := s.Storage.GetUserNoteIds(ctx, userId)
+	if err != nil {
+		s.log.Error("failed to get notes of user", slog.String("user_id", userId), "error", err)
+	}
+
+	return ids
+}
+
+func generationKey(noteId int) string {
+	return "note:" + strconv.Itoa(noteId) + ":generation"
+}
+
+func entryKey(noteId int, generation, suffix string) string {
+	key := "note:" + strconv.Itoa(noteId) + ":" + generation
+	if suffix != "" {
+		key += ":" + suffix
+	}
+
+	return key
+}
@@ -0,0 +1,303 @@
+package cache_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"main/internal/models/workspace"
+	"main/internal/router"
+	"main/internal/storage"
+	"main/internal/storage/cache"
+	"main/internal/storage/memory"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+type observer struct {
+	mu     sync.Mutex
+	hits   map[string]int
+	misses map[string]int
+}
+
+func newObserver() *observer {
+	return &observer{hits: make(map[string]int), misses: make(map[string]int)}
+}
+
+func (o *observer) ObserveCacheLookup(entry string, hit bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if hit {
+		o.hits[entry]++
+	} else {
+		o.misses[entry]++
+	}
+}
+
+// backends returns every backend, the redis one runs against miniredis
+func backends() map[string]func(t *testing.T) cache.Backend {
+	return map[string]func(t *testing.T) cache.Backend{
+		"lru": func(t *testing.T) cache.Backend {
+			return cache.NewLRU(1000, time.Minute)
+		},
+		"redis": func(t *testing.T) cache.Backend {
+			server := miniredis.RunT(t)
+
+			return cache.NewRedis(redis.NewClient(&redis.Options{Addr: server.Addr()}), "test:", time.Minute)
+		},
+	}
+}
+
+// gate is storage whose armed access check blocks until released, it is used
+// to finish a check after a change made in the meantime
+type gate struct {
+	router.Storage
+	armed   atomic.Bool
+	entered chan struct{}
+	release chan struct{}
+	primary atomic.Bool
+}
+
+func newGate() *gate {
+	return &gate{Storage: memory.New(), entered: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (g *gate) CanUserReadNote(ctx context.Context, userId string, noteId int) (bool, error) {
+	allowed, err := g.Storage.CanUserReadNote(ctx, userId, noteId)
+
+	g.primary.Store(storage.PrimaryRequired(ctx))
+
+	if g.armed.CompareAndSwap(true, false) {
+		g.entered <- struct{}{}
+		<-g.release
+	}
+
+	return allowed, err
+}
+
+func TestCache(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, newBackend func(t *testing.T) cache.Backend)
+	}{
+		{"ChangeInvalidatesNote", testChangeInvalidatesNote},
+		{"RemovedMemberLosesAccess", testRemovedMemberLosesAccess},
+		{"CheckFinishedAfterInvalidationIsNotServed", testCheckFinishedAfterInvalidation},
+		{"ChecksOfUsersAreKeptApart", testChecksOfUsersAreKeptApart},
+		{"ResultsAreReadFromPrimary", testResultsAreReadFromPrimary},
+	}
+
+	for name, newBackend := range backends() {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				tt.run(t, newBackend)
+			})
+		}
+	}
+}
+
+func newCache(t *testing.T, s router.Storage, backend cache.Backend) (*cache.Storage, *observer) {
+	t.Helper()
+
+	o := newObserver()
+	c := cache.New(s, backend, o, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	t.Cleanup(func() { _ = c.Close() })
+
+	return c, o
+}
+
+func createUser(t *testing.T, s router.Storage) (string, string) {
+	t.Helper()
+
+	email := "user-" + uuid.NewString() + "@example.com"
+
+	id, err := s.CreateUser(context.Background(), email, "User", "hash")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	return id, email
+}
+
+func testChangeInvalidatesNote(t *testing.T, newBackend func(t *testing.T) cache.Backend) {
+	ctx := context.Background()
+	c, o := newCache(t, memory.New(), newBackend(t))
+	userId, _ := createUser(t, c)
+
+	id, err := c.CreateNote(ctx, "first", userId)
+	if err != nil {
+		t.Fatalf("CreateNote: %v", err)
+	}
+
+	for range 2 {
+		if _, err := c.GetNoteById(ctx, id); err != nil {
+			t.Fatalf("GetNoteById: %v", err)
+		}
+	}
+	if o.hits["note"] != 1 || o.misses["note"] != 1 {
+		t.Fatalf("lookups: got %d hits and %d misses, want 1 and 1", o.hits["note"], o.misses["note"])
+	}
+
+	if err := c.UpdateNoteTitle(ctx, id, "renamed"); err != nil {
+		t.Fatalf("UpdateNoteTitle: %v", err)
+	}
+
+	got, err := c.GetNoteById(ctx, id)
+	if err != nil {
+		t.Fatalf("GetNoteById: %v", err)
+	}
+	if got.Title != "renamed" {
+		t.Fatalf("GetNoteById after change: got title %q, want %q", got.Title, "renamed")
+	}
+}
+
+func testRemovedMemberLosesAccess(t *testing.T, newBackend func(t *testing.T) cache.Backend) {
+	ctx := context.Background()
+	c, _ := newCache(t, memory.New(), newBackend(t))
+
+	ownerId, _ := createUser(t, c)
+	memberId, memberEmail := createUser(t, c)
+
+	workspaceId, noteId := sharedNote(t, c, ownerId, memberId, memberEmail)
+
+	wantRead(t, c, memberId, noteId, true)
+	wantRead(t, c, memberId, noteId, true)
+
+	if err := c.RemoveWorkspaceMember(ctx, workspaceId, memberId); err != nil {
+		t.Fatalf("RemoveWorkspaceMember: %v", err)
+	}
+
+	wantRead(t, c, memberId, noteId, false)
+}
+
+// testCheckFinishedAfterInvalidation runs a check which reads "allowed" before
+// the member is removed and stores it after the removal invalidated the note
+func testCheckFinishedAfterInvalidation(t *testing.T, newBackend func(t *testing.T) cache.Backend) {
+	ctx := context.Background()
+	g := newGate()
+	c, _ := newCache(t, g, newBackend(t))
+
+	ownerId, _ := createUser(t, c)
+	memberId, memberEmail := createUser(t, c)
+
+	workspaceId, noteId := sharedNote(t, c, ownerId, memberId, memberEmail)
+
+	g.armed.Store(true)
+
+	done := make(chan bool)
+	go func() {
+		allowed, _ := c.CanUserReadNote(ctx, memberId, noteId)
+		done <- allowed
+	}()
+
+	<-g.entered
+
+	if err := c.RemoveWorkspaceMember(ctx, workspaceId, memberId); err != nil {
+		t.Fatalf("RemoveWorkspaceMember: %v", err)
+	}
+
+	close(g.release)
+
+	if !<-done {
+		t.Fatal("check started before removal: got denied, want allowed")
+	}
+
+	wantRead(t, c, memberId, noteId, false)
+}
+
+func testChecksOfUsersAreKeptApart(t *testing.T, newBackend func(t *testing.T) cache.Backend) {
+	ctx := context.Background()
+	c, o := newCache(t, memory.New(), newBackend(t))
+
+	ownerId, _ := createUser(t, c)
+	strangerId, _ := createUser(t, c)
+
+	noteId, err := c.CreateNote(ctx, "private", ownerId)
+	if err != nil {
+		t.Fatalf("CreateNote: %v", err)
+	}
+
+	// concurrent checks of different users are all cached
+	var wg sync.WaitGroup
+	for _, userId := range []string{ownerId, strangerId} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, _ = c.CanUserReadNote(ctx, userId, noteId)
+		}()
+	}
+	wg.Wait()
+
+	wantRead(t, c, ownerId, noteId, true)
+	wantRead(t, c, strangerId, noteId, false)
+
+	if o.hits["access"] != 2 {
+		t.Fatalf("access lookups: got %d hits, want 2", o.hits["access"])
+	}
+}
+
+func testResultsAreReadFromPrimary(t *testing.T, newBackend func(t *testing.T) cache.Backend) {
+	ctx := context.Background()
+	g := newGate()
+	c, _ := newCache(t, g, newBackend(t))
+
+	userId, _ := createUser(t, c)
+
+	noteId, err := c.CreateNote(ctx, "note", userId)
+	if err != nil {
+		t.Fatalf("CreateNote: %v", err)
+	}
+
+	wantRead(t, c, userId, noteId, true)
+
+	if !g.primary.Load() {
+		t.Fatal("cached check wasn't read from primary")
+	}
+}
+
+// sharedNote creates workspace of the owner with a note and the member in it
+func sharedNote(t *testing.T, s router.Storage, ownerId, memberId, memberEmail string) (string, int) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	workspaceId, err := s.CreateWorkspace(ctx, "team", ownerId)
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+
+	noteId, err := s.CreateWorkspaceNote(ctx, "shared", workspaceId)
+	if err != nil {
+		t.Fatalf("CreateWorkspaceNote: %v", err)
+	}
+
+	invitationId, err := s.CreateWorkspaceInvitation(ctx, workspaceId, memberEmail, workspace.RoleMember, ownerId, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("CreateWorkspaceInvitation: %v", err)
+	}
+
+	if _, err := s.AcceptWorkspaceInvitation(ctx, invitationId, memberEmail, memberId); err != nil {
+		t.Fatalf("AcceptWorkspaceInvitation: %v", err)
+	}
+
+	return workspaceId, noteId
+}
+
+func wantRead(t *testing.T, s router.Storage, userId string, noteId int, want bool) {
+	t.Helper()
+
+	got, err := s.CanUserReadNote(context.Background(), userId, noteId)
+	if err != nil {
+		t.Fatalf("CanUserReadNote: %v", err)
+	}
+	if got != want {
+		t.Fatalf("CanUserReadNote of note %d: got %v, want %v", noteId, got, want)
+	}
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis shares entries between instances, so invalidation made by one of
+// them is seen by all
+type Redis struct {
+	client redis.UniversalClient
+	prefix string
+	ttl    time.Duration
+}
+
+func NewRedis(client redis.UniversalClient, prefix string, ttl time.Duration) *Redis {
+	return &Redis{client: client, prefix: prefix, ttl: ttl}
+}
+
+func (c *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	const op = "cache.Redis.Get"
+
+	value, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return value, true, nil
+}
+
+func (c *Redis) Set(ctx context.Context, key string, value []byte) error {
+	const op = "cache.Redis.Set"
+
+	if err := c.client.Set(ctx, c.prefix+key, value, c.ttl).Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (c *Redis) Add(ctx context.Context, key string, value []byte) (bool, error) {
+	const op = "cache.Redis.Add"
+
+	added, err := c.client.SetNX(ctx, c.prefix+key, value, c.ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return added, nil
+}
+
+func (c *Redis) Close() error {
+	return c.client.Close()
+}
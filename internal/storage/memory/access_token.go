@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"main/internal/auth/denylist"
+	"main/internal/storage"
+	"time"
+)
+
+type revokedToken struct {
+	userId    string
+	expiresAt time.Time
+}
+
+func (s *Storage) RevokeAccessToken(ctx context.Context, jti, userId string, expiresAt time.Time) error {
+	const op = "storage.memory.RevokeAccessToken"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	// token revoked twice keeps its first entry
+	if _, ok := s.revokedTokens[jti]; !ok {
+		s.revokedTokens[jti] = revokedToken{userId: userId, expiresAt: expiresAt}
+	}
+
+	return nil
+}
+
+func (s *Storage) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	const op = "storage.memory.IsAccessTokenRevoked"
+
+	if err := s.lock(ctx); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	_, ok := s.revokedTokens[jti]
+
+	return ok, nil
+}
+
+func (s *Storage) DeleteExpiredRevokedAccessTokens(ctx context.Context) (int, error) {
+	const op = "storage.memory.DeleteExpiredRevokedAccessTokens"
+
+	if err := s.lock(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	deletedAt := now()
+
+	return countDeleted(s.revokedTokens, func(token revokedToken) bool {
+		return token.expiresAt.Before(deletedAt)
+	}), nil
+}
+
+func (s *Storage) GetUserState(ctx context.Context, userId string) (denylist.UserState, error) {
+	const op = "storage.memory.GetUserState"
+
+	if err := s.lock(ctx); err != nil {
+		return denylist.UserState{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	u, ok := s.users[userId]
+	if !ok {
+		return denylist.UserState{}, storage.ErrUserNotFound
+	}
+
+	return denylist.UserState{
+		TokensValidAfter: u.TokensValidAfter,
+		DisabledAt:       u.DisabledAt,
+	}, nil
+}
+
+func (s *Storage) SetTokensValidAfter(ctx context.Context, userId string, validAfter time.Time) error {
+	const op = "storage.memory.SetTokensValidAfter"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	// updated_at is left untouched, like in postgres storage
+	u, ok := s.users[userId]
+	if !ok {
+		return storage.ErrUserNotFound
+	}
+	u.TokensValidAfter = &validAfter
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"main/internal/models/user"
+	"main/internal/storage"
+	"slices"
+	"sort"
+	"strings"
+)
+
+func (s *Storage) ListAccounts(ctx context.Context, query string, limit, offset int) ([]user.Account, error) {
+	const op = "storage.memory.ListAccounts"
+
+	if err := s.lock(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	// matching email or name case-insensitively
+	query = strings.ToLower(query)
+
+	var users []*userRecord
+	for _, u := range s.users {
+		if strings.Contains(strings.ToLower(u.Email), query) || strings.Contains(strings.ToLower(u.Name), query) {
+			users = append(users, u)
+		}
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].createdAt.Equal(users[j].createdAt) {
+			return users[i].ID < users[j].ID
+		}
+		return users[i].createdAt.After(users[j].createdAt)
+	})
+
+	accounts := []user.Account{}
+	for _, u := range page(users, limit, offset) {
+		accounts = append(accounts, s.account(u))
+	}
+
+	return accounts, nil
+}
+
+func (s *Storage) GetAccount(ctx context.Context, id string) (user.Account, error) {
+	const op = "storage.memory.GetAccount"
+
+	if err := s.lock(ctx); err != nil {
+		return user.Account{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	u, ok := s.users[id]
+	if !ok {
+		return user.Account{}, storage.ErrUserNotFound
+	}
+
+	return s.account(u), nil
+}
+
+func (s *Storage) GetUserUsage(ctx context.Context, id string) (user.Usage, error) {
+	const op = "storage.memory.GetUserUsage"
+
+	if err := s.lock(ctx); err != nil {
+		return user.Usage{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	var usage user.Usage
+	for _, n := range s.notes {
+		if n.UserId != nil && *n.UserId == id {
+			usage.Notes++
+			usage.Nodes += len(s.noteNodes(n.Id))
+		}
+	}
+
+	return usage, nil
+}
+
+func (s *Storage) DisableUser(ctx context.Context, id string) error {
+	return s.changeUser(ctx, "storage.memory.DisableUser", id, func(u *userRecord) {
+		if u.DisabledAt == nil {
+			disabledAt := now()
+			u.DisabledAt = &disabledAt
+		}
+	})
+}
+
+func (s *Storage) EnableUser(ctx context.Context, id string) error {
+	return s.changeUser(ctx, "storage.memory.EnableUser", id, func(u *userRecord) {
+		u.DisabledAt = nil
+	})
+}
+
+func (s *Storage) SetUserRole(ctx context.Context, id, role string) error {
+	return s.changeUser(ctx, "storage.memory.SetUserRole", id, func(u *userRecord) {
+		u.Role = role
+	})
+}
+
+// GrantRoleByEmails sets role to every existing user with one of emails
+func (s *Storage) GrantRoleByEmails(ctx context.Context, emails []string, role string) (int, error) {
+	const op = "storage.memory.GrantRoleByEmails"
+
+	if err := s.lock(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	granted := 0
+	for _, u := range s.users {
+		if slices.Contains(emails, u.Email) && u.Role != role {
+			u.Role = role
+			u.UpdatedAt = formatTime(now())
+			granted++
+		}
+	}
+
+	return granted, nil
+}
+
+func (s *Storage) account(u *userRecord) user.Account {
+	return user.Account{
+		ID:               u.ID,
+		Email:            u.Email,
+		Name:             u.Name,
+		Role:             u.Role,
+		EmailVerifiedAt:  u.EmailVerifiedAt,
+		DisabledAt:       u.DisabledAt,
+		TwoFactorEnabled: s.isTwoFactorEnabled(u.ID),
+		CreatedAt:        u.CreatedAt,
+	}
+}
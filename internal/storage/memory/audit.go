@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"main/internal/audit"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+)
+
+func (s *Storage) RecordAuditEvent(ctx context.Context, event audit.Event) error {
+	const op = "storage.memory.RecordAuditEvent"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	s.lastAudit++
+	event.Id = s.lastAudit
+	event.OccurredAt = now()
+	event.Details = maps.Clone(event.Details)
+
+	s.auditEvents = append(s.auditEvents, event)
+
+	return nil
+}
+
+func (s *Storage) GetAuditEvents(ctx context.Context, filter audit.Filter) ([]audit.Event, error) {
+	const op = "storage.memory.GetAuditEvents"
+
+	if err := s.lock(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	var events []audit.Event
+	for _, event := range s.auditEvents {
+		if matchAuditEvent(event, filter) {
+			events = append(events, event)
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].OccurredAt.Equal(events[j].OccurredAt) {
+			return events[i].Id > events[j].Id
+		}
+		return events[i].OccurredAt.After(events[j].OccurredAt)
+	})
+
+	return page(events, filter.Limit, filter.Offset), nil
+}
+
+func (s *Storage) DeleteAuditEventsBefore(ctx context.Context, before time.Time) (int, error) {
+	const op = "storage.memory.DeleteAuditEventsBefore"
+
+	if err := s.lock(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	count := len(s.auditEvents)
+
+	s.auditEvents = slices.DeleteFunc(s.auditEvents, func(event audit.Event) bool {
+		return event.OccurredAt.Before(before)
+	})
+
+	return count - len(s.auditEvents), nil
+}
+
+// matchAuditEvent applies non-zero fields of filter to the event
+func matchAuditEvent(event audit.Event, filter audit.Filter) bool {
+	actor := ""
+	if event.ActorId != nil {
+		actor = *event.ActorId
+	}
+
+	switch {
+	case filter.UserId != "" && actor != filter.UserId &&
+		(event.TargetType != audit.TargetUser || event.TargetId != filter.UserId):
+		return false
+	case filter.ActorId != "" && actor != filter.ActorId:
+		return false
+	case filter.Action != "" && event.Action != filter.Action:
+		return false
+	case filter.TargetType != "" && event.TargetType != filter.TargetType:
+		return false
+	case filter.TargetId != "" && event.TargetId != filter.TargetId:
+		return false
+	case filter.From != nil && event.OccurredAt.Before(*filter.From):
+		return false
+	case filter.To != nil && !event.OccurredAt.Before(*filter.To):
+		return false
+	}
+
+	return true
+}
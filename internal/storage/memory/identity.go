@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"main/internal/auth/oidc"
+	"main/internal/storage"
+	"time"
+)
+
+type identityKey struct {
+	provider string
+	subject  string
+}
+
+func (s *Storage) CreateOIDCState(ctx context.Context, state oidc.State) error {
+	const op = "storage.memory.CreateOIDCState"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	if _, ok := s.oidcStates[state.State]; ok {
+		return fmt.Errorf("%s: state already exists", op)
+	}
+	s.oidcStates[state.State] = state
+
+	return nil
+}
+
+func (s *Storage) ConsumeOIDCState(ctx context.Context, state string) (oidc.State, error) {
+	const op = "storage.memory.ConsumeOIDCState"
+
+	if err := s.lock(ctx); err != nil {
+		return oidc.State{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	// deleting state so it can be used only once
+	stored, ok := s.oidcStates[state]
+	if !ok || !stored.ExpiresAt.After(now()) {
+		return oidc.State{}, storage.ErrOIDCStateNotFound
+	}
+	delete(s.oidcStates, state)
+
+	return stored, nil
+}
+
+func (s *Storage) DeleteExpiredOIDCStates(ctx context.Context) (int, error) {
+	const op = "storage.memory.DeleteExpiredOIDCStates"
+
+	if err := s.lock(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	deletedAt := now()
+
+	return countDeleted(s.oidcStates, func(state oidc.State) bool {
+		return state.ExpiresAt.Before(deletedAt)
+	}), nil
+}
+
+func (s *Storage) GetUserIdByIdentity(ctx context.Context, provider, subject string) (string, error) {
+	const op = "storage.memory.GetUserIdByIdentity"
+
+	if err := s.lock(ctx); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	userId, ok := s.identities[identityKey{provider: provider, subject: subject}]
+	if !ok {
+		return "", storage.ErrIdentityNotFound
+	}
+
+	return userId, nil
+}
+
+func (s *Storage) LinkUserIdentity(ctx context.Context, userId string, identity oidc.Identity) error {
+	const op = "storage.memory.LinkUserIdentity"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[userId]; !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	// identity can belong to a single user only
+	key := identityKey{provider: identity.Provider, subject: identity.Subject}
+	if _, ok := s.identities[key]; ok {
+		return fmt.Errorf("%s: identity is already linked", op)
+	}
+	s.identities[key] = userId
+
+	return nil
+}
+
+func (s *Storage) CreateUserWithIdentity(ctx context.Context, identity oidc.Identity) (string, error) {
+	const op = "storage.memory.CreateUserWithIdentity"
+
+	if err := s.lock(ctx); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	key := identityKey{provider: identity.Provider, subject: identity.Subject}
+	if _, ok := s.identities[key]; ok {
+		return "", fmt.Errorf("%s: identity is already linked", op)
+	}
+
+	// creating user without password, it can be set later with password reset
+	var emailVerifiedAt *time.Time
+	if identity.EmailVerified {
+		verifiedAt := now()
+		emailVerifiedAt = &verifiedAt
+	}
+
+	id, err := s.createUser(identity.Email, identity.Name, "", emailVerifiedAt)
+	if err != nil {
+		return "", err
+	}
+
+	// linking identity
+	s.identities[key] = id
+
+	return id, nil
+}
@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"main/internal/scheduler"
+	"sort"
+	"time"
+)
+
+// TryJobLock takes lock of the job in this process, it's enough since memory
+// storage can't be shared between replicas
+func (s *Storage) TryJobLock(ctx context.Context, job string) (func(), bool, error) {
+	const op = "storage.memory.TryJobLock"
+
+	if err := s.lock(ctx); err != nil {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	if s.jobLocks[job] {
+		return nil, false, nil
+	}
+	s.jobLocks[job] = true
+
+	unlock := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		delete(s.jobLocks, job)
+	}
+
+	return unlock, true, nil
+}
+
+func (s *Storage) StartJobRun(ctx context.Context, job string, scheduledAt time.Time, instance string) (int64, bool, error) {
+	const op = "storage.memory.StartJobRun"
+
+	if err := s.lock(ctx); err != nil {
+		return 0, false, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	// run of the scheduled time is recorded once
+	for _, run := range s.jobRuns {
+		if run.Job == job && run.ScheduledAt.Equal(scheduledAt) {
+			return 0, false, nil
+		}
+	}
+
+	s.lastJobRun++
+	s.jobRuns[s.lastJobRun] = &scheduler.Run{
+		Id:          s.lastJobRun,
+		Job:         job,
+		ScheduledAt: scheduledAt,
+		StartedAt:   now(),
+		Status:      scheduler.StatusRunning,
+		Instance:    instance,
+	}
+
+	return s.lastJobRun, true, nil
+}
+
+func (s *Storage) FinishJobRun(ctx context.Context, id int64, status string, attempts int, runErr string) error {
+	const op = "storage.memory.FinishJobRun"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	run, ok := s.jobRuns[id]
+	if !ok {
+		return nil
+	}
+
+	finishedAt := now()
+	durationMs := finishedAt.Sub(run.StartedAt).Milliseconds()
+
+	run.Status = status
+	run.Attempts = attempts
+	run.Error = runErr
+	run.FinishedAt = &finishedAt
+	run.DurationMs = &durationMs
+
+	return nil
+}
+
+func (s *Storage) GetJobRuns(ctx context.Context, job, status string, limit, offset int) ([]scheduler.Run, error) {
+	const op = "storage.memory.GetJobRuns"
+
+	if err := s.lock(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	var runs []scheduler.Run
+	for _, run := range s.jobRuns {
+		if (job == "" || run.Job == job) && (status == "" || run.Status == status) {
+			runs = append(runs, *run)
+		}
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		if runs[i].StartedAt.Equal(runs[j].StartedAt) {
+			return runs[i].Id > runs[j].Id
+		}
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
+
+	return page(runs, limit, offset), nil
+}
+
+func (s *Storage) DeleteJobRunsBefore(ctx context.Context, before time.Time) (int, error) {
+	const op = "storage.memory.DeleteJobRunsBefore"
+
+	if err := s.lock(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	return countDeleted(s.jobRuns, func(run *scheduler.Run) bool {
+		return run.StartedAt.Before(before) && run.Status != scheduler.StatusRunning
+	}), nil
+}
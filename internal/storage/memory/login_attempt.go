@@ -0,0 +1,169 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"main/internal/auth/lockout"
+	"main/internal/storage"
+	"slices"
+	"time"
+)
+
+func (s *Storage) GetLoginAttempt(ctx context.Context, key string) (lockout.Attempt, error) {
+	const op = "storage.memory.GetLoginAttempt"
+
+	if err := s.lock(ctx); err != nil {
+		return lockout.Attempt{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	attempt, ok := s.loginAttempts[key]
+	if !ok {
+		return lockout.Attempt{}, storage.ErrLoginAttemptNotFound
+	}
+
+	return *attempt, nil
+}
+
+func (s *Storage) ReserveLoginAttempt(ctx context.Context, key string, until time.Time) (lockout.Attempt, bool, error) {
+	const op = "storage.memory.ReserveLoginAttempt"
+
+	if err := s.lock(ctx); err != nil {
+		return lockout.Attempt{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	reservedAt := now()
+
+	attempt, ok := s.loginAttempts[key]
+	if !ok {
+		attempt = &lockout.Attempt{Key: key, LastFailureAt: reservedAt}
+		s.loginAttempts[key] = attempt
+	}
+
+	if attempt.LockedUntil != nil && attempt.LockedUntil.After(reservedAt) {
+		return *attempt, false, nil
+	}
+	attempt.LockedUntil = &until
+
+	return *attempt, true, nil
+}
+
+func (s *Storage) AddLoginFailure(ctx context.Context, key string, window time.Duration) (lockout.Attempt, error) {
+	const op = "storage.memory.AddLoginFailure"
+
+	if err := s.lock(ctx); err != nil {
+		return lockout.Attempt{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	failedAt := now()
+
+	attempt, ok := s.loginAttempts[key]
+	if !ok {
+		attempt = &lockout.Attempt{Key: key}
+		s.loginAttempts[key] = attempt
+	}
+
+	// counting from scratch when the last failure is outside of window
+	if attempt.LastFailureAt.Before(failedAt.Add(-window)) {
+		attempt.Failures = 1
+	} else {
+		attempt.Failures++
+	}
+	attempt.LastFailureAt = failedAt
+
+	return *attempt, nil
+}
+
+func (s *Storage) LockLoginKey(ctx context.Context, key string, until time.Time) error {
+	const op = "storage.memory.LockLoginKey"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	if attempt, ok := s.loginAttempts[key]; ok {
+		attempt.LockedUntil = &until
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteLoginAttempt(ctx context.Context, key string) error {
+	const op = "storage.memory.DeleteLoginAttempt"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	delete(s.loginAttempts, key)
+
+	return nil
+}
+
+func (s *Storage) RecordLoginFailure(ctx context.Context, key string, at time.Time) error {
+	const op = "storage.memory.RecordLoginFailure"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	s.loginFailures[key] = append(s.loginFailures[key], at)
+
+	return nil
+}
+
+func (s *Storage) GetLoginFailures(ctx context.Context, key string, since time.Time) ([]time.Time, error) {
+	const op = "storage.memory.GetLoginFailures"
+
+	if err := s.lock(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	failures := []time.Time{}
+	for _, at := range s.loginFailures[key] {
+		if !at.Before(since) {
+			failures = append(failures, at)
+		}
+	}
+	slices.SortFunc(failures, time.Time.Compare)
+
+	return failures, nil
+}
+
+// DeleteStaleLoginAttempts deletes attempts and ip failures which are out of window
+func (s *Storage) DeleteStaleLoginAttempts(ctx context.Context, window time.Duration) (int, error) {
+	const op = "storage.memory.DeleteStaleLoginAttempts"
+
+	if err := s.lock(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	deletedAt := now()
+
+	deleted := countDeleted(s.loginAttempts, func(attempt *lockout.Attempt) bool {
+		return attempt.LastFailureAt.Before(deletedAt.Add(-window)) &&
+			(attempt.LockedUntil == nil || attempt.LockedUntil.Before(deletedAt))
+	})
+
+	for key, failures := range s.loginFailures {
+		kept := slices.DeleteFunc(failures, func(at time.Time) bool {
+			return at.Before(deletedAt.Add(-window))
+		})
+		deleted += len(failures) - len(kept)
+
+		if len(kept) == 0 {
+			delete(s.loginFailures, key)
+		} else {
+			s.loginFailures[key] = kept
+		}
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"context"
+	"main/internal/audit"
+	"main/internal/auth"
+	"main/internal/auth/lockout"
+	"main/internal/auth/oidc"
+	"main/internal/auth/passkey"
+	"main/internal/auth/pat"
+	"main/internal/models/note"
+	"main/internal/models/workspace"
+	"main/internal/scheduler"
+	"main/internal/webhook"
+	"sync"
+	"time"
+)
+
+// Storage keeps everything in process memory. It follows the semantics of
+// the postgres storage, including foreign keys cascades, and is safe for
+// concurrent use. Data is lost when the process exits
+type Storage struct {
+	mu sync.Mutex
+
+	notes      map[int]*note.Note
+	nodes      map[int]*note.NoteNode
+	lastNote   int
+	lastNode   int
+	users      map[string]*userRecord
+	workspaces map[string]*workspace.Workspace
+	members    map[string]map[string]*memberRecord
+	invites    map[string]*workspace.Invitation
+
+	refreshTokens map[string]*auth.RefreshToken
+	revokedTokens map[string]revokedToken
+	oneTimeTokens []*oneTimeToken
+	totps         map[string]*auth.TOTP
+	recoveryCodes map[string][]*recoveryCode
+	oidcStates    map[string]oidc.State
+	identities    map[identityKey]string
+	sessions      map[string]passkey.Session
+	passkeys      map[string]*passkey.Passkey
+	pats          map[string]*pat.PersonalAccessToken
+	loginAttempts map[string]*lockout.Attempt
+	loginFailures map[string][]time.Time
+
+	auditEvents  []audit.Event
+	lastAudit    int64
+	webhooks     map[string]*webhook.Webhook
+	deliveries   map[int64]*webhook.Delivery
+	lastDelivery int64
+	jobRuns      map[int64]*scheduler.Run
+	lastJobRun   int64
+	jobLocks     map[string]bool
+}
+
+func New() *Storage {
+	return &Storage{
+		notes:         map[int]*note.Note{},
+		nodes:         map[int]*note.NoteNode{},
+		users:         map[string]*userRecord{},
+		workspaces:    map[string]*workspace.Workspace{},
+		members:       map[string]map[string]*memberRecord{},
+		invites:       map[string]*workspace.Invitation{},
+		refreshTokens: map[string]*auth.RefreshToken{},
+		revokedTokens: map[string]revokedToken{},
+		totps:         map[string]*auth.TOTP{},
+		recoveryCodes: map[string][]*recoveryCode{},
+		oidcStates:    map[string]oidc.State{},
+		identities:    map[identityKey]string{},
+		sessions:      map[string]passkey.Session{},
+		passkeys:      map[string]*passkey.Passkey{},
+		pats:          map[string]*pat.PersonalAccessToken{},
+		loginAttempts: map[string]*lockout.Attempt{},
+		loginFailures: map[string][]time.Time{},
+		webhooks:      map[string]*webhook.Webhook{},
+		deliveries:    map[int64]*webhook.Delivery{},
+		jobRuns:       map[int64]*scheduler.Run{},
+		jobLocks:      map[string]bool{},
+	}
+}
+
+// Close is a no-op, it's here so the storage can replace postgres one
+func (s *Storage) Close() error {
+	return nil
+}
+
+// lock takes the storage mutex unless ctx is already done, so cancelled
+// requests fail the same way as with database backends
+func (s *Storage) lock(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+
+	return nil
+}
+
+// now is the time of a change, it's truncated to microseconds like postgres timestamps
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// countDeleted deletes entries of m matching expired and returns their count
+func countDeleted[K comparable, V any](m map[K]V, expired func(V) bool) int {
+	count := 0
+	for key, value := range m {
+		if expired(value) {
+			delete(m, key)
+			count++
+		}
+	}
+
+	return count
+}
+
+// page returns the part of items selected by limit and offset
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+
+	if limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+
+	return items
+}
@@ -0,0 +1,14 @@
+package memory_test
+
+import (
+	"main/internal/router"
+	"main/internal/storage/memory"
+	"main/internal/storage/storagetest"
+	"testing"
+)
+
+func TestContract(t *testing.T) {
+	storagetest.RunContract(t, func(t *testing.T) router.Storage {
+		return memory.New()
+	})
+}
@@ -0,0 +1,174 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"main/internal/models/note"
+	"main/internal/storage"
+	"main/internal/webhook"
+	"sort"
+)
+
+func (s *Storage) AddNoteNode(ctx context.Context, noteId int, contentType string, content string) (int, error) {
+	const op = "storage.memory.AddNoteNode"
+
+	if err := s.lock(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	if _, ok := s.notes[noteId]; !ok {
+		return 0, storage.ErrNoteNotFound
+	}
+
+	id := s.addNode(noteId, note.ContentType(contentType), content)
+
+	s.touchNote(noteId)
+	s.enqueueNoteEvent(webhook.EventNodeCreated, webhook.Data{NoteId: noteId, NodeId: id})
+
+	return id, nil
+}
+
+// addNode appends node to the end of the note
+func (s *Storage) addNode(noteId int, contentType note.ContentType, content string) int {
+	s.lastNode++
+
+	s.nodes[s.lastNode] = &note.NoteNode{
+		Id:          s.lastNode,
+		NoteId:      noteId,
+		Order:       len(s.noteNodes(noteId)),
+		ContentType: contentType,
+		Content:     content,
+	}
+
+	return s.lastNode
+}
+
+func (s *Storage) DeleteNoteNode(ctx context.Context, id int) error {
+	const op = "storage.memory.DeleteNoteNode"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	node, ok := s.nodes[id]
+	if !ok {
+		return storage.ErrNoteNodeNotFound
+	}
+	delete(s.nodes, id)
+
+	// update all note nodes' order after deleted node
+	for _, other := range s.noteNodes(node.NoteId) {
+		if other.Order > node.Order {
+			other.Order--
+		}
+	}
+
+	s.touchNote(node.NoteId)
+	s.enqueueNoteEvent(webhook.EventNodeDeleted, webhook.Data{NoteId: node.NoteId, NodeId: id})
+
+	return nil
+}
+
+func (s *Storage) UpdateNoteNodeContent(ctx context.Context, id int, content string) error {
+	const op = "storage.memory.UpdateNoteNodeContent"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	node, ok := s.nodes[id]
+	if !ok {
+		return storage.ErrNoteNodeNotFound
+	}
+	node.Content = content
+
+	s.touchNote(node.NoteId)
+	s.enqueueNoteEvent(webhook.EventNodeUpdated, webhook.Data{NoteId: node.NoteId, NodeId: id})
+
+	return nil
+}
+
+func (s *Storage) CanUserEditNoteNode(ctx context.Context, userId string, noteNodeId int) (bool, error) {
+	const op = "storage.memory.CanUserEditNoteNode"
+
+	if err := s.lock(ctx); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	node, ok := s.nodes[noteNodeId]
+	if !ok {
+		return false, nil
+	}
+	n, ok := s.notes[node.NoteId]
+
+	return ok && s.canEdit(userId, n), nil
+}
+
+func (s *Storage) CanUserReadNoteNode(ctx context.Context, userId string, noteNodeId int) (bool, error) {
+	const op = "storage.memory.CanUserReadNoteNode"
+
+	if err := s.lock(ctx); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	node, ok := s.nodes[noteNodeId]
+	if !ok {
+		return false, nil
+	}
+	n, ok := s.notes[node.NoteId]
+
+	return ok && s.canRead(userId, n), nil
+}
+
+func (s *Storage) GetNodeById(ctx context.Context, id int) (note.NoteNode, error) {
+	const op = "storage.memory.GetNodeById"
+
+	if err := s.lock(ctx); err != nil {
+		return note.NoteNode{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	node, ok := s.nodes[id]
+	if !ok {
+		return note.NoteNode{}, storage.ErrNoteNodeNotFound
+	}
+
+	return *node, nil
+}
+
+func (s *Storage) GetAllNotesNodes(ctx context.Context, noteId int) ([]note.NoteNode, error) {
+	const op = "storage.memory.GetAllNotesNodes"
+
+	if err := s.lock(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	var nodes []note.NoteNode
+	for _, node := range s.noteNodes(noteId) {
+		nodes = append(nodes, *node)
+	}
+
+	return nodes, nil
+}
+
+// noteNodes returns nodes of the note sorted by order
+func (s *Storage) noteNodes(noteId int) []*note.NoteNode {
+	var nodes []*note.NoteNode
+	for _, node := range s.nodes {
+		if node.NoteId == noteId {
+			nodes = append(nodes, node)
+		}
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Order < nodes[j].Order
+	})
+
+	return nodes
+}
@@ -0,0 +1,324 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"main/internal/models/note"
+	"main/internal/models/workspace"
+	"main/internal/storage"
+	"main/internal/webhook"
+	"sort"
+)
+
+func (s *Storage) CreateNote(ctx context.Context, noteTitle string, userId string) (int, error) {
+	const op = "storage.memory.CreateNote"
+
+	if err := s.lock(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[userId]; !ok {
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	return s.createNote(noteTitle, &userId, nil), nil
+}
+
+func (s *Storage) CreateWorkspaceNote(ctx context.Context, noteTitle string, workspaceId string) (int, error) {
+	const op = "storage.memory.CreateWorkspaceNote"
+
+	if err := s.lock(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	if _, ok := s.workspaces[workspaceId]; !ok {
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrWorkspaceNotFound)
+	}
+
+	return s.createNote(noteTitle, nil, &workspaceId), nil
+}
+
+// createNote creates note owned by user or workspace with blank text note node
+func (s *Storage) createNote(title string, userId, workspaceId *string) int {
+	s.lastNote++
+	createdAt := now()
+
+	s.notes[s.lastNote] = &note.Note{
+		Id:          s.lastNote,
+		UserId:      userId,
+		WorkspaceId: workspaceId,
+		Title:       title,
+		CreatedAt:   createdAt,
+		UpdatedAt:   createdAt,
+	}
+	s.addNode(s.lastNote, note.ContentTypeText, "")
+
+	s.enqueueNoteEvent(webhook.EventNoteCreated, webhook.Data{NoteId: s.lastNote})
+
+	return s.lastNote
+}
+
+func (s *Storage) GetUserNotes(ctx context.Context, userId string) ([]note.NotePreview, error) {
+	const op = "storage.memory.GetUserNotes"
+
+	if err := s.lock(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	return s.selectNotes(func(n *note.Note) bool {
+		return s.canRead(userId, n)
+	}), nil
+}
+
+func (s *Storage) GetWorkspaceNotes(ctx context.Context, workspaceId string) ([]note.NotePreview, error) {
+	const op = "storage.memory.GetWorkspaceNotes"
+
+	if err := s.lock(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	return s.selectNotes(func(n *note.Note) bool {
+		return n.WorkspaceId != nil && *n.WorkspaceId == workspaceId
+	}), nil
+}
+
+// selectNotes returns previews of matching notes, recently updated first
+func (s *Storage) selectNotes(match func(n *note.Note) bool) []note.NotePreview {
+	notes := []note.NotePreview{}
+	for _, n := range s.notes {
+		if match(n) {
+			notes = append(notes, preview(n))
+		}
+	}
+
+	sort.Slice(notes, func(i, j int) bool {
+		if notes[i].UpdatedAt.Equal(notes[j].UpdatedAt) {
+			return notes[i].Id > notes[j].Id
+		}
+		return notes[i].UpdatedAt.After(notes[j].UpdatedAt)
+	})
+
+	return notes
+}
+
+func (s *Storage) GetNoteById(ctx context.Context, id int) (note.Note, error) {
+	const op = "storage.memory.GetNoteById"
+
+	if err := s.lock(ctx); err != nil {
+		return note.Note{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	// getting note by id without nodes
+	n, ok := s.notes[id]
+	if !ok {
+		return note.Note{}, storage.ErrNoteNotFound
+	}
+
+	return *n, nil
+}
+
+func (s *Storage) UpdateNoteTitle(ctx context.Context, id int, title string) error {
+	return s.changeNote(ctx, "storage.memory.UpdateNoteTitle", webhook.EventNoteUpdated, id, func(n *note.Note) {
+		n.Title = title
+		n.UpdatedAt = now()
+	})
+}
+
+func (s *Storage) ArchiveNote(ctx context.Context, id int) error {
+	return s.changeNote(ctx, "storage.memory.ArchiveNote", webhook.EventNoteArchived, id, func(n *note.Note) {
+		archivedAt := now()
+		n.ArchivedAt = &archivedAt
+	})
+}
+
+func (s *Storage) UnarchiveNote(ctx context.Context, id int) error {
+	return s.changeNote(ctx, "storage.memory.UnarchiveNote", webhook.EventNoteUnarchived, id, func(n *note.Note) {
+		n.ArchivedAt = nil
+	})
+}
+
+func (s *Storage) DeleteNote(ctx context.Context, id int) error {
+	return s.changeNote(ctx, "storage.memory.DeleteNote", webhook.EventNoteDeleted, id, func(n *note.Note) {
+		s.deleteNote(n.Id)
+	})
+}
+
+// changeNote applies change to the note and queues webhook event. Event of
+// deleted note is queued before deletion, while note is still there to find its webhooks
+func (s *Storage) changeNote(ctx context.Context, op, event string, id int, change func(n *note.Note)) error {
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	n, ok := s.notes[id]
+	if !ok {
+		return storage.ErrNoteNotFound
+	}
+
+	s.enqueueNoteEvent(event, webhook.Data{NoteId: id})
+	change(n)
+
+	return nil
+}
+
+// deleteNote deletes note with its nodes
+func (s *Storage) deleteNote(id int) {
+	for nodeId, node := range s.nodes {
+		if node.NoteId == id {
+			delete(s.nodes, nodeId)
+		}
+	}
+	delete(s.notes, id)
+}
+
+// UpdateFullNote updates title of the note and content of the given nodes,
+// it returns the number of changed rows
+func (s *Storage) UpdateFullNote(ctx context.Context, id int, fullNote note.Note) (int, error) {
+	const op = "storage.memory.UpdateFullNote"
+
+	if err := s.lock(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	var rowsAffected int
+
+	// updating note title
+	if n, ok := s.notes[id]; ok {
+		n.Title = fullNote.Title
+		n.UpdatedAt = now()
+		rowsAffected++
+	}
+
+	// iterating over note nodes and updating content
+	for _, fullNode := range fullNote.Nodes {
+		if node, ok := s.nodes[fullNode.Id]; ok {
+			node.Content = fullNode.Content
+			rowsAffected++
+		}
+	}
+
+	s.enqueueNoteEvent(webhook.EventNoteUpdated, webhook.Data{NoteId: id})
+
+	return rowsAffected, nil
+}
+
+func (s *Storage) UpdateNoteNodeOrder(ctx context.Context, noteId int, oldOrder int, newOrder int) error {
+	const op = "storage.memory.UpdateNoteNodeOrder"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	nodes := s.noteNodes(noteId)
+
+	// check if note node with new_order out of bounds
+	if len(nodes) == 0 {
+		return fmt.Errorf("%s: %w: no note nodes found with note_id=%d", op, storage.ErrNoteNodeNotFound, noteId)
+	}
+	if newOrder < 0 || newOrder >= len(nodes) {
+		return fmt.Errorf("%s: %w: newOrder %d is out of range (must be between 0 and %d)", op, storage.ErrNoteNodeNotFound, newOrder, len(nodes)-1)
+	}
+
+	// check if note node with old_order exists
+	if oldOrder < 0 || oldOrder >= len(nodes) {
+		return fmt.Errorf("%s: %w: no note node found with note_id=%d and order=%d", op, storage.ErrNoteNodeNotFound, noteId, oldOrder)
+	}
+
+	// update all note nodes' order between old_order and new_order
+	for _, node := range nodes {
+		switch {
+		case node.Order == oldOrder:
+			node.Order = newOrder
+		case node.Order > oldOrder && node.Order <= newOrder:
+			node.Order--
+		case node.Order < oldOrder && node.Order >= newOrder:
+			node.Order++
+		}
+	}
+
+	s.touchNote(noteId)
+	s.enqueueNoteEvent(webhook.EventNoteUpdated, webhook.Data{NoteId: noteId})
+
+	return nil
+}
+
+func (s *Storage) CanUserEditNote(ctx context.Context, userId string, noteId int) (bool, error) {
+	const op = "storage.memory.CanUserEditNote"
+
+	if err := s.lock(ctx); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	n, ok := s.notes[noteId]
+
+	return ok && s.canEdit(userId, n), nil
+}
+
+func (s *Storage) CanUserReadNote(ctx context.Context, userId string, noteId int) (bool, error) {
+	const op = "storage.memory.CanUserReadNote"
+
+	if err := s.lock(ctx); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	n, ok := s.notes[noteId]
+
+	return ok && s.canRead(userId, n), nil
+}
+
+// canRead reports whether user owns the note or is a member of its workspace
+func (s *Storage) canRead(userId string, n *note.Note) bool {
+	if n.UserId != nil && *n.UserId == userId {
+		return true
+	}
+	if n.WorkspaceId == nil {
+		return false
+	}
+
+	_, ok := s.members[*n.WorkspaceId][userId]
+
+	return ok
+}
+
+// canEdit is like canRead, but guests of the workspace are excluded
+func (s *Storage) canEdit(userId string, n *note.Note) bool {
+	if n.UserId != nil && *n.UserId == userId {
+		return true
+	}
+	if n.WorkspaceId == nil {
+		return false
+	}
+
+	member, ok := s.members[*n.WorkspaceId][userId]
+
+	return ok && member.role != workspace.RoleGuest
+}
+
+// touchNote sets updated_at field on note
+func (s *Storage) touchNote(id int) {
+	if n, ok := s.notes[id]; ok {
+		n.UpdatedAt = now()
+	}
+}
+
+func preview(n *note.Note) note.NotePreview {
+	return note.NotePreview{
+		Id:          n.Id,
+		UserId:      n.UserId,
+		WorkspaceId: n.WorkspaceId,
+		Title:       n.Title,
+		CreatedAt:   n.CreatedAt,
+		UpdatedAt:   n.UpdatedAt,
+		ArchivedAt:  n.ArchivedAt,
+	}
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"main/internal/storage"
+	"slices"
+	"time"
+)
+
+type oneTimeToken struct {
+	userId    string
+	purpose   string
+	tokenHash string
+	expiresAt time.Time
+	usedAt    *time.Time
+}
+
+func (s *Storage) CreateOneTimeToken(ctx context.Context, userId, purpose, tokenHash string, expiresAt time.Time) error {
+	const op = "storage.memory.CreateOneTimeToken"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	// invalidating previously issued tokens with the same purpose
+	s.oneTimeTokens = slices.DeleteFunc(s.oneTimeTokens, func(token *oneTimeToken) bool {
+		return token.userId == userId && token.purpose == purpose && token.usedAt == nil
+	})
+
+	s.oneTimeTokens = append(s.oneTimeTokens, &oneTimeToken{
+		userId:    userId,
+		purpose:   purpose,
+		tokenHash: tokenHash,
+		expiresAt: expiresAt,
+	})
+
+	return nil
+}
+
+func (s *Storage) ConsumeOneTimeToken(ctx context.Context, purpose, tokenHash string) (string, error) {
+	const op = "storage.memory.ConsumeOneTimeToken"
+
+	if err := s.lock(ctx); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	usedAt := now()
+
+	for _, token := range s.oneTimeTokens {
+		if token.purpose == purpose && token.tokenHash == tokenHash && token.usedAt == nil && token.expiresAt.After(usedAt) {
+			token.usedAt = &usedAt
+			return token.userId, nil
+		}
+	}
+
+	return "", storage.ErrOneTimeTokenNotFound
+}
+
+func (s *Storage) DeleteExpiredOneTimeTokens(ctx context.Context) (int, error) {
+	const op = "storage.memory.DeleteExpiredOneTimeTokens"
+
+	if err := s.lock(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	deletedAt := now()
+	count := len(s.oneTimeTokens)
+
+	s.oneTimeTokens = slices.DeleteFunc(s.oneTimeTokens, func(token *oneTimeToken) bool {
+		return token.expiresAt.Before(deletedAt)
+	})
+
+	return count - len(s.oneTimeTokens), nil
+}
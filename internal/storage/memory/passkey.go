@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"main/internal/auth/passkey"
+	"main/internal/storage"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+func (s *Storage) CreateWebAuthnSession(ctx context.Context, session passkey.Session) error {
+	const op = "storage.memory.CreateWebAuthnSession"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	if _, ok := s.sessions[session.Id]; ok {
+		return fmt.Errorf("%s: session already exists", op)
+	}
+	s.sessions[session.Id] = session
+
+	return nil
+}
+
+func (s *Storage) ConsumeWebAuthnSession(ctx context.Context, id, purpose string) (passkey.Session, error) {
+	const op = "storage.memory.ConsumeWebAuthnSession"
+
+	if err := s.lock(ctx); err != nil {
+		return passkey.Session{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	// deleting session so the challenge can be used only once
+	session, ok := s.sessions[id]
+	if !ok || session.Purpose != purpose || !session.ExpiresAt.After(now()) {
+		return passkey.Session{}, storage.ErrWebAuthnSessionNotFound
+	}
+	delete(s.sessions, id)
+
+	return session, nil
+}
+
+func (s *Storage) DeleteExpiredWebAuthnSessions(ctx context.Context) (int, error) {
+	const op = "storage.memory.DeleteExpiredWebAuthnSessions"
+
+	if err := s.lock(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	deletedAt := now()
+
+	return countDeleted(s.sessions, func(session passkey.Session) bool {
+		return session.ExpiresAt.Before(deletedAt)
+	}), nil
+}
+
+func (s *Storage) CreatePasskey(ctx context.Context, pk passkey.Passkey) error {
+	const op = "storage.memory.CreatePasskey"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	// check if credential is already registered
+	for _, other := range s.passkeys {
+		if bytes.Equal(other.CredentialId, pk.CredentialId) {
+			return storage.ErrPasskeyAlreadyExists
+		}
+	}
+
+	pk.Id = uuid.New().String()
+	pk.CreatedAt = now()
+	pk.LastUsedAt = nil
+	s.passkeys[pk.Id] = &pk
+
+	return nil
+}
+
+func (s *Storage) GetUserPasskeys(ctx context.Context, userId string) ([]passkey.Passkey, error) {
+	const op = "storage.memory.GetUserPasskeys"
+
+	if err := s.lock(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	passkeys := []passkey.Passkey{}
+	for _, pk := range s.passkeys {
+		if pk.UserId == userId {
+			passkeys = append(passkeys, *pk)
+		}
+	}
+
+	sort.Slice(passkeys, func(i, j int) bool {
+		return passkeys[i].CreatedAt.Before(passkeys[j].CreatedAt)
+	})
+
+	return passkeys, nil
+}
+
+func (s *Storage) UpdatePasskeyUsage(ctx context.Context, id string, signCount int64, backupState bool) error {
+	const op = "storage.memory.UpdatePasskeyUsage"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	// check if passkey exists
+	pk, ok := s.passkeys[id]
+	if !ok {
+		return storage.ErrPasskeyNotFound
+	}
+
+	usedAt := now()
+	pk.SignCount = signCount
+	pk.BackupState = backupState
+	pk.LastUsedAt = &usedAt
+
+	return nil
+}
+
+func (s *Storage) DeletePasskey(ctx context.Context, id, userId string) error {
+	const op = "storage.memory.DeletePasskey"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	// check if passkey exists and belongs to user
+	pk, ok := s.passkeys[id]
+	if !ok || pk.UserId != userId {
+		return storage.ErrPasskeyNotFound
+	}
+	delete(s.passkeys, id)
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"main/internal/auth/pat"
+	"main/internal/storage"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (s *Storage) CreatePersonalAccessToken(ctx context.Context, token pat.PersonalAccessToken) (pat.PersonalAccessToken, error) {
+	const op = "storage.memory.CreatePersonalAccessToken"
+
+	if err := s.lock(ctx); err != nil {
+		return pat.PersonalAccessToken{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	for _, other := range s.pats {
+		if other.TokenHash == token.TokenHash {
+			return pat.PersonalAccessToken{}, fmt.Errorf("%s: token hash already exists", op)
+		}
+	}
+
+	token.Id = uuid.New().String()
+	token.Scopes = slices.Clone(token.Scopes)
+	token.LastUsedAt = nil
+	token.CreatedAt = now()
+	s.pats[token.Id] = &token
+
+	return token, nil
+}
+
+func (s *Storage) GetPersonalAccessTokenByHash(ctx context.Context, tokenHash string) (pat.PersonalAccessToken, error) {
+	const op = "storage.memory.GetPersonalAccessTokenByHash"
+
+	if err := s.lock(ctx); err != nil {
+		return pat.PersonalAccessToken{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	for _, token := range s.pats {
+		if token.TokenHash == tokenHash {
+			return *token, nil
+		}
+	}
+
+	return pat.PersonalAccessToken{}, storage.ErrPersonalAccessTokenNotFound
+}
+
+func (s *Storage) GetUserPersonalAccessTokens(ctx context.Context, userId string) ([]pat.PersonalAccessToken, error) {
+	const op = "storage.memory.GetUserPersonalAccessTokens"
+
+	if err := s.lock(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	tokens := []pat.PersonalAccessToken{}
+	for _, token := range s.pats {
+		if token.UserId == userId {
+			tokens = append(tokens, *token)
+		}
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+	})
+
+	return tokens, nil
+}
+
+// TouchPersonalAccessToken updates last usage time, at most once a minute per token
+func (s *Storage) TouchPersonalAccessToken(ctx context.Context, id string) error {
+	const op = "storage.memory.TouchPersonalAccessToken"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	token, ok := s.pats[id]
+	if !ok {
+		return nil
+	}
+
+	usedAt := now()
+	if token.LastUsedAt == nil || token.LastUsedAt.Before(usedAt.Add(-time.Minute)) {
+		token.LastUsedAt = &usedAt
+	}
+
+	return nil
+}
+
+func (s *Storage) DeletePersonalAccessToken(ctx context.Context, id, userId string) error {
+	const op = "storage.memory.DeletePersonalAccessToken"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	// check if token exists and belongs to user
+	token, ok := s.pats[id]
+	if !ok || token.UserId != userId {
+		return storage.ErrPersonalAccessTokenNotFound
+	}
+	delete(s.pats, id)
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"main/internal/auth"
+	"main/internal/storage"
+	"time"
+)
+
+func (s *Storage) CreateRefreshToken(ctx context.Context, id, userId, familyId, tokenHash string, expiresAt time.Time) error {
+	const op = "storage.memory.CreateRefreshToken"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	s.refreshTokens[id] = &auth.RefreshToken{
+		Id:        id,
+		UserId:    userId,
+		FamilyId:  familyId,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+	}
+
+	return nil
+}
+
+func (s *Storage) RotateRefreshToken(ctx context.Context, oldId, newId, userId, familyId, tokenHash string, expiresAt time.Time) error {
+	const op = "storage.memory.RotateRefreshToken"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	// check if old token was revoked in the meantime
+	old, ok := s.refreshTokens[oldId]
+	if !ok {
+		return storage.ErrRefreshTokenNotFound
+	}
+
+	// marking old token as used (keeping the first use time for reuse detection)
+	if old.UsedAt == nil {
+		usedAt := now()
+		old.UsedAt = &usedAt
+	}
+	if old.ReplacedBy == nil {
+		old.ReplacedBy = &newId
+	}
+
+	// creating new token in the same family
+	s.refreshTokens[newId] = &auth.RefreshToken{
+		Id:        newId,
+		UserId:    userId,
+		FamilyId:  familyId,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+	}
+
+	return nil
+}
+
+func (s *Storage) GetRefreshTokenById(ctx context.Context, id string) (auth.RefreshToken, error) {
+	const op = "storage.memory.GetRefreshTokenById"
+
+	if err := s.lock(ctx); err != nil {
+		return auth.RefreshToken{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	token, ok := s.refreshTokens[id]
+	if !ok {
+		return auth.RefreshToken{}, storage.ErrRefreshTokenNotFound
+	}
+
+	return *token, nil
+}
+
+func (s *Storage) RevokeRefreshTokenFamily(ctx context.Context, familyId string) (int, error) {
+	const op = "storage.memory.RevokeRefreshTokenFamily"
+
+	if err := s.lock(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	// deleting all tokens of the family
+	return countDeleted(s.refreshTokens, func(token *auth.RefreshToken) bool {
+		return token.FamilyId == familyId
+	}), nil
+}
+
+func (s *Storage) DeleteExpiredRefreshTokens(ctx context.Context) (int, error) {
+	const op = "storage.memory.DeleteExpiredRefreshTokens"
+
+	if err := s.lock(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	deletedAt := now()
+
+	return countDeleted(s.refreshTokens, func(token *auth.RefreshToken) bool {
+		return token.ExpiresAt.Before(deletedAt)
+	}), nil
+}
+
+// CountActiveUsers counts users having a refresh token which can still be used
+func (s *Storage) CountActiveUsers(ctx context.Context) (int, error) {
+	const op = "storage.memory.CountActiveUsers"
+
+	if err := s.lock(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	countedAt := now()
+
+	active := map[string]bool{}
+	for _, token := range s.refreshTokens {
+		if token.ExpiresAt.After(countedAt) && token.UsedAt == nil {
+			active[token.UserId] = true
+		}
+	}
+
+	return len(active), nil
+}
+
+func (s *Storage) DeleteUserRefreshTokens(ctx context.Context, userId string) (int, error) {
+	const op = "storage.memory.DeleteUserRefreshTokens"
+
+	if err := s.lock(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	return countDeleted(s.refreshTokens, func(token *auth.RefreshToken) bool {
+		return token.UserId == userId
+	}), nil
+}
@@ -0,0 +1,147 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"main/internal/auth"
+	"main/internal/storage"
+	"time"
+)
+
+type recoveryCode struct {
+	codeHash string
+	usedAt   *time.Time
+}
+
+func (s *Storage) SaveTOTPSecret(ctx context.Context, userId, secret string) error {
+	const op = "storage.memory.SaveTOTPSecret"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	// saving pending secret (confirmed secret is never overwritten)
+	if totp, ok := s.totps[userId]; ok && totp.ConfirmedAt != nil {
+		return storage.ErrTOTPAlreadyEnabled
+	}
+
+	s.totps[userId] = &auth.TOTP{
+		UserId:    userId,
+		Secret:    secret,
+		CreatedAt: now(),
+	}
+
+	return nil
+}
+
+func (s *Storage) GetTOTP(ctx context.Context, userId string) (auth.TOTP, error) {
+	const op = "storage.memory.GetTOTP"
+
+	if err := s.lock(ctx); err != nil {
+		return auth.TOTP{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	totp, ok := s.totps[userId]
+	if !ok {
+		return auth.TOTP{}, storage.ErrTOTPNotFound
+	}
+
+	return *totp, nil
+}
+
+func (s *Storage) IsTwoFactorEnabled(ctx context.Context, userId string) (bool, error) {
+	const op = "storage.memory.IsTwoFactorEnabled"
+
+	if err := s.lock(ctx); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	return s.isTwoFactorEnabled(userId), nil
+}
+
+func (s *Storage) isTwoFactorEnabled(userId string) bool {
+	totp, ok := s.totps[userId]
+
+	return ok && totp.ConfirmedAt != nil
+}
+
+func (s *Storage) ConfirmTOTP(ctx context.Context, userId string, step int64, recoveryCodeHashes []string) error {
+	const op = "storage.memory.ConfirmTOTP"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	// enabling totp
+	totp, ok := s.totps[userId]
+	if !ok || totp.ConfirmedAt != nil {
+		return storage.ErrTOTPAlreadyEnabled
+	}
+	confirmedAt := now()
+	totp.ConfirmedAt = &confirmedAt
+	totp.LastUsedStep = step
+
+	// replacing recovery codes
+	codes := make([]*recoveryCode, 0, len(recoveryCodeHashes))
+	for _, codeHash := range recoveryCodeHashes {
+		codes = append(codes, &recoveryCode{codeHash: codeHash})
+	}
+	s.recoveryCodes[userId] = codes
+
+	return nil
+}
+
+func (s *Storage) UpdateTOTPLastUsedStep(ctx context.Context, userId string, step int64) error {
+	const op = "storage.memory.UpdateTOTPLastUsedStep"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	// check if code was used concurrently
+	totp, ok := s.totps[userId]
+	if !ok || totp.LastUsedStep >= step {
+		return storage.ErrTOTPCodeAlreadyUsed
+	}
+	totp.LastUsedStep = step
+
+	return nil
+}
+
+func (s *Storage) UseRecoveryCode(ctx context.Context, userId, codeHash string) error {
+	const op = "storage.memory.UseRecoveryCode"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	for _, code := range s.recoveryCodes[userId] {
+		if code.codeHash == codeHash && code.usedAt == nil {
+			usedAt := now()
+			code.usedAt = &usedAt
+			return nil
+		}
+	}
+
+	return storage.ErrRecoveryCodeNotFound
+}
+
+func (s *Storage) DeleteTOTP(ctx context.Context, userId string) error {
+	const op = "storage.memory.DeleteTOTP"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	delete(s.totps, userId)
+	delete(s.recoveryCodes, userId)
+
+	return nil
+}
@@ -0,0 +1,287 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"main/internal/auth"
+	"main/internal/auth/passkey"
+	"main/internal/auth/pat"
+	"main/internal/models/user"
+	"main/internal/models/workspace"
+	"main/internal/storage"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// userRecord keeps creation time for ordering, user has it as a string
+type userRecord struct {
+	user.User
+	createdAt time.Time
+}
+
+func (s *Storage) CreateUser(ctx context.Context, email, name, passwordHash string) (string, error) {
+	const op = "storage.memory.CreateUser"
+
+	if err := s.lock(ctx); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	return s.createUser(email, name, passwordHash, nil)
+}
+
+// createUser inserts user, email is unique like in the users table
+func (s *Storage) createUser(email, name, passwordHash string, emailVerifiedAt *time.Time) (string, error) {
+	if s.userByEmail(email) != nil {
+		return "", storage.ErrUserAlreadyExists
+	}
+
+	createdAt := now()
+	id := uuid.New().String()
+
+	s.users[id] = &userRecord{
+		User: user.User{
+			ID:              id,
+			Email:           email,
+			Name:            name,
+			PasswordHash:    passwordHash,
+			CreatedAt:       formatTime(createdAt),
+			UpdatedAt:       formatTime(createdAt),
+			EmailVerifiedAt: emailVerifiedAt,
+			Role:            user.RoleUser,
+		},
+		createdAt: createdAt,
+	}
+
+	return id, nil
+}
+
+func (s *Storage) GetUser(ctx context.Context, email string) (user.User, error) {
+	const op = "storage.memory.GetUser"
+
+	if err := s.lock(ctx); err != nil {
+		return user.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	u := s.userByEmail(email)
+	if u == nil {
+		return user.User{}, storage.ErrUserNotFound
+	}
+
+	return u.User, nil
+}
+
+func (s *Storage) GetUserById(ctx context.Context, id string) (user.User, error) {
+	const op = "storage.memory.GetUserById"
+
+	if err := s.lock(ctx); err != nil {
+		return user.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	u, ok := s.users[id]
+	if !ok {
+		return user.User{}, storage.ErrUserNotFound
+	}
+
+	return u.User, nil
+}
+
+func (s *Storage) UpdateUserPassword(ctx context.Context, id, passwordHash string) error {
+	return s.changeUser(ctx, "storage.memory.UpdateUserPassword", id, func(u *userRecord) {
+		u.PasswordHash = passwordHash
+	})
+}
+
+func (s *Storage) SetEmailVerified(ctx context.Context, id string) error {
+	return s.changeUser(ctx, "storage.memory.SetEmailVerified", id, func(u *userRecord) {
+		if u.EmailVerifiedAt == nil {
+			verifiedAt := now()
+			u.EmailVerifiedAt = &verifiedAt
+		}
+	})
+}
+
+func (s *Storage) UpdateUserName(ctx context.Context, id, name string) error {
+	return s.changeUser(ctx, "storage.memory.UpdateUserName", id, func(u *userRecord) {
+		u.Name = name
+	})
+}
+
+func (s *Storage) SetPendingEmail(ctx context.Context, id, email string) error {
+	return s.changeUser(ctx, "storage.memory.SetPendingEmail", id, func(u *userRecord) {
+		u.PendingEmail = &email
+	})
+}
+
+// ConfirmEmailChange replaces email with the pending one and marks it verified
+func (s *Storage) ConfirmEmailChange(ctx context.Context, id string) (string, error) {
+	const op = "storage.memory.ConfirmEmailChange"
+
+	if err := s.lock(ctx); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	u, ok := s.users[id]
+	if !ok || u.PendingEmail == nil {
+		return "", storage.ErrNoPendingEmail
+	}
+
+	// check if email was taken after change was requested
+	if other := s.userByEmail(*u.PendingEmail); other != nil && other.ID != id {
+		return "", storage.ErrUserAlreadyExists
+	}
+
+	verifiedAt := now()
+	u.Email = *u.PendingEmail
+	u.PendingEmail = nil
+	u.EmailVerifiedAt = &verifiedAt
+	u.UpdatedAt = formatTime(verifiedAt)
+
+	return u.Email, nil
+}
+
+func (s *Storage) GetUserNoteIds(ctx context.Context, userId string) ([]int, error) {
+	const op = "storage.memory.GetUserNoteIds"
+
+	if err := s.lock(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	ids := []int{}
+	for _, n := range s.notes {
+		if n.UserId != nil && *n.UserId == userId {
+			ids = append(ids, n.Id)
+		}
+	}
+
+	return ids, nil
+}
+
+// GetSolelyOwnedWorkspaceIds returns workspaces which have no other owner than the user
+func (s *Storage) GetSolelyOwnedWorkspaceIds(ctx context.Context, userId string) ([]string, error) {
+	const op = "storage.memory.GetSolelyOwnedWorkspaceIds"
+
+	if err := s.lock(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	return s.solelyOwnedWorkspaceIds(userId), nil
+}
+
+// solelyOwnedWorkspaceIds must be called with mu held
+func (s *Storage) solelyOwnedWorkspaceIds(userId string) []string {
+	ids := []string{}
+	for workspaceId, members := range s.members {
+		if member, ok := members[userId]; ok && member.role == workspace.RoleOwner && !hasOtherOwner(members, userId) {
+			ids = append(ids, workspaceId)
+		}
+	}
+	slices.Sort(ids)
+
+	return ids
+}
+
+// DeleteUser deletes user with everything owned by him, like foreign keys cascade does.
+// Workspaces are not deleted, so the only owner of a workspace gets ErrSoleWorkspaceOwner
+func (s *Storage) DeleteUser(ctx context.Context, id string) error {
+	const op = "storage.memory.DeleteUser"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[id]; !ok {
+		return storage.ErrUserNotFound
+	}
+	if len(s.solelyOwnedWorkspaceIds(id)) > 0 {
+		return storage.ErrSoleWorkspaceOwner
+	}
+	delete(s.users, id)
+
+	for _, n := range s.notes {
+		if n.UserId != nil && *n.UserId == id {
+			s.deleteNote(n.Id)
+		}
+	}
+	for _, members := range s.members {
+		delete(members, id)
+	}
+	for _, invitation := range s.invites {
+		if invitation.InvitedBy != nil && *invitation.InvitedBy == id {
+			invitation.InvitedBy = nil
+		}
+	}
+
+	s.deleteUserAuth(id)
+	s.deleteUserWebhooks(id)
+
+	return nil
+}
+
+// changeUser applies change to the user and bumps its updated_at
+func (s *Storage) changeUser(ctx context.Context, op, id string, change func(u *userRecord)) error {
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	u, ok := s.users[id]
+	if !ok {
+		return storage.ErrUserNotFound
+	}
+
+	change(u)
+	u.UpdatedAt = formatTime(now())
+
+	return nil
+}
+
+func (s *Storage) userByEmail(email string) *userRecord {
+	for _, u := range s.users {
+		if u.Email == email {
+			return u
+		}
+	}
+
+	return nil
+}
+
+// formatTime formats time the way timestamps are scanned into strings
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+// deleteUserAuth deletes credentials and tokens of the deleted user
+func (s *Storage) deleteUserAuth(userId string) {
+	countDeleted(s.refreshTokens, func(token *auth.RefreshToken) bool {
+		return token.UserId == userId
+	})
+	countDeleted(s.revokedTokens, func(token revokedToken) bool {
+		return token.userId == userId
+	})
+	s.oneTimeTokens = slices.DeleteFunc(s.oneTimeTokens, func(token *oneTimeToken) bool {
+		return token.userId == userId
+	})
+	delete(s.totps, userId)
+	delete(s.recoveryCodes, userId)
+	countDeleted(s.identities, func(id string) bool {
+		return id == userId
+	})
+	countDeleted(s.sessions, func(session passkey.Session) bool {
+		return session.UserId != nil && *session.UserId == userId
+	})
+	countDeleted(s.passkeys, func(pk *passkey.Passkey) bool {
+		return pk.UserId == userId
+	})
+	countDeleted(s.pats, func(token *pat.PersonalAccessToken) bool {
+		return token.UserId == userId
+	})
+}
@@ -0,0 +1,292 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"main/internal/storage"
+	"main/internal/webhook"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (s *Storage) CreateWebhook(ctx context.Context, userId, url string, events []string, secret string) (webhook.Webhook, error) {
+	const op = "storage.memory.CreateWebhook"
+
+	if err := s.lock(ctx); err != nil {
+		return webhook.Webhook{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[userId]; !ok {
+		return webhook.Webhook{}, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	created := &webhook.Webhook{
+		Id:        uuid.New().String(),
+		UserId:    userId,
+		URL:       url,
+		Events:    slices.Clone(events),
+		Secret:    secret,
+		CreatedAt: now(),
+	}
+	s.webhooks[created.Id] = created
+
+	return *created, nil
+}
+
+func (s *Storage) GetUserWebhooks(ctx context.Context, userId string) ([]webhook.Webhook, error) {
+	const op = "storage.memory.GetUserWebhooks"
+
+	if err := s.lock(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	webhooks := []webhook.Webhook{}
+	for _, hook := range s.webhooks {
+		if hook.UserId == userId {
+			webhooks = append(webhooks, *hook)
+		}
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.After(webhooks[j].CreatedAt)
+	})
+
+	return webhooks, nil
+}
+
+func (s *Storage) GetWebhook(ctx context.Context, id, userId string) (webhook.Webhook, error) {
+	const op = "storage.memory.GetWebhook"
+
+	if err := s.lock(ctx); err != nil {
+		return webhook.Webhook{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	hook, ok := s.webhooks[id]
+	if !ok || hook.UserId != userId {
+		return webhook.Webhook{}, storage.ErrWebhookNotFound
+	}
+
+	return *hook, nil
+}
+
+// DeleteWebhook deletes webhook with its delivery log, pending deliveries are dropped
+func (s *Storage) DeleteWebhook(ctx context.Context, id, userId string) error {
+	const op = "storage.memory.DeleteWebhook"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	hook, ok := s.webhooks[id]
+	if !ok || hook.UserId != userId {
+		return storage.ErrWebhookNotFound
+	}
+	s.deleteWebhook(id)
+
+	return nil
+}
+
+func (s *Storage) GetWebhookDeliveries(ctx context.Context, webhookId string, limit, offset int) ([]webhook.Delivery, error) {
+	const op = "storage.memory.GetWebhookDeliveries"
+
+	if err := s.lock(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	var deliveries []webhook.Delivery
+	for _, delivery := range s.deliveries {
+		if delivery.WebhookId == webhookId {
+			deliveries = append(deliveries, *delivery)
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		if deliveries[i].CreatedAt.Equal(deliveries[j].CreatedAt) {
+			return deliveries[i].Id > deliveries[j].Id
+		}
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+
+	return page(deliveries, limit, offset), nil
+}
+
+// RedeliverWebhookDelivery queues a copy of the delivery, so its own log entry stays untouched
+func (s *Storage) RedeliverWebhookDelivery(ctx context.Context, id int64, webhookId string) (int64, error) {
+	const op = "storage.memory.RedeliverWebhookDelivery"
+
+	if err := s.lock(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	delivery, ok := s.deliveries[id]
+	if !ok || delivery.WebhookId != webhookId {
+		return 0, storage.ErrWebhookDeliveryNotFound
+	}
+
+	return s.enqueueDelivery(webhookId, delivery.Event, delivery.Payload), nil
+}
+
+func (s *Storage) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]webhook.PendingDelivery, error) {
+	const op = "storage.memory.ClaimWebhookDeliveries"
+
+	if err := s.lock(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	claimedAt := now()
+
+	var due []*webhook.Delivery
+	for _, delivery := range s.deliveries {
+		if delivery.Status == webhook.StatusPending && !delivery.NextAttemptAt.After(claimedAt) {
+			due = append(due, delivery)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+	})
+
+	// claimed deliveries are postponed for lease, so they aren't claimed twice
+	var deliveries []webhook.PendingDelivery
+	for _, delivery := range page(due, limit, 0) {
+		delivery.NextAttemptAt = claimedAt.Add(lease)
+
+		hook := s.webhooks[delivery.WebhookId]
+		deliveries = append(deliveries, webhook.PendingDelivery{
+			Id:       delivery.Id,
+			Event:    delivery.Event,
+			Payload:  delivery.Payload,
+			Attempts: delivery.Attempts,
+			URL:      hook.URL,
+			Secret:   hook.Secret,
+		})
+	}
+
+	return deliveries, nil
+}
+
+func (s *Storage) MarkWebhookDeliverySucceeded(ctx context.Context, id int64, responseStatus int) error {
+	const op = "storage.memory.MarkWebhookDeliverySucceeded"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	delivery, ok := s.deliveries[id]
+	if !ok {
+		return nil
+	}
+
+	deliveredAt := now()
+	delivery.Status = webhook.StatusSucceeded
+	delivery.Attempts++
+	delivery.ResponseStatus = &responseStatus
+	delivery.LastError = ""
+	delivery.LastAttemptAt = &deliveredAt
+	delivery.DeliveredAt = &deliveredAt
+
+	return nil
+}
+
+func (s *Storage) MarkWebhookDeliveryFailed(ctx context.Context, id int64, responseStatus *int, lastError string, retryAt *time.Time) error {
+	const op = "storage.memory.MarkWebhookDeliveryFailed"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	delivery, ok := s.deliveries[id]
+	if !ok {
+		return nil
+	}
+
+	attemptedAt := now()
+	delivery.Status = webhook.StatusFailed
+	if retryAt != nil {
+		delivery.Status = webhook.StatusPending
+		delivery.NextAttemptAt = *retryAt
+	}
+	delivery.Attempts++
+	delivery.ResponseStatus = responseStatus
+	delivery.LastError = lastError
+	delivery.LastAttemptAt = &attemptedAt
+
+	return nil
+}
+
+// enqueueNoteEvent writes deliveries of the note event to the outbox. Webhooks of
+// everyone who can read the note receive it, if their filter matches the event
+// itself or the wildcard of its group
+func (s *Storage) enqueueNoteEvent(event string, data webhook.Data) {
+	n, ok := s.notes[data.NoteId]
+	if !ok {
+		return
+	}
+
+	wildcard, _, _ := strings.Cut(event, ".")
+	wildcard += ".*"
+
+	var payload []byte
+	for _, hook := range s.webhooks {
+		if !s.canRead(hook.UserId, n) {
+			continue
+		}
+		if !slices.Contains(hook.Events, event) && !slices.Contains(hook.Events, wildcard) {
+			continue
+		}
+
+		if payload == nil {
+			// payload is built from known types, so marshalling can't fail
+			payload, _ = webhook.NewPayload(event, data)
+		}
+		s.enqueueDelivery(hook.Id, event, payload)
+	}
+}
+
+func (s *Storage) enqueueDelivery(webhookId, event string, payload []byte) int64 {
+	s.lastDelivery++
+	createdAt := now()
+
+	s.deliveries[s.lastDelivery] = &webhook.Delivery{
+		Id:            s.lastDelivery,
+		WebhookId:     webhookId,
+		Event:         event,
+		Payload:       payload,
+		Status:        webhook.StatusPending,
+		NextAttemptAt: createdAt,
+		CreatedAt:     createdAt,
+	}
+
+	return s.lastDelivery
+}
+
+// deleteWebhook deletes webhook with its deliveries
+func (s *Storage) deleteWebhook(id string) {
+	delete(s.webhooks, id)
+
+	countDeleted(s.deliveries, func(delivery *webhook.Delivery) bool {
+		return delivery.WebhookId == id
+	})
+}
+
+// deleteUserWebhooks deletes webhooks of the deleted user
+func (s *Storage) deleteUserWebhooks(userId string) {
+	for id, hook := range s.webhooks {
+		if hook.UserId == userId {
+			s.deleteWebhook(id)
+		}
+	}
+}
@@ -0,0 +1,435 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"main/internal/models/workspace"
+	"main/internal/storage"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type memberRecord struct {
+	role     string
+	joinedAt time.Time
+}
+
+func (s *Storage) CreateWorkspace(ctx context.Context, name, ownerId string) (string, error) {
+	const op = "storage.memory.CreateWorkspace"
+
+	if err := s.lock(ctx); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[ownerId]; !ok {
+		return "", fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	createdAt := now()
+	id := uuid.New().String()
+
+	s.workspaces[id] = &workspace.Workspace{
+		Id:        id,
+		Name:      name,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+
+	// creator becomes the first owner
+	s.members[id] = map[string]*memberRecord{
+		ownerId: {role: workspace.RoleOwner, joinedAt: createdAt},
+	}
+
+	return id, nil
+}
+
+func (s *Storage) GetWorkspace(ctx context.Context, id string) (workspace.Workspace, error) {
+	const op = "storage.memory.GetWorkspace"
+
+	if err := s.lock(ctx); err != nil {
+		return workspace.Workspace{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	ws, ok := s.workspaces[id]
+	if !ok {
+		return workspace.Workspace{}, storage.ErrWorkspaceNotFound
+	}
+
+	return *ws, nil
+}
+
+func (s *Storage) GetUserWorkspaces(ctx context.Context, userId string) ([]workspace.Membership, error) {
+	const op = "storage.memory.GetUserWorkspaces"
+
+	if err := s.lock(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	memberships := []workspace.Membership{}
+	for id, members := range s.members {
+		member, ok := members[userId]
+		if !ok {
+			continue
+		}
+
+		ws := s.workspaces[id]
+		memberships = append(memberships, workspace.Membership{
+			Id:        ws.Id,
+			Name:      ws.Name,
+			Role:      member.role,
+			CreatedAt: ws.CreatedAt,
+		})
+	}
+
+	sort.Slice(memberships, func(i, j int) bool {
+		return memberships[i].CreatedAt.Before(memberships[j].CreatedAt)
+	})
+
+	return memberships, nil
+}
+
+func (s *Storage) UpdateWorkspaceName(ctx context.Context, id, name string) error {
+	const op = "storage.memory.UpdateWorkspaceName"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	ws, ok := s.workspaces[id]
+	if !ok {
+		return storage.ErrWorkspaceNotFound
+	}
+	ws.Name = name
+	ws.UpdatedAt = now()
+
+	return nil
+}
+
+// DeleteWorkspace deletes workspace with its members, invitations and notes
+func (s *Storage) DeleteWorkspace(ctx context.Context, id string) error {
+	const op = "storage.memory.DeleteWorkspace"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	if _, ok := s.workspaces[id]; !ok {
+		return storage.ErrWorkspaceNotFound
+	}
+	delete(s.workspaces, id)
+	delete(s.members, id)
+
+	countDeleted(s.invites, func(invitation *workspace.Invitation) bool {
+		return invitation.WorkspaceId == id
+	})
+	for _, n := range s.notes {
+		if n.WorkspaceId != nil && *n.WorkspaceId == id {
+			s.deleteNote(n.Id)
+		}
+	}
+
+	return nil
+}
+
+func (s *Storage) GetWorkspaceNoteIds(ctx context.Context, workspaceId string) ([]int, error) {
+	const op = "storage.memory.GetWorkspaceNoteIds"
+
+	if err := s.lock(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	var ids []int
+	for _, n := range s.notes {
+		if n.WorkspaceId != nil && *n.WorkspaceId == workspaceId {
+			ids = append(ids, n.Id)
+		}
+	}
+
+	return ids, nil
+}
+
+func (s *Storage) GetWorkspaceRole(ctx context.Context, workspaceId, userId string) (string, error) {
+	const op = "storage.memory.GetWorkspaceRole"
+
+	if err := s.lock(ctx); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	member, ok := s.members[workspaceId][userId]
+	if !ok {
+		return "", storage.ErrWorkspaceMemberNotFound
+	}
+
+	return member.role, nil
+}
+
+func (s *Storage) GetWorkspaceMembers(ctx context.Context, workspaceId string) ([]workspace.Member, error) {
+	const op = "storage.memory.GetWorkspaceMembers"
+
+	if err := s.lock(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	members := []workspace.Member{}
+	for userId, member := range s.members[workspaceId] {
+		u := s.users[userId]
+		members = append(members, workspace.Member{
+			UserId:   userId,
+			Email:    u.Email,
+			Name:     u.Name,
+			Role:     member.role,
+			JoinedAt: member.joinedAt,
+		})
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].JoinedAt.Before(members[j].JoinedAt)
+	})
+
+	return members, nil
+}
+
+func (s *Storage) IsWorkspaceMemberEmail(ctx context.Context, workspaceId, email string) (bool, error) {
+	const op = "storage.memory.IsWorkspaceMemberEmail"
+
+	if err := s.lock(ctx); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	u := s.userByEmail(email)
+	if u == nil {
+		return false, nil
+	}
+	_, ok := s.members[workspaceId][u.ID]
+
+	return ok, nil
+}
+
+func (s *Storage) SetWorkspaceMemberRole(ctx context.Context, workspaceId, userId, role string) error {
+	return s.changeWorkspaceMember(ctx, "storage.memory.SetWorkspaceMemberRole", workspaceId, userId, role != workspace.RoleOwner, func(members map[string]*memberRecord) {
+		members[userId].role = role
+	})
+}
+
+func (s *Storage) RemoveWorkspaceMember(ctx context.Context, workspaceId, userId string) error {
+	return s.changeWorkspaceMember(ctx, "storage.memory.RemoveWorkspaceMember", workspaceId, userId, true, func(members map[string]*memberRecord) {
+		delete(members, userId)
+	})
+}
+
+// changeWorkspaceMember applies change to a member, unless it leaves the workspace without owners
+func (s *Storage) changeWorkspaceMember(ctx context.Context, op, workspaceId, userId string, losesOwnership bool, change func(members map[string]*memberRecord)) error {
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	if _, ok := s.workspaces[workspaceId]; !ok {
+		return storage.ErrWorkspaceNotFound
+	}
+
+	members := s.members[workspaceId]
+	member, ok := members[userId]
+	if !ok {
+		return storage.ErrWorkspaceMemberNotFound
+	}
+
+	if member.role == workspace.RoleOwner && losesOwnership && !hasOtherOwner(members, userId) {
+		return storage.ErrLastWorkspaceOwner
+	}
+
+	change(members)
+
+	return nil
+}
+
+func hasOtherOwner(members map[string]*memberRecord, userId string) bool {
+	for id, member := range members {
+		if id != userId && member.role == workspace.RoleOwner {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CreateWorkspaceInvitation invites email to the workspace. Expired invitation
+// for the same email is replaced, pending one is reported as ErrInvitationAlreadyExists
+func (s *Storage) CreateWorkspaceInvitation(ctx context.Context, workspaceId, email, role, invitedBy string, expiresAt time.Time) (string, error) {
+	const op = "storage.memory.CreateWorkspaceInvitation"
+
+	if err := s.lock(ctx); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	if _, ok := s.workspaces[workspaceId]; !ok {
+		return "", fmt.Errorf("%s: %w", op, storage.ErrWorkspaceNotFound)
+	}
+
+	createdAt := now()
+	invitation := &workspace.Invitation{
+		Id:          uuid.New().String(),
+		WorkspaceId: workspaceId,
+		Email:       email,
+		Role:        role,
+		InvitedBy:   &invitedBy,
+		ExpiresAt:   expiresAt,
+		CreatedAt:   createdAt,
+	}
+
+	// replaced invitation keeps its id
+	for _, other := range s.invites {
+		if other.WorkspaceId == workspaceId && other.Email == email {
+			if !other.ExpiresAt.Before(createdAt) {
+				return "", storage.ErrInvitationAlreadyExists
+			}
+			invitation.Id = other.Id
+		}
+	}
+	s.invites[invitation.Id] = invitation
+
+	return invitation.Id, nil
+}
+
+func (s *Storage) GetWorkspaceInvitations(ctx context.Context, workspaceId string) ([]workspace.Invitation, error) {
+	const op = "storage.memory.GetWorkspaceInvitations"
+
+	if err := s.lock(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	return s.selectInvitations(func(invitation *workspace.Invitation) bool {
+		return invitation.WorkspaceId == workspaceId
+	}), nil
+}
+
+func (s *Storage) GetUserInvitations(ctx context.Context, email string) ([]workspace.Invitation, error) {
+	const op = "storage.memory.GetUserInvitations"
+
+	if err := s.lock(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	return s.selectInvitations(func(invitation *workspace.Invitation) bool {
+		return invitation.Email == email
+	}), nil
+}
+
+// selectInvitations returns pending matching invitations, oldest first
+func (s *Storage) selectInvitations(match func(invitation *workspace.Invitation) bool) []workspace.Invitation {
+	selectedAt := now()
+
+	invitations := []workspace.Invitation{}
+	for _, invitation := range s.invites {
+		if match(invitation) && invitation.ExpiresAt.After(selectedAt) {
+			selected := *invitation
+			selected.WorkspaceName = s.workspaces[invitation.WorkspaceId].Name
+			invitations = append(invitations, selected)
+		}
+	}
+
+	sort.Slice(invitations, func(i, j int) bool {
+		return invitations[i].CreatedAt.Before(invitations[j].CreatedAt)
+	})
+
+	return invitations
+}
+
+func (s *Storage) DeleteWorkspaceInvitation(ctx context.Context, id, workspaceId string) error {
+	const op = "storage.memory.DeleteWorkspaceInvitation"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	invitation, ok := s.invites[id]
+	if !ok || invitation.WorkspaceId != workspaceId {
+		return storage.ErrInvitationNotFound
+	}
+	delete(s.invites, id)
+
+	return nil
+}
+
+// AcceptWorkspaceInvitation consumes invitation sent to email and adds user to the workspace
+func (s *Storage) AcceptWorkspaceInvitation(ctx context.Context, id, email, userId string) (string, error) {
+	const op = "storage.memory.AcceptWorkspaceInvitation"
+
+	if err := s.lock(ctx); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	invitation, ok := s.pendingInvitation(id, email)
+	if !ok {
+		return "", storage.ErrInvitationNotFound
+	}
+
+	// check if user is already member, invitation is kept then
+	members := s.members[invitation.WorkspaceId]
+	if _, ok := members[userId]; ok {
+		return "", storage.ErrWorkspaceMemberAlreadyExists
+	}
+
+	delete(s.invites, id)
+	members[userId] = &memberRecord{role: invitation.Role, joinedAt: now()}
+
+	return invitation.WorkspaceId, nil
+}
+
+// DeclineWorkspaceInvitation deletes invitation sent to email
+func (s *Storage) DeclineWorkspaceInvitation(ctx context.Context, id, email string) error {
+	const op = "storage.memory.DeclineWorkspaceInvitation"
+
+	if err := s.lock(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	if _, ok := s.pendingInvitation(id, email); !ok {
+		return storage.ErrInvitationNotFound
+	}
+	delete(s.invites, id)
+
+	return nil
+}
+
+func (s *Storage) DeleteExpiredWorkspaceInvitations(ctx context.Context) (int, error) {
+	const op = "storage.memory.DeleteExpiredWorkspaceInvitations"
+
+	if err := s.lock(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer s.mu.Unlock()
+
+	deletedAt := now()
+
+	return countDeleted(s.invites, func(invitation *workspace.Invitation) bool {
+		return invitation.ExpiresAt.Before(deletedAt)
+	}), nil
+}
+
+// pendingInvitation returns not expired invitation sent to email
+func (s *Storage) pendingInvitation(id, email string) (*workspace.Invitation, bool) {
+	invitation, ok := s.invites[id]
+	if !ok || invitation.Email != email || !invitation.ExpiresAt.After(now()) {
+		return nil, false
+	}
+
+	return invitation, true
+}
@@ -0,0 +1,159 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+
+	"github.com/pressly/goose/v3"
+)
+
+var ErrSchemaBehind = errors.New("database schema is behind migrations")
+
+// Migrator applies migrations to the database, applied ones are recorded in
+// goose_db_version table
+type Migrator struct {
+	db       *sql.DB
+	provider *goose.Provider
+	log      *slog.Logger
+}
+
+// New returns migrator of db, opts are passed to goose, like session locker
+// which keeps migrations of several instances from running concurrently
+func New(dialect goose.Dialect, db *sql.DB, migrations fs.FS, log *slog.Logger, opts ...goose.ProviderOption) (*Migrator, error) {
+	const op = "storage.migrator.New"
+
+	provider, err := goose.NewProvider(dialect, db, migrations, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Migrator{db: db, provider: provider, log: log}, nil
+}
+
+// Up applies every pending migration
+func (m *Migrator) Up(ctx context.Context) error {
+	const op = "storage.migrator.Up"
+
+	results, err := m.provider.Up(ctx)
+	m.logResults(results...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(results) == 0 {
+		m.log.Info("no pending migrations")
+	}
+
+	return nil
+}
+
+// Down rolls back the last applied migration
+func (m *Migrator) Down(ctx context.Context) error {
+	const op = "storage.migrator.Down"
+
+	result, err := m.provider.Down(ctx)
+	m.logResults(result)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Redo rolls back the last applied migration and applies it again
+func (m *Migrator) Redo(ctx context.Context) error {
+	const op = "storage.migrator.Redo"
+
+	result, err := m.provider.Down(ctx)
+	m.logResults(result)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	result, err = m.provider.UpByOne(ctx)
+	m.logResults(result)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Reset rolls back every applied migration, data of the database is lost
+func (m *Migrator) Reset(ctx context.Context) error {
+	const op = "storage.migrator.Reset"
+
+	results, err := m.provider.DownTo(ctx, 0)
+	m.logResults(results...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Status returns every migration with its state, in order of versions
+func (m *Migrator) Status(ctx context.Context) ([]*goose.MigrationStatus, error) {
+	const op = "storage.migrator.Status"
+
+	statuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return statuses, nil
+}
+
+// Check returns ErrSchemaBehind if some migrations are not applied
+func (m *Migrator) Check(ctx context.Context) error {
+	const op = "storage.migrator.Check"
+
+	pending, err := m.provider.HasPending(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !pending {
+		return nil
+	}
+
+	current, target, err := m.provider.GetVersions(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return fmt.Errorf("%s: %w: version is %d, latest migration is %d", op, ErrSchemaBehind, current, target)
+}
+
+// Close closes the database, it's used when the migrator opened it
+func (m *Migrator) Close() error {
+	return m.db.Close()
+}
+
+func (m *Migrator) logResults(results ...*goose.MigrationResult) {
+	for _, result := range results {
+		if result == nil || result.Source == nil || result.Error != nil {
+			continue
+		}
+
+		m.log.Info("migration "+result.Direction,
+			slog.Int64("version", result.Source.Version),
+			slog.String("path", result.Source.Path),
+			slog.Duration("duration", result.Duration),
+		)
+	}
+}
+
+// Create writes an empty SQL migration named after name to dir, version is the current time
+func Create(dir, name string) error {
+	const op = "storage.migrator.Create"
+
+	if err := goose.Create(nil, dir, name, "sql"); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
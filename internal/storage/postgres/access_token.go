@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"main/internal/auth/denylist"
+	"main/internal/storage"
+	"time"
+)
+
+func (s *Storage) RevokeAccessToken(ctx context.Context, jti, userId string, expiresAt time.Time) error {
+	const op = "storage.postgres.RevokeAccessToken"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, revokeAccessTokenQuery, jti, userId, expiresAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	const op = "storage.postgres.IsAccessTokenRevoked"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var exists int
+
+	err := s.db.GetContext(ctx, &exists, isAccessTokenRevokedQuery, jti)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return exists == 1, nil
+}
+
+func (s *Storage) DeleteExpiredRevokedAccessTokens(ctx context.Context) (int, error) {
+	const op = "storage.postgres.DeleteExpiredRevokedAccessTokens"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// deleting expired denylist entries
+	res, err := s.db.ExecContext(ctx, deleteExpiredRevokedAccessTokensQuery)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// counting rows
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+func (s *Storage) GetUserState(ctx context.Context, userId string) (denylist.UserState, error) {
+	const op = "storage.postgres.GetUserState"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var state denylist.UserState
+
+	err := s.db.GetContext(ctx, &state, getUserStateQuery, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return denylist.UserState{}, storage.ErrUserNotFound
+	}
+	if err != nil {
+		return denylist.UserState{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return state, nil
+}
+
+func (s *Storage) SetTokensValidAfter(ctx context.Context, userId string, validAfter time.Time) error {
+	const op = "storage.postgres.SetTokensValidAfter"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, setTokensValidAfterQuery, userId, validAfter)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if user wasn't found
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteUserRefreshTokens(ctx context.Context, userId string) (int, error) {
+	const op = "storage.postgres.DeleteUserRefreshTokens"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, deleteUserRefreshTokensQuery, userId)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// counting rows
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(rowsAffected), nil
+}
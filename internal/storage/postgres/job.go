@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"main/internal/scheduler"
+	"time"
+)
+
+// TryJobLock takes session advisory lock on a dedicated connection. Lock is
+// released with unlock or by the database when the connection is lost
+func (s *Storage) TryJobLock(ctx context.Context, job string) (func(), bool, error) {
+	const op = "storage.postgres.TryJobLock"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var acquired bool
+
+	err = conn.GetContext(ctx, &acquired, tryJobLockQuery, job)
+	if err != nil {
+		_ = conn.Close()
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+	if !acquired {
+		_ = conn.Close()
+		return nil, false, nil
+	}
+
+	// lock outlives ctx, so it's released even if the job was cancelled
+	unlock := func() {
+		_, _ = conn.ExecContext(context.Background(), releaseJobLockQuery, job)
+		_ = conn.Close()
+	}
+
+	return unlock, true, nil
+}
+
+func (s *Storage) StartJobRun(ctx context.Context, job string, scheduledAt time.Time, instance string) (int64, bool, error) {
+	const op = "storage.postgres.StartJobRun"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var id int64
+
+	err := s.db.GetContext(ctx, &id, startJobRunQuery, job, scheduledAt, instance)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, true, nil
+}
+
+func (s *Storage) FinishJobRun(ctx context.Context, id int64, status string, attempts int, runErr string) error {
+	const op = "storage.postgres.FinishJobRun"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, finishJobRunQuery, id, status, attempts, runErr)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) GetJobRuns(ctx context.Context, job, status string, limit, offset int) ([]scheduler.Run, error) {
+	const op = "storage.postgres.GetJobRuns"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var runs []scheduler.Run
+
+	err := s.db.SelectContext(ctx, &runs, getJobRunsQuery, job, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if runs == nil {
+		return []scheduler.Run{}, nil
+	}
+
+	return runs, nil
+}
+
+func (s *Storage) DeleteJobRunsBefore(ctx context.Context, before time.Time) (int, error) {
+	const op = "storage.postgres.DeleteJobRunsBefore"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, deleteJobRunsBeforeQuery, before)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// counting rows
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(rowsAffected), nil
+}
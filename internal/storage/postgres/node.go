@@ -1,35 +1,56 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"main/internal/models/note"
 	"main/internal/storage"
+	"main/internal/webhook"
+
+	"github.com/lib/pq"
 )
 
-func (s *Storage) AddNoteNode(noteId int, contentType string, content string) (int, error) {
-	const op = "storage.postgres.CreateNoteNode"
+func (s *Storage) AddNoteNode(ctx context.Context, noteId int, contentType string, content string) (int, error) {
+	const op = "storage.postgres.AddNoteNode"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
 
 	// begin transaction
-	tx := s.db.MustBegin()
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
 
 	// creating note node
 	var id int
 
-	err := tx.Get(&id, createBlankNoteNodeQuery, noteId, contentType, content)
+	err = tx.GetContext(ctx, &id, createBlankNoteNodeQuery, noteId, contentType, content)
 	if err != nil {
 		_ = tx.Rollback()
+		// check if note doesn't exist
+		var sqlxerr *pq.Error
+		if errors.As(err, &sqlxerr) && sqlxerr.Code == ErrForeignKeyViolation {
+			return 0, storage.ErrNoteNotFound
+		}
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
 	// set updated_at field on note
-	_, err = tx.Exec(setUpdatedAtQuery, noteId)
+	_, err = tx.ExecContext(ctx, setUpdatedAtQuery, noteId)
 	if err != nil {
 		_ = tx.Rollback()
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
+	err = enqueueNoteEvent(ctx, tx, op, webhook.EventNodeCreated, webhook.Data{NoteId: noteId, NodeId: id})
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
 	// commit transaction
 	if err := tx.Commit(); err != nil {
 		return 0, fmt.Errorf("%s: %w", op, err)
@@ -38,16 +59,26 @@ func (s *Storage) AddNoteNode(noteId int, contentType string, content string) (i
 	return id, nil
 }
 
-func (s *Storage) DeleteNoteNode(id int) error {
+func (s *Storage) DeleteNoteNode(ctx context.Context, id int) error {
 	const op = "storage.postgres.DeleteNoteNode"
 
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
 	// begin transaction
-	tx := s.db.MustBegin()
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
 
 	// deleting node with returning (note_id, order)
 	var tempNoteNode note.NoteNode
 
-	err := tx.Get(&tempNoteNode, deleteNoteNodeQuery, id)
+	err = tx.GetContext(ctx, &tempNoteNode, deleteNoteNodeQuery, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		_ = tx.Rollback()
+		return storage.ErrNoteNodeNotFound
+	}
 	if err != nil {
 		_ = tx.Rollback()
 		return fmt.Errorf("%s: %w", op, err)
@@ -57,19 +88,25 @@ func (s *Storage) DeleteNoteNode(id int) error {
 	var noteId, order = tempNoteNode.NoteId, tempNoteNode.Order
 
 	// update all note nodes' order after deleted node
-	_, err = tx.Exec(updateOrderAfterDeleteQuery, noteId, order)
+	_, err = tx.ExecContext(ctx, updateOrderAfterDeleteQuery, noteId, order)
 	if err != nil {
 		_ = tx.Rollback()
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	// set updated_at field on note
-	_, err = tx.Exec(setUpdatedAtQuery, noteId)
+	_, err = tx.ExecContext(ctx, setUpdatedAtQuery, noteId)
 	if err != nil {
 		_ = tx.Rollback()
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	err = enqueueNoteEvent(ctx, tx, op, webhook.EventNodeDeleted, webhook.Data{NoteId: noteId, NodeId: id})
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
 	// commit transaction
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("%s: %w", op, err)
@@ -78,16 +115,22 @@ func (s *Storage) DeleteNoteNode(id int) error {
 	return nil
 }
 
-func (s *Storage) UpdateNoteNodeContent(id int, content string) error {
+func (s *Storage) UpdateNoteNodeContent(ctx context.Context, id int, content string) error {
 	const op = "storage.postgres.UpdateNoteNodeContent"
 
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
 	// begin transaction
-	tx := s.db.MustBegin()
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
 
 	// updating note node with returning note_id
 	var noteId int
 
-	err := tx.Get(&noteId, updateNoteNodeContentQuery, id, content)
+	err = tx.GetContext(ctx, &noteId, updateNoteNodeContentQuery, id, content)
 	if errors.Is(err, sql.ErrNoRows) {
 		_ = tx.Rollback()
 		return storage.ErrNoteNodeNotFound
@@ -98,12 +141,18 @@ func (s *Storage) UpdateNoteNodeContent(id int, content string) error {
 	}
 
 	// set updated_at field on note
-	_, err = tx.Exec(setUpdatedAtQuery, noteId)
+	_, err = tx.ExecContext(ctx, setUpdatedAtQuery, noteId)
 	if err != nil {
 		_ = tx.Rollback()
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	err = enqueueNoteEvent(ctx, tx, op, webhook.EventNodeUpdated, webhook.Data{NoteId: noteId, NodeId: id})
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
 	// commit transaction
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("%s: %w", op, err)
@@ -112,12 +161,15 @@ func (s *Storage) UpdateNoteNodeContent(id int, content string) error {
 	return nil
 }
 
-func (s *Storage) IsUserNoteNodeOwner(userId string, noteNodeId int) (bool, error) {
-	const op = "storage.postgres.IsUserNoteNodeOwner"
+func (s *Storage) CanUserEditNoteNode(ctx context.Context, userId string, noteNodeId int) (bool, error) {
+	const op = "storage.postgres.CanUserEditNoteNode"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
 
 	var exists int
 
-	err := s.db.Get(&exists, isUserNodeOwnerQuery, userId, noteNodeId)
+	err := s.db.GetContext(ctx, &exists, canUserEditNoteNodeQuery, userId, noteNodeId)
 	if err != nil {
 		return false, fmt.Errorf("%s: %w", op, err)
 	}
@@ -125,13 +177,32 @@ func (s *Storage) IsUserNoteNodeOwner(userId string, noteNodeId int) (bool, erro
 	return exists == 1, nil
 }
 
-func (s *Storage) GetNodeById(id int) (note.NoteNode, error) {
-	const op = "storage.postgres.GetNoteIdByNoteNodeId"
+func (s *Storage) CanUserReadNoteNode(ctx context.Context, userId string, noteNodeId int) (bool, error) {
+	const op = "storage.postgres.CanUserReadNoteNode"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var exists int
+
+	err := s.db.GetContext(ctx, &exists, canUserReadNoteNodeQuery, userId, noteNodeId)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return exists == 1, nil
+}
+
+func (s *Storage) GetNodeById(ctx context.Context, id int) (note.NoteNode, error) {
+	const op = "storage.postgres.GetNodeById"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
 
 	// getting note id by note node id
 	var node note.NoteNode
 
-	err := s.db.Get(&node, getNoteIdByNoteNodeIdQuery, id)
+	err := s.db.GetContext(ctx, &node, getNoteIdByNoteNodeIdQuery, id)
 	if errors.Is(err, sql.ErrNoRows) {
 		return node, storage.ErrNoteNodeNotFound
 	}
@@ -142,12 +213,15 @@ func (s *Storage) GetNodeById(id int) (note.NoteNode, error) {
 	return node, nil
 }
 
-func (s *Storage) GetAllNotesNodes(noteId int) ([]note.NoteNode, error) {
+func (s *Storage) GetAllNotesNodes(ctx context.Context, noteId int) ([]note.NoteNode, error) {
 	const op = "storage.postgres.GetAllNotesNodes"
 
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
 	var nodes []note.NoteNode
 
-	err := s.db.Select(&nodes, getAllNotesNodesQuery, noteId)
+	err := s.reader(ctx).SelectContext(ctx, &nodes, getAllNotesNodesQuery, noteId)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nodes, storage.ErrNoteNotFound
 	}
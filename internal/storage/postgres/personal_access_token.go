@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"main/internal/auth/pat"
+	"main/internal/storage"
+)
+
+func (s *Storage) CreatePersonalAccessToken(ctx context.Context, token pat.PersonalAccessToken) (pat.PersonalAccessToken, error) {
+	const op = "storage.postgres.CreatePersonalAccessToken"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var created pat.PersonalAccessToken
+
+	err := s.db.GetContext(ctx, &created, createPersonalAccessTokenQuery,
+		token.UserId, token.Name, token.Prefix, token.TokenHash, token.Scopes, token.ExpiresAt,
+	)
+	if err != nil {
+		return pat.PersonalAccessToken{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return created, nil
+}
+
+func (s *Storage) GetPersonalAccessTokenByHash(ctx context.Context, tokenHash string) (pat.PersonalAccessToken, error) {
+	const op = "storage.postgres.GetPersonalAccessTokenByHash"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var token pat.PersonalAccessToken
+
+	err := s.db.GetContext(ctx, &token, getPersonalAccessTokenByHashQuery, tokenHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return pat.PersonalAccessToken{}, storage.ErrPersonalAccessTokenNotFound
+	}
+	if err != nil {
+		return pat.PersonalAccessToken{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return token, nil
+}
+
+func (s *Storage) GetUserPersonalAccessTokens(ctx context.Context, userId string) ([]pat.PersonalAccessToken, error) {
+	const op = "storage.postgres.GetUserPersonalAccessTokens"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	tokens := []pat.PersonalAccessToken{}
+
+	err := s.db.SelectContext(ctx, &tokens, getUserPersonalAccessTokensQuery, userId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tokens, nil
+}
+
+// TouchPersonalAccessToken updates last usage time, at most once a minute per token
+func (s *Storage) TouchPersonalAccessToken(ctx context.Context, id string) error {
+	const op = "storage.postgres.TouchPersonalAccessToken"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, touchPersonalAccessTokenQuery, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) DeletePersonalAccessToken(ctx context.Context, id, userId string) error {
+	const op = "storage.postgres.DeletePersonalAccessToken"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, deletePersonalAccessTokenQuery, id, userId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if token exists and belongs to user
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrPersonalAccessTokenNotFound
+	}
+
+	return nil
+}
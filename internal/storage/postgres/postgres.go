@@ -1,62 +1,256 @@
 package postgres
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"main/internal/config"
+	"main/internal/storage/migrator"
+	"main/internal/tracing"
+	"main/migrations"
+	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/XSAM/otelsql"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
-	"github.com/pressly/goose"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Storage struct {
-	db *sqlx.DB
+	db            primary
+	replicas      []*sqlx.DB
+	nextReplica   atomic.Uint64
+	queryTimeout  time.Duration
+	queryTimeouts map[string]time.Duration
 }
 
 func New(cfg *config.Config, log *slog.Logger) (*Storage, error) {
 	const op = "storage.postgres.New"
 
-	// get source name from config
-	sourceName := getSourceName(cfg)
+	// open connection with primary and wait until it accepts connections
+	db, err := open(cfg, getSourceName(cfg), log)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check schema, migrations are applied only if auto migrate is enabled
+	err = prepareSchema(db, cfg, log)
+	if err != nil {
+		_ = db.Close()
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// open connections with replicas
+	replicas := make([]*sqlx.DB, 0, len(cfg.Postgres.Replicas))
+	for i, sourceName := range cfg.Postgres.Replicas {
+		replica, err := open(cfg, sourceName, log.With(slog.Int("replica", i)))
+		if err != nil {
+			_ = db.Close()
+			closeAll(replicas)
+
+			return nil, fmt.Errorf("%s: replica %d: %w", op, i, err)
+		}
+
+		replicas = append(replicas, replica)
+	}
+
+	// writes are tracked only if there are replicas to lag behind them
+	var writes *recentWrites
+	if len(replicas) > 0 {
+		writes = newRecentWrites(cfg.Postgres.ReadYourWritesWindow)
+
+		log.Info("read replicas enabled", slog.Int("count", len(replicas)))
+	}
+
+	return &Storage{
+		db:            primary{DB: db, writes: writes},
+		replicas:      replicas,
+		queryTimeout:  cfg.Postgres.QueryTimeout,
+		queryTimeouts: cfg.Postgres.QueryTimeouts,
+	}, nil
+}
+
+// open opens connection pool with limits from config and pings the database,
+// retrying with doubling delay, so the app may start before the database does
+func open(cfg *config.Config, sourceName string, log *slog.Logger) (*sqlx.DB, error) {
+	const op = "storage.postgres.open"
 
-	// open connection with db
-	db, err := sqlx.Open(
+	// every query is traced
+	sqlDB, err := otelsql.Open(
 		"postgres",
 		sourceName,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitConnPrepare:      true,
+			OmitRows:             true,
+		}),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %v", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sqlDB.SetMaxOpenConns(cfg.Postgres.MaxOpenConns)
+	if cfg.Postgres.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(cfg.Postgres.MaxIdleConns)
+	}
+	sqlDB.SetConnMaxLifetime(cfg.Postgres.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.Postgres.ConnMaxIdleTime)
+
+	attempts := max(cfg.Postgres.ConnectAttempts, 1)
+	delay := cfg.Postgres.ConnectBackoff
+
+	for attempt := 1; ; attempt++ {
+		err = ping(sqlDB, cfg.Postgres.QueryTimeout)
+		if err == nil {
+			break
+		}
+		if attempt >= attempts {
+			_ = sqlDB.Close()
+
+			return nil, fmt.Errorf("%s: database is unreachable after %d attempts: %w", op, attempts, err)
+		}
+
+		log.Warn("database is unreachable, retrying",
+			slog.Int("attempt", attempt),
+			slog.Duration("delay", delay),
+			"error", err,
+		)
+
+		time.Sleep(delay)
+
+		delay *= 2
+		if cfg.Postgres.ConnectBackoffMax > 0 {
+			delay = min(delay, cfg.Postgres.ConnectBackoffMax)
+		}
+	}
+
+	return sqlx.NewDb(sqlDB, "postgres"), nil
+}
+
+func ping(db *sql.DB, timeout time.Duration) error {
+	ctx := context.Background()
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	return db.PingContext(ctx)
+}
+
+// Close waits for running queries and closes connection pools
+func (s *Storage) Close() error {
+	errs := []error{s.db.Close()}
+
+	for _, replica := range s.replicas {
+		errs = append(errs, replica.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+func closeAll(dbs []*sqlx.DB) {
+	for _, db := range dbs {
+		_ = db.Close()
+	}
+}
+
+// withTimeout limits ctx with timeout of the storage method op is named after
+// and starts span of the method, cancel ends the span
+func (s *Storage) withTimeout(ctx context.Context, op string) (context.Context, context.CancelFunc) {
+	ctx, span := tracing.Tracer().Start(ctx, op, trace.WithAttributes(semconv.DBSystemPostgreSQL))
+
+	timeout, ok := s.queryTimeouts[strings.TrimPrefix(op, "storage.postgres.")]
+	if !ok {
+		timeout = s.queryTimeout
+	}
+
+	var cancel context.CancelFunc
+	if timeout <= 0 {
+		ctx, cancel = context.WithCancel(ctx)
+	} else {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	return ctx, func() {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			span.SetStatus(codes.Error, "query timeout")
+		}
+
+		cancel()
+		span.End()
+	}
+}
+
+// Stats returns statistics of primary connection pool
+func (s *Storage) Stats() sql.DBStats {
+	return s.db.Stats()
+}
+
+// NewMigrator opens connection with primary for migrations, it's closed with the migrator
+func NewMigrator(cfg *config.Config, log *slog.Logger) (*migrator.Migrator, error) {
+	const op = "storage.postgres.NewMigrator"
+
+	db, err := open(cfg, getSourceName(cfg), log)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	// init migrations
-	err = initMigrations(db, cfg, log)
+	m, err := newMigrator(db.DB, log)
 	if err != nil {
+		_ = db.Close()
+
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return &Storage{
-		db: db,
-	}, nil
+	return m, nil
 }
 
-func initMigrations(db *sqlx.DB, cfg *config.Config, log *slog.Logger) error {
-	const op = "storage.postgres.initMigrations"
+// newMigrator returns migrator which holds postgres advisory lock while migrations
+// run, so instances started together with auto migrate apply them one at a time
+func newMigrator(db *sql.DB, log *slog.Logger) (*migrator.Migrator, error) {
+	const op = "storage.postgres.newMigrator"
 
-	err := goose.SetDialect("postgres")
+	locker, err := lock.NewPostgresSessionLocker()
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	if cfg.IsReload {
-		if err = goose.Reset(db.DB, cfg.MigrationsPath); err != nil {
-			return fmt.Errorf("%s: %w", op, err)
-		}
+	m, err := migrator.New(goose.DialectPostgres, db, migrations.Postgres(), log, goose.WithSessionLocker(locker))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return m, nil
+}
+
+// prepareSchema applies pending migrations if auto migrate is enabled,
+// otherwise it fails if the schema is behind them
+func prepareSchema(db *sqlx.DB, cfg *config.Config, log *slog.Logger) error {
+	const op = "storage.postgres.prepareSchema"
 
-		log.Info("database reset")
+	m, err := newMigrator(db.DB, log)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	err = goose.Up(db.DB, cfg.MigrationsPath)
+	if cfg.AutoMigrate {
+		err = m.Up(context.Background())
+	} else {
+		err = m.Check(context.Background())
+	}
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -0,0 +1,81 @@
+package postgres_test
+
+import (
+	"cmp"
+	"io"
+	"log/slog"
+	"main/internal/config"
+	"main/internal/router"
+	"main/internal/storage/postgres"
+	"main/internal/storage/storagetest"
+	"os"
+	"sync"
+	"testing"
+)
+
+// testConfig returns config of TEST_POSTGRES_HOST database, the test is skipped if it isn't set
+func testConfig(t *testing.T) *config.Config {
+	host := os.Getenv("TEST_POSTGRES_HOST")
+	if host == "" {
+		t.Skip("TEST_POSTGRES_HOST is not set")
+	}
+
+	cfg := &config.Config{AutoMigrate: true}
+	cfg.Postgres = config.Postgres{
+		Host:     host,
+		Port:     cmp.Or(os.Getenv("TEST_POSTGRES_PORT"), "5432"),
+		User:     cmp.Or(os.Getenv("TEST_POSTGRES_USER"), "postgres"),
+		Password: cmp.Or(os.Getenv("TEST_POSTGRES_PASSWORD"), "postgres"),
+		Name:     cmp.Or(os.Getenv("TEST_POSTGRES_NAME"), "postgres"),
+		SSLMode:  "disable",
+	}
+
+	return cfg
+}
+
+// TestContract runs against database of TEST_POSTGRES_HOST, pending migrations are applied to it
+func TestContract(t *testing.T) {
+	cfg := testConfig(t)
+
+	s, err := postgres.New(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("postgres.New: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	storagetest.RunContract(t, func(t *testing.T) router.Storage {
+		return s
+	})
+}
+
+// TestConcurrentAutoMigrate starts instances together, advisory lock lets
+// one of them apply migrations while the others wait for it
+func TestConcurrentAutoMigrate(t *testing.T) {
+	cfg := testConfig(t)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+
+	for range cap(errs) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			s, err := postgres.New(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			if err == nil {
+				err = s.Close()
+			}
+			errs <- err
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("postgres.New: %v", err)
+		}
+	}
+}
@@ -48,9 +48,21 @@ const (
 		WHERE note_id = $1
 		ORDER BY "order";
 	`
-	isUserNodeOwnerQuery = `
-    SELECT COUNT(*) FROM note_nodes
-    WHERE id = $2 AND (note_id IN (SELECT id FROM notes WHERE user_id = $1));
+	canUserEditNoteNodeQuery = `
+		SELECT COUNT(*) FROM note_nodes nn
+		JOIN notes n ON n.id = nn.note_id
+		WHERE nn.id = $2 AND (n.user_id = $1 OR n.workspace_id IN (
+			SELECT workspace_id FROM workspace_members
+			WHERE user_id = $1 AND role <> 'guest'
+		));
+	`
+	canUserReadNoteNodeQuery = `
+		SELECT COUNT(*) FROM note_nodes nn
+		JOIN notes n ON n.id = nn.note_id
+		WHERE nn.id = $2 AND (n.user_id = $1 OR n.workspace_id IN (
+			SELECT workspace_id FROM workspace_members
+			WHERE user_id = $1
+		));
 	`
 	getNoteIdByNoteNodeIdQuery = `
 		SELECT * FROM note_nodes
@@ -75,7 +87,10 @@ const (
 	`
 	getNotesByUserIdQuery = `
 		SELECT * FROM notes
-		WHERE user_id = $1
+		WHERE user_id = $1 OR workspace_id IN (
+			SELECT workspace_id FROM workspace_members
+			WHERE user_id = $1
+		)
 		ORDER BY updated_at DESC;
 	`
 	updateNoteTitleQuery = `
@@ -102,9 +117,30 @@ const (
 		SET updated_at = NOW()
 		WHERE id = $1;
 	`
-	isUserNoteOwnerQuery = `
+	canUserEditNoteQuery = `
+		SELECT COUNT(*) FROM notes
+		WHERE id = $2 AND (user_id = $1 OR workspace_id IN (
+			SELECT workspace_id FROM workspace_members
+			WHERE user_id = $1 AND role <> 'guest'
+		));
+	`
+	canUserReadNoteQuery = `
 		SELECT COUNT(*) FROM notes
-		WHERE id = $2 AND user_id = $1;`
+		WHERE id = $2 AND (user_id = $1 OR workspace_id IN (
+			SELECT workspace_id FROM workspace_members
+			WHERE user_id = $1
+		));
+	`
+	createWorkspaceNoteQuery = `
+		INSERT INTO notes (title, workspace_id)
+		VALUES ($1, $2)
+		RETURNING id;
+	`
+	getWorkspaceNotesQuery = `
+		SELECT * FROM notes
+		WHERE workspace_id = $1
+		ORDER BY updated_at DESC;
+	`
 )
 
 // users' queries
@@ -122,15 +158,73 @@ const (
 		SELECT * FROM users
 		WHERE id = $1;
 	`
+	updateUserPasswordQuery = `
+		UPDATE users
+		SET password_hash = $2, updated_at = NOW()
+		WHERE id = $1;
+	`
+	setEmailVerifiedQuery = `
+		UPDATE users
+		SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+		WHERE id = $1;
+	`
+	updateUserNameQuery = `
+		UPDATE users
+		SET name = $2, updated_at = NOW()
+		WHERE id = $1;
+	`
+	setPendingEmailQuery = `
+		UPDATE users
+		SET pending_email = $2, updated_at = NOW()
+		WHERE id = $1;
+	`
+	confirmEmailChangeQuery = `
+		UPDATE users
+		SET email = pending_email, pending_email = NULL, email_verified_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND pending_email IS NOT NULL
+		RETURNING email;
+	`
+	getUserNoteIdsQuery = `
+		SELECT id FROM notes
+		WHERE user_id = $1;
+	`
+	getSolelyOwnedWorkspaceIdsQuery = `
+		SELECT m.workspace_id FROM workspace_members m
+		WHERE m.user_id = $1 AND m.role = 'owner' AND NOT EXISTS (
+			SELECT 1 FROM workspace_members o
+			WHERE o.workspace_id = m.workspace_id AND o.user_id <> m.user_id AND o.role = 'owner'
+		)
+		ORDER BY m.workspace_id;
+	`
+	lockOwnedWorkspacesQuery = `
+		SELECT w.id FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE m.user_id = $1 AND m.role = 'owner'
+		ORDER BY w.id
+		FOR UPDATE OF w;
+	`
+	deleteUserQuery = `
+		DELETE FROM users
+		WHERE id = $1;
+	`
 )
 
 // auth tokens' queries
 const (
 	createRefreshTokenQuery = `
-		INSERT INTO refresh_tokens (id, user_id, token_hash, expires_at) 
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at) 
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id;
 	`
+	markRefreshTokenUsedQuery = `
+		UPDATE refresh_tokens
+		SET used_at = COALESCE(used_at, NOW()), replaced_by = COALESCE(replaced_by, $2)
+		WHERE id = $1;
+	`
+	deleteRefreshTokenFamilyQuery = `
+		DELETE FROM refresh_tokens
+		WHERE family_id = $1;
+	`
 	getRefreshTokenByIdQuery = `
 		SELECT * FROM refresh_tokens
 		WHERE id = $1;
@@ -143,4 +237,531 @@ const (
 		DELETE FROM refresh_tokens
 		WHERE id = $1;
 	`
+	countActiveUsersQuery = `
+		SELECT COUNT(DISTINCT user_id) FROM refresh_tokens
+		WHERE expires_at > NOW() AND used_at IS NULL;
+	`
+	revokeAccessTokenQuery = `
+		INSERT INTO revoked_access_tokens (jti, user_id, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (jti) DO NOTHING;
+	`
+	isAccessTokenRevokedQuery = `
+		SELECT COUNT(*) FROM revoked_access_tokens
+		WHERE jti = $1;
+	`
+	deleteExpiredRevokedAccessTokensQuery = `
+		DELETE FROM revoked_access_tokens
+		WHERE expires_at < NOW();
+	`
+	getUserStateQuery = `
+		SELECT tokens_valid_after, disabled_at FROM users
+		WHERE id = $1;
+	`
+	setTokensValidAfterQuery = `
+		UPDATE users
+		SET tokens_valid_after = $2
+		WHERE id = $1;
+	`
+	deleteUserRefreshTokensQuery = `
+		DELETE FROM refresh_tokens
+		WHERE user_id = $1;
+	`
+	createOneTimeTokenQuery = `
+		INSERT INTO one_time_tokens (user_id, purpose, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4);
+	`
+	consumeOneTimeTokenQuery = `
+		UPDATE one_time_tokens
+		SET used_at = NOW()
+		WHERE purpose = $1 AND token_hash = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id;
+	`
+	deleteOneTimeTokensQuery = `
+		DELETE FROM one_time_tokens
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL;
+	`
+	deleteExpiredOneTimeTokensQuery = `
+		DELETE FROM one_time_tokens
+		WHERE expires_at < NOW();
+	`
+)
+
+// two-factor queries
+const (
+	saveTOTPSecretQuery = `
+		INSERT INTO user_totp (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, last_used_step = 0, created_at = NOW()
+		WHERE user_totp.confirmed_at IS NULL;
+	`
+	getTOTPQuery = `
+		SELECT * FROM user_totp
+		WHERE user_id = $1;
+	`
+	isTwoFactorEnabledQuery = `
+		SELECT COUNT(*) FROM user_totp
+		WHERE user_id = $1 AND confirmed_at IS NOT NULL;
+	`
+	confirmTOTPQuery = `
+		UPDATE user_totp
+		SET confirmed_at = NOW(), last_used_step = $2
+		WHERE user_id = $1 AND confirmed_at IS NULL;
+	`
+	updateTOTPLastUsedStepQuery = `
+		UPDATE user_totp
+		SET last_used_step = $2
+		WHERE user_id = $1 AND last_used_step < $2;
+	`
+	deleteTOTPQuery = `
+		DELETE FROM user_totp
+		WHERE user_id = $1;
+	`
+	createRecoveryCodeQuery = `
+		INSERT INTO recovery_codes (user_id, code_hash)
+		VALUES ($1, $2);
+	`
+	useRecoveryCodeQuery = `
+		UPDATE recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL;
+	`
+	deleteRecoveryCodesQuery = `
+		DELETE FROM recovery_codes
+		WHERE user_id = $1;
+	`
+)
+
+// external identities' queries
+const (
+	createOIDCStateQuery = `
+		INSERT INTO oidc_states (state, provider, code_verifier, nonce, expires_at)
+		VALUES (:state, :provider, :code_verifier, :nonce, :expires_at);
+	`
+	consumeOIDCStateQuery = `
+		DELETE FROM oidc_states
+		WHERE state = $1 AND expires_at > NOW()
+		RETURNING *;
+	`
+	deleteExpiredOIDCStatesQuery = `
+		DELETE FROM oidc_states
+		WHERE expires_at < NOW();
+	`
+	getUserIdByIdentityQuery = `
+		SELECT user_id FROM user_identities
+		WHERE provider = $1 AND subject = $2;
+	`
+	createUserIdentityQuery = `
+		INSERT INTO user_identities (provider, subject, user_id, email)
+		VALUES ($1, $2, $3, $4);
+	`
+	createExternalUserQuery = `
+		INSERT INTO users (email, name, password_hash, email_verified_at)
+		VALUES ($1, $2, '', CASE WHEN $3 THEN NOW() END)
+		RETURNING id;
+	`
+)
+
+// passkeys' queries
+const (
+	createWebAuthnSessionQuery = `
+		INSERT INTO webauthn_sessions (id, user_id, purpose, data, expires_at)
+		VALUES (:id, :user_id, :purpose, :data, :expires_at);
+	`
+	consumeWebAuthnSessionQuery = `
+		DELETE FROM webauthn_sessions
+		WHERE id = $1 AND purpose = $2 AND expires_at > NOW()
+		RETURNING *;
+	`
+	deleteExpiredWebAuthnSessionsQuery = `
+		DELETE FROM webauthn_sessions
+		WHERE expires_at < NOW();
+	`
+	createPasskeyQuery = `
+		INSERT INTO passkeys (user_id, name, credential_id, public_key, attestation_type, transports, aaguid, sign_count, backup_eligible, backup_state)
+		VALUES (:user_id, :name, :credential_id, :public_key, :attestation_type, :transports, :aaguid, :sign_count, :backup_eligible, :backup_state);
+	`
+	getUserPasskeysQuery = `
+		SELECT * FROM passkeys
+		WHERE user_id = $1
+		ORDER BY created_at;
+	`
+	updatePasskeyUsageQuery = `
+		UPDATE passkeys
+		SET sign_count = $2, backup_state = $3, last_used_at = NOW()
+		WHERE id = $1;
+	`
+	deletePasskeyQuery = `
+		DELETE FROM passkeys
+		WHERE id = $1 AND user_id = $2;
+	`
+)
+
+// personal access tokens' queries
+const (
+	createPersonalAccessTokenQuery = `
+		INSERT INTO personal_access_tokens (user_id, name, token_prefix, token_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING *;
+	`
+	getPersonalAccessTokenByHashQuery = `
+		SELECT * FROM personal_access_tokens
+		WHERE token_hash = $1;
+	`
+	getUserPersonalAccessTokensQuery = `
+		SELECT * FROM personal_access_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC;
+	`
+	touchPersonalAccessTokenQuery = `
+		UPDATE personal_access_tokens
+		SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute');
+	`
+	deletePersonalAccessTokenQuery = `
+		DELETE FROM personal_access_tokens
+		WHERE id = $1 AND user_id = $2;
+	`
+)
+
+// login attempts' queries
+const (
+	getLoginAttemptQuery = `
+		SELECT * FROM login_attempts
+		WHERE key = $1;
+	`
+	reserveLoginAttemptQuery = `
+		INSERT INTO login_attempts (key, failures, last_failure_at, locked_until)
+		VALUES ($1, 0, NOW(), $2)
+		ON CONFLICT (key) DO UPDATE
+		SET locked_until = EXCLUDED.locked_until
+		WHERE login_attempts.locked_until IS NULL OR login_attempts.locked_until <= NOW()
+		RETURNING *;
+	`
+	addLoginFailureQuery = `
+		INSERT INTO login_attempts (key, failures, last_failure_at)
+		VALUES ($1, 1, NOW())
+		ON CONFLICT (key) DO UPDATE
+		SET failures = CASE
+				WHEN login_attempts.last_failure_at < NOW() - $2 * INTERVAL '1 microsecond' THEN 1
+				ELSE login_attempts.failures + 1
+			END,
+			last_failure_at = NOW()
+		RETURNING *;
+	`
+	lockLoginKeyQuery = `
+		UPDATE login_attempts
+		SET locked_until = $2
+		WHERE key = $1;
+	`
+	deleteLoginAttemptQuery = `
+		DELETE FROM login_attempts
+		WHERE key = $1;
+	`
+	deleteStaleLoginAttemptsQuery = `
+		DELETE FROM login_attempts
+		WHERE last_failure_at < NOW() - $1 * INTERVAL '1 microsecond'
+			AND (locked_until IS NULL OR locked_until < NOW());
+	`
+	recordLoginFailureQuery = `
+		INSERT INTO login_failures (key, failed_at)
+		VALUES ($1, $2);
+	`
+	getLoginFailuresQuery = `
+		SELECT failed_at FROM login_failures
+		WHERE key = $1 AND failed_at >= $2
+		ORDER BY failed_at;
+	`
+	deleteStaleLoginFailuresQuery = `
+		DELETE FROM login_failures
+		WHERE failed_at < NOW() - $1 * INTERVAL '1 microsecond';
+	`
+)
+
+// admin queries
+const (
+	listAccountsQuery = `
+		SELECT u.id, u.email, u.name, u.role, u.email_verified_at, u.disabled_at, u.created_at,
+			EXISTS (SELECT 1 FROM user_totp t WHERE t.user_id = u.id AND t.confirmed_at IS NOT NULL) AS two_factor_enabled
+		FROM users u
+		WHERE $1 = '' OR u.email ILIKE '%' || $1 || '%' OR u.name ILIKE '%' || $1 || '%'
+		ORDER BY u.created_at DESC, u.id
+		LIMIT $2 OFFSET $3;
+	`
+	getAccountQuery = `
+		SELECT u.id, u.email, u.name, u.role, u.email_verified_at, u.disabled_at, u.created_at,
+			EXISTS (SELECT 1 FROM user_totp t WHERE t.user_id = u.id AND t.confirmed_at IS NOT NULL) AS two_factor_enabled
+		FROM users u
+		WHERE u.id = $1;
+	`
+	getUserUsageQuery = `
+		SELECT
+			(SELECT COUNT(*) FROM notes WHERE user_id = $1) AS notes,
+			(SELECT COUNT(*) FROM note_nodes WHERE note_id IN (SELECT id FROM notes WHERE user_id = $1)) AS nodes;
+	`
+	disableUserQuery = `
+		UPDATE users
+		SET disabled_at = COALESCE(disabled_at, NOW()), updated_at = NOW()
+		WHERE id = $1;
+	`
+	enableUserQuery = `
+		UPDATE users
+		SET disabled_at = NULL, updated_at = NOW()
+		WHERE id = $1;
+	`
+	setUserRoleQuery = `
+		UPDATE users
+		SET role = $2, updated_at = NOW()
+		WHERE id = $1;
+	`
+	grantRoleByEmailsQuery = `
+		UPDATE users
+		SET role = $2, updated_at = NOW()
+		WHERE email = ANY($1) AND role <> $2;
+	`
+)
+
+// workspaces' queries
+const (
+	createWorkspaceQuery = `
+		INSERT INTO workspaces (name)
+		VALUES ($1)
+		RETURNING id;
+	`
+	getWorkspaceQuery = `
+		SELECT * FROM workspaces
+		WHERE id = $1;
+	`
+	lockWorkspaceQuery = `
+		SELECT id FROM workspaces
+		WHERE id = $1
+		FOR UPDATE;
+	`
+	getUserWorkspacesQuery = `
+		SELECT w.id, w.name, m.role, w.created_at
+		FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE m.user_id = $1
+		ORDER BY w.created_at;
+	`
+	updateWorkspaceNameQuery = `
+		UPDATE workspaces
+		SET name = $2, updated_at = NOW()
+		WHERE id = $1;
+	`
+	deleteWorkspaceQuery = `
+		DELETE FROM workspaces
+		WHERE id = $1;
+	`
+	getWorkspaceNoteIdsQuery = `
+		SELECT id FROM notes
+		WHERE workspace_id = $1;
+	`
+	addWorkspaceMemberQuery = `
+		INSERT INTO workspace_members (workspace_id, user_id, role)
+		VALUES ($1, $2, $3);
+	`
+	getWorkspaceRoleQuery = `
+		SELECT role FROM workspace_members
+		WHERE workspace_id = $1 AND user_id = $2;
+	`
+	getWorkspaceMembersQuery = `
+		SELECT m.user_id, u.email, u.name, m.role, m.created_at AS joined_at
+		FROM workspace_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.workspace_id = $1
+		ORDER BY m.created_at;
+	`
+	isWorkspaceMemberEmailQuery = `
+		SELECT COUNT(*) FROM workspace_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.workspace_id = $1 AND u.email = $2;
+	`
+	countOtherWorkspaceOwnersQuery = `
+		SELECT COUNT(*) FROM workspace_members
+		WHERE workspace_id = $1 AND user_id <> $2 AND role = 'owner';
+	`
+	setWorkspaceMemberRoleQuery = `
+		UPDATE workspace_members
+		SET role = $3
+		WHERE workspace_id = $1 AND user_id = $2;
+	`
+	removeWorkspaceMemberQuery = `
+		DELETE FROM workspace_members
+		WHERE workspace_id = $1 AND user_id = $2;
+	`
+	createWorkspaceInvitationQuery = `
+		INSERT INTO workspace_invitations (workspace_id, email, role, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (workspace_id, email) DO UPDATE
+		SET role = EXCLUDED.role, invited_by = EXCLUDED.invited_by,
+			expires_at = EXCLUDED.expires_at, created_at = NOW()
+		WHERE workspace_invitations.expires_at < NOW()
+		RETURNING id;
+	`
+	getWorkspaceInvitationsQuery = `
+		SELECT i.id, i.workspace_id, w.name AS workspace_name, i.email, i.role, i.invited_by, i.expires_at, i.created_at
+		FROM workspace_invitations i
+		JOIN workspaces w ON w.id = i.workspace_id
+		WHERE i.workspace_id = $1 AND i.expires_at > NOW()
+		ORDER BY i.created_at;
+	`
+	getUserInvitationsQuery = `
+		SELECT i.id, i.workspace_id, w.name AS workspace_name, i.email, i.role, i.invited_by, i.expires_at, i.created_at
+		FROM workspace_invitations i
+		JOIN workspaces w ON w.id = i.workspace_id
+		WHERE i.email = $1 AND i.expires_at > NOW()
+		ORDER BY i.created_at;
+	`
+	deleteWorkspaceInvitationQuery = `
+		DELETE FROM workspace_invitations
+		WHERE id = $1 AND workspace_id = $2;
+	`
+	consumeWorkspaceInvitationQuery = `
+		DELETE FROM workspace_invitations
+		WHERE id = $1 AND email = $2 AND expires_at > NOW()
+		RETURNING workspace_id, role;
+	`
+	deleteExpiredWorkspaceInvitationsQuery = `
+		DELETE FROM workspace_invitations
+		WHERE expires_at < NOW();
+	`
+)
+
+// audit log queries
+const (
+	recordAuditEventQuery = `
+		INSERT INTO audit_events (action, actor_id, target_type, target_id, ip, user_agent, request_id, details)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+	`
+	getAuditEventsQuery = `
+		SELECT * FROM audit_events
+		WHERE ($1 = '' OR actor_id = NULLIF($1, '')::uuid OR (target_type = 'user' AND target_id = $1))
+			AND ($2 = '' OR actor_id = NULLIF($2, '')::uuid)
+			AND ($3 = '' OR action = $3)
+			AND ($4 = '' OR target_type = $4)
+			AND ($5 = '' OR target_id = $5)
+			AND ($6::timestamptz IS NULL OR occurred_at >= $6)
+			AND ($7::timestamptz IS NULL OR occurred_at < $7)
+		ORDER BY occurred_at DESC, id DESC
+		LIMIT $8 OFFSET $9;
+	`
+	deleteAuditEventsBeforeQuery = `
+		DELETE FROM audit_events
+		WHERE occurred_at < $1;
+	`
+)
+
+// webhook queries
+const (
+	createWebhookQuery = `
+		INSERT INTO webhooks (user_id, url, events, secret)
+		VALUES ($1, $2, $3, $4)
+		RETURNING *;
+	`
+	getUserWebhooksQuery = `
+		SELECT * FROM webhooks
+		WHERE user_id = $1
+		ORDER BY created_at DESC;
+	`
+	getWebhookQuery = `
+		SELECT * FROM webhooks
+		WHERE id = $1 AND user_id = $2;
+	`
+	deleteWebhookQuery = `
+		DELETE FROM webhooks
+		WHERE id = $1 AND user_id = $2;
+	`
+	getWebhookDeliveriesQuery = `
+		SELECT * FROM webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3;
+	`
+	redeliverWebhookDeliveryQuery = `
+		INSERT INTO webhook_deliveries (webhook_id, event, payload)
+		SELECT webhook_id, event, payload FROM webhook_deliveries
+		WHERE id = $1 AND webhook_id = $2
+		RETURNING id;
+	`
+	// webhooks of everyone who can read the note: its owner or workspace members.
+	// Filter matches the event itself or the wildcard of its group
+	enqueueNoteWebhookDeliveriesQuery = `
+		INSERT INTO webhook_deliveries (webhook_id, event, payload)
+		SELECT w.id, $2::text, $3::jsonb
+		FROM webhooks w
+		JOIN notes n ON n.id = $1
+		WHERE (w.user_id = n.user_id OR EXISTS (
+				SELECT 1 FROM workspace_members m
+				WHERE m.workspace_id = n.workspace_id AND m.user_id = w.user_id
+			))
+			AND (
+				$2::text = ANY(string_to_array(w.events, ' '))
+				OR split_part($2::text, '.', 1) || '.*' = ANY(string_to_array(w.events, ' '))
+			);
+	`
+	claimWebhookDeliveriesQuery = `
+		WITH claimed AS (
+			UPDATE webhook_deliveries
+			SET next_attempt_at = NOW() + make_interval(secs => $2)
+			WHERE id IN (
+				SELECT id FROM webhook_deliveries
+				WHERE status = 'pending' AND next_attempt_at <= NOW()
+				ORDER BY next_attempt_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, webhook_id, event, payload, attempts
+		)
+		SELECT c.id, c.event, c.payload, c.attempts, w.url, w.secret
+		FROM claimed c
+		JOIN webhooks w ON w.id = c.webhook_id;
+	`
+	markWebhookDeliverySucceededQuery = `
+		UPDATE webhook_deliveries
+		SET status = 'succeeded', attempts = attempts + 1, response_status = $2,
+			last_error = '', last_attempt_at = NOW(), delivered_at = NOW()
+		WHERE id = $1;
+	`
+	markWebhookDeliveryFailedQuery = `
+		UPDATE webhook_deliveries
+		SET status = CASE WHEN $4::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+			attempts = attempts + 1, response_status = $2, last_error = $3,
+			last_attempt_at = NOW(), next_attempt_at = COALESCE($4, next_attempt_at)
+		WHERE id = $1;
+	`
+)
+
+// job queries
+const (
+	// advisory lock key is namespaced, so it doesn't clash with other locks
+	tryJobLockQuery = `
+		SELECT pg_try_advisory_lock(hashtext('jobs'), hashtext($1));
+	`
+	releaseJobLockQuery = `
+		SELECT pg_advisory_unlock(hashtext('jobs'), hashtext($1));
+	`
+	startJobRunQuery = `
+		INSERT INTO job_runs (job, scheduled_at, instance)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (job, scheduled_at) DO NOTHING
+		RETURNING id;
+	`
+	finishJobRunQuery = `
+		UPDATE job_runs
+		SET status = $2, attempts = $3, error = $4, finished_at = NOW(),
+			duration_ms = (EXTRACT(EPOCH FROM NOW() - started_at) * 1000)::BIGINT
+		WHERE id = $1;
+	`
+	getJobRunsQuery = `
+		SELECT * FROM job_runs
+		WHERE ($1 = '' OR job = $1) AND ($2 = '' OR status = $2)
+		ORDER BY started_at DESC, id DESC
+		LIMIT $3 OFFSET $4;
+	`
+	deleteJobRunsBeforeQuery = `
+		DELETE FROM job_runs
+		WHERE started_at < $1 AND status <> 'running';
+	`
 )
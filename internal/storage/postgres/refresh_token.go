@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -9,23 +10,74 @@ import (
 	"time"
 )
 
-func (s *Storage) CreateRefreshToken(id, userId, tokenHash string, expiresAt time.Time) error {
+func (s *Storage) CreateRefreshToken(ctx context.Context, id, userId, familyId, tokenHash string, expiresAt time.Time) error {
 	const op = "storage.postgres.CreateRefreshToken"
 
-	_, err := s.db.Exec(createRefreshTokenQuery, id, userId, tokenHash, expiresAt)
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, createRefreshTokenQuery, id, userId, familyId, tokenHash, expiresAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) RotateRefreshToken(ctx context.Context, oldId, newId, userId, familyId, tokenHash string, expiresAt time.Time) error {
+	const op = "storage.postgres.RotateRefreshToken"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// begin transaction
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// marking old token as used (keeping the first use time for reuse detection)
+	res, err := tx.ExecContext(ctx, markRefreshTokenUsedQuery, oldId, newId)
 	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if old token was revoked in the meantime
+	rows, err := res.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		_ = tx.Rollback()
+		return storage.ErrRefreshTokenNotFound
+	}
+
+	// creating new token in the same family
+	_, err = tx.ExecContext(ctx, createRefreshTokenQuery, newId, userId, familyId, tokenHash, expiresAt)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	return nil
 }
 
-func (s *Storage) GetRefreshTokenById(id string) (auth.RefreshToken, error) {
+func (s *Storage) GetRefreshTokenById(ctx context.Context, id string) (auth.RefreshToken, error) {
 	const op = "storage.postgres.GetRefreshTokenById"
 
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
 	var refreshToken auth.RefreshToken
 
-	err := s.db.Get(&refreshToken, getRefreshTokenByIdQuery, id)
+	err := s.db.GetContext(ctx, &refreshToken, getRefreshTokenByIdQuery, id)
 	if errors.Is(err, sql.ErrNoRows) {
 		return auth.RefreshToken{}, storage.ErrRefreshTokenNotFound
 	}
@@ -36,11 +88,35 @@ func (s *Storage) GetRefreshTokenById(id string) (auth.RefreshToken, error) {
 	return refreshToken, nil
 }
 
-func (s *Storage) DeleteExpiredRefreshTokens() (int, error) {
+func (s *Storage) RevokeRefreshTokenFamily(ctx context.Context, familyId string) (int, error) {
+	const op = "storage.postgres.RevokeRefreshTokenFamily"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// deleting all tokens of the family
+	res, err := s.db.ExecContext(ctx, deleteRefreshTokenFamilyQuery, familyId)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// counting rows
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+func (s *Storage) DeleteExpiredRefreshTokens(ctx context.Context) (int, error) {
 	const op = "storage.postgres.DeleteExpiredRefreshTokens"
 
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
 	// deleting expired refresh tokens
-	rows, err := s.db.Exec(deleteExpiredRefreshTokensQuery)
+	rows, err := s.db.ExecContext(ctx, deleteExpiredRefreshTokensQuery)
 	if err != nil {
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
@@ -53,3 +129,20 @@ func (s *Storage) DeleteExpiredRefreshTokens() (int, error) {
 
 	return int(rowsAffected), nil
 }
+
+// CountActiveUsers counts users having a refresh token which can still be used
+func (s *Storage) CountActiveUsers(ctx context.Context) (int, error) {
+	const op = "storage.postgres.CountActiveUsers"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var count int
+
+	err := s.db.GetContext(ctx, &count, countActiveUsersQuery)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count, nil
+}
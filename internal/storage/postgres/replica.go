@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"main/internal/storage"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// primary is the database every write goes to. Writes are remembered, so
+// following reads of the client aren't served by replicas which may not have them yet
+type primary struct {
+	*sqlx.DB
+	writes *recentWrites
+}
+
+func (p primary) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	p.writes.add(ctx)
+
+	return p.DB.ExecContext(ctx, query, args...)
+}
+
+func (p primary) NamedExecContext(ctx context.Context, query string, arg any) (sql.Result, error) {
+	p.writes.add(ctx)
+
+	return p.DB.NamedExecContext(ctx, query, arg)
+}
+
+// BeginTxx counts every transaction as a write, read-only ones don't use transactions
+func (p primary) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error) {
+	p.writes.add(ctx)
+
+	return p.DB.BeginTxx(ctx, opts)
+}
+
+// GetContext counts statements returning rows, like INSERT ... RETURNING, as writes
+func (p primary) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	if isWrite(query) {
+		p.writes.add(ctx)
+	}
+
+	return p.DB.GetContext(ctx, dest, query, args...)
+}
+
+func (p primary) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	if isWrite(query) {
+		p.writes.add(ctx)
+	}
+
+	return p.DB.SelectContext(ctx, dest, query, args...)
+}
+
+func isWrite(query string) bool {
+	keyword, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+
+	switch strings.ToUpper(keyword) {
+	case "INSERT", "UPDATE", "DELETE", "WITH":
+		return true
+	}
+
+	return false
+}
+
+// reader returns database for reads which tolerate replication lag: one of
+// replicas in turn, or primary if there are none, ctx requires primary or the
+// user of ctx wrote recently
+func (s *Storage) reader(ctx context.Context) *sqlx.DB {
+	if len(s.replicas) == 0 || storage.PrimaryRequired(ctx) || s.db.writes.recent(ctx) {
+		return s.db.DB
+	}
+
+	next := s.nextReplica.Add(1)
+
+	return s.replicas[next%uint64(len(s.replicas))]
+}
+
+// recentWrites tracks writes in two ways. Last write carried by the client in
+// ctx works across instances, so it's the primary mechanism. Times of the last
+// write of every user are kept in process for window as well, they cover clients
+// which don't carry last write, but only for requests served by the same instance
+type recentWrites struct {
+	window time.Duration
+
+	mu        sync.Mutex
+	users     map[string]time.Time
+	lastSweep time.Time
+}
+
+func newRecentWrites(window time.Duration) *recentWrites {
+	return &recentWrites{
+		window: window,
+		users:  make(map[string]time.Time),
+	}
+}
+
+// add remembers write of ctx in its last write and for the user of ctx
+func (w *recentWrites) add(ctx context.Context) {
+	if w == nil {
+		return
+	}
+
+	now := time.Now()
+
+	if lastWrite, ok := storage.LastWriteFromContext(ctx); ok {
+		lastWrite.Set(now)
+	}
+
+	userId, ok := storage.UserFromContext(ctx)
+	if !ok {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.users[userId] = now
+	w.sweep(now)
+}
+
+// recent reports whether the client or the user of ctx wrote within window
+func (w *recentWrites) recent(ctx context.Context) bool {
+	if w == nil {
+		return false
+	}
+
+	// last write is accepted a window ahead as well to tolerate clock skew
+	// between instances, but a forged far future time doesn't pin the client to primary
+	if lastWrite, ok := storage.LastWriteFromContext(ctx); ok {
+		if since := time.Since(lastWrite.Time()); since > -w.window && since < w.window {
+			return true
+		}
+	}
+
+	userId, ok := storage.UserFromContext(ctx)
+	if !ok {
+		return false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	wroteAt, ok := w.users[userId]
+
+	return ok && time.Since(wroteAt) < w.window
+}
+
+// sweep drops writes older than window, at most once per window
+func (w *recentWrites) sweep(now time.Time) {
+	if now.Sub(w.lastSweep) < w.window {
+		return
+	}
+	w.lastSweep = now
+
+	for userId, wroteAt := range w.users {
+		if now.Sub(wroteAt) >= w.window {
+			delete(w.users, userId)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"main/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const window = 5 * time.Second
+
+// newInstance returns storage of one instance, databases are never queried
+func newInstance() *Storage {
+	return &Storage{
+		db:       primary{DB: sqlx.NewDb(&sql.DB{}, "postgres"), writes: newRecentWrites(window)},
+		replicas: []*sqlx.DB{sqlx.NewDb(&sql.DB{}, "postgres")},
+	}
+}
+
+func TestLastWriteAcrossInstances(t *testing.T) {
+	writer, reader := newInstance(), newInstance()
+
+	// request served by the writer instance records the write for the client
+	lastWrite := storage.NewLastWrite(time.Time{})
+	writer.db.writes.add(storage.WithLastWrite(storage.WithUser(context.Background(), "user"), lastWrite))
+
+	if lastWrite.Time().IsZero() {
+		t.Fatal("write isn't recorded in last write")
+	}
+
+	// next request of the client comes to another instance with the time of the write
+	ctx := storage.WithLastWrite(storage.WithUser(context.Background(), "user"), storage.NewLastWrite(lastWrite.Time()))
+	if reader.reader(ctx) != reader.db.DB {
+		t.Fatal("read after write on another instance went to replica")
+	}
+
+	// without last write the other instance knows nothing about the write
+	if reader.reader(storage.WithUser(context.Background(), "user")) == reader.db.DB {
+		t.Fatal("read without last write went to primary")
+	}
+}
+
+func TestLastWriteWindow(t *testing.T) {
+	s := newInstance()
+
+	tests := []struct {
+		name    string
+		at      time.Time
+		primary bool
+	}{
+		{"never wrote", time.Time{}, false},
+		{"recent write", time.Now().Add(-time.Second), true},
+		{"write out of window", time.Now().Add(-2 * window), false},
+		{"clock skew", time.Now().Add(time.Second), true},
+		{"far future", time.Now().Add(24 * time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := storage.WithLastWrite(context.Background(), storage.NewLastWrite(tt.at))
+
+			if got := s.reader(ctx) == s.db.DB; got != tt.primary {
+				t.Fatalf("read from primary: got %v, want %v", got, tt.primary)
+			}
+		})
+	}
+}
+
+func TestUserWriteInProcess(t *testing.T) {
+	s := newInstance()
+
+	ctx := storage.WithUser(context.Background(), "user")
+	s.db.writes.add(ctx)
+
+	if s.reader(ctx) != s.db.DB {
+		t.Fatal("read after write of the user went to replica")
+	}
+	if s.reader(storage.WithUser(context.Background(), "other")) == s.db.DB {
+		t.Fatal("read of another user went to primary")
+	}
+}
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -11,16 +12,20 @@ import (
 )
 
 const (
-	ErrUniqueViolation = "23505"
+	ErrUniqueViolation     = "23505"
+	ErrForeignKeyViolation = "23503"
 )
 
-func (s *Storage) CreateUser(email, name, passwordHash string) (string, error) {
+func (s *Storage) CreateUser(ctx context.Context, email, name, passwordHash string) (string, error) {
 	const op = "storage.postgres.CreateUser"
 
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
 	// creating user
 	var id string
 
-	err := s.db.Get(&id, createUserQuery, email, name, passwordHash)
+	err := s.db.GetContext(ctx, &id, createUserQuery, email, name, passwordHash)
 	if err != nil {
 		// check if user already exists
 		var sqlxerr *pq.Error
@@ -34,13 +39,16 @@ func (s *Storage) CreateUser(email, name, passwordHash string) (string, error) {
 	return id, nil
 }
 
-func (s *Storage) GetUser(email string) (user.User, error) {
+func (s *Storage) GetUser(ctx context.Context, email string) (user.User, error) {
 	const op = "storage.postgres.GetUser"
 
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
 	// getting user by email
 	var userFromDB user.User
 
-	err := s.db.Get(&userFromDB, getUserByEmailQuery, email)
+	err := s.db.GetContext(ctx, &userFromDB, getUserByEmailQuery, email)
 	if errors.Is(err, sql.ErrNoRows) {
 		return user.User{}, storage.ErrUserNotFound
 	}
@@ -51,13 +59,16 @@ func (s *Storage) GetUser(email string) (user.User, error) {
 	return userFromDB, nil
 }
 
-func (s *Storage) GetUserById(id string) (user.User, error) {
-	const op = "storage.postgres.GetUser"
+func (s *Storage) GetUserById(ctx context.Context, id string) (user.User, error) {
+	const op = "storage.postgres.GetUserById"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
 
 	// getting user by id
 	var userFromDB user.User
 
-	err := s.db.Get(&userFromDB, getUserByIdQuery, id)
+	err := s.db.GetContext(ctx, &userFromDB, getUserByIdQuery, id)
 	if errors.Is(err, sql.ErrNoRows) {
 		return user.User{}, storage.ErrUserNotFound
 	}
@@ -67,3 +78,215 @@ func (s *Storage) GetUserById(id string) (user.User, error) {
 
 	return userFromDB, nil
 }
+
+func (s *Storage) UpdateUserPassword(ctx context.Context, id, passwordHash string) error {
+	const op = "storage.postgres.UpdateUserPassword"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, updateUserPasswordQuery, id, passwordHash)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if user wasn't found
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (s *Storage) SetEmailVerified(ctx context.Context, id string) error {
+	const op = "storage.postgres.SetEmailVerified"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, setEmailVerifiedQuery, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if user wasn't found
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (s *Storage) UpdateUserName(ctx context.Context, id, name string) error {
+	const op = "storage.postgres.UpdateUserName"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, updateUserNameQuery, id, name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if user wasn't found
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (s *Storage) SetPendingEmail(ctx context.Context, id, email string) error {
+	const op = "storage.postgres.SetPendingEmail"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, setPendingEmailQuery, id, email)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if user wasn't found
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange replaces email with the pending one and marks it verified
+func (s *Storage) ConfirmEmailChange(ctx context.Context, id string) (string, error) {
+	const op = "storage.postgres.ConfirmEmailChange"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var email string
+
+	err := s.db.GetContext(ctx, &email, confirmEmailChangeQuery, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", storage.ErrNoPendingEmail
+	}
+	if err != nil {
+		// check if email was taken after change was requested
+		var sqlxerr *pq.Error
+		if errors.As(err, &sqlxerr) && sqlxerr.Code == ErrUniqueViolation {
+			return "", storage.ErrUserAlreadyExists
+		}
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return email, nil
+}
+
+func (s *Storage) GetUserNoteIds(ctx context.Context, userId string) ([]int, error) {
+	const op = "storage.postgres.GetUserNoteIds"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	ids := []int{}
+
+	err := s.db.SelectContext(ctx, &ids, getUserNoteIdsQuery, userId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ids, nil
+}
+
+// GetSolelyOwnedWorkspaceIds returns workspaces which have no other owner than the user
+func (s *Storage) GetSolelyOwnedWorkspaceIds(ctx context.Context, userId string) ([]string, error) {
+	const op = "storage.postgres.GetSolelyOwnedWorkspaceIds"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	ids := []string{}
+
+	err := s.db.SelectContext(ctx, &ids, getSolelyOwnedWorkspaceIdsQuery, userId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ids, nil
+}
+
+// DeleteUser deletes user, everything owned by him is removed by foreign keys cascade.
+// Workspaces are not, so the only owner of a workspace gets ErrSoleWorkspaceOwner
+func (s *Storage) DeleteUser(ctx context.Context, id string) error {
+	const op = "storage.postgres.DeleteUser"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// begin transaction
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// owned workspaces are locked, so concurrent member changes can't make
+	// the user their only owner after the check
+	var owned []string
+
+	err = tx.SelectContext(ctx, &owned, lockOwnedWorkspacesQuery, id)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	var solelyOwned []string
+
+	err = tx.SelectContext(ctx, &solelyOwned, getSolelyOwnedWorkspaceIdsQuery, id)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if len(solelyOwned) > 0 {
+		_ = tx.Rollback()
+		return storage.ErrSoleWorkspaceOwner
+	}
+
+	res, err := tx.ExecContext(ctx, deleteUserQuery, id)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if user wasn't found
+	rows, err := res.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		_ = tx.Rollback()
+		return storage.ErrUserNotFound
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"main/internal/models/user"
+	"main/internal/storage"
+)
+
+func (s *Storage) ListAccounts(ctx context.Context, query string, limit, offset int) ([]user.Account, error) {
+	const op = "storage.sqlite.ListAccounts"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var accounts []user.Account
+
+	err := s.db.SelectContext(ctx, &accounts, listAccountsQuery, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if accounts == nil {
+		return []user.Account{}, nil
+	}
+
+	return accounts, nil
+}
+
+func (s *Storage) GetAccount(ctx context.Context, id string) (user.Account, error) {
+	const op = "storage.sqlite.GetAccount"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var account user.Account
+
+	err := s.db.GetContext(ctx, &account, getAccountQuery, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return user.Account{}, storage.ErrUserNotFound
+	}
+	if err != nil {
+		return user.Account{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return account, nil
+}
+
+func (s *Storage) GetUserUsage(ctx context.Context, id string) (user.Usage, error) {
+	const op = "storage.sqlite.GetUserUsage"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var usage user.Usage
+
+	err := s.db.GetContext(ctx, &usage, getUserUsageQuery, id)
+	if err != nil {
+		return user.Usage{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return usage, nil
+}
+
+func (s *Storage) DisableUser(ctx context.Context, id string) error {
+	const op = "storage.sqlite.DisableUser"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	return s.execUserUpdate(ctx, op, disableUserQuery, id)
+}
+
+func (s *Storage) EnableUser(ctx context.Context, id string) error {
+	const op = "storage.sqlite.EnableUser"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	return s.execUserUpdate(ctx, op, enableUserQuery, id)
+}
+
+func (s *Storage) SetUserRole(ctx context.Context, id, role string) error {
+	const op = "storage.sqlite.SetUserRole"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	return s.execUserUpdate(ctx, op, setUserRoleQuery, id, role)
+}
+
+// GrantRoleByEmails sets role to every existing user with one of emails
+func (s *Storage) GrantRoleByEmails(ctx context.Context, emails []string, role string) (int, error) {
+	const op = "storage.sqlite.GrantRoleByEmails"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// sqlite has no arrays, emails are passed as json array
+	emailsJSON, err := json.Marshal(emails)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := s.db.ExecContext(ctx, grantRoleByEmailsQuery, string(emailsJSON), role)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// counting rows
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+func (s *Storage) execUserUpdate(ctx context.Context, op, query string, args ...any) error {
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if user wasn't found
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"main/internal/audit"
+	"time"
+)
+
+func (s *Storage) RecordAuditEvent(ctx context.Context, event audit.Event) error {
+	const op = "storage.sqlite.RecordAuditEvent"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, recordAuditEventQuery,
+		event.Action,
+		event.ActorId,
+		event.TargetType,
+		event.TargetId,
+		event.IP,
+		event.UserAgent,
+		event.RequestId,
+		event.Details,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) GetAuditEvents(ctx context.Context, filter audit.Filter) ([]audit.Event, error) {
+	const op = "storage.sqlite.GetAuditEvents"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var events []audit.Event
+
+	err := s.db.SelectContext(ctx, &events, getAuditEventsQuery,
+		filter.UserId,
+		filter.ActorId,
+		filter.Action,
+		filter.TargetType,
+		filter.TargetId,
+		utc(filter.From),
+		utc(filter.To),
+		filter.Limit,
+		filter.Offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if events == nil {
+		return []audit.Event{}, nil
+	}
+
+	return events, nil
+}
+
+func (s *Storage) DeleteAuditEventsBefore(ctx context.Context, before time.Time) (int, error) {
+	const op = "storage.sqlite.DeleteAuditEventsBefore"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, deleteAuditEventsBeforeQuery, before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// counting rows
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(rowsAffected), nil
+}
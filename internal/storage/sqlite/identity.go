@@ -0,0 +1,141 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"main/internal/auth/oidc"
+	"main/internal/storage"
+)
+
+func (s *Storage) CreateOIDCState(ctx context.Context, state oidc.State) error {
+	const op = "storage.sqlite.CreateOIDCState"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	state.ExpiresAt = state.ExpiresAt.UTC()
+
+	_, err := s.db.NamedExecContext(ctx, createOIDCStateQuery, state)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) ConsumeOIDCState(ctx context.Context, state string) (oidc.State, error) {
+	const op = "storage.sqlite.ConsumeOIDCState"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// deleting state so it can be used only once
+	var stateFromDB oidc.State
+
+	err := s.db.GetContext(ctx, &stateFromDB, consumeOIDCStateQuery, state)
+	if errors.Is(err, sql.ErrNoRows) {
+		return oidc.State{}, storage.ErrOIDCStateNotFound
+	}
+	if err != nil {
+		return oidc.State{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return stateFromDB, nil
+}
+
+func (s *Storage) DeleteExpiredOIDCStates(ctx context.Context) (int, error) {
+	const op = "storage.sqlite.DeleteExpiredOIDCStates"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, deleteExpiredOIDCStatesQuery)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// counting rows
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+func (s *Storage) GetUserIdByIdentity(ctx context.Context, provider, subject string) (string, error) {
+	const op = "storage.sqlite.GetUserIdByIdentity"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var userId string
+
+	err := s.db.GetContext(ctx, &userId, getUserIdByIdentityQuery, provider, subject)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", storage.ErrIdentityNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userId, nil
+}
+
+func (s *Storage) LinkUserIdentity(ctx context.Context, userId string, identity oidc.Identity) error {
+	const op = "storage.sqlite.LinkUserIdentity"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, createUserIdentityQuery, identity.Provider, identity.Subject, userId, identity.Email)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) CreateUserWithIdentity(ctx context.Context, identity oidc.Identity) (string, error) {
+	const op = "storage.sqlite.CreateUserWithIdentity"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// begin transaction
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	// creating user without password, it can be set later with password reset
+	var id string
+
+	err = tx.GetContext(ctx, &id, createExternalUserQuery, identity.Email, identity.Name, identity.EmailVerified)
+	if err != nil {
+		_ = tx.Rollback()
+
+		// check if user already exists
+		if isUniqueViolation(err) {
+			return "", storage.ErrUserAlreadyExists
+		}
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	// linking identity
+	_, err = tx.ExecContext(ctx, createUserIdentityQuery, identity.Provider, identity.Subject, id, identity.Email)
+	if err != nil {
+		_ = tx.Rollback()
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,110 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"main/internal/scheduler"
+	"time"
+)
+
+// TryJobLock takes lock of the job in this process, it's enough since database
+// file isn't shared between replicas
+func (s *Storage) TryJobLock(ctx context.Context, job string) (func(), bool, error) {
+	const op = "storage.sqlite.TryJobLock"
+
+	if err := ctx.Err(); err != nil {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.jobLocksMu.Lock()
+	defer s.jobLocksMu.Unlock()
+
+	if s.jobLocks[job] {
+		return nil, false, nil
+	}
+	s.jobLocks[job] = true
+
+	unlock := func() {
+		s.jobLocksMu.Lock()
+		defer s.jobLocksMu.Unlock()
+
+		delete(s.jobLocks, job)
+	}
+
+	return unlock, true, nil
+}
+
+func (s *Storage) StartJobRun(ctx context.Context, job string, scheduledAt time.Time, instance string) (int64, bool, error) {
+	const op = "storage.sqlite.StartJobRun"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var id int64
+
+	err := s.db.GetContext(ctx, &id, startJobRunQuery, job, scheduledAt.UTC(), instance)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, true, nil
+}
+
+func (s *Storage) FinishJobRun(ctx context.Context, id int64, status string, attempts int, runErr string) error {
+	const op = "storage.sqlite.FinishJobRun"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, finishJobRunQuery, id, status, attempts, runErr)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) GetJobRuns(ctx context.Context, job, status string, limit, offset int) ([]scheduler.Run, error) {
+	const op = "storage.sqlite.GetJobRuns"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var runs []scheduler.Run
+
+	err := s.db.SelectContext(ctx, &runs, getJobRunsQuery, job, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if runs == nil {
+		return []scheduler.Run{}, nil
+	}
+
+	return runs, nil
+}
+
+func (s *Storage) DeleteJobRunsBefore(ctx context.Context, before time.Time) (int, error) {
+	const op = "storage.sqlite.DeleteJobRunsBefore"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, deleteJobRunsBeforeQuery, before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// counting rows
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(rowsAffected), nil
+}
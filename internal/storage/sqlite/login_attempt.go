@@ -0,0 +1,159 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"main/internal/auth/lockout"
+	"main/internal/storage"
+	"time"
+)
+
+func (s *Storage) GetLoginAttempt(ctx context.Context, key string) (lockout.Attempt, error) {
+	const op = "storage.sqlite.GetLoginAttempt"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var attempt lockout.Attempt
+
+	err := s.db.GetContext(ctx, &attempt, getLoginAttemptQuery, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return lockout.Attempt{}, storage.ErrLoginAttemptNotFound
+	}
+	if err != nil {
+		return lockout.Attempt{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return attempt, nil
+}
+
+// ReserveLoginAttempt sets locked_until in a single upsert, which is skipped
+// while the key is locked, so only one of concurrent reservations wins
+func (s *Storage) ReserveLoginAttempt(ctx context.Context, key string, until time.Time) (lockout.Attempt, bool, error) {
+	const op = "storage.sqlite.ReserveLoginAttempt"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var attempt lockout.Attempt
+
+	err := s.db.GetContext(ctx, &attempt, reserveLoginAttemptQuery, key, until.UTC(), time.Now().UTC())
+	if errors.Is(err, sql.ErrNoRows) {
+		// the key is locked, nothing was changed
+		attempt, err = s.GetLoginAttempt(ctx, key)
+		if err != nil {
+			return lockout.Attempt{}, false, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return attempt, false, nil
+	}
+	if err != nil {
+		return lockout.Attempt{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return attempt, true, nil
+}
+
+func (s *Storage) AddLoginFailure(ctx context.Context, key string, window time.Duration) (lockout.Attempt, error) {
+	const op = "storage.sqlite.AddLoginFailure"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// incrementing in a single upsert so concurrent failures are not lost
+	var attempt lockout.Attempt
+
+	err := s.db.GetContext(ctx, &attempt, addLoginFailureQuery, key, time.Now().UTC().Add(-window))
+	if err != nil {
+		return lockout.Attempt{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return attempt, nil
+}
+
+func (s *Storage) LockLoginKey(ctx context.Context, key string, until time.Time) error {
+	const op = "storage.sqlite.LockLoginKey"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, lockLoginKeyQuery, key, until.UTC())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteLoginAttempt(ctx context.Context, key string) error {
+	const op = "storage.sqlite.DeleteLoginAttempt"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, deleteLoginAttemptQuery, key)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) RecordLoginFailure(ctx context.Context, key string, at time.Time) error {
+	const op = "storage.sqlite.RecordLoginFailure"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, recordLoginFailureQuery, key, at.UTC())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) GetLoginFailures(ctx context.Context, key string, since time.Time) ([]time.Time, error) {
+	const op = "storage.sqlite.GetLoginFailures"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	failures := []time.Time{}
+
+	err := s.db.SelectContext(ctx, &failures, getLoginFailuresQuery, key, since.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return failures, nil
+}
+
+// DeleteStaleLoginAttempts deletes attempts and ip failures which are out of window
+func (s *Storage) DeleteStaleLoginAttempts(ctx context.Context, window time.Duration) (int, error) {
+	const op = "storage.sqlite.DeleteStaleLoginAttempts"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	deleted := 0
+
+	for _, query := range []string{deleteStaleLoginAttemptsQuery, deleteStaleLoginFailuresQuery} {
+		res, err := s.db.ExecContext(ctx, query, time.Now().UTC().Add(-window))
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+
+		// counting rows
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+
+		deleted += int(rowsAffected)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,230 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"main/internal/models/note"
+	"main/internal/storage"
+	"main/internal/webhook"
+)
+
+func (s *Storage) AddNoteNode(ctx context.Context, noteId int, contentType string, content string) (int, error) {
+	const op = "storage.sqlite.AddNoteNode"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// begin transaction
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// creating note node
+	var id int
+
+	err = tx.GetContext(ctx, &id, createBlankNoteNodeQuery, noteId, contentType, content)
+	if err != nil {
+		_ = tx.Rollback()
+		// check if note doesn't exist
+		if isForeignKeyViolation(err) {
+			return 0, storage.ErrNoteNotFound
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// set updated_at field on note
+	_, err = tx.ExecContext(ctx, setUpdatedAtQuery, noteId)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = enqueueNoteEvent(ctx, tx, op, webhook.EventNodeCreated, webhook.Data{NoteId: noteId, NodeId: id})
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+func (s *Storage) DeleteNoteNode(ctx context.Context, id int) error {
+	const op = "storage.sqlite.DeleteNoteNode"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// begin transaction
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// deleting node with returning (note_id, order)
+	var tempNoteNode note.NoteNode
+
+	err = tx.GetContext(ctx, &tempNoteNode, deleteNoteNodeQuery, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		_ = tx.Rollback()
+		return storage.ErrNoteNodeNotFound
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// getting note_id and order from deleted node
+	var noteId, order = tempNoteNode.NoteId, tempNoteNode.Order
+
+	// update all note nodes' order after deleted node
+	_, err = tx.ExecContext(ctx, updateOrderAfterDeleteQuery, noteId, order)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// set updated_at field on note
+	_, err = tx.ExecContext(ctx, setUpdatedAtQuery, noteId)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = enqueueNoteEvent(ctx, tx, op, webhook.EventNodeDeleted, webhook.Data{NoteId: noteId, NodeId: id})
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) UpdateNoteNodeContent(ctx context.Context, id int, content string) error {
+	const op = "storage.sqlite.UpdateNoteNodeContent"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// begin transaction
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// updating note node with returning note_id
+	var noteId int
+
+	err = tx.GetContext(ctx, &noteId, updateNoteNodeContentQuery, id, content)
+	if errors.Is(err, sql.ErrNoRows) {
+		_ = tx.Rollback()
+		return storage.ErrNoteNodeNotFound
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// set updated_at field on note
+	_, err = tx.ExecContext(ctx, setUpdatedAtQuery, noteId)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = enqueueNoteEvent(ctx, tx, op, webhook.EventNodeUpdated, webhook.Data{NoteId: noteId, NodeId: id})
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) CanUserEditNoteNode(ctx context.Context, userId string, noteNodeId int) (bool, error) {
+	const op = "storage.sqlite.CanUserEditNoteNode"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var exists int
+
+	err := s.db.GetContext(ctx, &exists, canUserEditNoteNodeQuery, userId, noteNodeId)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return exists == 1, nil
+}
+
+func (s *Storage) CanUserReadNoteNode(ctx context.Context, userId string, noteNodeId int) (bool, error) {
+	const op = "storage.sqlite.CanUserReadNoteNode"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var exists int
+
+	err := s.db.GetContext(ctx, &exists, canUserReadNoteNodeQuery, userId, noteNodeId)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return exists == 1, nil
+}
+
+func (s *Storage) GetNodeById(ctx context.Context, id int) (note.NoteNode, error) {
+	const op = "storage.sqlite.GetNodeById"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// getting note id by note node id
+	var node note.NoteNode
+
+	err := s.db.GetContext(ctx, &node, getNoteIdByNoteNodeIdQuery, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return node, storage.ErrNoteNodeNotFound
+	}
+	if err != nil {
+		return node, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return node, nil
+}
+
+func (s *Storage) GetAllNotesNodes(ctx context.Context, noteId int) ([]note.NoteNode, error) {
+	const op = "storage.sqlite.GetAllNotesNodes"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var nodes []note.NoteNode
+
+	err := s.db.SelectContext(ctx, &nodes, getAllNotesNodesQuery, noteId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nodes, storage.ErrNoteNotFound
+	}
+	if err != nil {
+		return nodes, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nodes, nil
+}
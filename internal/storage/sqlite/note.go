@@ -0,0 +1,402 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"main/internal/models/note"
+	"main/internal/storage"
+	"main/internal/webhook"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func (s *Storage) CreateNote(ctx context.Context, noteTitle string, userId string) (int, error) {
+	const op = "storage.sqlite.CreateNote"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	return s.createNote(ctx, op, createNoteQuery, noteTitle, userId)
+}
+
+func (s *Storage) GetUserNotes(ctx context.Context, userId string) ([]note.NotePreview, error) {
+	const op = "storage.sqlite.GetUserNotes"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var notes []note.NotePreview
+
+	// getting notes by user_id
+	err := s.db.SelectContext(ctx, &notes, getNotesByUserIdQuery, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return []note.NotePreview{}, nil
+	}
+	if err != nil {
+		return notes, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if notes == nil {
+		return []note.NotePreview{}, nil
+	}
+
+	return notes, nil
+}
+
+func (s *Storage) CreateWorkspaceNote(ctx context.Context, noteTitle string, workspaceId string) (int, error) {
+	const op = "storage.sqlite.CreateWorkspaceNote"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	return s.createNote(ctx, op, createWorkspaceNoteQuery, noteTitle, workspaceId)
+}
+
+// createNote creates note owned by user or workspace, depending on query
+func (s *Storage) createNote(ctx context.Context, op, query, noteTitle, ownerId string) (int, error) {
+	// begin transaction
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// creating note
+	var id int
+
+	err = tx.GetContext(ctx, &id, query, noteTitle, ownerId)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// adding blank text note node
+	_, err = tx.ExecContext(ctx, createBlankNoteNodeQuery, id, note.ContentTypeText, "")
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = enqueueNoteEvent(ctx, tx, op, webhook.EventNoteCreated, webhook.Data{NoteId: id})
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+func (s *Storage) GetWorkspaceNotes(ctx context.Context, workspaceId string) ([]note.NotePreview, error) {
+	const op = "storage.sqlite.GetWorkspaceNotes"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var notes []note.NotePreview
+
+	// getting notes by workspace_id
+	err := s.db.SelectContext(ctx, &notes, getWorkspaceNotesQuery, workspaceId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if notes == nil {
+		return []note.NotePreview{}, nil
+	}
+
+	return notes, nil
+}
+
+func (s *Storage) GetNoteById(ctx context.Context, id int) (note.Note, error) {
+	const op = "storage.sqlite.GetNoteById"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// getting note by id without nodes
+	var noteFromDB note.Note
+
+	err := s.db.GetContext(ctx, &noteFromDB, getNoteQuery, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return noteFromDB, storage.ErrNoteNotFound
+	}
+	if err != nil {
+		return noteFromDB, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return noteFromDB, nil
+}
+
+func (s *Storage) UpdateNoteTitle(ctx context.Context, id int, title string) error {
+	const op = "storage.sqlite.UpdateNoteTitle"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	return s.changeNote(ctx, op, updateNoteTitleQuery, webhook.EventNoteUpdated, id, title)
+}
+
+// changeNote runs query against the note and queues webhook event in the same
+// transaction. Event of deleted note is queued before deletion, while note is
+// still there to find its webhooks
+func (s *Storage) changeNote(ctx context.Context, op, query, event string, id int, args ...any) error {
+	// begin transaction
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = enqueueNoteEvent(ctx, tx, op, event, webhook.Data{NoteId: id})
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, query, append([]any{id}, args...)...)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if note wasn't found
+	rows, err := res.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		_ = tx.Rollback()
+		return storage.ErrNoteNotFound
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) UpdateFullNote(ctx context.Context, id int, note note.Note) (int, error) {
+	const op = "storage.sqlite.UpdateFullNote"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var rowsAffected int
+
+	// begin transaction
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// updating note title
+	res, err := tx.ExecContext(ctx, updateNoteTitleQuery, id, note.Title)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// counting rows
+	rows, err := res.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	rowsAffected += int(rows)
+
+	// iterating over note nodes and updating content
+	rows, err = updateAllNestedNodes(ctx, tx, op, note.Nodes)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rowsAffected += int(rows)
+
+	err = enqueueNoteEvent(ctx, tx, op, webhook.EventNoteUpdated, webhook.Data{NoteId: id})
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return rowsAffected, nil
+}
+
+func (s *Storage) ArchiveNote(ctx context.Context, id int) error {
+	const op = "storage.sqlite.ArchiveNote"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	return s.changeNote(ctx, op, archiveNoteQuery, webhook.EventNoteArchived, id)
+}
+
+func (s *Storage) UnarchiveNote(ctx context.Context, id int) error {
+	const op = "storage.sqlite.UnarchiveNote"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	return s.changeNote(ctx, op, unarchiveNoteQuery, webhook.EventNoteUnarchived, id)
+}
+
+func (s *Storage) DeleteNote(ctx context.Context, id int) error {
+	const op = "storage.sqlite.DeleteNote"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	return s.changeNote(ctx, op, deleteNoteQuery, webhook.EventNoteDeleted, id)
+}
+
+func (s *Storage) UpdateNoteNodeOrder(ctx context.Context, noteId int, oldOrder int, newOrder int) error {
+	const op = "storage.sqlite.UpdateNoteNodeOrder"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// begin transaction
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if note node with new_order out of bounds
+	err = checkBounds(ctx, tx, op, noteId, newOrder)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if note node with old_order exists
+	err = isNoteNodeExists(ctx, tx, op, noteId, oldOrder)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// update all note nodes' order between old_order and new_order
+	_, err = tx.ExecContext(ctx, updateOrderQuery, noteId, oldOrder, newOrder)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// set updated_at field on note
+	_, err = tx.ExecContext(ctx, setUpdatedAtQuery, noteId)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = enqueueNoteEvent(ctx, tx, op, webhook.EventNoteUpdated, webhook.Data{NoteId: noteId})
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) CanUserEditNote(ctx context.Context, userId string, noteId int) (bool, error) {
+	const op = "storage.sqlite.CanUserEditNote"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var exists int
+
+	err := s.db.GetContext(ctx, &exists, canUserEditNoteQuery, userId, noteId)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return exists == 1, nil
+}
+
+func (s *Storage) CanUserReadNote(ctx context.Context, userId string, noteId int) (bool, error) {
+	const op = "storage.sqlite.CanUserReadNote"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var exists int
+
+	err := s.db.GetContext(ctx, &exists, canUserReadNoteQuery, userId, noteId)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return exists == 1, nil
+}
+
+func updateAllNestedNodes(ctx context.Context, tx *sqlx.Tx, op string, nodes []note.NoteNode) (int64, error) {
+	var rowsAffected int64
+
+	for _, noteNode := range nodes {
+		res, err := tx.ExecContext(ctx, updateNoteNodeContentQuery, noteNode.Id, noteNode.Content)
+		if err != nil {
+			_ = tx.Rollback()
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			_ = tx.Rollback()
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+
+		rowsAffected += rows
+	}
+
+	return rowsAffected, nil
+}
+
+func checkBounds(ctx context.Context, tx *sqlx.Tx, op string, noteId int, newOrder int) error {
+	var count int
+
+	err := tx.GetContext(ctx, &count, nodesCountQuery, noteId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: no note nodes found with note_id=%d", storage.ErrNoteNodeNotFound, noteId)
+	}
+	if newOrder < 0 || newOrder >= count {
+		return fmt.Errorf("%w: newOrder %d is out of range (must be between 0 and %d)", storage.ErrNoteNodeNotFound, newOrder, count-1)
+	}
+
+	return nil
+}
+
+func isNoteNodeExists(ctx context.Context, tx *sqlx.Tx, op string, noteId int, oldOrder int) error {
+	var exists int
+
+	err := tx.GetContext(ctx, &exists, getNoteNodeByOrderQuery, noteId, oldOrder)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if exists == 0 {
+		return fmt.Errorf("%w: no note node found with note_id=%d and order=%d", storage.ErrNoteNodeNotFound, noteId, oldOrder)
+	}
+
+	return nil
+}
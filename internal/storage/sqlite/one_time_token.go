@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"main/internal/storage"
+	"time"
+)
+
+func (s *Storage) CreateOneTimeToken(ctx context.Context, userId, purpose, tokenHash string, expiresAt time.Time) error {
+	const op = "storage.sqlite.CreateOneTimeToken"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// begin transaction
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// invalidating previously issued tokens with the same purpose
+	_, err = tx.ExecContext(ctx, deleteOneTimeTokensQuery, userId, purpose)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx, createOneTimeTokenQuery, userId, purpose, tokenHash, expiresAt.UTC())
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) ConsumeOneTimeToken(ctx context.Context, purpose, tokenHash string) (string, error) {
+	const op = "storage.sqlite.ConsumeOneTimeToken"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var userId string
+
+	err := s.db.GetContext(ctx, &userId, consumeOneTimeTokenQuery, purpose, tokenHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", storage.ErrOneTimeTokenNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userId, nil
+}
+
+func (s *Storage) DeleteExpiredOneTimeTokens(ctx context.Context) (int, error) {
+	const op = "storage.sqlite.DeleteExpiredOneTimeTokens"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, deleteExpiredOneTimeTokensQuery)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// counting rows
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(rowsAffected), nil
+}
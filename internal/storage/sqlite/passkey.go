@@ -0,0 +1,147 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"main/internal/auth/passkey"
+	"main/internal/storage"
+)
+
+func (s *Storage) CreateWebAuthnSession(ctx context.Context, session passkey.Session) error {
+	const op = "storage.sqlite.CreateWebAuthnSession"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	session.ExpiresAt = session.ExpiresAt.UTC()
+
+	_, err := s.db.NamedExecContext(ctx, createWebAuthnSessionQuery, session)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) ConsumeWebAuthnSession(ctx context.Context, id, purpose string) (passkey.Session, error) {
+	const op = "storage.sqlite.ConsumeWebAuthnSession"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// deleting session so the challenge can be used only once
+	var session passkey.Session
+
+	err := s.db.GetContext(ctx, &session, consumeWebAuthnSessionQuery, id, purpose)
+	if errors.Is(err, sql.ErrNoRows) {
+		return passkey.Session{}, storage.ErrWebAuthnSessionNotFound
+	}
+	if err != nil {
+		return passkey.Session{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return session, nil
+}
+
+func (s *Storage) DeleteExpiredWebAuthnSessions(ctx context.Context) (int, error) {
+	const op = "storage.sqlite.DeleteExpiredWebAuthnSessions"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, deleteExpiredWebAuthnSessionsQuery)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// counting rows
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+func (s *Storage) CreatePasskey(ctx context.Context, passkey passkey.Passkey) error {
+	const op = "storage.sqlite.CreatePasskey"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	_, err := s.db.NamedExecContext(ctx, createPasskeyQuery, passkey)
+	if err != nil {
+		// check if credential is already registered
+		if isUniqueViolation(err) {
+			return storage.ErrPasskeyAlreadyExists
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) GetUserPasskeys(ctx context.Context, userId string) ([]passkey.Passkey, error) {
+	const op = "storage.sqlite.GetUserPasskeys"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	passkeys := []passkey.Passkey{}
+
+	err := s.db.SelectContext(ctx, &passkeys, getUserPasskeysQuery, userId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return passkeys, nil
+}
+
+func (s *Storage) UpdatePasskeyUsage(ctx context.Context, id string, signCount int64, backupState bool) error {
+	const op = "storage.sqlite.UpdatePasskeyUsage"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, updatePasskeyUsageQuery, id, signCount, backupState)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if passkey exists
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrPasskeyNotFound
+	}
+
+	return nil
+}
+
+func (s *Storage) DeletePasskey(ctx context.Context, id, userId string) error {
+	const op = "storage.sqlite.DeletePasskey"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, deletePasskeyQuery, id, userId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if passkey exists and belongs to user
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrPasskeyNotFound
+	}
+
+	return nil
+}
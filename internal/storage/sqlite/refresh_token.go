@@ -0,0 +1,148 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"main/internal/auth"
+	"main/internal/storage"
+	"time"
+)
+
+func (s *Storage) CreateRefreshToken(ctx context.Context, id, userId, familyId, tokenHash string, expiresAt time.Time) error {
+	const op = "storage.sqlite.CreateRefreshToken"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, createRefreshTokenQuery, id, userId, familyId, tokenHash, expiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) RotateRefreshToken(ctx context.Context, oldId, newId, userId, familyId, tokenHash string, expiresAt time.Time) error {
+	const op = "storage.sqlite.RotateRefreshToken"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// begin transaction
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// marking old token as used (keeping the first use time for reuse detection)
+	res, err := tx.ExecContext(ctx, markRefreshTokenUsedQuery, oldId, newId)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if old token was revoked in the meantime
+	rows, err := res.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		_ = tx.Rollback()
+		return storage.ErrRefreshTokenNotFound
+	}
+
+	// creating new token in the same family
+	_, err = tx.ExecContext(ctx, createRefreshTokenQuery, newId, userId, familyId, tokenHash, expiresAt.UTC())
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) GetRefreshTokenById(ctx context.Context, id string) (auth.RefreshToken, error) {
+	const op = "storage.sqlite.GetRefreshTokenById"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var refreshToken auth.RefreshToken
+
+	err := s.db.GetContext(ctx, &refreshToken, getRefreshTokenByIdQuery, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return auth.RefreshToken{}, storage.ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return auth.RefreshToken{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return refreshToken, nil
+}
+
+func (s *Storage) RevokeRefreshTokenFamily(ctx context.Context, familyId string) (int, error) {
+	const op = "storage.sqlite.RevokeRefreshTokenFamily"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// deleting all tokens of the family
+	res, err := s.db.ExecContext(ctx, deleteRefreshTokenFamilyQuery, familyId)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// counting rows
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+func (s *Storage) DeleteExpiredRefreshTokens(ctx context.Context) (int, error) {
+	const op = "storage.sqlite.DeleteExpiredRefreshTokens"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// deleting expired refresh tokens
+	rows, err := s.db.ExecContext(ctx, deleteExpiredRefreshTokensQuery)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// counting rows
+	rowsAffected, err := rows.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// CountActiveUsers counts users having a refresh token which can still be used
+func (s *Storage) CountActiveUsers(ctx context.Context) (int, error) {
+	const op = "storage.sqlite.CountActiveUsers"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var count int
+
+	err := s.db.GetContext(ctx, &count, countActiveUsersQuery)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count, nil
+}
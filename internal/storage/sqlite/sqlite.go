@@ -0,0 +1,217 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"main/internal/config"
+	"main/internal/storage/migrator"
+	"main/internal/tracing"
+	"main/migrations"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/XSAM/otelsql"
+	"github.com/jmoiron/sqlx"
+	"github.com/pressly/goose/v3"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// timeFormat is the format times are stored in. Every stored time is UTC, so
+// comparing them as text gives the same order as comparing the times
+const timeFormat = "2006-01-02 15:04:05.999999999-07:00"
+
+func init() {
+	// queries use now() like in postgres, it has the same precision and format as stored times
+	sqlite.MustRegisterScalarFunction("now", 0, func(*sqlite.FunctionContext, []driver.Value) (driver.Value, error) {
+		return time.Now().UTC().Truncate(time.Microsecond).Format(timeFormat), nil
+	})
+}
+
+type Storage struct {
+	db            *sqlx.DB
+	queryTimeout  time.Duration
+	queryTimeouts map[string]time.Duration
+
+	// jobLocks are held jobs, database file can't be shared between replicas,
+	// so locking in the process is enough
+	jobLocksMu sync.Mutex
+	jobLocks   map[string]bool
+}
+
+func New(cfg *config.Config, log *slog.Logger) (*Storage, error) {
+	const op = "storage.sqlite.New"
+
+	db, err := open(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check schema, migrations are applied only if auto migrate is enabled
+	err = prepareSchema(db, cfg, log)
+	if err != nil {
+		_ = db.Close()
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Storage{
+		db:            db,
+		queryTimeout:  cfg.SQLite.QueryTimeout,
+		queryTimeouts: cfg.SQLite.QueryTimeouts,
+		jobLocks:      make(map[string]bool),
+	}, nil
+}
+
+// Close waits for running queries and closes the database
+func (s *Storage) Close() error {
+	return s.db.Close()
+}
+
+// withTimeout limits ctx with timeout of the storage method op is named after
+// and starts span of the method, cancel ends the span
+func (s *Storage) withTimeout(ctx context.Context, op string) (context.Context, context.CancelFunc) {
+	ctx, span := tracing.Tracer().Start(ctx, op, trace.WithAttributes(semconv.DBSystemSqlite))
+
+	timeout, ok := s.queryTimeouts[strings.TrimPrefix(op, "storage.sqlite.")]
+	if !ok {
+		timeout = s.queryTimeout
+	}
+
+	var cancel context.CancelFunc
+	if timeout <= 0 {
+		ctx, cancel = context.WithCancel(ctx)
+	} else {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	return ctx, func() {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			span.SetStatus(codes.Error, "query timeout")
+		}
+
+		cancel()
+		span.End()
+	}
+}
+
+// Stats returns connection pool statistics
+func (s *Storage) Stats() sql.DBStats {
+	return s.db.Stats()
+}
+
+func open(cfg *config.Config) (*sqlx.DB, error) {
+	const op = "storage.sqlite.open"
+
+	// open database file, every query is traced
+	sqlDB, err := otelsql.Open(
+		"sqlite",
+		getSourceName(cfg),
+		otelsql.WithAttributes(semconv.DBSystemSqlite),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitConnPrepare:      true,
+			OmitRows:             true,
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// sqlite allows a single writer, one connection serializes writes instead
+	// of failing them with busy errors, and keeps in-memory database alive
+	sqlDB.SetMaxOpenConns(1)
+	sqlDB.SetConnMaxLifetime(0)
+	sqlDB.SetConnMaxIdleTime(0)
+
+	return sqlx.NewDb(sqlDB, "sqlite"), nil
+}
+
+// NewMigrator opens the database file for migrations, it's closed with the migrator
+func NewMigrator(cfg *config.Config, log *slog.Logger) (*migrator.Migrator, error) {
+	const op = "storage.sqlite.NewMigrator"
+
+	db, err := open(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	m, err := migrator.New(goose.DialectSQLite3, db.DB, migrations.SQLite(), log)
+	if err != nil {
+		_ = db.Close()
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return m, nil
+}
+
+// prepareSchema applies pending migrations if auto migrate is enabled,
+// otherwise it fails if the schema is behind them
+func prepareSchema(db *sqlx.DB, cfg *config.Config, log *slog.Logger) error {
+	const op = "storage.sqlite.prepareSchema"
+
+	m, err := migrator.New(goose.DialectSQLite3, db.DB, migrations.SQLite(), log)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if cfg.AutoMigrate {
+		err = m.Up(context.Background())
+	} else {
+		err = m.Check(context.Background())
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// getSourceName enables foreign keys, which sqlite has off by default, and
+// makes the driver write times in timeFormat
+func getSourceName(cfg *config.Config) string {
+	return fmt.Sprintf(
+		"file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_time_format=sqlite",
+		cfg.SQLite.Path,
+	)
+}
+
+// utc converts optional time to UTC, times are compared as text, so all of them
+// have to be in the same zone
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	converted := t.UTC()
+	return &converted
+}
+
+// isUniqueViolation reports whether err is a violation of unique or primary key constraint
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+
+	return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+}
+
+// isForeignKeyViolation reports whether err is a violation of foreign key constraint
+func isForeignKeyViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+
+	return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY
+}
@@ -0,0 +1,87 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"main/internal/config"
+	"main/internal/models/note"
+	"main/internal/router"
+	"main/internal/storage/sqlite"
+	"main/internal/storage/storagetest"
+	"main/internal/tracing"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newStorage(t *testing.T) *sqlite.Storage {
+	cfg := &config.Config{AutoMigrate: true}
+	cfg.SQLite.Path = filepath.Join(t.TempDir(), "notes.db")
+
+	s, err := sqlite.New(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("sqlite.New: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	return s
+}
+
+func TestContract(t *testing.T) {
+	storagetest.RunContract(t, func(t *testing.T) router.Storage {
+		return newStorage(t)
+	})
+}
+
+func TestMethodSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	s := newStorage(t)
+
+	ctx, parent := tracing.Tracer().Start(context.Background(), "handler")
+	if _, err := s.CreateUser(ctx, "user@example.com", "User", "hash"); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	parent.End()
+
+	for _, span := range recorder.Ended() {
+		if span.Name() != "storage.sqlite.CreateUser" {
+			continue
+		}
+
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Fatalf("span of CreateUser isn't a child of the caller span")
+		}
+
+		return
+	}
+
+	t.Fatal("span of CreateUser isn't recorded")
+}
+
+func TestQueryTimeoutIsLookedUpByMethod(t *testing.T) {
+	cfg := &config.Config{AutoMigrate: true}
+	cfg.SQLite.Path = filepath.Join(t.TempDir(), "notes.db")
+	cfg.SQLite.QueryTimeouts = map[string]time.Duration{"UpdateFullNote": time.Nanosecond}
+
+	s, err := sqlite.New(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("sqlite.New: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	_, err = s.UpdateFullNote(context.Background(), 1, note.Note{Title: "title"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("UpdateFullNote: got error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
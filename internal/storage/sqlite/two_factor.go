@@ -0,0 +1,207 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"main/internal/auth"
+	"main/internal/storage"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func (s *Storage) SaveTOTPSecret(ctx context.Context, userId, secret string) error {
+	const op = "storage.sqlite.SaveTOTPSecret"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// saving pending secret (confirmed secret is never overwritten)
+	res, err := s.db.ExecContext(ctx, saveTOTPSecretQuery, userId, secret)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return storage.ErrTOTPAlreadyEnabled
+	}
+
+	return nil
+}
+
+func (s *Storage) GetTOTP(ctx context.Context, userId string) (auth.TOTP, error) {
+	const op = "storage.sqlite.GetTOTP"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var totp auth.TOTP
+
+	err := s.db.GetContext(ctx, &totp, getTOTPQuery, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return auth.TOTP{}, storage.ErrTOTPNotFound
+	}
+	if err != nil {
+		return auth.TOTP{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return totp, nil
+}
+
+func (s *Storage) IsTwoFactorEnabled(ctx context.Context, userId string) (bool, error) {
+	const op = "storage.sqlite.IsTwoFactorEnabled"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var exists int
+
+	err := s.db.GetContext(ctx, &exists, isTwoFactorEnabledQuery, userId)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return exists == 1, nil
+}
+
+func (s *Storage) ConfirmTOTP(ctx context.Context, userId string, step int64, recoveryCodeHashes []string) error {
+	const op = "storage.sqlite.ConfirmTOTP"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// begin transaction
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// enabling totp
+	res, err := tx.ExecContext(ctx, confirmTOTPQuery, userId, step)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		_ = tx.Rollback()
+		return storage.ErrTOTPAlreadyEnabled
+	}
+
+	// replacing recovery codes
+	err = replaceRecoveryCodes(ctx, tx, op, userId, recoveryCodeHashes)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) UpdateTOTPLastUsedStep(ctx context.Context, userId string, step int64) error {
+	const op = "storage.sqlite.UpdateTOTPLastUsedStep"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, updateTOTPLastUsedStepQuery, userId, step)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if code was used concurrently
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return storage.ErrTOTPCodeAlreadyUsed
+	}
+
+	return nil
+}
+
+func (s *Storage) UseRecoveryCode(ctx context.Context, userId, codeHash string) error {
+	const op = "storage.sqlite.UseRecoveryCode"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, useRecoveryCodeQuery, userId, codeHash)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return storage.ErrRecoveryCodeNotFound
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteTOTP(ctx context.Context, userId string) error {
+	const op = "storage.sqlite.DeleteTOTP"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// begin transaction
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx, deleteTOTPQuery, userId)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx, deleteRecoveryCodesQuery, userId)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sqlx.Tx, op string, userId string, codeHashes []string) error {
+	_, err := tx.ExecContext(ctx, deleteRecoveryCodesQuery, userId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, codeHash := range codeHashes {
+		_, err := tx.ExecContext(ctx, createRecoveryCodeQuery, userId, codeHash)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,275 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"main/internal/models/user"
+	"main/internal/storage"
+)
+
+func (s *Storage) CreateUser(ctx context.Context, email, name, passwordHash string) (string, error) {
+	const op = "storage.sqlite.CreateUser"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// creating user
+	var id string
+
+	err := s.db.GetContext(ctx, &id, createUserQuery, email, name, passwordHash)
+	if err != nil {
+		// check if user already exists
+		if isUniqueViolation(err) {
+			return "", storage.ErrUserAlreadyExists
+		}
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+func (s *Storage) GetUser(ctx context.Context, email string) (user.User, error) {
+	const op = "storage.sqlite.GetUser"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// getting user by email
+	var userFromDB user.User
+
+	err := s.db.GetContext(ctx, &userFromDB, getUserByEmailQuery, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return user.User{}, storage.ErrUserNotFound
+	}
+	if err != nil {
+		return user.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userFromDB, nil
+}
+
+func (s *Storage) GetUserById(ctx context.Context, id string) (user.User, error) {
+	const op = "storage.sqlite.GetUserById"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// getting user by id
+	var userFromDB user.User
+
+	err := s.db.GetContext(ctx, &userFromDB, getUserByIdQuery, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return user.User{}, storage.ErrUserNotFound
+	}
+	if err != nil {
+		return user.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userFromDB, nil
+}
+
+func (s *Storage) UpdateUserPassword(ctx context.Context, id, passwordHash string) error {
+	const op = "storage.sqlite.UpdateUserPassword"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, updateUserPasswordQuery, id, passwordHash)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if user wasn't found
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (s *Storage) SetEmailVerified(ctx context.Context, id string) error {
+	const op = "storage.sqlite.SetEmailVerified"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, setEmailVerifiedQuery, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if user wasn't found
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (s *Storage) UpdateUserName(ctx context.Context, id, name string) error {
+	const op = "storage.sqlite.UpdateUserName"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, updateUserNameQuery, id, name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if user wasn't found
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (s *Storage) SetPendingEmail(ctx context.Context, id, email string) error {
+	const op = "storage.sqlite.SetPendingEmail"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, setPendingEmailQuery, id, email)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if user wasn't found
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange replaces email with the pending one and marks it verified
+func (s *Storage) ConfirmEmailChange(ctx context.Context, id string) (string, error) {
+	const op = "storage.sqlite.ConfirmEmailChange"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var email string
+
+	err := s.db.GetContext(ctx, &email, confirmEmailChangeQuery, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", storage.ErrNoPendingEmail
+	}
+	if err != nil {
+		// check if email was taken after change was requested
+		if isUniqueViolation(err) {
+			return "", storage.ErrUserAlreadyExists
+		}
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return email, nil
+}
+
+func (s *Storage) GetUserNoteIds(ctx context.Context, userId string) ([]int, error) {
+	const op = "storage.sqlite.GetUserNoteIds"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	ids := []int{}
+
+	err := s.db.SelectContext(ctx, &ids, getUserNoteIdsQuery, userId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ids, nil
+}
+
+// GetSolelyOwnedWorkspaceIds returns workspaces which have no other owner than the user
+func (s *Storage) GetSolelyOwnedWorkspaceIds(ctx context.Context, userId string) ([]string, error) {
+	const op = "storage.sqlite.GetSolelyOwnedWorkspaceIds"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	ids := []string{}
+
+	err := s.db.SelectContext(ctx, &ids, getSolelyOwnedWorkspaceIdsQuery, userId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ids, nil
+}
+
+// DeleteUser deletes user, everything owned by him is removed by foreign keys cascade.
+// Workspaces are not, so the only owner of a workspace gets ErrSoleWorkspaceOwner
+func (s *Storage) DeleteUser(ctx context.Context, id string) error {
+	const op = "storage.sqlite.DeleteUser"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// begin transaction
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// the only connection serializes transactions, so member changes can't
+	// make the user the only owner of a workspace after the check
+	var solelyOwned []string
+
+	err = tx.SelectContext(ctx, &solelyOwned, getSolelyOwnedWorkspaceIdsQuery, id)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if len(solelyOwned) > 0 {
+		_ = tx.Rollback()
+		return storage.ErrSoleWorkspaceOwner
+	}
+
+	res, err := tx.ExecContext(ctx, deleteUserQuery, id)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if user wasn't found
+	rows, err := res.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		_ = tx.Rollback()
+		return storage.ErrUserNotFound
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"main/internal/storage"
+	"main/internal/webhook"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func (s *Storage) CreateWebhook(ctx context.Context, userId, url string, events []string, secret string) (webhook.Webhook, error) {
+	const op = "storage.sqlite.CreateWebhook"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var created webhook.Webhook
+
+	err := s.db.GetContext(ctx, &created, createWebhookQuery, userId, url, webhook.Events(events), secret)
+	if err != nil {
+		return webhook.Webhook{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return created, nil
+}
+
+func (s *Storage) GetUserWebhooks(ctx context.Context, userId string) ([]webhook.Webhook, error) {
+	const op = "storage.sqlite.GetUserWebhooks"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var webhooks []webhook.Webhook
+
+	err := s.db.SelectContext(ctx, &webhooks, getUserWebhooksQuery, userId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if webhooks == nil {
+		return []webhook.Webhook{}, nil
+	}
+
+	return webhooks, nil
+}
+
+func (s *Storage) GetWebhook(ctx context.Context, id, userId string) (webhook.Webhook, error) {
+	const op = "storage.sqlite.GetWebhook"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var found webhook.Webhook
+
+	err := s.db.GetContext(ctx, &found, getWebhookQuery, id, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return webhook.Webhook{}, storage.ErrWebhookNotFound
+	}
+	if err != nil {
+		return webhook.Webhook{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return found, nil
+}
+
+// DeleteWebhook deletes webhook with its delivery log, pending deliveries are dropped
+func (s *Storage) DeleteWebhook(ctx context.Context, id, userId string) error {
+	const op = "storage.sqlite.DeleteWebhook"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, deleteWebhookQuery, id, userId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if webhook wasn't found
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return storage.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (s *Storage) GetWebhookDeliveries(ctx context.Context, webhookId string, limit, offset int) ([]webhook.Delivery, error) {
+	const op = "storage.sqlite.GetWebhookDeliveries"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var deliveries []webhook.Delivery
+
+	err := s.db.SelectContext(ctx, &deliveries, getWebhookDeliveriesQuery, webhookId, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if deliveries == nil {
+		return []webhook.Delivery{}, nil
+	}
+
+	return deliveries, nil
+}
+
+// RedeliverWebhookDelivery queues a copy of the delivery, so its own log entry stays untouched
+func (s *Storage) RedeliverWebhookDelivery(ctx context.Context, id int64, webhookId string) (int64, error) {
+	const op = "storage.sqlite.RedeliverWebhookDelivery"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var newId int64
+
+	err := s.db.GetContext(ctx, &newId, redeliverWebhookDeliveryQuery, id, webhookId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, storage.ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return newId, nil
+}
+
+func (s *Storage) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]webhook.PendingDelivery, error) {
+	const op = "storage.sqlite.ClaimWebhookDeliveries"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var deliveries []webhook.PendingDelivery
+
+	err := s.db.SelectContext(ctx, &deliveries, claimWebhookDeliveriesQuery, limit, time.Now().UTC().Add(lease))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deliveries, nil
+}
+
+func (s *Storage) MarkWebhookDeliverySucceeded(ctx context.Context, id int64, responseStatus int) error {
+	const op = "storage.sqlite.MarkWebhookDeliverySucceeded"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, markWebhookDeliverySucceededQuery, id, responseStatus)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) MarkWebhookDeliveryFailed(ctx context.Context, id int64, responseStatus *int, lastError string, retryAt *time.Time) error {
+	const op = "storage.sqlite.MarkWebhookDeliveryFailed"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, markWebhookDeliveryFailedQuery, id, responseStatus, lastError, utc(retryAt))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// enqueueNoteEvent writes deliveries of the note event to the outbox, it has to run
+// in the transaction of the change, so events are neither lost nor sent for rolled back changes
+func enqueueNoteEvent(ctx context.Context, tx *sqlx.Tx, op string, event string, data webhook.Data) error {
+	payload, err := webhook.NewPayload(event, data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	wildcard, _, _ := strings.Cut(event, ".")
+
+	_, err = tx.ExecContext(ctx, enqueueNoteWebhookDeliveriesQuery, data.NoteId, event, payload, wildcard+".*")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
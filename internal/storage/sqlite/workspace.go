@@ -0,0 +1,460 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"main/internal/models/workspace"
+	"main/internal/storage"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func (s *Storage) CreateWorkspace(ctx context.Context, name, ownerId string) (string, error) {
+	const op = "storage.sqlite.CreateWorkspace"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// begin transaction
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	// creating workspace
+	var id string
+
+	err = tx.GetContext(ctx, &id, createWorkspaceQuery, name)
+	if err != nil {
+		_ = tx.Rollback()
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	// creator becomes the first owner
+	_, err = tx.ExecContext(ctx, addWorkspaceMemberQuery, id, ownerId, workspace.RoleOwner)
+	if err != nil {
+		_ = tx.Rollback()
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+func (s *Storage) GetWorkspace(ctx context.Context, id string) (workspace.Workspace, error) {
+	const op = "storage.sqlite.GetWorkspace"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var ws workspace.Workspace
+
+	err := s.db.GetContext(ctx, &ws, getWorkspaceQuery, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return workspace.Workspace{}, storage.ErrWorkspaceNotFound
+	}
+	if err != nil {
+		return workspace.Workspace{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ws, nil
+}
+
+func (s *Storage) GetUserWorkspaces(ctx context.Context, userId string) ([]workspace.Membership, error) {
+	const op = "storage.sqlite.GetUserWorkspaces"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var memberships []workspace.Membership
+
+	err := s.db.SelectContext(ctx, &memberships, getUserWorkspacesQuery, userId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if memberships == nil {
+		return []workspace.Membership{}, nil
+	}
+
+	return memberships, nil
+}
+
+func (s *Storage) UpdateWorkspaceName(ctx context.Context, id, name string) error {
+	const op = "storage.sqlite.UpdateWorkspaceName"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, updateWorkspaceNameQuery, id, name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if workspace wasn't found
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return storage.ErrWorkspaceNotFound
+	}
+
+	return nil
+}
+
+// DeleteWorkspace deletes workspace with its members, invitations and notes
+func (s *Storage) DeleteWorkspace(ctx context.Context, id string) error {
+	const op = "storage.sqlite.DeleteWorkspace"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, deleteWorkspaceQuery, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if workspace wasn't found
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return storage.ErrWorkspaceNotFound
+	}
+
+	return nil
+}
+
+func (s *Storage) GetWorkspaceNoteIds(ctx context.Context, workspaceId string) ([]int, error) {
+	const op = "storage.sqlite.GetWorkspaceNoteIds"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var ids []int
+
+	err := s.db.SelectContext(ctx, &ids, getWorkspaceNoteIdsQuery, workspaceId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ids, nil
+}
+
+func (s *Storage) GetWorkspaceRole(ctx context.Context, workspaceId, userId string) (string, error) {
+	const op = "storage.sqlite.GetWorkspaceRole"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var role string
+
+	err := s.db.GetContext(ctx, &role, getWorkspaceRoleQuery, workspaceId, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", storage.ErrWorkspaceMemberNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return role, nil
+}
+
+func (s *Storage) GetWorkspaceMembers(ctx context.Context, workspaceId string) ([]workspace.Member, error) {
+	const op = "storage.sqlite.GetWorkspaceMembers"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var members []workspace.Member
+
+	err := s.db.SelectContext(ctx, &members, getWorkspaceMembersQuery, workspaceId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if members == nil {
+		return []workspace.Member{}, nil
+	}
+
+	return members, nil
+}
+
+func (s *Storage) IsWorkspaceMemberEmail(ctx context.Context, workspaceId, email string) (bool, error) {
+	const op = "storage.sqlite.IsWorkspaceMemberEmail"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var exists int
+
+	err := s.db.GetContext(ctx, &exists, isWorkspaceMemberEmailQuery, workspaceId, email)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return exists == 1, nil
+}
+
+func (s *Storage) SetWorkspaceMemberRole(ctx context.Context, workspaceId, userId, role string) error {
+	const op = "storage.sqlite.SetWorkspaceMemberRole"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	return s.changeWorkspaceMember(ctx, op, workspaceId, userId, role != workspace.RoleOwner, setWorkspaceMemberRoleQuery, workspaceId, userId, role)
+}
+
+func (s *Storage) RemoveWorkspaceMember(ctx context.Context, workspaceId, userId string) error {
+	const op = "storage.sqlite.RemoveWorkspaceMember"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	return s.changeWorkspaceMember(ctx, op, workspaceId, userId, true, removeWorkspaceMemberQuery, workspaceId, userId)
+}
+
+// changeWorkspaceMember runs query against a member with the workspace locked, so
+// concurrent changes can't leave the workspace without owners
+func (s *Storage) changeWorkspaceMember(ctx context.Context, op, workspaceId, userId string, losesOwnership bool, query string, args ...any) error {
+	// begin transaction
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	var id string
+
+	err = tx.GetContext(ctx, &id, lockWorkspaceQuery, workspaceId)
+	if errors.Is(err, sql.ErrNoRows) {
+		_ = tx.Rollback()
+		return storage.ErrWorkspaceNotFound
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	var role string
+
+	err = tx.GetContext(ctx, &role, getWorkspaceRoleQuery, workspaceId, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		_ = tx.Rollback()
+		return storage.ErrWorkspaceMemberNotFound
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if role == workspace.RoleOwner && losesOwnership {
+		if err := ensureOtherOwner(ctx, tx, op, workspaceId, userId); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func ensureOtherOwner(ctx context.Context, tx *sqlx.Tx, op, workspaceId, userId string) error {
+	var owners int
+
+	err := tx.GetContext(ctx, &owners, countOtherWorkspaceOwnersQuery, workspaceId, userId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if owners == 0 {
+		return storage.ErrLastWorkspaceOwner
+	}
+
+	return nil
+}
+
+// CreateWorkspaceInvitation invites email to the workspace. Expired invitation
+// for the same email is replaced, pending one is reported as ErrInvitationAlreadyExists
+func (s *Storage) CreateWorkspaceInvitation(ctx context.Context, workspaceId, email, role, invitedBy string, expiresAt time.Time) (string, error) {
+	const op = "storage.sqlite.CreateWorkspaceInvitation"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var id string
+
+	err := s.db.GetContext(ctx, &id, createWorkspaceInvitationQuery, workspaceId, email, role, invitedBy, expiresAt.UTC())
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", storage.ErrInvitationAlreadyExists
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+func (s *Storage) GetWorkspaceInvitations(ctx context.Context, workspaceId string) ([]workspace.Invitation, error) {
+	const op = "storage.sqlite.GetWorkspaceInvitations"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	return s.selectInvitations(ctx, op, getWorkspaceInvitationsQuery, workspaceId)
+}
+
+func (s *Storage) GetUserInvitations(ctx context.Context, email string) ([]workspace.Invitation, error) {
+	const op = "storage.sqlite.GetUserInvitations"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	return s.selectInvitations(ctx, op, getUserInvitationsQuery, email)
+}
+
+func (s *Storage) selectInvitations(ctx context.Context, op, query string, arg string) ([]workspace.Invitation, error) {
+	var invitations []workspace.Invitation
+
+	err := s.db.SelectContext(ctx, &invitations, query, arg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if invitations == nil {
+		return []workspace.Invitation{}, nil
+	}
+
+	return invitations, nil
+}
+
+func (s *Storage) DeleteWorkspaceInvitation(ctx context.Context, id, workspaceId string) error {
+	const op = "storage.sqlite.DeleteWorkspaceInvitation"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, deleteWorkspaceInvitationQuery, id, workspaceId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// check if invitation wasn't found
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return storage.ErrInvitationNotFound
+	}
+
+	return nil
+}
+
+// AcceptWorkspaceInvitation consumes invitation sent to email and adds user to the workspace
+func (s *Storage) AcceptWorkspaceInvitation(ctx context.Context, id, email, userId string) (string, error) {
+	const op = "storage.sqlite.AcceptWorkspaceInvitation"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	// begin transaction
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	var invitation struct {
+		WorkspaceId string `db:"workspace_id"`
+		Role        string `db:"role"`
+	}
+
+	err = tx.GetContext(ctx, &invitation, consumeWorkspaceInvitationQuery, id, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		_ = tx.Rollback()
+		return "", storage.ErrInvitationNotFound
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx, addWorkspaceMemberQuery, invitation.WorkspaceId, userId, invitation.Role)
+	if err != nil {
+		_ = tx.Rollback()
+
+		// check if user is already member
+		if isUniqueViolation(err) {
+			return "", storage.ErrWorkspaceMemberAlreadyExists
+		}
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return invitation.WorkspaceId, nil
+}
+
+// DeclineWorkspaceInvitation deletes invitation sent to email
+func (s *Storage) DeclineWorkspaceInvitation(ctx context.Context, id, email string) error {
+	const op = "storage.sqlite.DeclineWorkspaceInvitation"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	var invitation struct {
+		WorkspaceId string `db:"workspace_id"`
+		Role        string `db:"role"`
+	}
+
+	err := s.db.GetContext(ctx, &invitation, consumeWorkspaceInvitationQuery, id, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.ErrInvitationNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteExpiredWorkspaceInvitations(ctx context.Context) (int, error) {
+	const op = "storage.sqlite.DeleteExpiredWorkspaceInvitations"
+
+	ctx, cancel := s.withTimeout(ctx, op)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, deleteExpiredWorkspaceInvitationsQuery)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// counting rows
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(rowsAffected), nil
+}
@@ -1,6 +1,11 @@
 package storage
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
 
 var (
 	ErrNoteNotFound     = errors.New("note not found")
@@ -8,6 +13,123 @@ var (
 
 	ErrUserAlreadyExists = errors.New("user with this email already exists")
 	ErrUserNotFound      = errors.New("user not found")
+	ErrNoPendingEmail    = errors.New("user has no pending email change")
 
 	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	ErrOneTimeTokenNotFound = errors.New("one-time token not found or expired")
+
+	ErrTOTPNotFound         = errors.New("totp not found")
+	ErrTOTPAlreadyEnabled   = errors.New("totp is already enabled")
+	ErrTOTPCodeAlreadyUsed  = errors.New("totp code is already used")
+	ErrRecoveryCodeNotFound = errors.New("recovery code not found")
+
+	ErrOIDCStateNotFound = errors.New("oidc state not found or expired")
+	ErrIdentityNotFound  = errors.New("external identity not found")
+
+	ErrWebAuthnSessionNotFound = errors.New("webauthn session not found or expired")
+	ErrPasskeyNotFound         = errors.New("passkey not found")
+	ErrPasskeyAlreadyExists    = errors.New("passkey already exists")
+
+	ErrPersonalAccessTokenNotFound = errors.New("personal access token not found")
+
+	ErrLoginAttemptNotFound = errors.New("login attempt not found")
+
+	ErrWorkspaceNotFound            = errors.New("workspace not found")
+	ErrWorkspaceMemberNotFound      = errors.New("workspace member not found")
+	ErrWorkspaceMemberAlreadyExists = errors.New("user is already workspace member")
+	ErrLastWorkspaceOwner           = errors.New("workspace must have at least one owner")
+	ErrSoleWorkspaceOwner           = errors.New("user is the only owner of a workspace")
+	ErrInvitationNotFound           = errors.New("invitation not found or expired")
+	ErrInvitationAlreadyExists      = errors.New("invitation already exists")
+
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
 )
+
+// queryCanceledState is SQLSTATE of a statement cancelled on client request
+const queryCanceledState = "57014"
+
+// IsCanceled reports whether err is caused by cancelled context or query timeout,
+// including statements which the database cancelled on client request
+func IsCanceled(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var sqlErr interface{ SQLState() string }
+
+	return errors.As(err, &sqlErr) && sqlErr.SQLState() == queryCanceledState
+}
+
+type userKey struct{}
+
+// WithUser marks ctx as made on behalf of the user, storage uses it to
+// keep reads of the user consistent with the user's writes
+func WithUser(ctx context.Context, userId string) context.Context {
+	return context.WithValue(ctx, userKey{}, userId)
+}
+
+// UserFromContext returns the user ctx is made on behalf of
+func UserFromContext(ctx context.Context) (string, bool) {
+	userId, ok := ctx.Value(userKey{}).(string)
+
+	return userId, ok && userId != ""
+}
+
+type primaryKey struct{}
+
+// WithPrimary makes reads of ctx go to the primary database. Results which
+// outlive the request, like cache entries, are read this way, so a lagging
+// replica can't put stale data back after invalidation
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// PrimaryRequired reports whether reads of ctx have to go to the primary database
+func PrimaryRequired(ctx context.Context) bool {
+	required, _ := ctx.Value(primaryKey{}).(bool)
+
+	return required
+}
+
+// LastWrite is time of the last write made by the client. The client carries
+// it between requests, so the client's reads stay on the primary database after
+// a write on every instance, not only on the one which made the write
+type LastWrite struct {
+	mu sync.Mutex
+	at time.Time
+}
+
+func NewLastWrite(at time.Time) *LastWrite {
+	return &LastWrite{at: at}
+}
+
+// Set records write made at
+func (w *LastWrite) Set(at time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.at = at
+}
+
+// Time returns time of the last write, zero if the client hasn't written
+func (w *LastWrite) Time() time.Time {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.at
+}
+
+type lastWriteKey struct{}
+
+// WithLastWrite makes storage read the client's last write time from w and record new writes into it
+func WithLastWrite(ctx context.Context, w *LastWrite) context.Context {
+	return context.WithValue(ctx, lastWriteKey{}, w)
+}
+
+// LastWriteFromContext returns last write of the client ctx is made on behalf of
+func LastWriteFromContext(ctx context.Context) (*LastWrite, bool) {
+	w, ok := ctx.Value(lastWriteKey{}).(*LastWrite)
+
+	return w, ok && w != nil
+}
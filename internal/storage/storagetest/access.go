@@ -0,0 +1,209 @@
+package storagetest
+
+import (
+	"context"
+	"main/internal/models/workspace"
+	"main/internal/router"
+	"main/internal/storage"
+	"testing"
+	"time"
+)
+
+func testNoteAccess(t *testing.T, s router.Storage) {
+	ctx := context.Background()
+	ownerId, _ := createUser(t, s)
+	strangerId, _ := createUser(t, s)
+
+	noteId, err := s.CreateNote(ctx, "private", ownerId)
+	mustNoError(t, err, "CreateNote")
+
+	nodeId := nodeIdsByOrder(t, s, noteId)[0]
+
+	wantAccess(t, s, ownerId, noteId, nodeId, true, true)
+	wantAccess(t, s, strangerId, noteId, nodeId, false, false)
+
+	// missing note can't be accessed by anyone
+	wantAccess(t, s, ownerId, -1, -1, false, false)
+}
+
+func testWorkspaces(t *testing.T, s router.Storage) {
+	ctx := context.Background()
+	ownerId, _ := createUser(t, s)
+	memberId, memberEmail := createUser(t, s)
+	guestId, guestEmail := createUser(t, s)
+
+	workspaceId, err := s.CreateWorkspace(ctx, "team", ownerId)
+	mustNoError(t, err, "CreateWorkspace")
+
+	role, err := s.GetWorkspaceRole(ctx, workspaceId, ownerId)
+	mustNoError(t, err, "GetWorkspaceRole")
+	if role != workspace.RoleOwner {
+		t.Fatalf("GetWorkspaceRole: creator has role %q", role)
+	}
+
+	noteId, err := s.CreateWorkspaceNote(ctx, "shared", workspaceId)
+	mustNoError(t, err, "CreateWorkspaceNote")
+	nodeId := nodeIdsByOrder(t, s, noteId)[0]
+
+	wantAccess(t, s, ownerId, noteId, nodeId, true, true)
+	wantAccess(t, s, memberId, noteId, nodeId, false, false)
+
+	join(t, s, workspaceId, ownerId, memberId, memberEmail, workspace.RoleMember)
+	join(t, s, workspaceId, ownerId, guestId, guestEmail, workspace.RoleGuest)
+
+	// guests read notes of the workspace, but don't edit them
+	wantAccess(t, s, memberId, noteId, nodeId, true, true)
+	wantAccess(t, s, guestId, noteId, nodeId, true, false)
+
+	mustNoError(t, s.SetWorkspaceMemberRole(ctx, workspaceId, guestId, workspace.RoleMember), "SetWorkspaceMemberRole")
+	wantAccess(t, s, guestId, noteId, nodeId, true, true)
+
+	// the last owner can't be demoted or removed
+	err = s.SetWorkspaceMemberRole(ctx, workspaceId, ownerId, workspace.RoleMember)
+	mustBeError(t, err, storage.ErrLastWorkspaceOwner, "SetWorkspaceMemberRole of the last owner")
+	err = s.RemoveWorkspaceMember(ctx, workspaceId, ownerId)
+	mustBeError(t, err, storage.ErrLastWorkspaceOwner, "RemoveWorkspaceMember of the last owner")
+
+	// removing member revokes access immediately
+	mustNoError(t, s.RemoveWorkspaceMember(ctx, workspaceId, memberId), "RemoveWorkspaceMember")
+	wantAccess(t, s, memberId, noteId, nodeId, false, false)
+
+	_, err = s.GetWorkspaceRole(ctx, workspaceId, memberId)
+	mustBeError(t, err, storage.ErrWorkspaceMemberNotFound, "GetWorkspaceRole of removed member")
+
+	err = s.RemoveWorkspaceMember(ctx, workspaceId, memberId)
+	mustBeError(t, err, storage.ErrWorkspaceMemberNotFound, "RemoveWorkspaceMember of removed member")
+
+	// deleting workspace deletes its notes
+	mustNoError(t, s.DeleteWorkspace(ctx, workspaceId), "DeleteWorkspace")
+
+	_, err = s.GetWorkspace(ctx, workspaceId)
+	mustBeError(t, err, storage.ErrWorkspaceNotFound, "GetWorkspace of deleted workspace")
+
+	_, err = s.GetNoteById(ctx, noteId)
+	mustBeError(t, err, storage.ErrNoteNotFound, "GetNoteById of deleted workspace note")
+}
+
+func testWorkspaceInvitations(t *testing.T, s router.Storage) {
+	ctx := context.Background()
+	ownerId, _ := createUser(t, s)
+	userId, email := createUser(t, s)
+
+	workspaceId, err := s.CreateWorkspace(ctx, "team", ownerId)
+	mustNoError(t, err, "CreateWorkspace")
+
+	invitationId, err := s.CreateWorkspaceInvitation(ctx, workspaceId, email, workspace.RoleMember, ownerId, time.Now().Add(time.Hour))
+	mustNoError(t, err, "CreateWorkspaceInvitation")
+
+	_, err = s.CreateWorkspaceInvitation(ctx, workspaceId, email, workspace.RoleMember, ownerId, time.Now().Add(time.Hour))
+	mustBeError(t, err, storage.ErrInvitationAlreadyExists, "CreateWorkspaceInvitation of invited email")
+
+	// invitation is accepted only by the invited email
+	_, err = s.AcceptWorkspaceInvitation(ctx, invitationId, uniqueEmail(), userId)
+	mustBeError(t, err, storage.ErrInvitationNotFound, "AcceptWorkspaceInvitation with other email")
+
+	accepted, err := s.AcceptWorkspaceInvitation(ctx, invitationId, email, userId)
+	mustNoError(t, err, "AcceptWorkspaceInvitation")
+	if accepted != workspaceId {
+		t.Fatalf("AcceptWorkspaceInvitation: got workspace %q, want %q", accepted, workspaceId)
+	}
+
+	_, err = s.AcceptWorkspaceInvitation(ctx, invitationId, email, userId)
+	mustBeError(t, err, storage.ErrInvitationNotFound, "AcceptWorkspaceInvitation twice")
+
+	// expired invitation can't be accepted
+	lateId, lateEmail := createUser(t, s)
+
+	expiredId, err := s.CreateWorkspaceInvitation(ctx, workspaceId, lateEmail, workspace.RoleMember, ownerId, time.Now().Add(-time.Minute))
+	mustNoError(t, err, "CreateWorkspaceInvitation")
+
+	_, err = s.AcceptWorkspaceInvitation(ctx, expiredId, lateEmail, lateId)
+	mustBeError(t, err, storage.ErrInvitationNotFound, "AcceptWorkspaceInvitation of expired invitation")
+}
+
+// join makes the user a member of the workspace through accepted invitation
+func join(t *testing.T, s router.Storage, workspaceId, ownerId, userId, email, role string) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	invitationId, err := s.CreateWorkspaceInvitation(ctx, workspaceId, email, role, ownerId, time.Now().Add(time.Hour))
+	mustNoError(t, err, "CreateWorkspaceInvitation")
+
+	_, err = s.AcceptWorkspaceInvitation(ctx, invitationId, email, userId)
+	mustNoError(t, err, "AcceptWorkspaceInvitation")
+}
+
+func wantAccess(t *testing.T, s router.Storage, userId string, noteId, nodeId int, read, edit bool) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	checks := []struct {
+		name  string
+		check func() (bool, error)
+		want  bool
+	}{
+		{"CanUserReadNote", func() (bool, error) { return s.CanUserReadNote(ctx, userId, noteId) }, read},
+		{"CanUserEditNote", func() (bool, error) { return s.CanUserEditNote(ctx, userId, noteId) }, edit},
+		{"CanUserReadNoteNode", func() (bool, error) { return s.CanUserReadNoteNode(ctx, userId, nodeId) }, read},
+		{"CanUserEditNoteNode", func() (bool, error) { return s.CanUserEditNoteNode(ctx, userId, nodeId) }, edit},
+	}
+
+	for _, c := range checks {
+		got, err := c.check()
+		mustNoError(t, err, c.name)
+
+		if got != c.want {
+			t.Fatalf("%s of note %d: got %v, want %v", c.name, noteId, got, c.want)
+		}
+	}
+}
+
+// testDeleteWorkspaceOwner covers deleting account of a workspace owner,
+// the workspace can't be left without owner
+func testDeleteWorkspaceOwner(t *testing.T, s router.Storage) {
+	ctx := context.Background()
+	ownerId, _ := createUser(t, s)
+	coOwnerId, coOwnerEmail := createUser(t, s)
+
+	workspaceId, err := s.CreateWorkspace(ctx, "team", ownerId)
+	mustNoError(t, err, "CreateWorkspace")
+
+	ids, err := s.GetSolelyOwnedWorkspaceIds(ctx, ownerId)
+	mustNoError(t, err, "GetSolelyOwnedWorkspaceIds")
+	if len(ids) != 1 || ids[0] != workspaceId {
+		t.Fatalf("GetSolelyOwnedWorkspaceIds: got %v, want [%s]", ids, workspaceId)
+	}
+
+	err = s.DeleteUser(ctx, ownerId)
+	mustBeError(t, err, storage.ErrSoleWorkspaceOwner, "DeleteUser of the only owner")
+
+	_, err = s.GetUserById(ctx, ownerId)
+	mustNoError(t, err, "GetUserById of refused deletion")
+
+	// with another owner the account can be deleted, the workspace stays
+	join(t, s, workspaceId, ownerId, coOwnerId, coOwnerEmail, workspace.RoleMember)
+	mustNoError(t, s.SetWorkspaceMemberRole(ctx, workspaceId, coOwnerId, workspace.RoleOwner), "SetWorkspaceMemberRole")
+
+	ids, err = s.GetSolelyOwnedWorkspaceIds(ctx, ownerId)
+	mustNoError(t, err, "GetSolelyOwnedWorkspaceIds")
+	if len(ids) != 0 {
+		t.Fatalf("GetSolelyOwnedWorkspaceIds with co-owner: got %v", ids)
+	}
+
+	mustNoError(t, s.DeleteUser(ctx, ownerId), "DeleteUser of one of owners")
+
+	role, err := s.GetWorkspaceRole(ctx, workspaceId, coOwnerId)
+	mustNoError(t, err, "GetWorkspaceRole")
+	if role != workspace.RoleOwner {
+		t.Fatalf("GetWorkspaceRole: remaining owner has role %q", role)
+	}
+
+	// the remaining owner is the only one now
+	err = s.DeleteUser(ctx, coOwnerId)
+	mustBeError(t, err, storage.ErrSoleWorkspaceOwner, "DeleteUser of the remaining owner")
+
+	mustNoError(t, s.DeleteWorkspace(ctx, workspaceId), "DeleteWorkspace")
+	mustNoError(t, s.DeleteUser(ctx, coOwnerId), "DeleteUser after workspace is deleted")
+}
@@ -0,0 +1,147 @@
+package storagetest
+
+import (
+	"context"
+	"main/internal/models/note"
+	"main/internal/router"
+	"main/internal/storage"
+	"slices"
+	"testing"
+)
+
+func testNodeOrder(t *testing.T, s router.Storage) {
+	ctx := context.Background()
+	userId, _ := createUser(t, s)
+
+	noteId, err := s.CreateNote(ctx, "ordered", userId)
+	mustNoError(t, err, "CreateNote")
+
+	blank := nodeIdsByOrder(t, s, noteId)[0]
+
+	// added nodes are appended
+	first, err := s.AddNoteNode(ctx, noteId, string(note.ContentTypeText), "first")
+	mustNoError(t, err, "AddNoteNode")
+	second, err := s.AddNoteNode(ctx, noteId, string(note.ContentTypeText), "second")
+	mustNoError(t, err, "AddNoteNode")
+
+	wantOrder(t, s, noteId, blank, first, second)
+
+	// moving node down shifts nodes between up
+	mustNoError(t, s.UpdateNoteNodeOrder(ctx, noteId, 0, 2), "UpdateNoteNodeOrder")
+	wantOrder(t, s, noteId, first, second, blank)
+
+	// moving node up shifts nodes between down
+	mustNoError(t, s.UpdateNoteNodeOrder(ctx, noteId, 2, 0), "UpdateNoteNodeOrder")
+	wantOrder(t, s, noteId, blank, first, second)
+
+	err = s.UpdateNoteNodeOrder(ctx, noteId, 0, 3)
+	mustBeError(t, err, storage.ErrNoteNodeNotFound, "UpdateNoteNodeOrder to out of range order")
+
+	err = s.UpdateNoteNodeOrder(ctx, noteId, 3, 0)
+	mustBeError(t, err, storage.ErrNoteNodeNotFound, "UpdateNoteNodeOrder from out of range order")
+
+	wantOrder(t, s, noteId, blank, first, second)
+
+	// deleting node closes the gap
+	mustNoError(t, s.DeleteNoteNode(ctx, first), "DeleteNoteNode")
+	wantOrder(t, s, noteId, blank, second)
+
+	node, err := s.GetNodeById(ctx, second)
+	mustNoError(t, err, "GetNodeById")
+	if node.NoteId != noteId || node.Order != 1 || node.Content != "second" {
+		t.Fatalf("GetNodeById: got %+v", node)
+	}
+}
+
+func testNodeChangesBumpUpdatedAt(t *testing.T, s router.Storage) {
+	ctx := context.Background()
+	userId, _ := createUser(t, s)
+
+	noteId, err := s.CreateNote(ctx, "bumped", userId)
+	mustNoError(t, err, "CreateNote")
+
+	var nodeId int
+
+	changes := []struct {
+		name   string
+		change func() error
+	}{
+		{"AddNoteNode", func() error {
+			nodeId, err = s.AddNoteNode(ctx, noteId, string(note.ContentTypeText), "text")
+			return err
+		}},
+		{"UpdateNoteNodeContent", func() error {
+			return s.UpdateNoteNodeContent(ctx, nodeId, "changed")
+		}},
+		{"UpdateNoteNodeOrder", func() error {
+			return s.UpdateNoteNodeOrder(ctx, noteId, 1, 0)
+		}},
+		{"UpdateFullNote", func() error {
+			_, err := s.UpdateFullNote(ctx, noteId, note.Note{Title: "full", Nodes: []note.NoteNode{{Id: nodeId, Content: "full"}}})
+			return err
+		}},
+		{"DeleteNoteNode", func() error {
+			return s.DeleteNoteNode(ctx, nodeId)
+		}},
+	}
+
+	for _, c := range changes {
+		before, err := s.GetNoteById(ctx, noteId)
+		mustNoError(t, err, "GetNoteById")
+
+		wait()
+		mustNoError(t, c.change(), c.name)
+
+		after, err := s.GetNoteById(ctx, noteId)
+		mustNoError(t, err, "GetNoteById")
+
+		if !after.UpdatedAt.After(before.UpdatedAt) {
+			t.Fatalf("%s: updated_at %v isn't after %v", c.name, after.UpdatedAt, before.UpdatedAt)
+		}
+	}
+}
+
+func testNodeNotFound(t *testing.T, s router.Storage) {
+	ctx := context.Background()
+
+	// ids of created nodes are positive, so the negative one doesn't exist
+	const missing = -1
+
+	_, err := s.GetNodeById(ctx, missing)
+	mustBeError(t, err, storage.ErrNoteNodeNotFound, "GetNodeById")
+
+	mustBeError(t, s.DeleteNoteNode(ctx, missing), storage.ErrNoteNodeNotFound, "DeleteNoteNode")
+	mustBeError(t, s.UpdateNoteNodeContent(ctx, missing, "text"), storage.ErrNoteNodeNotFound, "UpdateNoteNodeContent")
+	mustBeError(t, s.UpdateNoteNodeOrder(ctx, missing, 0, 0), storage.ErrNoteNodeNotFound, "UpdateNoteNodeOrder")
+}
+
+// nodeIdsByOrder returns ids of nodes of the note, nodes are returned in any order
+func nodeIdsByOrder(t *testing.T, s router.Storage, noteId int) []int {
+	t.Helper()
+
+	nodes, err := s.GetAllNotesNodes(context.Background(), noteId)
+	mustNoError(t, err, "GetAllNotesNodes")
+
+	slices.SortFunc(nodes, func(a, b note.NoteNode) int {
+		return a.Order - b.Order
+	})
+
+	ids := make([]int, 0, len(nodes))
+	for i, node := range nodes {
+		if node.Order != i {
+			t.Fatalf("GetAllNotesNodes: orders aren't consecutive from 0: %+v", nodes)
+		}
+
+		ids = append(ids, node.Id)
+	}
+
+	return ids
+}
+
+func wantOrder(t *testing.T, s router.Storage, noteId int, want ...int) {
+	t.Helper()
+
+	if got := nodeIdsByOrder(t, s, noteId); !slices.Equal(got, want) {
+		t.Fatalf("nodes by order: got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,107 @@
+package storagetest
+
+import (
+	"context"
+	"main/internal/models/note"
+	"main/internal/router"
+	"main/internal/storage"
+	"slices"
+	"testing"
+)
+
+func testNotes(t *testing.T, s router.Storage) {
+	ctx := context.Background()
+	userId, _ := createUser(t, s)
+
+	id, err := s.CreateNote(ctx, "first", userId)
+	mustNoError(t, err, "CreateNote")
+
+	created, err := s.GetNoteById(ctx, id)
+	mustNoError(t, err, "GetNoteById")
+	if created.Title != "first" || created.UserId == nil || *created.UserId != userId || created.ArchivedAt != nil {
+		t.Fatalf("GetNoteById: got %+v", created)
+	}
+
+	// note is created with one blank text node
+	nodes, err := s.GetAllNotesNodes(ctx, id)
+	mustNoError(t, err, "GetAllNotesNodes")
+	if len(nodes) != 1 || nodes[0].Order != 0 || nodes[0].ContentType != note.ContentTypeText || nodes[0].Content != "" {
+		t.Fatalf("GetAllNotesNodes: got %+v, want one blank text node", nodes)
+	}
+
+	wait()
+	mustNoError(t, s.UpdateNoteTitle(ctx, id, "renamed"), "UpdateNoteTitle")
+
+	renamed, err := s.GetNoteById(ctx, id)
+	mustNoError(t, err, "GetNoteById")
+	if renamed.Title != "renamed" {
+		t.Fatalf("UpdateNoteTitle: title is %q", renamed.Title)
+	}
+	if !renamed.UpdatedAt.After(created.UpdatedAt) {
+		t.Fatalf("UpdateNoteTitle: updated_at %v isn't after %v", renamed.UpdatedAt, created.UpdatedAt)
+	}
+
+	mustNoError(t, s.ArchiveNote(ctx, id), "ArchiveNote")
+	archived, err := s.GetNoteById(ctx, id)
+	mustNoError(t, err, "GetNoteById")
+	if archived.ArchivedAt == nil {
+		t.Fatal("ArchiveNote: archived_at isn't set")
+	}
+
+	mustNoError(t, s.UnarchiveNote(ctx, id), "UnarchiveNote")
+	unarchived, err := s.GetNoteById(ctx, id)
+	mustNoError(t, err, "GetNoteById")
+	if unarchived.ArchivedAt != nil {
+		t.Fatal("UnarchiveNote: archived_at is still set")
+	}
+
+	// user's notes are listed recently updated first
+	otherId, err := s.CreateNote(ctx, "second", userId)
+	mustNoError(t, err, "CreateNote")
+
+	previews, err := s.GetUserNotes(ctx, userId)
+	mustNoError(t, err, "GetUserNotes")
+	if len(previews) != 2 || previews[0].Id != otherId || previews[1].Id != id {
+		t.Fatalf("GetUserNotes: got %+v, want notes %d and %d", previews, otherId, id)
+	}
+
+	ids, err := s.GetUserNoteIds(ctx, userId)
+	mustNoError(t, err, "GetUserNoteIds")
+	slices.Sort(ids)
+	if !slices.Equal(ids, []int{id, otherId}) {
+		t.Fatalf("GetUserNoteIds: got %v, want %v", ids, []int{id, otherId})
+	}
+
+	// deleting note deletes its nodes
+	mustNoError(t, s.DeleteNote(ctx, id), "DeleteNote")
+
+	_, err = s.GetNoteById(ctx, id)
+	mustBeError(t, err, storage.ErrNoteNotFound, "GetNoteById of deleted note")
+
+	_, err = s.GetNodeById(ctx, nodes[0].Id)
+	mustBeError(t, err, storage.ErrNoteNodeNotFound, "GetNodeById of deleted note")
+}
+
+func testNoteNotFound(t *testing.T, s router.Storage) {
+	ctx := context.Background()
+
+	// ids of created notes are positive, so the negative one doesn't exist
+	const missing = -1
+
+	_, err := s.GetNoteById(ctx, missing)
+	mustBeError(t, err, storage.ErrNoteNotFound, "GetNoteById")
+
+	mustBeError(t, s.UpdateNoteTitle(ctx, missing, "title"), storage.ErrNoteNotFound, "UpdateNoteTitle")
+	mustBeError(t, s.ArchiveNote(ctx, missing), storage.ErrNoteNotFound, "ArchiveNote")
+	mustBeError(t, s.UnarchiveNote(ctx, missing), storage.ErrNoteNotFound, "UnarchiveNote")
+	mustBeError(t, s.DeleteNote(ctx, missing), storage.ErrNoteNotFound, "DeleteNote")
+
+	_, err = s.AddNoteNode(ctx, missing, string(note.ContentTypeText), "text")
+	mustBeError(t, err, storage.ErrNoteNotFound, "AddNoteNode")
+
+	nodes, err := s.GetAllNotesNodes(ctx, missing)
+	mustNoError(t, err, "GetAllNotesNodes")
+	if len(nodes) != 0 {
+		t.Fatalf("GetAllNotesNodes: got %+v, want no nodes", nodes)
+	}
+}
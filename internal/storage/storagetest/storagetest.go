@@ -0,0 +1,86 @@
+// Package storagetest is the contract every storage backend follows. Backends
+// run it from their own tests, so they keep the same semantics as postgres
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"main/internal/router"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NewStorage returns storage the suite runs against. Storage may be shared
+// between calls, the suite uses unique emails and keys in every test
+type NewStorage func(t *testing.T) router.Storage
+
+// RunContract runs the suite against storage of newStorage
+func RunContract(t *testing.T, newStorage NewStorage) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, s router.Storage)
+	}{
+		{"Notes", testNotes},
+		{"NoteNotFound", testNoteNotFound},
+		{"NodeOrder", testNodeOrder},
+		{"NodeChangesBumpUpdatedAt", testNodeChangesBumpUpdatedAt},
+		{"NodeNotFound", testNodeNotFound},
+		{"NoteAccess", testNoteAccess},
+		{"Users", testUsers},
+		{"RefreshTokens", testRefreshTokens},
+		{"OneTimeTokens", testOneTimeTokens},
+		{"LoginAttempts", testLoginAttempts},
+		{"LoginAttemptReservation", testLoginAttemptReservation},
+		{"LoginFailures", testLoginFailures},
+		{"Workspaces", testWorkspaces},
+		{"WorkspaceInvitations", testWorkspaceInvitations},
+		{"DeleteWorkspaceOwner", testDeleteWorkspaceOwner},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newStorage(t))
+		})
+	}
+}
+
+// createUser creates user with unique email
+func createUser(t *testing.T, s router.Storage) (id, email string) {
+	t.Helper()
+
+	email = uniqueEmail()
+
+	id, err := s.CreateUser(context.Background(), email, "Test User", "hash")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	return id, email
+}
+
+func uniqueEmail() string {
+	return "user-" + uuid.NewString() + "@example.com"
+}
+
+// wait makes the following change happen at a later timestamp, stored times have microsecond precision
+func wait() {
+	time.Sleep(2 * time.Millisecond)
+}
+
+func mustNoError(t *testing.T, err error, what string) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatalf("%s: %v", what, err)
+	}
+}
+
+func mustBeError(t *testing.T, err, target error, what string) {
+	t.Helper()
+
+	if !errors.Is(err, target) {
+		t.Fatalf("%s: got error %v, want %v", what, err, target)
+	}
+}
@@ -0,0 +1,254 @@
+package storagetest
+
+import (
+	"context"
+	"main/internal/router"
+	"main/internal/storage"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func testUsers(t *testing.T, s router.Storage) {
+	ctx := context.Background()
+	id, email := createUser(t, s)
+
+	_, err := s.CreateUser(ctx, email, "Other", "hash")
+	mustBeError(t, err, storage.ErrUserAlreadyExists, "CreateUser with taken email")
+
+	byEmail, err := s.GetUser(ctx, email)
+	mustNoError(t, err, "GetUser")
+	if byEmail.ID != id || byEmail.PasswordHash != "hash" || byEmail.EmailVerifiedAt != nil {
+		t.Fatalf("GetUser: got %+v", byEmail)
+	}
+
+	_, err = s.GetUser(ctx, uniqueEmail())
+	mustBeError(t, err, storage.ErrUserNotFound, "GetUser of missing email")
+
+	_, err = s.GetUserById(ctx, uuid.NewString())
+	mustBeError(t, err, storage.ErrUserNotFound, "GetUserById of missing user")
+
+	mustNoError(t, s.SetEmailVerified(ctx, id), "SetEmailVerified")
+	mustNoError(t, s.UpdateUserPassword(ctx, id, "new hash"), "UpdateUserPassword")
+
+	byId, err := s.GetUserById(ctx, id)
+	mustNoError(t, err, "GetUserById")
+	if byId.EmailVerifiedAt == nil || byId.PasswordHash != "new hash" {
+		t.Fatalf("GetUserById: got %+v", byId)
+	}
+
+	// deleting user deletes the user's notes
+	noteId, err := s.CreateNote(ctx, "owned", id)
+	mustNoError(t, err, "CreateNote")
+
+	mustNoError(t, s.DeleteUser(ctx, id), "DeleteUser")
+
+	_, err = s.GetUserById(ctx, id)
+	mustBeError(t, err, storage.ErrUserNotFound, "GetUserById of deleted user")
+
+	_, err = s.GetNoteById(ctx, noteId)
+	mustBeError(t, err, storage.ErrNoteNotFound, "GetNoteById of deleted user's note")
+}
+
+func testRefreshTokens(t *testing.T, s router.Storage) {
+	ctx := context.Background()
+	userId, _ := createUser(t, s)
+
+	familyId := uuid.NewString()
+	firstId, secondId := uuid.NewString(), uuid.NewString()
+	expiresAt := time.Now().Add(time.Hour)
+
+	mustNoError(t, s.CreateRefreshToken(ctx, firstId, userId, familyId, "first", expiresAt), "CreateRefreshToken")
+	mustNoError(t, s.RotateRefreshToken(ctx, firstId, secondId, userId, familyId, "second", expiresAt), "RotateRefreshToken")
+
+	// rotated token is kept as used, so its reuse is detected
+	first, err := s.GetRefreshTokenById(ctx, firstId)
+	mustNoError(t, err, "GetRefreshTokenById")
+	if first.UsedAt == nil || first.ReplacedBy == nil || *first.ReplacedBy != secondId {
+		t.Fatalf("GetRefreshTokenById: got %+v, want token used and replaced by %s", first, secondId)
+	}
+
+	second, err := s.GetRefreshTokenById(ctx, secondId)
+	mustNoError(t, err, "GetRefreshTokenById")
+	if second.FamilyId != familyId || second.UsedAt != nil {
+		t.Fatalf("GetRefreshTokenById: got %+v", second)
+	}
+
+	revoked, err := s.RevokeRefreshTokenFamily(ctx, familyId)
+	mustNoError(t, err, "RevokeRefreshTokenFamily")
+	if revoked != 2 {
+		t.Fatalf("RevokeRefreshTokenFamily: revoked %d tokens, want 2", revoked)
+	}
+
+	_, err = s.GetRefreshTokenById(ctx, secondId)
+	mustBeError(t, err, storage.ErrRefreshTokenNotFound, "GetRefreshTokenById of revoked token")
+
+	err = s.RotateRefreshToken(ctx, secondId, uuid.NewString(), userId, familyId, "third", expiresAt)
+	mustBeError(t, err, storage.ErrRefreshTokenNotFound, "RotateRefreshToken of revoked token")
+}
+
+func testOneTimeTokens(t *testing.T, s router.Storage) {
+	ctx := context.Background()
+	userId, _ := createUser(t, s)
+
+	const purpose = "password_reset"
+	first, second := uuid.NewString(), uuid.NewString()
+
+	mustNoError(t, s.CreateOneTimeToken(ctx, userId, purpose, first, time.Now().Add(time.Hour)), "CreateOneTimeToken")
+
+	// issuing a new token invalidates the previous one
+	mustNoError(t, s.CreateOneTimeToken(ctx, userId, purpose, second, time.Now().Add(time.Hour)), "CreateOneTimeToken")
+
+	_, err := s.ConsumeOneTimeToken(ctx, purpose, first)
+	mustBeError(t, err, storage.ErrOneTimeTokenNotFound, "ConsumeOneTimeToken of replaced token")
+
+	_, err = s.ConsumeOneTimeToken(ctx, "email_verification", second)
+	mustBeError(t, err, storage.ErrOneTimeTokenNotFound, "ConsumeOneTimeToken with other purpose")
+
+	consumedBy, err := s.ConsumeOneTimeToken(ctx, purpose, second)
+	mustNoError(t, err, "ConsumeOneTimeToken")
+	if consumedBy != userId {
+		t.Fatalf("ConsumeOneTimeToken: got user %q, want %q", consumedBy, userId)
+	}
+
+	_, err = s.ConsumeOneTimeToken(ctx, purpose, second)
+	mustBeError(t, err, storage.ErrOneTimeTokenNotFound, "ConsumeOneTimeToken twice")
+
+	expired := uuid.NewString()
+	mustNoError(t, s.CreateOneTimeToken(ctx, userId, purpose, expired, time.Now().Add(-time.Minute)), "CreateOneTimeToken")
+
+	_, err = s.ConsumeOneTimeToken(ctx, purpose, expired)
+	mustBeError(t, err, storage.ErrOneTimeTokenNotFound, "ConsumeOneTimeToken of expired token")
+}
+
+func testLoginAttempts(t *testing.T, s router.Storage) {
+	ctx := context.Background()
+	key := "account:" + uniqueEmail()
+
+	_, err := s.GetLoginAttempt(ctx, key)
+	mustBeError(t, err, storage.ErrLoginAttemptNotFound, "GetLoginAttempt of new key")
+
+	for want := 1; want <= 3; want++ {
+		attempt, err := s.AddLoginFailure(ctx, key, time.Hour)
+		mustNoError(t, err, "AddLoginFailure")
+
+		if attempt.Failures != want {
+			t.Fatalf("AddLoginFailure: got %d failures, want %d", attempt.Failures, want)
+		}
+	}
+
+	lockedUntil := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	mustNoError(t, s.LockLoginKey(ctx, key, lockedUntil), "LockLoginKey")
+
+	attempt, err := s.GetLoginAttempt(ctx, key)
+	mustNoError(t, err, "GetLoginAttempt")
+	if attempt.Failures != 3 || attempt.LockedUntil == nil || !attempt.LockedUntil.Equal(lockedUntil) {
+		t.Fatalf("GetLoginAttempt: got %+v, want 3 failures locked until %v", attempt, lockedUntil)
+	}
+
+	mustNoError(t, s.DeleteLoginAttempt(ctx, key), "DeleteLoginAttempt")
+
+	_, err = s.GetLoginAttempt(ctx, key)
+	mustBeError(t, err, storage.ErrLoginAttemptNotFound, "GetLoginAttempt of deleted key")
+}
+
+func testLoginFailures(t *testing.T, s router.Storage) {
+	ctx := context.Background()
+	key := "ip:" + uuid.NewString()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	failures, err := s.GetLoginFailures(ctx, key, now.Add(-time.Hour))
+	mustNoError(t, err, "GetLoginFailures")
+	if len(failures) != 0 {
+		t.Fatalf("GetLoginFailures of new key: got %v", failures)
+	}
+
+	// recorded out of order, returned oldest first
+	for _, ago := range []time.Duration{10 * time.Minute, 2 * time.Hour, 30 * time.Minute} {
+		mustNoError(t, s.RecordLoginFailure(ctx, key, now.Add(-ago)), "RecordLoginFailure")
+	}
+
+	failures, err = s.GetLoginFailures(ctx, key, now.Add(-time.Hour))
+	mustNoError(t, err, "GetLoginFailures")
+	if len(failures) != 2 || !failures[0].Equal(now.Add(-30*time.Minute)) || !failures[1].Equal(now.Add(-10*time.Minute)) {
+		t.Fatalf("GetLoginFailures: got %v, want failures of 30 and 10 minutes ago", failures)
+	}
+
+	// failures out of window are deleted with stale attempts by the cleanup job
+	cleaner, ok := s.(interface {
+		DeleteStaleLoginAttempts(ctx context.Context, window time.Duration) (int, error)
+	})
+	if !ok {
+		t.Fatal("storage doesn't delete stale login attempts")
+	}
+
+	_, err = cleaner.DeleteStaleLoginAttempts(ctx, time.Hour)
+	mustNoError(t, err, "DeleteStaleLoginAttempts")
+
+	failures, err = s.GetLoginFailures(ctx, key, now.Add(-3*time.Hour))
+	mustNoError(t, err, "GetLoginFailures")
+	if len(failures) != 2 {
+		t.Fatalf("GetLoginFailures after stale are deleted: got %v, want 2 failures", failures)
+	}
+}
+
+func testLoginAttemptReservation(t *testing.T, s router.Storage) {
+	ctx := context.Background()
+	key := "ip:" + uuid.NewString()
+	until := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	reserved, ok, err := s.ReserveLoginAttempt(ctx, key, until)
+	mustNoError(t, err, "ReserveLoginAttempt")
+	if !ok || reserved.Failures != 0 || reserved.LockedUntil == nil || !reserved.LockedUntil.Equal(until) {
+		t.Fatalf("ReserveLoginAttempt of new key: got %+v, %v, want reservation until %v", reserved, ok, until)
+	}
+
+	// reserved key can't be reserved again until it is released
+	held, ok, err := s.ReserveLoginAttempt(ctx, key, until.Add(time.Hour))
+	mustNoError(t, err, "ReserveLoginAttempt")
+	if ok || held.LockedUntil == nil || !held.LockedUntil.Equal(until) {
+		t.Fatalf("ReserveLoginAttempt of reserved key: got %+v, %v, want it held until %v", held, ok, until)
+	}
+
+	mustNoError(t, s.LockLoginKey(ctx, key, time.Now().Add(-time.Second)), "LockLoginKey")
+
+	_, ok, err = s.ReserveLoginAttempt(ctx, key, until)
+	mustNoError(t, err, "ReserveLoginAttempt")
+	if !ok {
+		t.Fatal("ReserveLoginAttempt of released key: not reserved")
+	}
+
+	// reservation doesn't count as a failure
+	attempt, err := s.AddLoginFailure(ctx, key, time.Hour)
+	mustNoError(t, err, "AddLoginFailure")
+	if attempt.Failures != 1 {
+		t.Fatalf("AddLoginFailure after reservation: got %d failures, want 1", attempt.Failures)
+	}
+
+	// only one of concurrent reservations wins
+	mustNoError(t, s.LockLoginKey(ctx, key, time.Now().Add(-time.Second)), "LockLoginKey")
+
+	const concurrent = 10
+
+	var wg sync.WaitGroup
+	var won atomic.Int32
+
+	for range concurrent {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if _, ok, err := s.ReserveLoginAttempt(ctx, key, until); err == nil && ok {
+				won.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if won.Load() != 1 {
+		t.Fatalf("concurrent ReserveLoginAttempt: %d reservations won, want 1", won.Load())
+	}
+}
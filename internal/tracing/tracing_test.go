@@ -0,0 +1,40 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestStartRequest(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	ctx, server := Tracer().Start(t.Context(), "GET /notes")
+
+	r := httptest.NewRequest(http.MethodGet, "/notes", nil).WithContext(ctx)
+
+	r, span := StartRequest(r, "handler.note.get.New")
+	if trace.SpanFromContext(r.Context()).SpanContext().SpanID() != span.SpanContext().SpanID() {
+		t.Fatal("request context doesn't carry the handler span")
+	}
+	span.End()
+	server.End()
+
+	ended := recorder.Ended()
+	if len(ended) != 2 || ended[0].Name() != "handler.note.get.New" {
+		t.Fatalf("ended spans: got %d, want handler span first", len(ended))
+	}
+
+	if ended[0].Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Fatal("handler span isn't a child of the server span")
+	}
+}
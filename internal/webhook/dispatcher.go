@@ -0,0 +1,170 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"main/internal/config"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxDrainLen limits how much of the receiver response is read to reuse the connection
+const maxDrainLen = 4096
+
+type DeliveryStore interface {
+	// ClaimWebhookDeliveries locks due deliveries for lease, so other instances
+	// skip them and a crashed dispatcher doesn't lose them
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]PendingDelivery, error)
+	MarkWebhookDeliverySucceeded(ctx context.Context, id int64, responseStatus int) error
+	// MarkWebhookDeliveryFailed schedules next attempt at retryAt, nil retryAt fails delivery for good
+	MarkWebhookDeliveryFailed(ctx context.Context, id int64, responseStatus *int, lastError string, retryAt *time.Time) error
+}
+
+type Dispatcher struct {
+	store  DeliveryStore
+	client *http.Client
+	cfg    config.Webhooks
+	log    *slog.Logger
+}
+
+func NewDispatcher(store DeliveryStore, cfg config.Webhooks, log *slog.Logger) *Dispatcher {
+	return &Dispatcher{
+		store:  store,
+		client: NewClient(cfg.Timeout, cfg.AllowPrivateAddresses),
+		cfg:    cfg,
+		log:    log.With(slog.String("op", "webhook.Dispatcher")),
+	}
+}
+
+// Run polls the outbox until ctx is cancelled. Deliveries in flight are
+// finished, so none is left claimed until its lease expires
+func (d *Dispatcher) Run(ctx context.Context) {
+	d.log.Info("webhook dispatcher started")
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.log.Info("webhook dispatcher stopped", "reason", ctx.Err())
+			return
+		case <-ticker.C:
+			d.dispatch(context.WithoutCancel(ctx))
+		}
+	}
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context) {
+	// lease outlives the request, so delivery isn't sent twice at the same time
+	deliveries, err := d.store.ClaimWebhookDeliveries(ctx, d.cfg.BatchSize, 2*d.cfg.Timeout)
+	if err != nil {
+		d.log.Error("failed to claim webhook deliveries", "error", err)
+		return
+	}
+
+	for _, delivery := range deliveries {
+		d.deliver(ctx, delivery)
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery PendingDelivery) {
+	log := d.log.With(slog.Int64("delivery_id", delivery.Id), slog.String("event", delivery.Event))
+
+	status, err := d.send(ctx, delivery)
+	if err == nil {
+		if err := d.store.MarkWebhookDeliverySucceeded(ctx, delivery.Id, status); err != nil {
+			log.Error("failed to mark webhook delivery succeeded", "error", err)
+		}
+
+		log.Info("webhook delivered", slog.Int("status", status))
+
+		return
+	}
+
+	var responseStatus *int
+	if status != 0 {
+		responseStatus = &status
+	}
+
+	attempt := delivery.Attempts + 1
+
+	var retryAt *time.Time
+	if attempt < d.cfg.MaxAttempts {
+		t := time.Now().Add(Backoff(attempt, d.cfg.BackoffBase, d.cfg.BackoffMax))
+		retryAt = &t
+	}
+
+	if err := d.store.MarkWebhookDeliveryFailed(ctx, delivery.Id, responseStatus, lastError(status, err), retryAt); err != nil {
+		log.Error("failed to mark webhook delivery failed", "error", err)
+	}
+
+	log.Warn("webhook delivery failed", slog.Int("attempt", attempt), slog.Bool("will_retry", retryAt != nil), "error", err)
+}
+
+// send posts signed payload, any response other than 2xx is an error
+func (d *Dispatcher) send(ctx context.Context, delivery PendingDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "notes-webhooks")
+	req.Header.Set(HeaderDeliveryId, strconv.FormatInt(delivery.Id, 10))
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, now, delivery.Payload))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	// drain body so connection can be reused, it's never stored
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, maxDrainLen))
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
+
+// lastError is what the webhook owner sees in the delivery log. Only the status
+// or the kind of failure is kept, neither the response nor network errors are
+// shown, so a webhook can't be used to read internal responses or addresses
+func lastError(status int, err error) string {
+	var netErr net.Error
+
+	switch {
+	case status != 0:
+		return fmt.Sprintf("unexpected status %d", status)
+	case errors.Is(err, ErrForbiddenAddress):
+		return ErrForbiddenAddress.Error()
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "request timed out"
+	default:
+		return "request failed"
+	}
+}
+
+// Backoff doubles delay after every failed attempt, starting with base and capped by max
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	delay := base
+
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+
+	return min(delay, max)
+}
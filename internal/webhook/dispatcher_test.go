@@ -0,0 +1,296 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"io"
+	"log/slog"
+	"main/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// store is a DeliveryStore which hands out its deliveries whenever they are due
+type store struct {
+	mu         sync.Mutex
+	deliveries map[int64]*PendingDelivery
+	retryAt    map[int64]time.Time
+	results    []result
+}
+
+type result struct {
+	id        int64
+	succeeded bool
+	status    *int
+	lastError string
+	retryAt   *time.Time
+}
+
+func newStore(deliveries ...PendingDelivery) *store {
+	s := &store{deliveries: make(map[int64]*PendingDelivery), retryAt: make(map[int64]time.Time)}
+
+	for _, d := range deliveries {
+		s.deliveries[d.Id] = &d
+	}
+
+	return s
+}
+
+func (s *store) ClaimWebhookDeliveries(_ context.Context, limit int, _ time.Duration) ([]PendingDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []PendingDelivery
+	for id, d := range s.deliveries {
+		if len(due) < limit && !s.retryAt[id].After(time.Now()) {
+			due = append(due, *d)
+		}
+	}
+
+	return due, nil
+}
+
+func (s *store) MarkWebhookDeliverySucceeded(_ context.Context, id int64, responseStatus int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.deliveries, id)
+	s.results = append(s.results, result{id: id, succeeded: true, status: &responseStatus})
+
+	return nil
+}
+
+func (s *store) MarkWebhookDeliveryFailed(_ context.Context, id int64, responseStatus *int, lastError string, retryAt *time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.results = append(s.results, result{id: id, status: responseStatus, lastError: lastError, retryAt: retryAt})
+
+	if retryAt == nil {
+		delete(s.deliveries, id)
+		return nil
+	}
+
+	// next attempt is due right away, so tests don't wait for backoff
+	s.deliveries[id].Attempts++
+
+	return nil
+}
+
+func newDispatcher(s *store, cfg config.Webhooks) *Dispatcher {
+	return NewDispatcher(s, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+var testCfg = config.Webhooks{
+	Timeout:               time.Second,
+	BatchSize:             10,
+	MaxAttempts:           3,
+	BackoffBase:           time.Minute,
+	BackoffMax:            time.Hour,
+	AllowPrivateAddresses: true,
+}
+
+func TestDeliveryIsSigned(t *testing.T) {
+	payload := []byte(`{"event":"note.created"}`)
+	const secret = "whsec_test"
+
+	var got *http.Request
+	var body []byte
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer receiver.Close()
+
+	s := newStore(PendingDelivery{Id: 7, Event: EventNoteCreated, Payload: payload, URL: receiver.URL, Secret: secret})
+	newDispatcher(s, testCfg).dispatch(context.Background())
+
+	if len(s.results) != 1 || !s.results[0].succeeded || *s.results[0].status != http.StatusOK {
+		t.Fatalf("results: got %+v, want one succeeded delivery", s.results)
+	}
+
+	if got.Header.Get(HeaderDeliveryId) != "7" || got.Header.Get(HeaderEvent) != EventNoteCreated {
+		t.Fatalf("headers: got %v", got.Header)
+	}
+
+	unix, err := strconv.ParseInt(got.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		t.Fatalf("timestamp header: %v", err)
+	}
+
+	want := Sign(secret, time.Unix(unix, 0), body)
+	if !hmac.Equal([]byte(got.Header.Get(HeaderSignature)), []byte(want)) {
+		t.Fatalf("signature: got %q, want %q", got.Header.Get(HeaderSignature), want)
+	}
+
+	if string(body) != string(payload) {
+		t.Fatalf("body: got %s, want %s", body, payload)
+	}
+}
+
+func TestFailedDeliveryIsRetriedUntilMaxAttempts(t *testing.T) {
+	var calls int
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("internal secret"))
+	}))
+	defer receiver.Close()
+
+	s := newStore(PendingDelivery{Id: 1, Event: EventNoteCreated, Payload: []byte(`{}`), URL: receiver.URL, Secret: "s"})
+	d := newDispatcher(s, testCfg)
+
+	for range testCfg.MaxAttempts + 2 {
+		d.dispatch(context.Background())
+	}
+
+	if calls != testCfg.MaxAttempts {
+		t.Fatalf("receiver called %d times, want %d", calls, testCfg.MaxAttempts)
+	}
+
+	for i, r := range s.results {
+		attempt := i + 1
+
+		if r.status == nil || *r.status != http.StatusInternalServerError {
+			t.Fatalf("attempt %d: got status %v, want %d", attempt, r.status, http.StatusInternalServerError)
+		}
+
+		// response body is never stored
+		if r.lastError != "unexpected status 500" {
+			t.Fatalf("attempt %d: got last error %q", attempt, r.lastError)
+		}
+
+		if attempt == testCfg.MaxAttempts {
+			if r.retryAt != nil {
+				t.Fatalf("attempt %d: retry at %v, want delivery failed for good", attempt, r.retryAt)
+			}
+			continue
+		}
+
+		wantDelay := Backoff(attempt, testCfg.BackoffBase, testCfg.BackoffMax)
+		if r.retryAt == nil || time.Until(*r.retryAt) > wantDelay || time.Until(*r.retryAt) < wantDelay-time.Minute {
+			t.Fatalf("attempt %d: retry at %v, want in %v", attempt, r.retryAt, wantDelay)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{20, time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := Backoff(tt.attempt, 30*time.Second, time.Hour); got != tt.want {
+			t.Fatalf("Backoff(%d): got %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestPrivateAddressIsRefused(t *testing.T) {
+	var called bool
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer receiver.Close()
+
+	cfg := testCfg
+	cfg.AllowPrivateAddresses = false
+
+	s := newStore(PendingDelivery{Id: 1, Event: EventNoteCreated, Payload: []byte(`{}`), URL: receiver.URL, Secret: "s"})
+	newDispatcher(s, cfg).dispatch(context.Background())
+
+	if called {
+		t.Fatal("delivery reached loopback receiver")
+	}
+
+	if len(s.results) != 1 || s.results[0].lastError != ErrForbiddenAddress.Error() || s.results[0].status != nil {
+		t.Fatalf("results: got %+v, want delivery refused", s.results)
+	}
+}
+
+func TestRedirectIsNotFollowed(t *testing.T) {
+	var redirected bool
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected = true
+	}))
+	defer target.Close()
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer receiver.Close()
+
+	s := newStore(PendingDelivery{Id: 1, Event: EventNoteCreated, Payload: []byte(`{}`), URL: receiver.URL, Secret: "s"})
+	newDispatcher(s, testCfg).dispatch(context.Background())
+
+	if redirected {
+		t.Fatal("redirect was followed")
+	}
+
+	if len(s.results) != 1 || s.results[0].status == nil || *s.results[0].status != http.StatusTemporaryRedirect {
+		t.Fatalf("results: got %+v, want failed delivery with status %d", s.results, http.StatusTemporaryRedirect)
+	}
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fd00:ec2::254", false},
+		{"fe80::1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsPublicAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Fatalf("IsPublicAddr(%s): got %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want error
+	}{
+		{"http://127.0.0.1:8080/hook", ErrForbiddenAddress},
+		{"http://169.254.169.254/latest/meta-data", ErrForbiddenAddress},
+		{"http://[::1]/hook", ErrForbiddenAddress},
+		{"http://localhost/hook", ErrForbiddenAddress},
+		{"ftp://93.184.216.34/hook", ErrInvalidURL},
+		{"https://93.184.216.34/hook", nil},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateURL(context.Background(), tt.url, false); err != tt.want {
+			t.Fatalf("ValidateURL(%s): got %v, want %v", tt.url, err, tt.want)
+		}
+	}
+}
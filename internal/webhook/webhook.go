@@ -0,0 +1,179 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// secretPrefix makes signing secrets recognizable for secret scanners
+const secretPrefix = "whsec_"
+
+const (
+	EventNoteCreated    = "note.created"
+	EventNoteUpdated    = "note.updated"
+	EventNoteArchived   = "note.archived"
+	EventNoteUnarchived = "note.unarchived"
+	EventNoteDeleted    = "note.deleted"
+	EventNodeCreated    = "node.created"
+	EventNodeUpdated    = "node.updated"
+	EventNodeDeleted    = "node.deleted"
+)
+
+var AllEvents = []string{
+	EventNoteCreated,
+	EventNoteUpdated,
+	EventNoteArchived,
+	EventNoteUnarchived,
+	EventNoteDeleted,
+	EventNodeCreated,
+	EventNodeUpdated,
+	EventNodeDeleted,
+}
+
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// headers sent with every delivery
+const (
+	HeaderDeliveryId = "X-Webhook-Delivery"
+	HeaderEvent      = "X-Webhook-Event"
+	HeaderTimestamp  = "X-Webhook-Timestamp"
+	HeaderSignature  = "X-Webhook-Signature"
+)
+
+var ErrUnknownEvent = errors.New("unknown webhook event")
+
+type Webhook struct {
+	Id        string    `json:"id" db:"id"`
+	UserId    string    `json:"-" db:"user_id"`
+	URL       string    `json:"url" db:"url"`
+	Events    Events    `json:"events" db:"events"`
+	Secret    string    `json:"-" db:"secret"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Events are stored as a space separated string. Besides exact events
+// filter may contain wildcards for a whole group, like "node.*"
+type Events []string
+
+func (e Events) Value() (driver.Value, error) {
+	return strings.Join(e, " "), nil
+}
+
+func (e *Events) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		*e = strings.Fields(v)
+	case []byte:
+		*e = strings.Fields(string(v))
+	case nil:
+		*e = Events{}
+	default:
+		return fmt.Errorf("unsupported events type %T", src)
+	}
+
+	return nil
+}
+
+// ValidateEvents checks that every filter entry is a known event or a wildcard of a known group
+func ValidateEvents(events []string) error {
+	for _, event := range events {
+		if slices.Contains(AllEvents, event) {
+			continue
+		}
+
+		group, found := strings.CutSuffix(event, ".*")
+		if found && slices.ContainsFunc(AllEvents, func(e string) bool {
+			return strings.HasPrefix(e, group+".")
+		}) {
+			continue
+		}
+
+		return fmt.Errorf("%w: %s", ErrUnknownEvent, event)
+	}
+
+	return nil
+}
+
+// Delivery is a single attempt series of sending event to the webhook, it's
+// written to the outbox in the same transaction as the change it describes
+type Delivery struct {
+	Id             int64           `json:"id" db:"id"`
+	WebhookId      string          `json:"webhook_id" db:"webhook_id"`
+	Event          string          `json:"event" db:"event"`
+	Payload        json.RawMessage `json:"payload" db:"payload"`
+	Status         string          `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	ResponseStatus *int            `json:"response_status" db:"response_status"`
+	LastError      string          `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at" db:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at" db:"last_attempt_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at" db:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+}
+
+// PendingDelivery is a delivery claimed by dispatcher together with its endpoint
+type PendingDelivery struct {
+	Id       int64           `db:"id"`
+	Event    string          `db:"event"`
+	Payload  json.RawMessage `db:"payload"`
+	Attempts int             `db:"attempts"`
+	URL      string          `db:"url"`
+	Secret   string          `db:"secret"`
+}
+
+// Payload is the body of a delivery. It carries only ids, receivers fetch
+// the current state through the API, so stale data is never sent
+type Payload struct {
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       Data      `json:"data"`
+}
+
+type Data struct {
+	NoteId int `json:"note_id"`
+	NodeId int `json:"node_id,omitempty"`
+}
+
+func NewPayload(event string, data Data) ([]byte, error) {
+	return json.Marshal(Payload{
+		Event:      event,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	})
+}
+
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return secretPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Sign returns value of the signature header. Timestamp is signed together with
+// the body, so receivers can reject replayed deliveries
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
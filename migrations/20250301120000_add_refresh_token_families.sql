@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE refresh_tokens
+  ADD COLUMN family_id UUID NOT NULL DEFAULT gen_random_uuid(),
+  ADD COLUMN used_at TIMESTAMPTZ,
+  ADD COLUMN replaced_by UUID;
+
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_refresh_tokens_family_id;
+
+ALTER TABLE refresh_tokens
+  DROP COLUMN IF EXISTS replaced_by,
+  DROP COLUMN IF EXISTS used_at,
+  DROP COLUMN IF EXISTS family_id;
+-- +goose StatementEnd
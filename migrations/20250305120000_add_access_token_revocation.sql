@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE revoked_access_tokens (
+  jti UUID PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  expires_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX idx_revoked_access_tokens_expires_at ON revoked_access_tokens (expires_at);
+
+ALTER TABLE users
+  ADD COLUMN tokens_valid_after TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+  DROP COLUMN IF EXISTS tokens_valid_after;
+
+DROP INDEX IF EXISTS idx_revoked_access_tokens_expires_at;
+DROP TABLE IF EXISTS revoked_access_tokens;
+-- +goose StatementEnd
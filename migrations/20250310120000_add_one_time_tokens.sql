@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE one_time_tokens (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  purpose TEXT NOT NULL,
+  token_hash TEXT UNIQUE NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL,
+  used_at TIMESTAMPTZ
+);
+CREATE INDEX idx_one_time_tokens_user_id ON one_time_tokens (user_id);
+
+ALTER TABLE users
+  ADD COLUMN email_verified_at TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+  DROP COLUMN IF EXISTS email_verified_at;
+
+DROP INDEX IF EXISTS idx_one_time_tokens_user_id;
+DROP TABLE IF EXISTS one_time_tokens;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE passkeys (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  credential_id BYTEA UNIQUE NOT NULL,
+  public_key BYTEA NOT NULL,
+  attestation_type TEXT NOT NULL,
+  transports TEXT NOT NULL DEFAULT '',
+  aaguid BYTEA,
+  sign_count BIGINT NOT NULL DEFAULT 0,
+  backup_eligible BOOLEAN NOT NULL DEFAULT FALSE,
+  backup_state BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  last_used_at TIMESTAMPTZ
+);
+CREATE INDEX idx_passkeys_user_id ON passkeys (user_id);
+
+CREATE TABLE webauthn_sessions (
+  id TEXT PRIMARY KEY,
+  user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+  purpose TEXT NOT NULL,
+  data BYTEA NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS webauthn_sessions;
+DROP INDEX IF EXISTS idx_passkeys_user_id;
+DROP TABLE IF EXISTS passkeys;
+-- +goose StatementEnd
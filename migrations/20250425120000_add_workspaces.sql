@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE workspaces (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  name TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE workspace_members (
+  workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  role TEXT NOT NULL CHECK (role IN ('owner', 'admin', 'member', 'guest')),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (workspace_id, user_id)
+);
+CREATE INDEX idx_workspace_members_user_id ON workspace_members (user_id);
+
+CREATE TABLE workspace_invitations (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+  email TEXT NOT NULL,
+  role TEXT NOT NULL CHECK (role IN ('admin', 'member', 'guest')),
+  invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  expires_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (workspace_id, email)
+);
+CREATE INDEX idx_workspace_invitations_email ON workspace_invitations (email);
+
+-- note belongs either to a user or to a workspace
+ALTER TABLE notes
+  ALTER COLUMN user_id DROP NOT NULL,
+  ADD COLUMN workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE,
+  ADD CONSTRAINT notes_single_owner CHECK ((user_id IS NULL) <> (workspace_id IS NULL));
+CREATE INDEX idx_notes_workspace_id ON notes (workspace_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM notes WHERE workspace_id IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_notes_workspace_id;
+ALTER TABLE notes
+  DROP CONSTRAINT IF EXISTS notes_single_owner,
+  DROP COLUMN IF EXISTS workspace_id,
+  ALTER COLUMN user_id SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_workspace_invitations_email;
+DROP TABLE IF EXISTS workspace_invitations;
+DROP INDEX IF EXISTS idx_workspace_members_user_id;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
+-- +goose StatementEnd
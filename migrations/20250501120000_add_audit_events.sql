@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+-- actor_id has no foreign key, so events outlive deleted accounts
+CREATE TABLE audit_events (
+  id BIGSERIAL PRIMARY KEY,
+  occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  action TEXT NOT NULL,
+  actor_id UUID,
+  target_type TEXT NOT NULL DEFAULT '',
+  target_id TEXT NOT NULL DEFAULT '',
+  ip TEXT NOT NULL DEFAULT '',
+  user_agent TEXT NOT NULL DEFAULT '',
+  request_id TEXT NOT NULL DEFAULT '',
+  details JSONB NOT NULL DEFAULT '{}'
+);
+CREATE INDEX idx_audit_events_occurred_at ON audit_events (occurred_at);
+CREATE INDEX idx_audit_events_actor_id ON audit_events (actor_id, occurred_at);
+CREATE INDEX idx_audit_events_target ON audit_events (target_type, target_id, occurred_at);
+
+-- entries are append-only, only retention may delete them
+CREATE FUNCTION audit_events_reject_update() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit events are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only
+  BEFORE UPDATE ON audit_events
+  FOR EACH ROW EXECUTE FUNCTION audit_events_reject_update();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events;
+DROP FUNCTION IF EXISTS audit_events_reject_update();
+DROP INDEX IF EXISTS idx_audit_events_target;
+DROP INDEX IF EXISTS idx_audit_events_actor_id;
+DROP INDEX IF EXISTS idx_audit_events_occurred_at;
+DROP TABLE IF EXISTS audit_events;
+-- +goose StatementEnd
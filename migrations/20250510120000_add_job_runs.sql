@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- one row per scheduled run, unique key keeps replicas from running the same tick twice
+CREATE TABLE job_runs (
+  id BIGSERIAL PRIMARY KEY,
+  job TEXT NOT NULL,
+  scheduled_at TIMESTAMPTZ NOT NULL,
+  started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  finished_at TIMESTAMPTZ,
+  duration_ms BIGINT,
+  status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'succeeded', 'failed')),
+  attempts INT NOT NULL DEFAULT 0,
+  error TEXT NOT NULL DEFAULT '',
+  instance TEXT NOT NULL DEFAULT '',
+  UNIQUE (job, scheduled_at)
+);
+CREATE INDEX idx_job_runs_started_at ON job_runs (started_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_job_runs_started_at;
+DROP TABLE IF EXISTS job_runs;
+-- +goose StatementEnd
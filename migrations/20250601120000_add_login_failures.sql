@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- failures of ip keys one per row, they are counted in a sliding window
+CREATE TABLE login_failures (
+  id BIGSERIAL PRIMARY KEY,
+  key TEXT NOT NULL,
+  failed_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX idx_login_failures_key_failed_at ON login_failures (key, failed_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_login_failures_key_failed_at;
+DROP TABLE IF EXISTS login_failures;
+-- +goose StatementEnd
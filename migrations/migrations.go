@@ -0,0 +1,24 @@
+// Package migrations embeds SQL migrations, so the binary applies them
+// without the source tree
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed *.sql sqlite/*.sql
+var files embed.FS
+
+// Postgres returns migrations of postgres storage
+func Postgres() fs.FS {
+	return files
+}
+
+// SQLite returns migrations of sqlite storage, they are kept apart as postgres ones don't run on it
+func SQLite() fs.FS {
+	// sub of an existing directory never fails
+	sub, _ := fs.Sub(files, "sqlite")
+
+	return sub
+}